@@ -0,0 +1,204 @@
+// Package analyzer provides a static analysis pass that checks structured fields
+// passed to erro constructors: an odd number of field arguments, non-string keys
+// and reserved keys. These mistakes are caught at CI time instead of producing
+// MissingFieldPlaceholder values at runtime.
+//
+// It is a separate module so the erro package itself stays free of dependencies.
+// Run it with the errocheck command or plug [Analyzer] into a multichecker.
+package analyzer
+
+import (
+	"go/ast"
+	"go/constant"
+	"go/types"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/inspect"
+	"golang.org/x/tools/go/ast/inspector"
+)
+
+// erroPath is the import path of the checked package.
+const erroPath = "github.com/maxbolgarin/erro"
+
+// Analyzer reports misuse of key-value fields in calls to erro.New, erro.Wrap and similar functions.
+var Analyzer = &analysis.Analyzer{
+	Name:     "errocheck",
+	Doc:      "check key-value fields passed to erro constructors",
+	Run:      run,
+	Requires: []*analysis.Analyzer{inspect.Analyzer},
+}
+
+// reservedKeys are field keys set by erro itself.
+var reservedKeys = map[string]bool{
+	"wrap_depth_exceeded": true,
+	"handoff_goroutine":   true,
+	"handoff_site":        true,
+	"receive_goroutine":   true,
+	"receive_site":        true,
+}
+
+// reservedPrefix is the default prefix of metadata keys in log fields.
+const reservedPrefix = "error_"
+
+// optionTypes are erro types that are passed among fields but are not fields.
+var optionTypes = map[string]bool{
+	"ErrorClass":    true,
+	"ErrorCategory": true,
+	"ErrorSeverity": true,
+	"errorOpt":      true,
+	"errorWork":     true,
+	"errorFields":   true,
+	"spanWork":      true,
+}
+
+// call describes the signature of a checked function: the index of the message
+// argument and of the first field argument.
+type call struct {
+	message int
+	fields  int
+}
+
+// funcs are checked package-level functions.
+var funcs = map[string]call{
+	"New":   {message: 0, fields: 1},
+	"Wrap":  {message: 1, fields: 2},
+	"Wrap2": {message: 2, fields: 3},
+	"Wrap3": {message: 3, fields: 4},
+}
+
+// methods are checked methods of collections.
+var methods = map[string]call{
+	"New":  {message: 0, fields: 1},
+	"Wrap": {message: 1, fields: 2},
+}
+
+func run(pass *analysis.Pass) (any, error) {
+	insp := pass.ResultOf[inspect.Analyzer].(*inspector.Inspector)
+	insp.Preorder([]ast.Node{(*ast.CallExpr)(nil)}, func(n ast.Node) {
+		expr := n.(*ast.CallExpr)
+		c, ok := checkedCall(pass, expr)
+		if !ok || expr.Ellipsis.IsValid() || len(expr.Args) < c.fields {
+			return
+		}
+		checkFields(pass, expr, c)
+	})
+	return nil, nil
+}
+
+func checkedCall(pass *analysis.Pass, expr *ast.CallExpr) (call, bool) {
+	var ident *ast.Ident
+	switch fun := expr.Fun.(type) {
+	case *ast.SelectorExpr:
+		ident = fun.Sel
+	case *ast.IndexExpr:
+		if sel, ok := fun.X.(*ast.SelectorExpr); ok {
+			ident = sel.Sel
+		}
+	case *ast.IndexListExpr:
+		if sel, ok := fun.X.(*ast.SelectorExpr); ok {
+			ident = sel.Sel
+		}
+	case *ast.Ident:
+		ident = fun
+	}
+	if ident == nil {
+		return call{}, false
+	}
+	fn, ok := pass.TypesInfo.Uses[ident].(*types.Func)
+	if !ok || fn.Pkg() == nil || fn.Pkg().Path() != erroPath {
+		return call{}, false
+	}
+	sig, ok := fn.Type().(*types.Signature)
+	if !ok || !sig.Variadic() {
+		return call{}, false
+	}
+	if sig.Recv() != nil {
+		c, ok := methods[fn.Name()]
+		return c, ok && isCollection(sig.Recv().Type())
+	}
+	c, ok := funcs[fn.Name()]
+	return c, ok
+}
+
+func isCollection(t types.Type) bool {
+	if p, ok := t.(*types.Pointer); ok {
+		t = p.Elem()
+	}
+	named, ok := t.(*types.Named)
+	if !ok {
+		return false
+	}
+	switch named.Obj().Name() {
+	case "List", "Set", "SafeList", "SafeSet":
+		return true
+	}
+	return false
+}
+
+func checkFields(pass *analysis.Pass, expr *ast.CallExpr, c call) {
+	args := expr.Args[c.fields:]
+
+	// Format verbs in a constant message consume the first arguments
+	if tv, ok := pass.TypesInfo.Types[expr.Args[c.message]]; ok && tv.Value != nil && tv.Value.Kind() == constant.String {
+		verbs := countVerbs(constant.StringVal(tv.Value))
+		if verbs > len(args) {
+			verbs = len(args)
+		}
+		args = args[verbs:]
+	}
+
+	fields := make([]ast.Expr, 0, len(args))
+	for _, arg := range args {
+		if !isOption(pass.TypesInfo.TypeOf(arg)) {
+			fields = append(fields, arg)
+		}
+	}
+
+	for i := 0; i < len(fields); i += 2 {
+		key := fields[i]
+		t := pass.TypesInfo.TypeOf(key)
+		if t == nil {
+			continue
+		}
+		if basic, ok := t.Underlying().(*types.Basic); !ok || basic.Info()&types.IsString == 0 {
+			pass.Reportf(key.Pos(), "field key must be a string, got %s", t)
+			continue
+		}
+		if tv := pass.TypesInfo.Types[key]; tv.Value != nil && tv.Value.Kind() == constant.String {
+			name := constant.StringVal(tv.Value)
+			if reservedKeys[name] || strings.HasPrefix(name, reservedPrefix) {
+				pass.Reportf(key.Pos(), "field key %q is reserved by erro", name)
+			}
+		}
+	}
+	if len(fields)%2 != 0 {
+		pass.Reportf(fields[len(fields)-1].Pos(), "odd number of field arguments: key without a value")
+	}
+}
+
+func isOption(t types.Type) bool {
+	if t == nil {
+		return false
+	}
+	named, ok := t.(*types.Named)
+	if !ok || named.Obj().Pkg() == nil || named.Obj().Pkg().Path() != erroPath {
+		return false
+	}
+	return optionTypes[named.Obj().Name()]
+}
+
+// countVerbs counts format verbs the same way erro.ApplyFormatVerbs does.
+func countVerbs(s string) int {
+	count := 0
+	for i := 0; i < len(s); i++ {
+		if s[i] != '%' || i+1 >= len(s) {
+			continue
+		}
+		if s[i+1] != '%' {
+			count++
+		}
+		i++
+	}
+	return count
+}
@@ -0,0 +1,26 @@
+package analyzer
+
+import (
+	"testing"
+
+	"golang.org/x/tools/go/analysis/analysistest"
+)
+
+func TestAnalyzer(t *testing.T) {
+	analysistest.Run(t, analysistest.TestData(), Analyzer, "a")
+}
+
+func TestCountVerbs(t *testing.T) {
+	tests := map[string]int{
+		"no verbs":        0,
+		"user %s":         1,
+		"%d%% of %v":      2,
+		"trailing %":      0,
+		"%s and %s and %": 2,
+	}
+	for s, expected := range tests {
+		if got := countVerbs(s); got != expected {
+			t.Errorf("countVerbs(%q) = %d, expected %d", s, got, expected)
+		}
+	}
+}
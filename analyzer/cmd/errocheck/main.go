@@ -0,0 +1,17 @@
+// Command errocheck checks key-value fields passed to erro constructors.
+//
+// Usage:
+//
+//	go install github.com/maxbolgarin/erro/analyzer/cmd/errocheck@latest
+//	errocheck ./...
+package main
+
+import (
+	"golang.org/x/tools/go/analysis/singlechecker"
+
+	"github.com/maxbolgarin/erro/analyzer"
+)
+
+func main() {
+	singlechecker.Main(analyzer.Analyzer)
+}
@@ -0,0 +1,22 @@
+package a
+
+import "github.com/maxbolgarin/erro"
+
+type key string
+
+func examples(err error, fields []any) {
+	_ = erro.New("ok", "key", "value", erro.ClassValidation, erro.Retryable())
+	_ = erro.New("ok", erro.Retryable(), "key", 1, erro.Fields("a"))
+	_ = erro.New("user %s not found", "bob", "key", "value")
+	_ = erro.New("variadic", fields...)
+	_ = erro.New("typed key", key("k"), 1)
+
+	_ = erro.New("odd", "key", "value", "dangling")       // want `odd number of field arguments`
+	_ = erro.Wrap(err, "bad key", 42, "value")            // want `field key must be a string, got int`
+	_ = erro.New("reserved", "error_id", "x")             // want `field key "error_id" is reserved by erro`
+	_ = erro.New("reserved", "wrap_depth_exceeded", true) // want `field key "wrap_depth_exceeded" is reserved by erro`
+	_, _ = erro.Wrap2(1, err, "generic", "key")           // want `odd number of field arguments`
+
+	var l erro.List
+	l.New("method", "key") // want `odd number of field arguments`
+}
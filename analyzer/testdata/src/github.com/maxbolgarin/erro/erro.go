@@ -0,0 +1,22 @@
+package erro
+
+type (
+	ErrorClass  string
+	errorOpt    func()
+	errorFields func() []any
+)
+
+const ClassValidation ErrorClass = "validation"
+
+type Error interface{ error }
+
+type List struct{}
+
+func New(message string, fields ...any) Error             { return nil }
+func Wrap(err error, message string, fields ...any) Error { return nil }
+func Wrap2[T any](v T, err error, message string, fields ...any) (T, error) {
+	return v, nil
+}
+func Retryable() errorOpt                               { return nil }
+func Fields(fields ...any) errorFields                  { return nil }
+func (l *List) New(message string, fields ...any) *List { return l }
@@ -0,0 +1,75 @@
+package erro
+
+// Defaults of the limits of [Attachment], see [WithAttachmentLimits].
+const (
+	DefaultMaxAttachmentSize  = 64 << 10
+	DefaultMaxAttachmentsSize = 256 << 10
+)
+
+// Blob is a payload attached to an error with [Attachment].
+// Data is encoded with base64 only when the error is serialized.
+type Blob struct {
+	Name        string `json:"name" bson:"name" db:"name"`
+	ContentType string `json:"content_type,omitempty" bson:"content_type,omitempty" db:"content_type,omitempty"`
+	Size        int    `json:"size" bson:"size" db:"size"`
+	Data        []byte `json:"data,omitempty" bson:"data,omitempty" db:"data,omitempty"`
+	// Dropped is true if the payload exceeded the limits and Data is empty.
+	Dropped bool `json:"dropped,omitempty" bson:"dropped,omitempty" db:"dropped,omitempty"`
+}
+
+// Attachment attaches a small payload for debugging, e.g. a request snapshot or a proto
+// dump. The data is copied. A payload larger than the per-attachment limit, or one that
+// makes the attachments of the error exceed the per-error limit, is dropped: the error
+// keeps only its name, content type and size, see [WithAttachmentLimits].
+//
+// Attachments are not part of Error() and log fields. Get them with [Attachments] in an
+// [EventDispatcher], or serialize them with [WithAttachments].
+//
+// Example:
+//
+//	err := erro.Wrap(err, "decode event", erro.Attachment("event.pb", raw, "application/x-protobuf"))
+func Attachment(name string, data []byte, contentType string) errorOpt {
+	return func(e *baseError) {
+		e.attach(Blob{Name: name, ContentType: contentType, Size: len(data), Data: data})
+	}
+}
+
+// Attachments returns the attachments of the error chain, from the outermost error.
+func Attachments(err error) []Blob {
+	var e *baseError
+	if !As(err, &e) {
+		return nil
+	}
+	var out []Blob
+	for w := e; w != nil; w = w.wrappedErr {
+		out = append(out, w.ext().attachments...)
+	}
+	return out
+}
+
+// attach adds a copy of the blob to the own attachments within the limits.
+func (e *baseError) attach(b Blob) {
+	total := 0
+	for _, a := range e.ext().attachments {
+		total += len(a.Data)
+	}
+	if b.Dropped || len(b.Data) > attachmentLimit(&maxAttachmentSize, DefaultMaxAttachmentSize) ||
+		total+len(b.Data) > attachmentLimit(&maxAttachmentsSize, DefaultMaxAttachmentsSize) {
+		b.Data, b.Dropped = nil, true
+	} else {
+		b.Data = append([]byte(nil), b.Data...)
+	}
+	if len(b.Name) > MaxKeyLength {
+		b.Name = truncateString(b.Name, MaxKeyLength)
+	}
+	x := e.setExt()
+	x.attachments = append(x.attachments, b)
+}
+
+// attachmentLimit returns the configured limit or the default.
+func attachmentLimit(limit *atomicValue[int], def int) int {
+	if n := limit.Load(); n > 0 {
+		return n
+	}
+	return def
+}
@@ -0,0 +1,85 @@
+package erro
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestAttachment(t *testing.T) {
+	data := []byte(`{"order":42}`)
+	err := New("decode order", Attachment("request.json", data, "application/json"))
+	data[0] = 'X'
+	wrapped := Wrap(err, "handle", Attachment("trace.txt", []byte("trace"), "text/plain"))
+
+	blobs := Attachments(wrapped)
+	if len(blobs) != 2 || blobs[0].Name != "trace.txt" || blobs[1].Name != "request.json" {
+		t.Fatalf("unexpected attachments %+v", blobs)
+	}
+	if string(blobs[1].Data) != `{"order":42}` || blobs[1].Size != 12 || blobs[1].ContentType != "application/json" {
+		t.Errorf("expected a copy of the data, got %+v", blobs[1])
+	}
+	if strings.Contains(wrapped.Error(), "42") {
+		t.Errorf("attachments must not be in the message, got %q", wrapped.Error())
+	}
+	if Attachments(New("no attachments")) != nil || Attachments(nil) != nil {
+		t.Error("expected no attachments")
+	}
+	if len(Attachments(wrapped.Redacted())) != 0 {
+		t.Error("Redacted must drop attachments")
+	}
+
+	// Serialized only with the option, base64 encoded
+	if ErrorToJSON(wrapped).Attachments != nil {
+		t.Error("attachments must be serialized only with WithAttachments")
+	}
+	out, mErr := json.Marshal(ErrorToJSON(err, WithAttachments()))
+	if mErr != nil {
+		t.Fatal(mErr)
+	}
+	if !bytes.Contains(out, []byte(`"attachments":[{"name":"request.json","content_type":"application/json","size":12,"data":"eyJvcmRlciI6NDJ9"}]`)) {
+		t.Errorf("unexpected JSON %s", out)
+	}
+	decoded, dErr := FromJSON(out)
+	if dErr != nil {
+		t.Fatal(dErr)
+	}
+	if blobs := Attachments(decoded); len(blobs) != 1 || string(blobs[0].Data) != `{"order":42}` {
+		t.Errorf("unexpected decoded attachments %+v", blobs)
+	}
+}
+
+func TestAttachmentLimits(t *testing.T) {
+	defer Configure(WithAttachmentLimits(0, 0))
+	Configure(WithAttachmentLimits(4, 6))
+
+	err := New("limits",
+		Attachment("small", []byte("abc"), ""),
+		Attachment("large", []byte("abcde"), "text/plain"),
+		Attachment("fits", []byte("abc"), ""),
+		Attachment("over total", []byte("a"), ""),
+	)
+	blobs := Attachments(err)
+	if len(blobs) != 4 {
+		t.Fatalf("expected 4 attachments, got %+v", blobs)
+	}
+	for i, dropped := range []bool{false, true, false, true} {
+		if blobs[i].Dropped != dropped || (len(blobs[i].Data) == 0) != dropped {
+			t.Errorf("%s: expected dropped %v, got %+v", blobs[i].Name, dropped, blobs[i])
+		}
+	}
+	if blobs[1].Size != 5 || blobs[1].ContentType != "text/plain" {
+		t.Errorf("a dropped attachment must keep its metadata, got %+v", blobs[1])
+	}
+
+	// Limits apply to decoded errors too
+	out, _ := json.Marshal(ErrorSchema{ID: "x", Message: "decoded", Attachments: []Blob{{Name: "big", Size: 10, Data: []byte("0123456789")}}})
+	decoded, dErr := FromJSON(out)
+	if dErr != nil {
+		t.Fatal(dErr)
+	}
+	if blobs := Attachments(decoded); len(blobs) != 1 || !blobs[0].Dropped {
+		t.Errorf("expected the decoded attachment to be dropped, got %+v", blobs)
+	}
+}
@@ -3,10 +3,16 @@ package erro
 import (
 	"encoding/json"
 	"fmt"
+	"strings"
+	"sync/atomic"
 	"time"
 )
 
 // baseError holds the root error with all context and metadata.
+//
+// Every error of a chain is a baseError, so its size is the cost of creating and
+// wrapping errors: metadata that is rarely set lives in [errorExtras], and the
+// flags are grouped at the end to avoid padding.
 type baseError struct {
 	// Core error info
 	originalErr error               // Original error if wrapping external error
@@ -15,20 +21,84 @@ type baseError struct {
 	fullMessage atomicValue[string] // Full message with fields (caching)
 
 	// Metadata
-	id        string        // Error id
-	class     ErrorClass    // Error class
-	category  ErrorCategory // Error category
-	severity  ErrorSeverity // Error severity
-	retryable bool          // Retryable flag
-	fields    []any         // Key-value fields
-	span      TraceSpan     // Span
-	created   time.Time     // Creation timestamp
+	id          string        // Error id
+	class       ErrorClass    // Error class
+	category    ErrorCategory // Error category
+	severity    ErrorSeverity // Error severity
+	attempt     int           // Retry attempt number
+	maxAttempts int           // Maximum number of retry attempts
+	fields      []any         // Key-value fields
+	span        TraceSpan     // Span
+	created     time.Time     // Creation timestamp
 
 	stack  rawStack           // Stack trace (program counters only - resolved on demand)
 	frames atomicValue[Stack] // Stack trace frames (for caching)
 
-	formatter        FormatErrorFunc
+	formatter FormatErrorFunc
+	template  *ErrorTemplate // Template the error was created from
+	extras    *errorExtras   // Rarely set metadata, nil if none is set
+
+	depth  int   // Number of wrapped erro layers
+	frozen int32 // Non-zero after [Freeze], accessed atomically
+
+	interpolate      bool  // Message has {key} placeholders, see [Interpolate]
+	retryable        bool  // Retryable flag
+	wholeChainFormat bool  // Formatter renders the whole chain, see [StrictLogfmt]
+	truncated        bool  // Layers were dropped because of [MaxWrapDepth]
+	intercepting     bool  // Interceptors are running on the error or are skipped, see [SkipInterceptors]
+	fieldErrors      bool  // Fields are the field errors of [ValidationErrors], kept by [Sanitize]
+	autoDone         uint8 // Chain was recorded by [WithAutoMetrics] and [WithAutoEvents]
+}
+
+// errorExtras holds the metadata of a [baseError] that most errors do not have.
+// It is allocated by the first option setting one of the fields and is not changed
+// after the error is created.
+type errorExtras struct {
+	format      string        // Message before format verbs were applied, see [Fingerprint]
+	hint        string        // Remediation guidance, see [Hint]
+	userMessage string        // Message safe to show to end users, see [UserMsg]
+	docURL      string        // Documentation link, see [DocURL]
+	retryAfter  time.Duration // Delay before the next retry
+	ttl         time.Duration // Freshness of the error, see [TTL]
+
 	stackTraceConfig *StackTraceConfig
+	callerSkip       int // Frames of the callers skipped in stack traces, see [WrapSkip]
+	compressLayers   int // Maximum number of layers in Error(), see [CompressChain]
+
+	handoff     *handoffInfo // Hand-off call site, see [Handoff]
+	goroutines  []byte       // Dump of all goroutines, see [AllGoroutines]
+	attachments []Blob       // Debug payloads, see [Attachment]
+}
+
+// noExtras is returned by [baseError.ext] for errors without extras. It must not be modified.
+var noExtras errorExtras
+
+// ext returns the extras of the error for reading. It is never nil.
+func (e *baseError) ext() *errorExtras {
+	if e.extras != nil {
+		return e.extras
+	}
+	return &noExtras
+}
+
+// setExt returns the extras of the error for writing, allocating them on first use.
+// It must only be called while the error is being created.
+func (e *baseError) setExt() *errorExtras {
+	if e.extras == nil {
+		e.extras = &errorExtras{}
+	}
+	return e.extras
+}
+
+// setExtras sets the metadata extras of the error, allocating them only
+// if one of the values is set.
+func (e *baseError) setExtras(retryAfter, ttl time.Duration, hint, userMessage, docURL string) {
+	if retryAfter == 0 && ttl == 0 && hint == "" && userMessage == "" && docURL == "" {
+		return
+	}
+	x := e.setExt()
+	x.retryAfter, x.ttl = retryAfter, ttl
+	x.hint, x.userMessage, x.docURL = hint, userMessage, docURL
 }
 
 // Error implements the error interface.
@@ -45,23 +115,9 @@ func (e *baseError) Error() (out string) {
 		}
 	}()
 
-	if formatter := e.Formatter(); formatter != nil {
-		out = formatter(e)
-	}
-	if out == "" {
-		out = FormatErrorMessage(e)
-	}
-	if unwrapped := e.Unwrap(); unwrapped != nil {
-		unwrappedMsg := unwrapped.Error()
-		if unwrappedMsg == "" {
-			return out
-		}
-		if out == "" {
-			return unwrappedMsg
-		}
-		return out + ": " + unwrappedMsg
-	}
-	return out
+	var b strings.Builder
+	writeErrorChain(&chainWriter{w: &b}, e, false)
+	return validUTF8(b.String())
 }
 
 // Format implements [fmt.Formatter] for stack trace printing.
@@ -83,7 +139,9 @@ func (e *baseError) Unwrap() error {
 // matching mechanism for [Error] types is the unique error ID.
 //
 // It checks if the target is also an [Error] type and compares their IDs.
-// If both have a non-empty ID and they match, it returns true.
+// If both have a non-empty ID and they match, it returns true. If the target has
+// no ID, it matches by class, category, severity and retryable flag unless
+// [WithIsMode] sets [IsIdentity], see [IsStrict].
 //
 // In all other cases, it returns false, delegating the decision to the
 // [errors.Is] function, which will then check the wrapped standard error
@@ -109,6 +167,9 @@ func (e *baseError) Is(target error) bool {
 	if eID != "" && targetID != "" {
 		return eID == targetID
 	}
+	if isMode.Load() == IsIdentity {
+		return false
+	}
 
 	// 2. Compare by Class if the target's class is specified.
 	// This allows for checking against error "types" or templates.
@@ -150,27 +211,131 @@ func (e *baseError) MarshalJSON() ([]byte, error) {
 }
 
 // UnmarshalJSON implements the [json.Unmarshaler] interface.
+//
+// It replaces the content of the error, so it must not be called on an error that
+// is shared with other goroutines. It returns [ErrFrozen] if the error is frozen.
 func (e *baseError) UnmarshalJSON(data []byte) error {
+	if e.IsFrozen() {
+		return ErrFrozen
+	}
 	var schema ErrorSchema
 	if err := json.Unmarshal(data, &schema); err != nil {
 		return err
 	}
-	e.originalErr = nil
-	e.message = schema.Message
-	e.created = schema.Created
-	e.span = nil
-	e.stack = nil
-	e.frames = atomicValue[Stack]{}
-	e.formatter = FormatErrorWithFields
-	e.id = schema.ID
-	e.class = schema.Class
-	e.category = schema.Category
-	e.severity = schema.Severity
-	e.retryable = schema.Retryable
-	e.fields = schema.Fields
+	// Start from a fresh error so no state of the previous content survives:
+	// wrapped errors, caches, stack, template or interceptor flags.
+	*e = baseError{
+		message:     schema.Message,
+		id:          schema.ID,
+		class:       schema.Class,
+		category:    schema.Category,
+		severity:    schema.Severity,
+		retryable:   schema.Retryable,
+		attempt:     schema.Attempt,
+		maxAttempts: schema.MaxAttempts,
+		fields:      fromKeyValueFields(schema.Fields),
+		created:     schema.Created,
+		formatter:   FormatErrorWithFields,
+	}
+	e.setExtras(schema.RetryAfter, schema.TTL, schema.Hint, schema.UserMessage, schema.DocURL)
+	for _, b := range schema.Attachments {
+		e.attach(b)
+	}
 	return nil
 }
 
+// TruncatedChain returns true if layers of the error chain were dropped because
+// wrapping exceeded [MaxWrapDepth].
+func TruncatedChain(err error) bool {
+	var e *baseError
+	if !As(err, &e) {
+		return false
+	}
+	for w := e; w != nil; w = w.wrappedErr {
+		if w.truncated {
+			return true
+		}
+	}
+	return false
+}
+
+// Freeze makes the error immutable: any further attempt to modify it in place,
+// such as json.Unmarshal into it, fails with [ErrFrozen]. It returns the error itself.
+func (e *baseError) Freeze() Error {
+	atomic.StoreInt32(&e.frozen, 1)
+	return e
+}
+
+// IsFrozen returns true if the error was frozen with [Freeze].
+func (e *baseError) IsFrozen() bool {
+	return atomic.LoadInt32(&e.frozen) != 0
+}
+
+// Freeze makes err immutable: any further attempt to modify it in place, such as
+// json.Unmarshal into it, fails with [ErrFrozen]. Errors created outside this package
+// are converted with [ExtractError] first. It returns the frozen error or nil if err is nil.
+func Freeze(err error) Error {
+	e := ExtractError(err)
+	if b, ok := e.(*baseError); ok {
+		b.Freeze()
+	}
+	return e
+}
+
+// IsFrozen returns true if the outermost error of this package in err's chain was frozen
+// with [Freeze].
+func IsFrozen(err error) bool {
+	var e interface{ IsFrozen() bool }
+	if !As(err, &e) {
+		return false
+	}
+	return e.IsFrozen()
+}
+
+// copyLayer returns a copy of the outermost layer sharing the wrapped errors.
+// The copy is not frozen, is not being intercepted and has an empty message cache;
+// TestCopyLayerCopiesAllFields checks that every other field is copied.
+func (e *baseError) copyLayer() *baseError {
+	c := &baseError{
+		originalErr:      e.originalErr,
+		wrappedErr:       e.wrappedErr,
+		message:          e.message,
+		interpolate:      e.interpolate,
+		id:               e.id,
+		class:            e.class,
+		category:         e.category,
+		severity:         e.severity,
+		retryable:        e.retryable,
+		attempt:          e.attempt,
+		maxAttempts:      e.maxAttempts,
+		span:             e.span,
+		created:          e.created,
+		stack:            e.stack,
+		formatter:        e.formatter,
+		wholeChainFormat: e.wholeChainFormat,
+		fieldErrors:      e.fieldErrors,
+		depth:            e.depth,
+		truncated:        e.truncated,
+		template:         e.template,
+		autoDone:         e.autoDone,
+	}
+	if len(e.fields) > 0 {
+		c.fields = append([]any(nil), e.fields...)
+	}
+	if e.extras != nil {
+		// Copied, so options applied to the copy, e.g. by [Enrich], keep the original
+		extras := *e.extras
+		if len(extras.attachments) > 0 {
+			extras.attachments = append([]Blob(nil), extras.attachments...)
+		}
+		c.extras = &extras
+	}
+	if frames := e.frames.Load(); frames != nil {
+		c.frames.Store(frames)
+	}
+	return c
+}
+
 // ID returns the error's identifier.
 func (e *baseError) ID() string {
 	if e.id == "" && e.wrappedErr != nil {
@@ -211,6 +376,85 @@ func (e *baseError) IsRetryable() bool {
 	return e.retryable
 }
 
+// Attempt returns the retry attempt number, or 0 if it is not set.
+func (e *baseError) Attempt() int {
+	if e.attempt == 0 && e.wrappedErr != nil {
+		return e.wrappedErr.Attempt()
+	}
+	return e.attempt
+}
+
+// MaxAttempts returns the maximum number of retry attempts, or 0 if it is not set.
+func (e *baseError) MaxAttempts() int {
+	if e.maxAttempts == 0 && e.wrappedErr != nil {
+		return e.wrappedErr.MaxAttempts()
+	}
+	return e.maxAttempts
+}
+
+// RetryAfter returns the delay before the next retry and whether it is set.
+func (e *baseError) RetryAfter() (time.Duration, bool) {
+	retryAfter := e.ext().retryAfter
+	if retryAfter == 0 && e.wrappedErr != nil {
+		return e.wrappedErr.RetryAfter()
+	}
+	return retryAfter, retryAfter > 0
+}
+
+// TTL returns the time the error stays fresh after its creation and whether it is set.
+func (e *baseError) TTL() (time.Duration, bool) {
+	ttl := e.ext().ttl
+	if ttl == 0 && e.wrappedErr != nil {
+		return e.wrappedErr.TTL()
+	}
+	return ttl, ttl > 0
+}
+
+// Expired reports whether the [TTL] of the error has passed since its creation.
+// Errors without a TTL never expire.
+func (e *baseError) Expired() bool {
+	ttl, ok := e.TTL()
+	return ok && now().Sub(e.Created()) >= ttl
+}
+
+// Tenant returns the tenant added with [Tenant] to the error or the closest wrapped
+// error with one, or an empty string.
+func (e *baseError) Tenant() string {
+	for w := e; w != nil; w = w.wrappedErr {
+		if tenant := ownField(w.fields, TenantField); tenant != "" {
+			return tenant
+		}
+	}
+	return ""
+}
+
+// Hint returns the remediation guidance of the error or the closest wrapped error.
+func (e *baseError) Hint() string {
+	hint := e.ext().hint
+	if hint == "" && e.wrappedErr != nil {
+		return e.wrappedErr.Hint()
+	}
+	return hint
+}
+
+// UserMessage returns the end-user message of the error or the closest wrapped error.
+func (e *baseError) UserMessage() string {
+	userMessage := e.ext().userMessage
+	if userMessage == "" && e.wrappedErr != nil {
+		return e.wrappedErr.UserMessage()
+	}
+	return userMessage
+}
+
+// DocURL returns the documentation link of the error or the closest wrapped error.
+func (e *baseError) DocURL() string {
+	docURL := e.ext().docURL
+	if docURL == "" && e.wrappedErr != nil {
+		return e.wrappedErr.DocURL()
+	}
+	return docURL
+}
+
 // Message returns the error's message.
 func (e *baseError) Message() string {
 	out := FormatErrorMessage(e)
@@ -272,6 +516,87 @@ func (e *baseError) AllFields() []any {
 	return fields
 }
 
+// AllFieldsAnnotated returns all fields of err and its wrapped errors, in the same order
+// as [Error.AllFields], with the layer that added each field. It returns nil if err has
+// no fields or is not an error of this package.
+func AllFieldsAnnotated(err error) []FieldWithOrigin {
+	var e interface{ AllFieldsAnnotated() []FieldWithOrigin }
+	if !As(err, &e) {
+		return nil
+	}
+	return e.AllFieldsAnnotated()
+}
+
+// AllFieldsAnnotated returns all fields from the error and its wrapped errors,
+// in the same order as [Error.AllFields], with the layer that added each field.
+func (e *baseError) AllFieldsAnnotated() []FieldWithOrigin {
+	var count int
+	for layer := e; layer != nil; layer = layer.wrappedErr {
+		count += len(layer.fields) / 2
+	}
+	if count == 0 {
+		return nil
+	}
+
+	fields := make([]FieldWithOrigin, 0, count)
+	depth := 0
+	for layer := e; layer != nil; layer = layer.wrappedErr {
+		if len(layer.fields) > 0 {
+			message := buildMessage(layer)
+			for i := 0; i+1 < len(layer.fields); i += 2 {
+				fields = append(fields, FieldWithOrigin{
+					Key:          valueToString(layer.fields[i]),
+					Value:        layer.fields[i+1],
+					LayerMessage: message,
+					Depth:        depth,
+				})
+			}
+		}
+		depth++
+	}
+	return fields
+}
+
+// LayerCount returns the number of erro layers in the chain, 1 for an error that
+// wraps nothing or an external error.
+func (e *baseError) LayerCount() int {
+	count := 0
+	for layer := e; layer != nil; layer = layer.wrappedErr {
+		count++
+	}
+	return count
+}
+
+// FieldsAt returns the fields added by the layer at depth i, 0 for the outermost
+// error, as in [FieldWithOrigin.Depth]. It returns nil if the layer has no fields
+// or i is out of range, see [Error.LayerCount].
+func (e *baseError) FieldsAt(i int) []any {
+	if i < 0 {
+		return nil
+	}
+	layer := e
+	for ; layer != nil && i > 0; i-- {
+		layer = layer.wrappedErr
+	}
+	if layer == nil {
+		return nil
+	}
+	return layer.Fields()
+}
+
+// Wrap wraps the error with a message and optional metadata, see the [Wrap] function.
+// It returns nil for a nil error.
+//
+// Example:
+//
+//	return err.Wrap("fetch user", "user_id", id)
+func (e *baseError) Wrap(message string, fields ...any) Error {
+	if e == nil {
+		return nil
+	}
+	return wrapf(e, message, fields...)
+}
+
 // BaseError returns the lowest-level error in the wrap chain.
 func (e *baseError) BaseError() Error {
 	if e.wrappedErr != nil {
@@ -296,23 +621,35 @@ func (e *baseError) Stack() Stack {
 }
 
 func (e *baseError) getStack(cfg *StackTraceConfig) Stack {
+	frames := e.frames.Load()
+	if frames != nil {
+		return frames
+	}
 	if e.stack == nil && e.wrappedErr != nil {
 		return e.wrappedErr.getStack(cfg)
 	}
-	frames := e.frames.Load()
-	if frames == nil && e.stack != nil {
+	if e.stack != nil {
 		frames = e.stack.toFrames(cfg)
 		e.frames.Store(frames)
 	}
 	return frames
 }
 
+// ownStack returns the stack trace of the layer without the wrapped errors.
+func (e *baseError) ownStack() Stack {
+	if frames := e.frames.Load(); frames != nil && e.stack != nil {
+		return frames
+	}
+	return e.stack.toFrames(e.ext().stackTraceConfig)
+}
+
 // StackTraceConfig returns the configuration for the stack trace.
 func (e *baseError) StackTraceConfig() *StackTraceConfig {
-	if e.stackTraceConfig == nil && e.wrappedErr != nil {
+	config := e.ext().stackTraceConfig
+	if config == nil && e.wrappedErr != nil {
 		return e.wrappedErr.StackTraceConfig()
 	}
-	return e.stackTraceConfig
+	return config
 }
 
 // Formatter returns the error's message formatter.
@@ -327,9 +664,9 @@ func newBaseError(message string, meta ...any) *baseError {
 	e := &baseError{
 		message:   truncateString(message, MaxMessageLength),
 		formatter: FormatErrorWithFields,
-		created:   time.Now(),
+		created:   now(),
 	}
-	return applyMeta(e, meta...)
+	return injectFault(applyMeta(e, meta...))
 }
 
 func newWrapError(errorToWrap error, message string, meta ...any) *baseError {
@@ -343,47 +680,131 @@ func newWrapError(errorToWrap error, message string, meta ...any) *baseError {
 	if !ok {
 		if !As(errorToWrap, &e.wrappedErr) {
 			e.originalErr = errorToWrap
-			e.created = time.Now()
+			e.created = now()
+			inheritSentinel(e, errorToWrap)
 		}
 	}
+	if e.wrappedErr == nil {
+		return injectFault(applyMeta(e, meta...))
+	}
+
+	e.depth = e.wrappedErr.depth + 1
+	if e.depth < MaxWrapDepth {
+		e = applyMeta(e, meta...)
+		detectCollisions(e)
+		return injectFault(e)
+	}
 
-	return applyMeta(e, meta...)
+	// Drop the oldest layers above the root cause to keep the chain bounded
+	e.wrappedErr = dropOldestLayers(e.wrappedErr, MaxWrapDepth-2)
+	e.depth = e.wrappedErr.depth + 1
+	e.truncated = true
+	e = applyMeta(e, meta...)
+	e.fields = append(e.fields, WrapDepthExceededField, true)
+	detectCollisions(e)
+	if handler := wrapDepthExceededHandler.Load(); handler != nil {
+		handler(e)
+	}
+	return injectFault(e)
+}
+
+// dropOldestLayers returns the chain with only the newest keep layers above the root
+// cause. The kept layers are copied, so the original chain is not changed.
+func dropOldestLayers(e *baseError, keep int) *baseError {
+	var layers []*baseError
+	root := e
+	for root.wrappedErr != nil {
+		layers = append(layers, root)
+		root = root.wrappedErr
+	}
+	if len(layers) <= keep {
+		return e
+	}
+	layers = layers[:keep]
+	for i := len(layers) - 1; i >= 0; i-- {
+		c := layers[i].copyLayer()
+		c.wrappedErr = root
+		c.depth = root.depth + 1
+		c.truncated = true
+		root = c
+	}
+	return root
 }
 
 func applyMeta(e *baseError, meta ...any) *baseError {
+	meta = expandProfiles(meta)
 	if len(meta) == 0 {
 		if e.wrappedErr == nil {
 			e.id = newID(e.created.UnixNano())
 		}
+		markInvalidUTF8(e)
+		autoStack(e)
+		inferCategory(e)
+		checkTenant(e)
+		stampBuildInfo(e)
+		e = intercept(e)
+		lifecycleCreated(e)
+		runAutoPolicies(e)
 		return e
 	}
 
+	var deadline deadlineContext
+	diag := newOptionDiagnostics()
 	preparedFields := make([]any, 0, getFieldsCapFromMeta(meta))
 	for _, f := range meta {
-		if f == nil {
+		if isNilOption(f) {
 			continue
 		}
 		switch val := f.(type) {
 		case errorOpt:
-			val(e)
+			diag.checkPosition(val, preparedFields)
+			var id string
+			if diag != nil {
+				id, e.id = e.id, idUnset
+			}
+			val(e) // Called directly to keep the caller frame depth of [StackTrace]
+			if diag != nil {
+				diag.countID(e, id)
+			}
 		case errorFields:
+			diag.checkPosition(val, preparedFields)
 			if fields := val(); len(fields) > 0 {
 				preparedFields = append(preparedFields, fields...)
 			}
 		case ErrorClass:
+			diag.checkPosition(val, preparedFields)
+			diag.count(val)
 			e.class = val
 		case ErrorCategory:
+			diag.checkPosition(val, preparedFields)
+			diag.count(val)
 			e.category = val
 		case ErrorSeverity:
+			diag.checkPosition(val, preparedFields)
+			diag.count(val)
 			e.severity = val
-		case errorWork:
+		case errorWork, spanWork:
+			diag.checkPosition(val, preparedFields)
 			continue
+		case callerSkip:
+			e.setExt().callerSkip = int(val)
+		case deadlineContext:
+			deadline = val
+		case messageFormat:
+			e.setExt().format = string(val)
 		default:
 			preparedFields = append(preparedFields, val)
 		}
 	}
+	diag.report(e)
 	if len(preparedFields)%2 != 0 {
-		preparedFields = append(preparedFields, MissingFieldPlaceholder)
+		preparedFields = append(preparedFields, missingField())
+	}
+	if deadline.ctx != nil {
+		preparedFields = appendDeadlineFields(e, deadline.ctx, preparedFields)
+	}
+	if e.category != "" {
+		preparedFields = appendCategoryDefaults(preparedFields, e.category)
 	}
 	if len(preparedFields) > maxPairsCount {
 		newPreparedFields := make([]any, maxPairsCount)
@@ -391,27 +812,75 @@ func applyMeta(e *baseError, meta ...any) *baseError {
 		preparedFields = newPreparedFields
 	}
 	e.fields = preparedFields
+	markInvalidUTF8(e)
 	if e.id == "" && e.wrappedErr == nil {
 		e.id = newID(e.created.UnixNano())
 	}
+	autoStack(e)
+	inferCategory(e)
+	checkTenant(e)
+	stampBuildInfo(e)
+	e = intercept(e)
+	lifecycleCreated(e)
 
 	for _, f := range meta {
-		switch f := f.(type) {
-		case errorWork:
+		if f, ok := f.(spanWork); ok && f != nil {
 			f(e)
 		}
 	}
+	for _, f := range meta {
+		if f, ok := f.(errorWork); ok && f != nil {
+			f(e)
+		}
+	}
+	runAutoPolicies(e)
 
 	return e
 }
 
+// autoStack captures a stack trace if the severity of the error reaches the
+// threshold set with [WithAutoStackSeverity] and the chain has no stack yet.
+// It must be called directly from applyMeta to keep the caller frame depth of [StackTrace].
+func autoStack(e *baseError) {
+	threshold := autoStackSeverity.Load().rank()
+	if threshold == 0 || e.Severity().rank() < threshold {
+		return
+	}
+	for w := e; w != nil; w = w.wrappedErr {
+		if w.stack != nil {
+			return
+		}
+	}
+	captureErrorStack(e, defaultSkipFrames)
+}
+
+// collectFields returns the key-value fields from meta, skipping options.
+func collectFields(meta []any) []any {
+	fields := make([]any, 0, len(meta))
+	for _, f := range meta {
+		switch val := f.(type) {
+		case nil, errorOpt, errorWork, spanWork, callerSkip, deadlineContext, messageFormat, *Profile, ErrorClass, ErrorCategory, ErrorSeverity:
+			continue
+		case errorFields:
+			if val != nil {
+				fields = append(fields, val()...)
+			}
+		default:
+			fields = append(fields, val)
+		}
+	}
+	return fields
+}
+
 func getFieldsCapFromMeta(meta []any) int {
 	resultedCap := 0
 	for _, f := range meta {
 		switch f := f.(type) {
 		case errorFields:
-			resultedCap += len(f())
-		case errorOpt, errorWork, ErrorClass, ErrorCategory, ErrorSeverity:
+			if f != nil {
+				resultedCap += len(f())
+			}
+		case errorOpt, errorWork, spanWork, callerSkip, deadlineContext, messageFormat, ErrorClass, ErrorCategory, ErrorSeverity:
 			continue
 		default:
 			resultedCap++
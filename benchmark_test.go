@@ -459,6 +459,42 @@ func Benchmark_ApplyFormatVerbs(b *testing.B) {
 	}
 }
 
+// Set benchmarks
+
+func newSetErrors() []error {
+	errs := make([]error, 256)
+	for i := range errs {
+		errs[i] = erro.New(fmt.Sprintf("dependency %d failed", i), erro.ClassUnavailable)
+	}
+	return errs
+}
+
+func Benchmark_SafeSet_Add_Parallel(b *testing.B) {
+	errs := newSetErrors()
+	set := erro.NewSafeSet()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for i := 0; pb.Next(); i++ {
+			set.Add(errs[i%len(errs)])
+		}
+	})
+}
+
+func Benchmark_ShardedSet_Add_Parallel(b *testing.B) {
+	errs := newSetErrors()
+	set := erro.NewShardedSet(0)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for i := 0; pb.Next(); i++ {
+			set.Add(errs[i%len(errs)])
+		}
+	})
+}
+
 func newErr() erro.Error {
 	fields := []any{"key3", "val3", "key4", 43}
 	id := "ID_123"
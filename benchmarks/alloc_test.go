@@ -0,0 +1,92 @@
+package benchmarks
+
+import (
+	"errors"
+	"runtime"
+	"testing"
+
+	"github.com/maxbolgarin/erro"
+)
+
+// gate is an allocation limit for a single operation.
+type gate struct {
+	name      string  // Name of the gated operation
+	maxAllocs float64 // Maximum allowed allocations per run
+	maxBytes  float64 // Maximum allowed allocated bytes per run
+	run       func()  // Operation to measure
+}
+
+// defaultGates returns the allocation gates of the hot paths of erro.
+//
+// Limits are the baselines measured with `go test -bench . -benchmem ./benchmarks`
+// (linux/amd64):
+//
+//	BenchmarkNew              273 ns/op     304 B/op     2 allocs/op
+//	BenchmarkNewWithFields    430 ns/op     368 B/op     3 allocs/op
+//	BenchmarkWrapDeep        2066 ns/op    2896 B/op    11 allocs/op (10 layers)
+//	BenchmarkErrorFormat      202 ns/op     160 B/op     2 allocs/op
+//	BenchmarkLogFields        494 ns/op     656 B/op     9 allocs/op
+//	BenchmarkStackCapture    1388 ns/op     368 B/op     4 allocs/op
+//
+// The stack trace of StackCapture is deeper in the test, so its limit is 384 bytes.
+// Every error is a 288-byte struct plus a generated ID string, which is the second
+// allocation. Bytes per operation follow the size of the struct, so keep rarely set
+// metadata out of it (see errorExtras) instead of raising the limits.
+func defaultGates() []gate {
+	base := errors.New("base error")
+	formatted := erro.New("request failed", "user_id", 42, "path", "/api/users")
+	logged := erro.New("request failed", "user_id", 42, erro.CategoryAPI, erro.SeverityHigh)
+
+	return []gate{
+		{name: "New", maxAllocs: 2, maxBytes: 304, run: func() { _ = erro.New("request failed") }},
+		{name: "NewWithFields", maxAllocs: 3, maxBytes: 368, run: func() { _ = erro.New("request failed", "user_id", 42, "path", "/api/users") }},
+		{name: "WrapDeep", maxAllocs: 11, maxBytes: 2896, run: func() { _ = wrapDeep(base, 10) }},
+		{name: "ErrorFormat", maxAllocs: 2, maxBytes: 160, run: func() { _ = erro.FormatErrorWithFields(formatted) }},
+		{name: "LogFields", maxAllocs: 9, maxBytes: 656, run: func() { _ = erro.LogFields(logged) }},
+		{name: "StackCapture", maxAllocs: 4, maxBytes: 384, run: func() { _ = erro.New("request failed", erro.StackTrace()) }},
+	}
+}
+
+func TestDefaultGates(t *testing.T) {
+	for _, g := range defaultGates() {
+		g := g
+		t.Run(g.name, func(t *testing.T) {
+			if allocs := testing.AllocsPerRun(100, g.run); allocs > g.maxAllocs {
+				t.Errorf("too many allocations: got %.1f, allowed %.1f", allocs, g.maxAllocs)
+			}
+			if bytes := bytesPerRun(100, g.run); bytes > g.maxBytes {
+				t.Errorf("too many allocated bytes: got %.1f, allowed %.1f", bytes, g.maxBytes)
+			}
+		})
+	}
+}
+
+// bytesPerRun returns the average number of bytes allocated by f, measured the same
+// way as [testing.AllocsPerRun] measures allocations, including the integer division.
+// Background allocations of the runtime are counted too, so the lowest of a few
+// measurements is returned.
+func bytesPerRun(runs int, f func()) float64 {
+	defer runtime.GOMAXPROCS(runtime.GOMAXPROCS(1))
+	f() // Warm up
+
+	var lowest uint64
+	for attempt := 0; attempt < 3; attempt++ {
+		var before, after runtime.MemStats
+		runtime.ReadMemStats(&before)
+		for i := 0; i < runs; i++ {
+			f()
+		}
+		runtime.ReadMemStats(&after)
+		if bytes := (after.TotalAlloc - before.TotalAlloc) / uint64(runs); attempt == 0 || bytes < lowest {
+			lowest = bytes
+		}
+	}
+	return float64(lowest)
+}
+
+func wrapDeep(err error, depth int) error {
+	for i := 0; i < depth; i++ {
+		err = erro.Wrap(err, "layer")
+	}
+	return err
+}
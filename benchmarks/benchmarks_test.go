@@ -0,0 +1,53 @@
+package benchmarks
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/maxbolgarin/erro"
+)
+
+func BenchmarkNew(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_ = erro.New("request failed")
+	}
+}
+
+func BenchmarkNewWithFields(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_ = erro.New("request failed", "user_id", 42, "path", "/api/users")
+	}
+}
+
+func BenchmarkWrapDeep(b *testing.B) {
+	base := errors.New("base error")
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_ = wrapDeep(base, 10)
+	}
+}
+
+func BenchmarkErrorFormat(b *testing.B) {
+	err := erro.New("request failed", "user_id", 42, "path", "/api/users")
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_ = erro.FormatErrorWithFields(err)
+	}
+}
+
+func BenchmarkLogFields(b *testing.B) {
+	err := erro.New("request failed", "user_id", 42, erro.CategoryAPI, erro.SeverityHigh)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_ = erro.LogFields(err)
+	}
+}
+
+func BenchmarkStackCapture(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_ = erro.New("request failed", erro.StackTrace())
+	}
+}
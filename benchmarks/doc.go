@@ -0,0 +1,10 @@
+// Package benchmarks contains the performance suite of erro with allocation gates
+// for its hot paths.
+//
+// Run the suite with:
+//
+//	go test -bench . -benchmem ./benchmarks
+//
+// Timings vary between machines, so the gates checked by the tests of this package
+// limit allocations and allocated bytes per operation, which are stable across hardware.
+package benchmarks
@@ -0,0 +1,198 @@
+package erro
+
+import (
+	"container/list"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Defaults of [ErrorBudgetConfig].
+const (
+	DefaultBudgetWindow      = time.Minute
+	DefaultBudgetMinRequests = 20
+	DefaultBudgetCoolDown    = 30 * time.Second
+	DefaultBudgetMaxRoutes   = 1000
+)
+
+// RouteField is the field key with the route of an error counted by an [ErrorBudget].
+const RouteField = "route"
+
+// BrownoutMessage is the message of errors returned by [ErrorBudget.Middleware] during a cool-down.
+const BrownoutMessage = "route error budget exceeded"
+
+// ErrorBudgetConfig configures an [ErrorBudget].
+type ErrorBudgetConfig struct {
+	// Budget is the maximum fraction of requests of a route that may fail within
+	// Window, e.g. 0.05 for 5%. Exceeding it starts a cool-down.
+	Budget float64
+	// Window is the time window of counting. Default is [DefaultBudgetWindow].
+	Window time.Duration
+	// MinRequests is the number of requests in a window before the budget is checked,
+	// so a single failure does not trip a quiet route. Default is [DefaultBudgetMinRequests].
+	MinRequests int
+	// CoolDown is the time a tripped route responds with 503. Default is [DefaultBudgetCoolDown].
+	CoolDown time.Duration
+	// Route returns the route of a request. It is required. Return a route pattern,
+	// e.g. "/orders/{id}", rather than the raw path, so clients can't create routes.
+	Route func(r *http.Request) string
+	// MaxRoutes limits the number of tracked routes; the least recently used route is
+	// forgotten when a new one exceeds it. Default is [DefaultBudgetMaxRoutes].
+	MaxRoutes int
+	// Counts reports whether an error spends the budget. Default counts errors with a
+	// 5xx [HTTPCode] except [ClassUnavailable] from the budget itself.
+	Counts func(err Error) bool
+}
+
+// ErrorBudget is an application-level brownout: it counts requests per route in
+// [ErrorBudget.Middleware] and classified errors per route as [ErrorMetrics], and
+// when the error rate of a route exceeds the budget, the route responds with 503
+// Service Unavailable and Retry-After for a cool-down period. It is safe for concurrent use.
+type ErrorBudget struct {
+	cfg ErrorBudgetConfig
+
+	mu     sync.Mutex
+	routes map[string]*routeBudget
+	recent *list.List // Routes from the most recently used, values are route names
+}
+
+type routeBudget struct {
+	elem        *list.Element // Element of the route in [ErrorBudget.recent]
+	windowStart time.Time
+	requests    int
+	errors      int
+	coolUntil   time.Time
+}
+
+// NewErrorBudget creates a new [ErrorBudget]. It panics if cfg.Route is nil.
+//
+// Errors are counted under the [RouteField] field, add it with [ErrorBudget.Route]
+// and record errors with [RecordMetrics]:
+//
+//	budget := erro.NewErrorBudget(erro.ErrorBudgetConfig{Budget: 0.2, Route: routePattern})
+//	mux.Handle("/orders", budget.Middleware(ordersHandler))
+//
+//	// In the handler
+//	err = erro.Wrap(err, "create order", budget.Route(r), erro.RecordMetrics(budget))
+func NewErrorBudget(cfg ErrorBudgetConfig) *ErrorBudget {
+	if cfg.Window <= 0 {
+		cfg.Window = DefaultBudgetWindow
+	}
+	if cfg.MinRequests <= 0 {
+		cfg.MinRequests = DefaultBudgetMinRequests
+	}
+	if cfg.CoolDown <= 0 {
+		cfg.CoolDown = DefaultBudgetCoolDown
+	}
+	if cfg.Route == nil {
+		panic("erro: ErrorBudgetConfig.Route is required")
+	}
+	if cfg.MaxRoutes <= 0 {
+		cfg.MaxRoutes = DefaultBudgetMaxRoutes
+	}
+	if cfg.Counts == nil {
+		cfg.Counts = func(err Error) bool {
+			return HTTPCode(err) >= http.StatusInternalServerError && err.Class() != ClassUnavailable
+		}
+	}
+	return &ErrorBudget{cfg: cfg, routes: make(map[string]*routeBudget), recent: list.New()}
+}
+
+// Middleware rejects requests to routes in a cool-down with [WriteHTTPError] of an error
+// with [ClassUnavailable] and [RetryAfter] set to the rest of the cool-down, and
+// counts other requests.
+func (b *ErrorBudget) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		route := b.cfg.Route(r)
+		if remaining := b.request(route); remaining > 0 {
+			WriteHTTPError(w, New(BrownoutMessage, RouteField, route, ClassUnavailable, RetryAfter(remaining)))
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// Route adds the route of the request as the [RouteField] field.
+func (b *ErrorBudget) Route(r *http.Request) errorFields {
+	return func() []any {
+		if r == nil {
+			return nil
+		}
+		return []any{RouteField, b.cfg.Route(r)}
+	}
+}
+
+// RecordError implements [ErrorMetrics]: it spends the budget of the route of the error
+// from the [RouteField] field. Errors without a route are ignored.
+func (b *ErrorBudget) RecordError(err Error) {
+	if err == nil || !b.cfg.Counts(err) {
+		return
+	}
+	route := keyFieldValue(err, err.AllFields(), RouteField)
+	if route == "" {
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	t := now()
+	rb := b.route(route, t)
+	if t.Before(rb.coolUntil) {
+		return
+	}
+	rb.errors++
+	if rb.requests >= b.cfg.MinRequests && float64(rb.errors) > b.cfg.Budget*float64(rb.requests) {
+		rb.coolUntil = t.Add(b.cfg.CoolDown)
+		rb.windowStart = rb.coolUntil
+		rb.requests, rb.errors = 0, 0
+	}
+}
+
+// CoolDown returns the rest of the cool-down of the route, zero if it serves requests.
+func (b *ErrorBudget) CoolDown(route string) time.Duration {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	rb, ok := b.routes[route]
+	if !ok {
+		return 0
+	}
+	if remaining := rb.coolUntil.Sub(now()); remaining > 0 {
+		return remaining
+	}
+	return 0
+}
+
+// request counts a request and returns the rest of the cool-down of the route.
+func (b *ErrorBudget) request(route string) time.Duration {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	t := now()
+	rb := b.route(route, t)
+	if remaining := rb.coolUntil.Sub(t); remaining > 0 {
+		return remaining
+	}
+	rb.requests++
+	return 0
+}
+
+// route returns the budget of the route, starting a new window if the current one is over.
+// A new route forgets the least recently used one if there are [ErrorBudgetConfig.MaxRoutes].
+func (b *ErrorBudget) route(route string, t time.Time) *routeBudget {
+	rb, ok := b.routes[route]
+	if ok {
+		b.recent.MoveToFront(rb.elem)
+	} else {
+		if len(b.routes) >= b.cfg.MaxRoutes {
+			oldest := b.recent.Back()
+			b.recent.Remove(oldest)
+			delete(b.routes, oldest.Value.(string))
+		}
+		rb = &routeBudget{windowStart: t, elem: b.recent.PushFront(route)}
+		b.routes[route] = rb
+	}
+	if !t.Before(rb.windowStart.Add(b.cfg.Window)) {
+		rb.windowStart = t
+		rb.requests, rb.errors = 0, 0
+	}
+	return rb
+}
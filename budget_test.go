@@ -0,0 +1,115 @@
+package erro
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestErrorBudget(t *testing.T) {
+	current := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	SetClock(func() time.Time { return current })
+	defer SetClock(nil)
+
+	budget := NewErrorBudget(ErrorBudgetConfig{Budget: 0.5, MinRequests: 4, CoolDown: 10 * time.Second,
+		Route: func(r *http.Request) string { return r.URL.Path }})
+	fail := true
+	handler := budget.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if fail {
+			WriteHTTPError(w, New("db down", CategoryDatabase, budget.Route(r), RecordMetrics(budget)))
+			return
+		}
+		if r.URL.Path == "/orders" {
+			_ = New("invalid order", ClassValidation, budget.Route(r), RecordMetrics(budget))
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	serve := func(path string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		return rec
+	}
+
+	// Below MinRequests the route is not tripped
+	for i := 0; i < 3; i++ {
+		if rec := serve("/orders"); rec.Code != http.StatusInternalServerError {
+			t.Fatalf("request %d: expected 500, got %d", i, rec.Code)
+		}
+	}
+	if budget.CoolDown("/orders") != 0 {
+		t.Fatal("route must not be tripped below MinRequests")
+	}
+	serve("/orders")
+	if budget.CoolDown("/orders") != 10*time.Second {
+		t.Fatalf("expected cool-down, got %v", budget.CoolDown("/orders"))
+	}
+
+	fail = false
+	current = current.Add(2500 * time.Millisecond)
+	rec := serve("/orders")
+	if rec.Code != http.StatusServiceUnavailable || rec.Header().Get("Retry-After") != "8" {
+		t.Errorf("expected 503 with Retry-After 8, got %d %q", rec.Code, rec.Header().Get("Retry-After"))
+	}
+	if rec := serve("/users"); rec.Code != http.StatusOK {
+		t.Errorf("other routes must be served, got %d", rec.Code)
+	}
+
+	// After the cool-down the route is served again, client errors do not spend the budget
+	current = current.Add(8 * time.Second)
+	for i := 0; i < 10; i++ {
+		if rec := serve("/orders"); rec.Code != http.StatusOK {
+			t.Fatalf("expected 200 after the cool-down, got %d", rec.Code)
+		}
+	}
+	if budget.CoolDown("/orders") != 0 {
+		t.Error("validation errors must not spend the budget")
+	}
+}
+
+func TestErrorBudgetWindow(t *testing.T) {
+	current := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	SetClock(func() time.Time { return current })
+	defer SetClock(nil)
+
+	budget := NewErrorBudget(ErrorBudgetConfig{Budget: 0.1, MinRequests: 1, Window: time.Minute,
+		Route: func(r *http.Request) string { return "api" }})
+	for i := 0; i < 20; i++ {
+		budget.request("api")
+	}
+	budget.RecordError(New("fail", ClassInternal, RouteField, "api"))
+	budget.RecordError(New("no route", ClassInternal))
+	if budget.CoolDown("api") != 0 {
+		t.Fatal("1 of 20 must be within the budget")
+	}
+
+	// A new window starts with zero requests
+	current = current.Add(time.Minute)
+	budget.request("api")
+	budget.RecordError(New("fail", ClassInternal, RouteField, "api"))
+	if budget.CoolDown("api") == 0 {
+		t.Error("expected the route to trip in the new window")
+	}
+	if budget.CoolDown("unknown") != 0 {
+		t.Error("unexpected cool-down of an unknown route")
+	}
+}
+
+func TestErrorBudgetMaxRoutes(t *testing.T) {
+	budget := NewErrorBudget(ErrorBudgetConfig{Budget: 0.1, MinRequests: 1, MaxRoutes: 2,
+		Route: func(r *http.Request) string { return r.URL.Path }})
+	budget.request("a")
+	budget.request("b")
+	budget.request("a")
+	budget.request("c")
+	if len(budget.routes) != 2 || budget.routes["b"] != nil || budget.routes["a"] == nil {
+		t.Errorf("expected the least recently used route to be forgotten, got %v", budget.routes)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("expected a panic without Route")
+		}
+	}()
+	NewErrorBudget(ErrorBudgetConfig{Budget: 0.1})
+}
@@ -0,0 +1,45 @@
+package erro
+
+import "runtime/debug"
+
+// Fields added to errors with [WithBuildInfo].
+const (
+	// BuildVersionField is the version of the main module, e.g. "v1.4.2".
+	BuildVersionField = "build_version"
+	// BuildRevisionField is the VCS revision the binary was built from.
+	BuildRevisionField = "build_revision"
+	// BuildDirtyField is true if the working tree had local modifications at build time.
+	BuildDirtyField = "build_dirty"
+)
+
+var buildInfoFields atomicValue[[]any]
+
+// stampBuildInfo adds the fields of [WithBuildInfo] to a root error.
+func stampBuildInfo(e *baseError) {
+	if e.wrappedErr != nil {
+		return
+	}
+	if fields := buildInfoFields.Load(); len(fields) > 0 && len(e.fields)+len(fields) <= maxPairsCount {
+		e.fields = append(e.fields[:len(e.fields):len(e.fields)], fields...)
+	}
+}
+
+// buildInfoFieldsOf returns the build fields of the build info, omitting unknown values.
+func buildInfoFieldsOf(info *debug.BuildInfo) []any {
+	if info == nil {
+		return nil
+	}
+	var fields []any
+	if v := info.Main.Version; v != "" && v != "(devel)" {
+		fields = append(fields, BuildVersionField, v)
+	}
+	for _, s := range info.Settings {
+		switch s.Key {
+		case "vcs.revision":
+			fields = append(fields, BuildRevisionField, s.Value)
+		case "vcs.modified":
+			fields = append(fields, BuildDirtyField, s.Value == "true")
+		}
+	}
+	return fields
+}
@@ -0,0 +1,45 @@
+package erro
+
+import (
+	"errors"
+	"reflect"
+	"runtime/debug"
+	"testing"
+)
+
+func TestBuildInfoFieldsOf(t *testing.T) {
+	info := &debug.BuildInfo{
+		Main: debug.Module{Path: "example.com/app", Version: "v1.4.2"},
+		Settings: []debug.BuildSetting{
+			{Key: "vcs", Value: "git"},
+			{Key: "vcs.revision", Value: "9f2c1e7"},
+			{Key: "vcs.modified", Value: "true"},
+		},
+	}
+	expected := []any{BuildVersionField, "v1.4.2", BuildRevisionField, "9f2c1e7", BuildDirtyField, true}
+	if fields := buildInfoFieldsOf(info); !reflect.DeepEqual(fields, expected) {
+		t.Errorf("unexpected fields: %v", fields)
+	}
+	if fields := buildInfoFieldsOf(&debug.BuildInfo{Main: debug.Module{Version: "(devel)"}}); len(fields) != 0 {
+		t.Errorf("expected no fields for a development build, got %v", fields)
+	}
+}
+
+func TestWithBuildInfo(t *testing.T) {
+	Configure(WithBuildInfo())
+	defer Configure(WithBuildInfo(false))
+	buildInfoFields.Store([]any{BuildVersionField, "v1.4.2", BuildRevisionField, "9f2c1e7"})
+
+	err := Wrap(New("disk full", "path", "/data"), "save")
+	if err.Error() != "save: disk full path=/data build_version=v1.4.2 build_revision=9f2c1e7" {
+		t.Errorf("expected build fields on the root error only, got %q", err.Error())
+	}
+	if v, _ := Field[string](Wrap(errors.New("external"), "read"), BuildRevisionField); v != "9f2c1e7" {
+		t.Errorf("expected build fields on errors wrapping external errors, got %q", v)
+	}
+
+	Configure(WithBuildInfo(false))
+	if len(New("disk full").Fields()) != 0 {
+		t.Error("expected no build fields when disabled")
+	}
+}
@@ -0,0 +1,219 @@
+package erro
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"os"
+	"runtime"
+	"sort"
+	"time"
+)
+
+// BundleVersion is the format version of bundles written by [Error.DebugBundle].
+const BundleVersion = 1
+
+// DebugBundle is a self-contained snapshot of an error for offline debugging, see
+// [Error.DebugBundle] and [OpenBundle].
+type DebugBundle struct {
+	Version  int           `json:"version"`
+	Created  time.Time     `json:"created"`
+	Error    string        `json:"error"`
+	Layers   []BundleLayer `json:"layers"`
+	Timeline []BundleEvent `json:"timeline"`
+	Env      BundleEnv     `json:"env"`
+}
+
+// BundleLayer is a layer of the error chain in a [DebugBundle], the outermost first.
+type BundleLayer struct {
+	Depth    int            `json:"depth"`
+	Message  string         `json:"message,omitempty"`
+	Cause    string         `json:"cause,omitempty"` // Text of the wrapped non-erro error
+	ID       string         `json:"id,omitempty"`
+	Class    ErrorClass     `json:"class,omitempty"`
+	Category ErrorCategory  `json:"category,omitempty"`
+	Severity ErrorSeverity  `json:"severity,omitempty"`
+	Created  time.Time      `json:"created,omitempty"`
+	Fields   []any          `json:"fields,omitempty"`
+	Stack    []StackContext `json:"stack,omitempty"`
+}
+
+// Events of the timeline of a [DebugBundle].
+const (
+	BundleEventCreated = "created" // A layer with a creation time was created, the message is its own message
+	BundleEventField   = "field"   // A layer has a time field, e.g. [DeadlineField], the message is its key
+	BundleEventBundled = "bundled" // The bundle was written
+)
+
+// BundleEvent is an event in the timeline of a [DebugBundle].
+type BundleEvent struct {
+	Time    time.Time `json:"time"`
+	Event   string    `json:"event"`
+	Depth   int       `json:"depth"`
+	Message string    `json:"message,omitempty"`
+}
+
+// BundleEnv describes the process that wrote a [DebugBundle].
+type BundleEnv struct {
+	GoVersion string            `json:"go_version"`
+	OS        string            `json:"os"`
+	Arch      string            `json:"arch"`
+	Path      string            `json:"path,omitempty"`    // Main package path
+	Version   string            `json:"version,omitempty"` // Main module version
+	Settings  map[string]string `json:"settings,omitempty"`
+	Hostname  string            `json:"hostname,omitempty"`
+	PID       int               `json:"pid"`
+}
+
+// DebugBundle packages the error chain into a single gzip-compressed JSON blob for
+// offline debugging: every layer with its own message, metadata, fields and stack trace,
+// the timeline of the chain (creation times and time fields of layers), and the Go
+// version, build info and host of the process. Fields are taken from [Error.Redacted],
+// so [Redact] and [SensitivitySecret] values and fields with [DefaultScrubKeys] are
+// redacted, and [Sensitive] values are filtered with the level of [WithRenderSensitivity].
+// Open the bundle with [OpenBundle].
+//
+// Example:
+//
+//	if blob, err := err.DebugBundle(); err == nil {
+//	    os.WriteFile("incident-"+err.ID()+".erro.gz", blob, 0o600)
+//	}
+func (e *baseError) DebugBundle() ([]byte, error) {
+	if e == nil {
+		return nil, nil
+	}
+
+	bundle := DebugBundle{
+		Version: BundleVersion,
+		Created: now(),
+		Error:   validUTF8(e.Error()),
+		Env:     bundleEnv(),
+	}
+	depth := 0
+	for layer := e.redactedCopy(); layer != nil; layer = layer.wrappedErr {
+		bundle.Layers = append(bundle.Layers, bundleLayer(layer, depth))
+		bundle.Timeline = appendBundleEvents(bundle.Timeline, layer, depth)
+		depth++
+	}
+	bundle.Timeline = append(bundle.Timeline, BundleEvent{Time: bundle.Created, Event: BundleEventBundled})
+	sort.SliceStable(bundle.Timeline, func(i, j int) bool {
+		return bundle.Timeline[i].Time.Before(bundle.Timeline[j].Time)
+	})
+
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if err := json.NewEncoder(zw).Encode(bundle); err != nil {
+		return nil, Wrap(err, "encode debug bundle")
+	}
+	if err := zw.Close(); err != nil {
+		return nil, Wrap(err, "compress debug bundle")
+	}
+	return buf.Bytes(), nil
+}
+
+// OpenBundle decodes a bundle written by [Error.DebugBundle].
+//
+// Example:
+//
+//	bundle, err := erro.OpenBundle(blob)
+//	for _, layer := range bundle.Layers {
+//	    fmt.Println(layer.Depth, layer.Message, layer.Fields)
+//	}
+func OpenBundle(data []byte) (*DebugBundle, error) {
+	zr, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, Wrap(err, "open debug bundle", ClassValidation)
+	}
+	raw, err := io.ReadAll(zr)
+	if err != nil {
+		return nil, Wrap(err, "decompress debug bundle", ClassValidation)
+	}
+	var bundle DebugBundle
+	if err := json.Unmarshal(raw, &bundle); err != nil {
+		return nil, Wrap(err, "decode debug bundle", ClassValidation)
+	}
+	if bundle.Version != BundleVersion {
+		return nil, Wrap(ErrInvalidSchema, "unsupported bundle version", "version", bundle.Version)
+	}
+	return &bundle, nil
+}
+
+// bundleLayer renders a layer of a redacted copy of the chain.
+func bundleLayer(e *baseError, depth int) BundleLayer {
+	layer := BundleLayer{
+		Depth:    depth,
+		Message:  validUTF8(e.message),
+		ID:       e.id,
+		Class:    e.class,
+		Category: e.category,
+		Severity: e.severity,
+		Created:  e.created,
+	}
+	if e.originalErr != nil {
+		layer.Cause = validUTF8(e.originalErr.Error())
+	}
+	if len(e.fields) > 0 {
+		layer.Fields = make([]any, len(e.fields))
+		for i, value := range e.fields {
+			value = resolveSensitive(value, 0)
+			if _, ok := value.(RedactedValue); ok {
+				value = redacted()
+			}
+			layer.Fields[i] = validUTF8Value(value)
+		}
+	}
+	if frames := e.ownStack(); len(frames) > 0 {
+		layer.Stack = make([]StackContext, len(frames))
+		for i, frame := range frames {
+			layer.Stack[i] = frame.GetContext()
+		}
+	}
+	return layer
+}
+
+// appendBundleEvents appends the timed events of a layer: its creation, as only layers
+// that wrap non-erro errors record it, and its time fields.
+func appendBundleEvents(events []BundleEvent, e *baseError, depth int) []BundleEvent {
+	if !e.created.IsZero() {
+		events = append(events, BundleEvent{
+			Time:    e.created,
+			Event:   BundleEventCreated,
+			Depth:   depth,
+			Message: validUTF8(e.message),
+		})
+	}
+	for i := 1; i < len(e.fields); i += 2 {
+		if t, ok := e.fields[i].(time.Time); ok {
+			events = append(events, BundleEvent{
+				Time:    t,
+				Event:   BundleEventField,
+				Depth:   depth,
+				Message: validUTF8(valueToString(e.fields[i-1])),
+			})
+		}
+	}
+	return events
+}
+
+// bundleEnv describes the current process.
+func bundleEnv() BundleEnv {
+	env := BundleEnv{
+		GoVersion: runtime.Version(),
+		OS:        runtime.GOOS,
+		Arch:      runtime.GOARCH,
+		PID:       os.Getpid(),
+	}
+	env.Hostname, _ = os.Hostname()
+	if buildInfo != nil {
+		env.Path = buildInfo.Path
+		env.Version = buildInfo.Main.Version
+		if len(buildInfo.Settings) > 0 {
+			env.Settings = make(map[string]string, len(buildInfo.Settings))
+			for _, s := range buildInfo.Settings {
+				env.Settings[s.Key] = s.Value
+			}
+		}
+	}
+	return env
+}
@@ -0,0 +1,70 @@
+package erro
+
+import (
+	"errors"
+	"runtime"
+	"testing"
+	"time"
+)
+
+func TestDebugBundle(t *testing.T) {
+	start := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	current := start
+	SetClock(func() time.Time { return current })
+	defer SetClock(nil)
+
+	inner := Wrap(errors.New("connection refused"), "query failed", ClassTimeout, StackTrace(),
+		"query", "SELECT 1", "password", "hunter2", Sensitive(SensitivitySecret, "card", "4242"))
+	current = start.Add(time.Second)
+	err := Wrap(inner, "get user", SeverityHigh, Sensitive(SensitivityInternal, "email", "a@b.c"), "deadline", start.Add(time.Minute))
+
+	blob, bundleErr := err.DebugBundle()
+	if bundleErr != nil {
+		t.Fatalf("unexpected error: %v", bundleErr)
+	}
+	bundle, openErr := OpenBundle(blob)
+	if openErr != nil {
+		t.Fatalf("unexpected error: %v", openErr)
+	}
+
+	if bundle.Version != BundleVersion || bundle.Error != err.Error() {
+		t.Errorf("unexpected bundle header: %d %q", bundle.Version, bundle.Error)
+	}
+	if bundle.Env.GoVersion != runtime.Version() || bundle.Env.OS != runtime.GOOS {
+		t.Errorf("unexpected environment: %+v", bundle.Env)
+	}
+	if len(bundle.Layers) != 2 {
+		t.Fatalf("expected 2 layers, got %d", len(bundle.Layers))
+	}
+
+	outer, root := bundle.Layers[0], bundle.Layers[1]
+	if outer.Message != "get user" || outer.Severity != SeverityHigh || len(outer.Fields) != 4 || outer.Fields[1] != "a@b.c" {
+		t.Errorf("unexpected outer layer: %+v", outer)
+	}
+	if root.Cause != "connection refused" || root.Class != ClassTimeout || root.ID != err.ID() {
+		t.Errorf("unexpected root layer: %+v", root)
+	}
+	if root.Fields[1] != "SELECT 1" || root.Fields[3] != RedactedPlaceholder || root.Fields[5] != RedactedPlaceholder {
+		t.Errorf("expected sensitive fields to be redacted, got %v", root.Fields)
+	}
+	if len(root.Stack) == 0 || len(outer.Stack) != 0 {
+		t.Errorf("expected stack only in the root layer, got %d and %d frames", len(root.Stack), len(outer.Stack))
+	}
+
+	timeline := bundle.Timeline
+	if len(timeline) != 3 {
+		t.Fatalf("unexpected timeline: %+v", timeline)
+	}
+	if timeline[0].Event != BundleEventCreated || timeline[0].Depth != 1 || timeline[0].Message != "query failed" {
+		t.Errorf("unexpected creation event: %+v", timeline[0])
+	}
+	if timeline[1].Event != BundleEventBundled || timeline[2].Event != BundleEventField || timeline[2].Message != "deadline" {
+		t.Errorf("expected events sorted by time, got %+v", timeline)
+	}
+}
+
+func TestOpenBundle_Invalid(t *testing.T) {
+	if _, err := OpenBundle([]byte("not a bundle")); err == nil || ExtractError(err).Class() != ClassValidation {
+		t.Errorf("expected validation error, got %v", err)
+	}
+}
@@ -0,0 +1,28 @@
+package erro
+
+import "time"
+
+var clock atomicValue[func() time.Time]
+
+// SetClock sets the time source used for the creation time of errors ([Error.Created]).
+// It is useful for deterministic tests and for logical clocks. Passing nil restores
+// the default [time.Now]. See errtest.Clock for a controllable fake.
+//
+// Example:
+//
+//	erro.SetClock(func() time.Time { return time.Unix(0, 0) })
+//	defer erro.SetClock(nil)
+func SetClock(now func() time.Time) {
+	if now == nil {
+		now = time.Now
+	}
+	clock.Store(now)
+}
+
+// now returns the current time of the clock set with [SetClock].
+func now() time.Time {
+	if c := clock.Load(); c != nil {
+		return c()
+	}
+	return time.Now()
+}
@@ -0,0 +1,131 @@
+// Command framehash resolves frame hashes logged with erro.WithFrameHashes back to
+// functions and files, using the symbol table of the binary that produced them.
+//
+// Usage:
+//
+//	go install github.com/maxbolgarin/erro/cmd/framehash@latest
+//	framehash -binary ./app -salt "$FRAME_SALT" 3f1c9a0e5b7d2c41:42 a07e4b1d9c3f5e28:118
+//	echo 3f1c9a0e5b7d2c41:42 | framehash -binary ./app -salt "$FRAME_SALT"
+//	framehash -binary ./app -salt "$FRAME_SALT" -dump > frames.txt
+//
+// The binary must be the exact build that logged the hashes and must not be stripped.
+// ELF and Mach-O binaries are supported.
+package main
+
+import (
+	"bufio"
+	"debug/elf"
+	"debug/gosym"
+	"debug/macho"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/maxbolgarin/erro"
+)
+
+func main() {
+	binary := flag.String("binary", "", "path to the binary that logged the hashes")
+	salt := flag.String("salt", "", "salt passed to erro.WithFrameHashes")
+	dump := flag.Bool("dump", false, "print all hashes of the binary instead of resolving")
+	flag.Parse()
+
+	if *binary == "" {
+		fmt.Fprintln(os.Stderr, "framehash: -binary is required")
+		flag.Usage()
+		os.Exit(2)
+	}
+	if err := run(os.Stdout, os.Stdin, *binary, *salt, *dump, flag.Args()); err != nil {
+		fmt.Fprintln(os.Stderr, "framehash:", err)
+		os.Exit(1)
+	}
+}
+
+func run(w io.Writer, r io.Reader, binary, salt string, dump bool, hashes []string) error {
+	table, err := readTable(binary)
+	if err != nil {
+		return err
+	}
+	if dump {
+		for _, fn := range table.Funcs {
+			file, _, _ := table.PCToLine(fn.Entry)
+			fmt.Fprintf(w, "%s %s %s\n", erro.StackFrame{FullName: fn.Name, File: file}.Hash(salt), fn.Name, file)
+		}
+		return nil
+	}
+
+	index := buildIndex(table, salt)
+	if len(hashes) == 0 {
+		scanner := bufio.NewScanner(r)
+		for scanner.Scan() {
+			hashes = append(hashes, strings.Fields(scanner.Text())...)
+		}
+		if err := scanner.Err(); err != nil {
+			return err
+		}
+	}
+	for _, hash := range hashes {
+		frame, ok := index.Resolve(hash)
+		switch {
+		case !ok:
+			fmt.Fprintf(w, "%s\t<unknown>\n", hash)
+		case frame.Line > 0:
+			fmt.Fprintf(w, "%s\t%s\n\t\t%s:%d\n", hash, frame.FullName, frame.File, frame.Line)
+		default:
+			fmt.Fprintf(w, "%s\t%s\n\t\t%s\n", hash, frame.FullName, frame.File)
+		}
+	}
+	return nil
+}
+
+func buildIndex(table *gosym.Table, salt string) *erro.FrameHashIndex {
+	index := erro.NewFrameHashIndex(salt)
+	for _, fn := range table.Funcs {
+		file, _, _ := table.PCToLine(fn.Entry)
+		index.Add(fn.Name, file)
+	}
+	return index
+}
+
+// readTable reads the Go symbol table of an ELF or Mach-O binary.
+func readTable(path string) (*gosym.Table, error) {
+	var pclntab, symtab []byte
+	var textStart uint64
+
+	if f, err := elf.Open(path); err == nil {
+		defer f.Close()
+		if s := f.Section(".gopclntab"); s != nil {
+			if pclntab, err = s.Data(); err != nil {
+				return nil, err
+			}
+		}
+		if s := f.Section(".gosymtab"); s != nil {
+			symtab, _ = s.Data()
+		}
+		if s := f.Section(".text"); s != nil {
+			textStart = s.Addr
+		}
+	} else if f, err := macho.Open(path); err == nil {
+		defer f.Close()
+		if s := f.Section("__gopclntab"); s != nil {
+			if pclntab, err = s.Data(); err != nil {
+				return nil, err
+			}
+		}
+		if s := f.Section("__gosymtab"); s != nil {
+			symtab, _ = s.Data()
+		}
+		if s := f.Section("__text"); s != nil {
+			textStart = s.Addr
+		}
+	} else {
+		return nil, fmt.Errorf("%s is not an ELF or Mach-O binary", path)
+	}
+
+	if len(pclntab) == 0 {
+		return nil, fmt.Errorf("%s has no Go symbol table", path)
+	}
+	return gosym.NewTable(symtab, gosym.NewLineTable(pclntab, textStart))
+}
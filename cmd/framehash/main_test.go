@@ -0,0 +1,58 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/maxbolgarin/erro"
+)
+
+func TestRun(t *testing.T) {
+	binary, err := os.Executable()
+	if err != nil {
+		t.Skip("executable path is not available")
+	}
+	if _, err := readTable(binary); err != nil {
+		t.Skipf("cannot read the symbol table: %v", err)
+	}
+
+	pc, file, line, _ := runtime.Caller(0)
+	function := runtime.FuncForPC(pc).Name()
+	hash := erro.StackFrame{FullName: function, File: file, Line: line}.Hash("salt")
+
+	var out bytes.Buffer
+	if err := run(&out, nil, binary, "salt", false, []string{hash + ":42", "0000000000000000"}); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), function) || !strings.Contains(out.String(), file+":42") {
+		t.Errorf("expected %s in output:\n%s", function, out.String())
+	}
+	if !strings.Contains(out.String(), "0000000000000000\t<unknown>") {
+		t.Errorf("expected an unknown hash in output:\n%s", out.String())
+	}
+
+	out.Reset()
+	if err := run(&out, strings.NewReader(hash+"\n"), binary, "salt", false, nil); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), function) {
+		t.Errorf("expected hashes from the reader to be resolved:\n%s", out.String())
+	}
+
+	out.Reset()
+	if err := run(&out, nil, binary, "salt", true, nil); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), hash+" "+function) {
+		t.Error("expected the hash of the test function in the dump")
+	}
+}
+
+func TestRunNotBinary(t *testing.T) {
+	if err := run(&bytes.Buffer{}, nil, "main.go", "", false, nil); err == nil {
+		t.Error("expected an error for a source file")
+	}
+}
@@ -0,0 +1,57 @@
+package erro
+
+import "log"
+
+// CollisionMode defines what happens when a wrapping error adds a field key that is
+// already present deeper in the chain with a different value, see [WithCollisionDetection].
+type CollisionMode int32
+
+const (
+	// CollisionIgnore disables collision detection. It is the default.
+	CollisionIgnore CollisionMode = iota
+	// CollisionLog prints collisions with the standard log package.
+	CollisionLog
+	// CollisionPanic panics with an error wrapping [ErrFieldCollision].
+	CollisionPanic
+	// CollisionAnnotate adds the [KeyConflictField] field to the wrapping error.
+	CollisionAnnotate
+)
+
+// detectCollisions checks the own fields of a wrapping error against the fields of the
+// wrapped errors. Values are compared by their string representation.
+func detectCollisions(e *baseError) {
+	mode := collisionMode.Load()
+	if mode == CollisionIgnore || len(e.fields) == 0 || e.wrappedErr == nil {
+		return
+	}
+	deeper := e.wrappedErr.AllFieldsAnnotated()
+	for i := 0; i+1 < len(e.fields); i += 2 {
+		key := valueToString(e.fields[i])
+		value := valueToString(e.fields[i+1])
+		shadowed, ok := findCollision(deeper, key, value)
+		if !ok {
+			continue
+		}
+		switch mode {
+		case CollisionLog:
+			log.Printf("erro: field %q=%q of %q shadows %q=%q of %q",
+				key, value, buildMessage(e), key, valueToString(shadowed.Value), shadowed.LayerMessage)
+		case CollisionPanic:
+			panic(Wrap(ErrFieldCollision, buildMessage(e),
+				"key", key, "value", value, "shadowed", shadowed.Value, "shadowed_in", shadowed.LayerMessage))
+		case CollisionAnnotate:
+			e.fields = append(e.fields, KeyConflictField, true)
+			return
+		}
+	}
+}
+
+// findCollision returns the first field with the key and a different value.
+func findCollision(fields []FieldWithOrigin, key, value string) (FieldWithOrigin, bool) {
+	for _, f := range fields {
+		if f.Key == key && valueToString(f.Value) != value {
+			return f, true
+		}
+	}
+	return FieldWithOrigin{}, false
+}
@@ -0,0 +1,62 @@
+package erro
+
+import (
+	"bytes"
+	"errors"
+	"log"
+	"strings"
+	"testing"
+)
+
+func TestCollisionDetection(t *testing.T) {
+	inner := New("query failed", "user_id", 1, "table", "users")
+
+	if err := Wrap(inner, "get user", "user_id", 2); len(err.Fields()) != 2 {
+		t.Errorf("expected no detection by default, got %v", err.Fields())
+	}
+
+	t.Run("Annotate", func(t *testing.T) {
+		Configure(WithCollisionDetection(CollisionAnnotate))
+		defer Configure(WithCollisionDetection(CollisionIgnore))
+
+		err := Wrap(Wrap(inner, "load"), "get user", "user_id", 2, "request", "r1")
+		if fields := err.Fields(); len(fields) != 6 || fields[4] != KeyConflictField || fields[5] != true {
+			t.Errorf("expected key conflict annotation, got %v", fields)
+		}
+		if err := Wrap(inner, "get user", "user_id", 1); len(err.Fields()) != 2 {
+			t.Errorf("expected same value not to be a collision, got %v", err.Fields())
+		}
+		if err := Wrap(errors.New("plain"), "get user", "user_id", 1); len(err.Fields()) != 2 {
+			t.Errorf("expected no collision for standard errors, got %v", err.Fields())
+		}
+	})
+
+	t.Run("Log", func(t *testing.T) {
+		var buf bytes.Buffer
+		defer log.SetOutput(log.Writer())
+		log.SetOutput(&buf)
+		Configure(WithCollisionDetection(CollisionLog))
+		defer Configure(WithCollisionDetection(CollisionIgnore))
+
+		Wrap(inner, "get user", "user_id", 2)
+		if out := buf.String(); !strings.Contains(out, `field "user_id"="2" of "get user" shadows "user_id"="1" of "query failed"`) {
+			t.Errorf("unexpected log output: %q", out)
+		}
+	})
+
+	t.Run("Panic", func(t *testing.T) {
+		Configure(WithCollisionDetection(CollisionPanic))
+		defer Configure(WithCollisionDetection(CollisionIgnore))
+
+		defer func() {
+			err, ok := recover().(error)
+			if !ok || !errors.Is(err, ErrFieldCollision) {
+				t.Fatalf("expected panic with ErrFieldCollision, got %v", err)
+			}
+			if m := LogFieldsMap(err, WithUserFields()); m["key"] != "table" || m["shadowed"] != "users" {
+				t.Errorf("unexpected panic fields: %v", m)
+			}
+		}()
+		Wrap(inner, "get user", "table", "orders")
+	})
+}
@@ -0,0 +1,313 @@
+package erro
+
+// ConfigOption configures package-wide behavior of erro, see [Configure].
+type ConfigOption func()
+
+var (
+	redactedPlaceholder     atomicValue[string]
+	missingFieldPlaceholder atomicValue[string]
+	autoStackSeverity       atomicValue[ErrorSeverity]
+	redactPanicValues       atomicValue[bool]
+	collisionMode           atomicValue[CollisionMode]
+	isMode                  atomicValue[IsMode]
+	autoMetrics             atomicValue[*autoPolicy]
+	autoEvents              atomicValue[*autoPolicy]
+	tenantPolicy            atomicValue[*TenantPolicy]
+	multiErrorFormatter     atomicValue[MultiErrorFormatter]
+	maxAttachmentSize       atomicValue[int]
+	maxAttachmentsSize      atomicValue[int]
+	rawUTF8                 atomicValue[bool]
+	optionDiagnosticsMode   atomicValue[OptionDiagnosticsMode]
+	renderSensitivityLevel  atomicValue[Sensitivity]
+)
+
+// IsMode defines how an erro error matches a target erro error in [errors.Is], see [WithIsMode].
+type IsMode int32
+
+const (
+	// IsMetadata matches errors with equal IDs and, when the target has no ID, errors
+	// with the same class, category, severity and retryable flag, so an [Error]
+	// implementation with only metadata works as a target. It is the default.
+	IsMetadata IsMode = iota
+	// IsIdentity matches only the same error value and errors with equal IDs, see [IsStrict].
+	IsIdentity
+)
+
+// Configure applies package-wide options. It is intended to be called once at
+// program start, but it is safe to call concurrently with error creation.
+//
+// Example:
+//
+//	erro.Configure(
+//	    erro.WithRedactedPlaceholder("<redacted>"),
+//	    erro.WithMissingFieldPlaceholder("<none>"),
+//	)
+func Configure(opts ...ConfigOption) {
+	for _, opt := range opts {
+		if opt != nil {
+			opt()
+		}
+	}
+}
+
+// WithRedactedPlaceholder sets the text that replaces redacted values in Error(),
+// log fields and JSON. An empty string restores [RedactedPlaceholder].
+func WithRedactedPlaceholder(placeholder string) ConfigOption {
+	return func() {
+		redactedPlaceholder.Store(placeholder)
+	}
+}
+
+// WithMissingFieldPlaceholder sets the value added to a key without a value when an
+// error is created. An empty string restores [MissingFieldPlaceholder].
+func WithMissingFieldPlaceholder(placeholder string) ConfigOption {
+	return func() {
+		missingFieldPlaceholder.Store(placeholder)
+	}
+}
+
+// WithAutoStackSeverity enables automatic stack trace capture for errors with the given
+// severity or higher, even without the [StackTrace] option. Errors with a lower or unknown
+// severity capture a stack only if asked explicitly. A stack is not captured again if
+// a wrapped error already has one. [SeverityUnknown] disables automatic capture.
+//
+// Example:
+//
+//	erro.Configure(erro.WithAutoStackSeverity(erro.SeverityHigh))
+func WithAutoStackSeverity(severity ErrorSeverity) ConfigOption {
+	return func() {
+		autoStackSeverity.Store(severity)
+	}
+}
+
+// WithRedactedPanicValues sets whether values recovered by [FromPanic] are added as
+// redacted fields, for panics that may carry user data.
+func WithRedactedPanicValues(redact ...bool) ConfigOption {
+	return func() {
+		redactPanicValues.Store(len(redact) == 0 || redact[0])
+	}
+}
+
+// WithCollisionDetection sets what happens when a wrapping error re-attaches a field key
+// already present deeper in the chain with a different value, a silent data-shadowing bug.
+// It is intended for debug builds and tests; [CollisionIgnore] disables the check.
+//
+// Example:
+//
+//	erro.Configure(erro.WithCollisionDetection(erro.CollisionPanic))
+func WithCollisionDetection(mode CollisionMode) ConfigOption {
+	return func() {
+		collisionMode.Store(mode)
+	}
+}
+
+// WithIsMode sets how erro errors match targets in [errors.Is] and [Is].
+// [IsStrict] always uses [IsIdentity].
+//
+// Example:
+//
+//	erro.Configure(erro.WithIsMode(erro.IsIdentity))
+func WithIsMode(mode IsMode) ConfigOption {
+	return func() {
+		isMode.Store(mode)
+	}
+}
+
+// WithAutoMetrics records every error created in the process that matches all rules to
+// the metrics, as if [RecordMetrics] was passed to every call site. A chain is recorded
+// once, by the first layer that matches, so wrapping does not count an error again;
+// don't pass [RecordMetrics] with the same metrics at call sites. Nil metrics disable it.
+//
+// Example:
+//
+//	erro.Configure(erro.WithAutoMetrics(recorder, erro.MinSeverity(erro.SeverityMedium)))
+func WithAutoMetrics(m ErrorMetrics, rules ...AutoRule) ConfigOption {
+	return func() {
+		if m == nil {
+			autoMetrics.Store((*autoPolicy)(nil))
+			return
+		}
+		autoMetrics.Store(&autoPolicy{rules: append([]AutoRule(nil), rules...), metrics: m})
+	}
+}
+
+// WithAutoEvents sends every error created in the process that matches all rules to the
+// dispatcher with a background context, see [WithAutoMetrics] and [SendEvent] for the
+// semantics. Nil dispatcher disables it.
+//
+// Example:
+//
+//	erro.Configure(erro.WithAutoEvents(dispatcher, erro.MinSeverity(erro.SeverityHigh)))
+func WithAutoEvents(d EventDispatcher, rules ...AutoRule) ConfigOption {
+	return func() {
+		if d == nil {
+			autoEvents.Store((*autoPolicy)(nil))
+			return
+		}
+		autoEvents.Store(&autoPolicy{rules: append([]AutoRule(nil), rules...), events: d})
+	}
+}
+
+// WithStackCapturer sets the [StackCapturer] of new errors. Nil restores the default
+// capturer of Go frames.
+//
+// Example:
+//
+//	erro.Configure(erro.WithStackCapturer(unwind.Capturer{}))
+func WithStackCapturer(c StackCapturer) ConfigOption {
+	return func() {
+		stackCapturer.Store(stackCapturerBox{capturer: c})
+	}
+}
+
+// WithTenantPolicy makes errors without a [Tenant] created in the packages of the policy
+// fail a runtime check, by their origin frame. The check finds the caller of every new
+// error, so it is intended for debug builds and tests. Nil policy disables it.
+//
+// Example:
+//
+//	erro.Configure(erro.WithTenantPolicy(&erro.TenantPolicy{
+//	    Packages: []string{"github.com/app/billing/..."},
+//	    Panic:    true,
+//	}))
+func WithTenantPolicy(policy *TenantPolicy) ConfigOption {
+	return func() {
+		if policy != nil {
+			copied := *policy
+			copied.Packages = append([]string(nil), policy.Packages...)
+			policy = &copied
+		}
+		tenantPolicy.Store(policy)
+	}
+}
+
+// WithMultiErrorFormatter sets the formatter of the messages of multi-errors of lists,
+// sets and [Join] that have no formatter of their own, see [List.WithFormatter].
+// Nil restores the default "multiple errors (2): [1] ...; [2] ..." format.
+//
+// Example:
+//
+//	erro.Configure(erro.WithMultiErrorFormatter(erro.MultiErrorFormat{
+//	    Header:      "%d errors: ",
+//	    Enumeration: "- ",
+//	    ShowCounts:  true,
+//	}.Formatter()))
+func WithMultiErrorFormatter(f MultiErrorFormatter) ConfigOption {
+	return func() {
+		multiErrorFormatter.Store(f)
+	}
+}
+
+// WithAttachmentLimits sets the maximum size of a payload of [Attachment] and of all
+// payloads attached to one error. Zero or less restores [DefaultMaxAttachmentSize] and
+// [DefaultMaxAttachmentsSize].
+func WithAttachmentLimits(maxSize, maxTotal int) ConfigOption {
+	return func() {
+		maxAttachmentSize.Store(maxSize)
+		maxAttachmentsSize.Store(maxTotal)
+	}
+}
+
+// WithUTF8Sanitization sets whether invalid UTF-8 sequences in messages and string field
+// values are replaced with U+FFFD in Error(), log fields and [ErrorToJSON], and whether
+// errors created with invalid UTF-8 get the [InvalidUTF8Field] field. It is enabled by
+// default; disable it to output the original bytes.
+func WithUTF8Sanitization(enable bool) ConfigOption {
+	return func() {
+		rawUTF8.Store(!enable)
+	}
+}
+
+// WithOptionDiagnostics sets what happens when options are misused at a call site: an
+// option passed as the value of a field, e.g. New("msg", "id", erro.ID("x")), or [ID],
+// a class, a category or a severity passed more than once. The call site outside of the
+// package is reported. It is intended for debug builds and tests; [OptionDiagnosticsOff]
+// disables the checks.
+//
+// Example:
+//
+//	erro.Configure(erro.WithOptionDiagnostics(erro.OptionDiagnosticsPanic))
+func WithOptionDiagnostics(mode OptionDiagnosticsMode) ConfigOption {
+	return func() {
+		optionDiagnosticsMode.Store(mode)
+	}
+}
+
+// WithRenderSensitivity sets the maximum class of [Sensitive] values rendered in Error(),
+// log fields and JSON; values of a higher class are redacted. Zero restores
+// [SensitivityInternal]. Log and JSON outputs can override it with [WithLogSensitivity]
+// and [WithJSONSensitivity].
+func WithRenderSensitivity(level Sensitivity) ConfigOption {
+	return func() {
+		renderSensitivityLevel.Store(level)
+	}
+}
+
+// WithBuildInfo sets whether root errors get the [BuildVersionField], [BuildRevisionField]
+// and [BuildDirtyField] fields from the build info of the binary, so error reports
+// identify the deployed commit. Values unknown to the build, e.g. the revision of a
+// binary built outside of a VCS checkout, are omitted. It is disabled by default.
+//
+// Example:
+//
+//	erro.Configure(erro.WithBuildInfo())
+//	// disk full path=/data build_version=v1.4.2 build_revision=9f2c1e7... build_dirty=false
+func WithBuildInfo(enable ...bool) ConfigOption {
+	return func() {
+		if len(enable) > 0 && !enable[0] {
+			buildInfoFields.Store([]any(nil))
+			return
+		}
+		buildInfoFields.Store(buildInfoFieldsOf(buildInfo))
+	}
+}
+
+// WithInterceptor adds an interceptor called with every error created with [New], [Wrap]
+// and the other constructors of the package, after its fields, metadata and stack trace
+// are set and before it is recorded by [WithAutoMetrics] and [WithAutoEvents]. Use it to
+// enrich errors centrally, e.g. with the build version, region or pod name. Interceptors
+// run in the order they were added, each with the result of the previous one; they are
+// called concurrently. Errors created in an interceptor are intercepted as well, so build
+// them with [Enrich] or [Error.Wrap], or pass [SkipInterceptors] to [New]; otherwise the
+// interceptor calls itself until the stack overflows. Nil removes all interceptors.
+//
+// Example:
+//
+//	erro.Configure(erro.WithInterceptor(func(e erro.Error) erro.Error {
+//	    return erro.Enrich(e, "pod", os.Getenv("POD_NAME"))
+//	}))
+func WithInterceptor(fn Interceptor) ConfigOption {
+	return func() {
+		interceptorsMu.Lock()
+		defer interceptorsMu.Unlock()
+		if fn == nil {
+			interceptors.Store([]Interceptor(nil))
+			return
+		}
+		current := interceptors.Load()
+		interceptors.Store(append(current[:len(current):len(current)], fn))
+	}
+}
+
+// GetRedactedPlaceholder returns the placeholder for redacted values set with
+// [WithRedactedPlaceholder], [RedactedPlaceholder] by default. Use it to redact
+// values outside of errors the same way.
+func GetRedactedPlaceholder() string {
+	return redacted()
+}
+
+// redacted returns the configured placeholder for redacted values.
+func redacted() string {
+	if p := redactedPlaceholder.Load(); p != "" {
+		return p
+	}
+	return RedactedPlaceholder
+}
+
+// missingField returns the configured placeholder for missing field values.
+func missingField() string {
+	if p := missingFieldPlaceholder.Load(); p != "" {
+		return p
+	}
+	return MissingFieldPlaceholder
+}
@@ -0,0 +1,126 @@
+package erro
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestConfigurePlaceholders(t *testing.T) {
+	Configure(WithRedactedPlaceholder("<redacted>"), WithMissingFieldPlaceholder("<none>"))
+	defer Configure(WithRedactedPlaceholder(""), WithMissingFieldPlaceholder(""))
+
+	err := New("login failed", "password", Redact("secret"), "dangling")
+	if got := err.Error(); got != "login failed password=<redacted> dangling=<none>" {
+		t.Errorf("unexpected Error(): %s", got)
+	}
+	if m := LogFieldsMap(err); m["password"] != "<redacted>" {
+		t.Errorf("expected configured placeholder in log fields, got %v", m["password"])
+	}
+	if schema := ErrorToJSON(err); schema.Fields[1] != "<redacted>" {
+		t.Errorf("expected configured placeholder in JSON, got %v", schema.Fields)
+	}
+
+	Configure(WithRedactedPlaceholder(""))
+	if got := New("test", "key", Redact("v")).Error(); got != "test key="+RedactedPlaceholder {
+		t.Errorf("expected default placeholder to be restored, got %s", got)
+	}
+}
+
+func TestSetClock(t *testing.T) {
+	fixed := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	SetClock(func() time.Time { return fixed })
+	defer SetClock(nil)
+
+	if created := New("test").Created(); !created.Equal(fixed) {
+		t.Errorf("expected created time %v, got %v", fixed, created)
+	}
+	SetClock(nil)
+	if created := New("test").Created(); created.Equal(fixed) {
+		t.Error("expected nil to restore the default clock")
+	}
+}
+
+func TestConfigureAutoStackSeverity(t *testing.T) {
+	if err := New("no policy", SeverityCritical); len(err.Stack()) != 0 {
+		t.Errorf("expected no stack without policy, got %d frames", len(err.Stack()))
+	}
+
+	Configure(WithAutoStackSeverity(SeverityHigh))
+	defer Configure(WithAutoStackSeverity(SeverityUnknown))
+
+	for _, err := range []Error{
+		New("critical", SeverityCritical),
+		New("high", SeverityHigh),
+		Wrap(errors.New("io"), "read failed", SeverityHigh),
+	} {
+		stack := err.Stack()
+		if len(stack) == 0 {
+			t.Errorf("expected stack for %q", err.Message())
+			continue
+		}
+		if stack[0].Name != "TestConfigureAutoStackSeverity" {
+			t.Errorf("expected stack to start at the caller, got %s", stack[0].FullName)
+		}
+	}
+
+	for _, err := range []Error{New("low", SeverityLow), New("unknown"), Wrap(errors.New("io"), "read failed")} {
+		if len(err.Stack()) != 0 {
+			t.Errorf("expected no stack for %q", err.Message())
+		}
+	}
+	if err := New("low", SeverityLow, StackTrace()); len(err.Stack()) == 0 {
+		t.Error("expected explicit stack trace to be captured below the threshold")
+	}
+
+	inner := New("inner", SeverityCritical)
+	outer := Wrap(inner, "outer")
+	if outer.(*baseError).stack != nil {
+		t.Error("expected wrapper to reuse the stack of the wrapped error")
+	}
+	if len(outer.Stack()) == 0 {
+		t.Error("expected stack from the wrapped error")
+	}
+}
+
+func TestIsMode(t *testing.T) {
+	target := &baseError{message: "template", class: ClassNotFound, category: CategoryDatabase}
+	err := Wrap(New("user not found", ClassNotFound, CategoryDatabase), "get user")
+	withID := Wrap(New("order not found", ID("ORD-404")), "get order")
+
+	if !Is(err, target) || IsStrict(err, target) {
+		t.Fatal("expected metadata matching only in Is by default")
+	}
+	if !IsStrict(withID, New("other", ID("ORD-404"))) || IsStrict(withID, New("other", ID("ORD-500"))) {
+		t.Error("IsStrict must match by ID")
+	}
+	if !IsStrict(err, err) || IsStrict(err, nil) || !IsStrict(nil, nil) {
+		t.Error("IsStrict must match the same error value")
+	}
+	if !IsStrict(Wrap(context.Canceled, "stop"), context.Canceled) {
+		t.Error("IsStrict must match wrapped standard errors")
+	}
+	if !IsStrict(Join(err, withID), withID) || IsStrict(Join(err, errors.New("x")), target) {
+		t.Error("IsStrict must check joined errors by identity")
+	}
+	if !IsStrict(Wrap(fmt.Errorf("ctx: %w", matchAllError{}), "outer"), target) {
+		t.Error("IsStrict must respect Is methods of other error types")
+	}
+
+	Configure(WithIsMode(IsIdentity))
+	defer Configure(WithIsMode(IsMetadata))
+	if Is(err, target) {
+		t.Error("expected no metadata matching with IsIdentity")
+	}
+	if !Is(withID, New("other", ID("ORD-404"))) || !Is(err, err) {
+		t.Error("expected ID and identity matching with IsIdentity")
+	}
+}
+
+// matchAllError matches any target in Is.
+type matchAllError struct{}
+
+func (matchAllError) Error() string        { return "any" }
+func (matchAllError) Is(target error) bool { return true }
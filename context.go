@@ -1,10 +1,18 @@
 package erro
 
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+)
+
 // ExtractError ensures that an error can be treated as an [Error].
 //
 // If the given error is already an [Error], it is returned as is.
 // If it's a standard error, it's wrapped in a new [Error] so that
-// it can be used with the features of this package.
+// it can be used with the features of this package. The wrapping error is only an
+// adapter for inspection: interceptors, lifecycle hooks, auto metrics and events
+// are not called for it.
 // If the error is nil, it returns nil.
 func ExtractError(err error) Error {
 	if err == nil {
@@ -17,7 +25,21 @@ func ExtractError(err error) Error {
 	if As(err, &errError) {
 		return errError
 	}
-	return newWrapError(err, "")
+	return newAdapterError(err)
+}
+
+// newAdapterError wraps an error not created by the package with an empty message
+// without running the creation hooks, see [ExtractError].
+func newAdapterError(err error) *baseError {
+	e := &baseError{
+		originalErr: err,
+		formatter:   FormatErrorWithFields,
+		created:     now(),
+	}
+	inheritSentinel(e, err)
+	e.id = newID(e.created.UnixNano())
+	stampBuildInfo(e)
+	return e
 }
 
 // LogFields returns a slice of alternating key-value pairs for structured
@@ -128,6 +150,7 @@ func LogError(err error, logFunc func(message string, fields ...any), optFuncs .
 		opts = (&LogOptions{}).ApplyOptions(optFuncs...)
 	}
 	logFunc(errError.Message(), getLogFields(errError, opts)...)
+	lifecycle(StageLogged, errError)
 }
 
 // LogErrorWithOptions executes a callback with the error message and structured fields,
@@ -156,21 +179,41 @@ func LogErrorWithOptions(err error, logFunc func(message string, fields ...any),
 	}
 
 	logFunc(errError.Message(), getLogFields(errError, opts)...)
+	lifecycle(StageLogged, errError)
 }
 
 // ErrorToJSON converts an error to a serializable [ErrorSchema] struct.
 //
 // This is useful for sending error details over the network or storing them
-// in a structured format. Sensitive fields are redacted.
-func ErrorToJSON(err Error) ErrorSchema {
+// in a structured format. Sensitive fields are redacted. Options are applied on top
+// of [DefaultJSONOptions], which are also used by json.Marshal.
+func ErrorToJSON(err Error, optFuncs ...JSONOption) ErrorSchema {
+	opts := DefaultJSONOptions
+	for _, opt := range optFuncs {
+		opt(&opts)
+	}
+	lifecycle(StageSerialized, err)
+
 	schema := ErrorSchema{
-		ID:        err.ID(),
-		Class:     err.Class(),
-		Category:  err.Category(),
-		Severity:  err.Severity(),
-		Created:   err.Created(),
-		Message:   err.Message(),
-		Retryable: err.IsRetryable(),
+		ID:          err.ID(),
+		Class:       err.Class(),
+		Category:    err.Category(),
+		Severity:    err.Severity(),
+		Created:     err.Created(),
+		Message:     validUTF8(err.Message()),
+		Retryable:   err.IsRetryable(),
+		Attempt:     err.Attempt(),
+		MaxAttempts: err.MaxAttempts(),
+		Hint:        HintOf(err),
+		DocURL:      DocURLOf(err),
+	}
+	schema.RetryAfter, _ = err.RetryAfter()
+	schema.TTL, _ = err.TTL()
+	if opts.Bilingual {
+		schema.UserMessage = UserMessageOf(err)
+	}
+	if opts.Attachments {
+		schema.Attachments = Attachments(err)
 	}
 
 	// Redact sensitive fields before serialization.
@@ -179,11 +222,21 @@ func ErrorToJSON(err Error) ErrorSchema {
 		redactedFields := make([]any, len(allFields))
 		copy(redactedFields, allFields)
 		for i := 1; i < len(redactedFields); i += 2 {
+			redactedFields[i] = resolveSensitive(redactedFields[i], opts.MaxSensitivity)
 			if _, ok := redactedFields[i].(RedactedValue); ok {
-				redactedFields[i] = RedactedPlaceholder
+				redactedFields[i] = redacted()
+			} else if opts.RawJSONValues {
+				redactedFields[i] = rawJSONValue(redactedFields[i])
 			}
+			redactedFields[i-1] = validUTF8Value(redactedFields[i-1])
+			redactedFields[i] = validUTF8Value(redactedFields[i])
+		}
+		schema.FieldErrors = fieldErrorsFromFields(redactedFields)
+		if opts.KeyValueFields {
+			schema.Fields = toKeyValueFields(redactedFields)
+		} else {
+			schema.Fields = redactedFields
 		}
-		schema.Fields = redactedFields
 	}
 
 	span := err.Span()
@@ -204,6 +257,127 @@ func ErrorToJSON(err Error) ErrorSchema {
 	return schema
 }
 
+// JSONOption is a function that configures JSON serialization options.
+type JSONOption func(*JSONOptions)
+
+// JSONOptions controls how fields are serialized by [ErrorToJSON].
+type JSONOptions struct {
+	// RawJSONValues embeds field values implementing [json.Marshaler] as raw JSON and
+	// stringifies other complex values, so every value is valid JSON with a stable type.
+	RawJSONValues bool
+	// KeyValueFields serializes fields as an array of {"key": ..., "value": ...} objects
+	// instead of a flat array of keys and values.
+	KeyValueFields bool
+	// Bilingual outputs both the technical message and the end-user message of [UserMsg]:
+	// [ErrorToJSON] adds the user message and [ToProblemDetails] adds the technical message.
+	// Use it for support tooling, public APIs should show only the user message.
+	Bilingual bool
+	// Attachments outputs the payloads of [Attachment] of the error chain, for dispatchers
+	// that ship them, e.g. to Sentry or a webhook.
+	Attachments bool
+	// MaxSensitivity is the maximum class of [Sensitive] values that are serialized,
+	// values of a higher class are redacted. Zero means the level of [WithRenderSensitivity].
+	MaxSensitivity Sensitivity
+}
+
+// DefaultJSONOptions are the options used by [ErrorToJSON] and json.Marshal.
+var DefaultJSONOptions = JSONOptions{}
+
+// KeyValue is a field serialized with [JSONOptions.KeyValueFields].
+type KeyValue struct {
+	Key   string `json:"key" bson:"key" db:"key"`
+	Value any    `json:"value" bson:"value" db:"value"`
+}
+
+// WithRawJSONValues returns a [JSONOption] to embed [json.Marshaler] values as raw JSON.
+func WithRawJSONValues(enable ...bool) JSONOption {
+	return func(opts *JSONOptions) {
+		opts.RawJSONValues = true
+		if len(enable) > 0 {
+			opts.RawJSONValues = enable[0]
+		}
+	}
+}
+
+// WithKeyValueFields returns a [JSONOption] to serialize fields as key/value objects.
+func WithKeyValueFields(enable ...bool) JSONOption {
+	return func(opts *JSONOptions) {
+		opts.KeyValueFields = true
+		if len(enable) > 0 {
+			opts.KeyValueFields = enable[0]
+		}
+	}
+}
+
+// WithBilingual returns a [JSONOption] to output both the technical and the end-user message.
+func WithBilingual(enable ...bool) JSONOption {
+	return func(opts *JSONOptions) {
+		opts.Bilingual = true
+		if len(enable) > 0 {
+			opts.Bilingual = enable[0]
+		}
+	}
+}
+
+// WithJSONSensitivity returns a [JSONOption] to set the maximum class of serialized [Sensitive] values.
+func WithJSONSensitivity(level Sensitivity) JSONOption {
+	return func(opts *JSONOptions) {
+		opts.MaxSensitivity = level
+	}
+}
+
+// WithAttachments returns a [JSONOption] to output the attachments of the error.
+func WithAttachments(enable ...bool) JSONOption {
+	return func(opts *JSONOptions) {
+		opts.Attachments = true
+		if len(enable) > 0 {
+			opts.Attachments = enable[0]
+		}
+	}
+}
+
+// rawJSONValue returns a value that is serialized as is: JSON primitives are kept,
+// [json.Marshaler] values are embedded as raw JSON and other values are stringified.
+func rawJSONValue(value any) any {
+	switch v := value.(type) {
+	case nil, string, bool, int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64:
+		return v
+	case json.Marshaler:
+		if b, err := v.MarshalJSON(); err == nil && json.Valid(b) {
+			return json.RawMessage(b)
+		}
+	}
+	return valueToString(value)
+}
+
+func toKeyValueFields(fields []any) []any {
+	out := make([]any, 0, len(fields)/2)
+	for i := 0; i+1 < len(fields); i += 2 {
+		out = append(out, KeyValue{Key: valueToString(fields[i]), Value: fields[i+1]})
+	}
+	return out
+}
+
+// fromKeyValueFields expands fields decoded from the key/value objects form into a flat list.
+// Fields in the flat form are returned as is.
+func fromKeyValueFields(fields []any) []any {
+	if len(fields) == 0 {
+		return fields
+	}
+	if _, ok := fields[0].(map[string]any); !ok {
+		return fields
+	}
+	out := make([]any, 0, len(fields)*2)
+	for _, f := range fields {
+		kv, ok := f.(map[string]any)
+		if !ok {
+			return fields
+		}
+		out = append(out, kv["key"], kv["value"])
+	}
+	return out
+}
+
 // LogOption is a function that configures logging options.
 type LogOption func(*LogOptions)
 
@@ -239,12 +413,36 @@ type LogOptions struct {
 	IncludeLine bool
 	// IncludeStack determines whether to include the full stack trace.
 	IncludeStack bool
+	// IncludeTemplateSite determines whether to include the declaration site of the
+	// template the error was created from, see [TemplateSite].
+	IncludeTemplateSite bool
 
 	// StackFormat defines how stack traces should be formatted.
 	StackFormat StackFormat
 
+	// FrameHashSalt enables the privacy mode of stack fields if it is not empty: the
+	// function, package, file, line and stack fields are replaced with the [FrameHashField]
+	// field of salted frame hashes, see [WithFrameHashes].
+	FrameHashSalt string
+
 	// FieldNamePrefix is a prefix added to all field names. Default is "error_".
 	FieldNamePrefix string
+
+	// SortFields determines whether user fields are sorted by key for byte-stable output.
+	SortFields bool
+
+	// NestedGroups determines whether [LogFieldsMap] puts fields added with [Group]
+	// into nested maps ({"db": {"query": ...}}) instead of dotted keys.
+	NestedGroups bool
+
+	// SuffixDuplicateKeys determines whether a user field key that was already added by an
+	// outer layer is suffixed with the depth of its layer ("user_id_2") instead of being
+	// repeated and overwritten in [LogFieldsMap], see [AllFieldsAnnotated].
+	SuffixDuplicateKeys bool
+
+	// MaxSensitivity is the maximum class of [Sensitive] values that are logged, values of
+	// a higher class are redacted. Zero means the level of [WithRenderSensitivity].
+	MaxSensitivity Sensitivity
 }
 
 // StackFormat defines how stack traces should be formatted in logs.
@@ -431,6 +629,16 @@ func WithLine(include ...bool) LogOption {
 	}
 }
 
+// WithTemplateSite returns a [LogOption] to enable or disable the template declaration site field.
+func WithTemplateSite(include ...bool) LogOption {
+	return func(opts *LogOptions) {
+		opts.IncludeTemplateSite = true
+		if len(include) > 0 {
+			opts.IncludeTemplateSite = include[0]
+		}
+	}
+}
+
 // WithStack returns a [LogOption] to enable or disable the full stack trace field.
 func WithStack(include ...bool) LogOption {
 	return func(opts *LogOptions) {
@@ -449,6 +657,21 @@ func WithStackFormat(format StackFormat) LogOption {
 	}
 }
 
+// WithFrameHashes returns a [LogOption] that replaces the names of stack frames with
+// salted hashes, for telemetry that must not contain function and file names. If the
+// stack is included, the [FrameHashField] field lists all frames, see [Stack.Hashes];
+// otherwise it has only the top frame if any of the function, package, file or line
+// fields is included. Keep the salt secret and resolve hashes with a [FrameHashIndex].
+//
+// Example:
+//
+//	erro.LogError(err, logger.Error, erro.WithStack(), erro.WithFrameHashes(os.Getenv("FRAME_SALT")))
+func WithFrameHashes(salt string) LogOption {
+	return func(opts *LogOptions) {
+		opts.FrameHashSalt = salt
+	}
+}
+
 // WithFieldNamePrefix returns a [LogOption] to set the field name prefix.
 func WithFieldNamePrefix(prefix string) LogOption {
 	return func(opts *LogOptions) {
@@ -456,6 +679,43 @@ func WithFieldNamePrefix(prefix string) LogOption {
 	}
 }
 
+// WithSortedFields returns a [LogOption] to enable or disable sorting of user fields by key.
+func WithSortedFields(sort ...bool) LogOption {
+	return func(opts *LogOptions) {
+		opts.SortFields = true
+		if len(sort) > 0 {
+			opts.SortFields = sort[0]
+		}
+	}
+}
+
+// WithNestedGroups returns a [LogOption] to put fields added with [Group] into nested maps in [LogFieldsMap].
+func WithNestedGroups(nested ...bool) LogOption {
+	return func(opts *LogOptions) {
+		opts.NestedGroups = true
+		if len(nested) > 0 {
+			opts.NestedGroups = nested[0]
+		}
+	}
+}
+
+// WithLogSensitivity returns a [LogOption] to set the maximum class of logged [Sensitive] values.
+func WithLogSensitivity(level Sensitivity) LogOption {
+	return func(opts *LogOptions) {
+		opts.MaxSensitivity = level
+	}
+}
+
+// WithDuplicateKeySuffix returns a [LogOption] to suffix duplicate user field keys of wrapped errors with their depth.
+func WithDuplicateKeySuffix(suffix ...bool) LogOption {
+	return func(opts *LogOptions) {
+		opts.SuffixDuplicateKeys = true
+		if len(suffix) > 0 {
+			opts.SuffixDuplicateKeys = suffix[0]
+		}
+	}
+}
+
 // ApplyOptions applies a set of option functions to [LogOptions].
 func (opts *LogOptions) ApplyOptions(optFuncs ...LogOption) LogOptions {
 	for _, optFunc := range optFuncs {
@@ -467,6 +727,10 @@ func (opts *LogOptions) ApplyOptions(optFuncs ...LogOption) LogOptions {
 // getLogFieldsMap converts [Error] to slog-compatible fields with options
 func getLogFieldsMap(ec Error, optsRaw ...LogOptions) map[string]any {
 	fields := getLogFields(ec, optsRaw...)
+	opts := DefaultLogOptions
+	if len(optsRaw) > 0 {
+		opts = optsRaw[0]
+	}
 
 	fieldsMap := make(map[string]any, len(fields))
 	for i := 0; i < len(fields); i += 2 {
@@ -477,11 +741,59 @@ func getLogFieldsMap(ec Error, optsRaw ...LogOptions) map[string]any {
 		if !ok {
 			key = valueToString(fields[i])
 		}
-		fieldsMap[truncateString(key, MaxKeyLength)] = fields[i+1]
+		key = truncateString(key, MaxKeyLength)
+		if opts.NestedGroups && strings.Contains(key, GroupSeparator) {
+			setNestedField(fieldsMap, key, fields[i+1])
+			continue
+		}
+		fieldsMap[key] = fields[i+1]
 	}
 	return fieldsMap
 }
 
+// setNestedField puts the value of a dotted key into nested maps. If a part of the path
+// is already taken by a plain value, the full dotted key is used to keep both values.
+func setNestedField(fieldsMap map[string]any, key string, value any) {
+	parts := strings.Split(key, GroupSeparator)
+	current := fieldsMap
+	for _, part := range parts[:len(parts)-1] {
+		next, exists := current[part]
+		if !exists {
+			nested := make(map[string]any)
+			current[part] = nested
+			current = nested
+			continue
+		}
+		nested, ok := next.(map[string]any)
+		if !ok {
+			fieldsMap[key] = value
+			return
+		}
+		current = nested
+	}
+	last := parts[len(parts)-1]
+	if _, ok := current[last].(map[string]any); ok {
+		fieldsMap[key] = value
+		return
+	}
+	current[last] = value
+}
+
+// suffixDuplicateKeys returns key-value pairs where a key seen in an outer layer gets the depth of its layer as a suffix.
+func suffixDuplicateKeys(annotated []FieldWithOrigin) []any {
+	fields := make([]any, 0, len(annotated)*2)
+	seen := make(map[string]struct{}, len(annotated))
+	for _, f := range annotated {
+		key := f.Key
+		if _, ok := seen[key]; ok {
+			key += "_" + strconv.Itoa(f.Depth)
+		}
+		seen[key] = struct{}{}
+		fields = append(fields, key, f.Value)
+	}
+	return fields
+}
+
 // getLogFields converts [Error] to logrus-compatible fields with options
 func getLogFields(ec Error, optsRaw ...LogOptions) []any {
 	if ec == nil {
@@ -494,7 +806,7 @@ func getLogFields(ec Error, optsRaw ...LogOptions) []any {
 	}
 
 	var (
-		errorMessage      = ec.Message()
+		errorMessage      = validUTF8(ec.Message())
 		errorID           = ec.ID()
 		errorCategory     = ec.Category()
 		errorSeverity     = ec.Severity()
@@ -517,12 +829,19 @@ func getLogFields(ec Error, optsRaw ...LogOptions) []any {
 	fields := make([]any, 0, len(errorFields)+30)
 
 	// Add user fields
+	if opts.IncludeUserFields && opts.SuffixDuplicateKeys && len(errorFields) > 0 {
+		errorFields = suffixDuplicateKeys(AllFieldsAnnotated(ec))
+	}
 	if opts.IncludeUserFields && len(errorFields) > 0 {
+		if opts.SortFields {
+			errorFields = sortFieldPairs(errorFields)
+		}
 		for i := 0; i < len(errorFields); i++ {
-			if _, ok := errorFields[i].(RedactedValue); ok {
-				fields = append(fields, RedactedPlaceholder)
+			value := resolveSensitive(errorFields[i], opts.MaxSensitivity)
+			if _, ok := value.(RedactedValue); ok {
+				fields = append(fields, redacted())
 			} else {
-				fields = append(fields, errorFields[i])
+				fields = append(fields, validUTF8Value(value))
 			}
 		}
 	}
@@ -553,8 +872,19 @@ func getLogFields(ec Error, optsRaw ...LogOptions) []any {
 			fields = append(fields, "parent_span_id", errorParentSpanID)
 		}
 	}
-	if opts.IncludeRetryable && errorRetryable {
-		fields = append(fields, opts.FieldNamePrefix+"retryable", errorRetryable)
+	if opts.IncludeRetryable {
+		if errorRetryable {
+			fields = append(fields, opts.FieldNamePrefix+"retryable", errorRetryable)
+		}
+		if attempt := ec.Attempt(); attempt > 0 {
+			fields = append(fields, opts.FieldNamePrefix+"attempt", attempt)
+		}
+		if maxAttempts := ec.MaxAttempts(); maxAttempts > 0 {
+			fields = append(fields, opts.FieldNamePrefix+"max_attempts", maxAttempts)
+		}
+		if retryAfter, ok := ec.RetryAfter(); ok {
+			fields = append(fields, opts.FieldNamePrefix+"retry_after", retryAfter)
+		}
 	}
 
 	// Add timing information
@@ -562,6 +892,10 @@ func getLogFields(ec Error, optsRaw ...LogOptions) []any {
 		fields = append(fields, opts.FieldNamePrefix+"created", errorCreated)
 	}
 
+	if opts.FrameHashSalt != "" {
+		return appendFrameHashes(fields, errorStack, opts)
+	}
+
 	if opts.IncludeFunction || opts.IncludePackage || opts.IncludeFile || opts.IncludeLine {
 		topFrame := errorStack.TopUserFrame()
 		if topFrame == nil && len(errorStack) > 0 {
@@ -582,6 +916,13 @@ func getLogFields(ec Error, optsRaw ...LogOptions) []any {
 		}
 	}
 
+	if opts.IncludeTemplateSite {
+		if site, ok := TemplateSite(ec); ok {
+			fields = append(fields, opts.FieldNamePrefix+"template_site",
+				site.Package+"/"+site.FileName+":"+strconv.Itoa(site.Line))
+		}
+	}
+
 	// Add stack trace if requested
 	if opts.IncludeStack {
 		stack := getStackTrace(errorStack, opts)
@@ -593,6 +934,24 @@ func getLogFields(ec Error, optsRaw ...LogOptions) []any {
 	return fields
 }
 
+// appendFrameHashes adds the hashes of the top frame or the whole stack.
+func appendFrameHashes(fields []any, stack Stack, opts LogOptions) []any {
+	if len(stack) == 0 {
+		return fields
+	}
+	if opts.IncludeStack {
+		return append(fields, opts.FieldNamePrefix+FrameHashField, stack.Hashes(opts.FrameHashSalt))
+	}
+	if opts.IncludeFunction || opts.IncludePackage || opts.IncludeFile || opts.IncludeLine {
+		topFrame := stack.TopUserFrame()
+		if topFrame == nil {
+			topFrame = &stack[0]
+		}
+		return append(fields, opts.FieldNamePrefix+FrameHashField, Stack{*topFrame}.Hashes(opts.FrameHashSalt))
+	}
+	return fields
+}
+
 // getStackTrace returns the stack trace in the requested format
 func getStackTrace(stack Stack, opts LogOptions) any {
 	if len(stack) == 0 {
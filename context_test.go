@@ -3,14 +3,19 @@ package erro
 import (
 	"encoding/json"
 	"errors"
+	"fmt"
 	"reflect"
+	"strconv"
+	"strings"
 	"testing"
+	"time"
 )
 
 type mockTraceSpan struct {
 	traceID      string
 	spanID       string
 	parentSpanID string
+	unsampled    bool
 }
 
 func (m *mockTraceSpan) RecordError(err Error) {}
@@ -29,6 +34,10 @@ func (m *mockTraceSpan) ParentSpanID() string {
 	return m.parentSpanID
 }
 
+func (m *mockTraceSpan) IsSampled() bool {
+	return !m.unsampled
+}
+
 func newMockSpan(traceID, spanID, parentSpanID string) TraceSpan {
 	return &mockTraceSpan{
 		traceID:      traceID,
@@ -1152,6 +1161,172 @@ func TestUnmarshalJSON(t *testing.T) {
 	}
 }
 
+func TestUnmarshalJSONResetsState(t *testing.T) {
+	err := New("old", StackTrace(&StackTraceConfig{}), CompressChain(1), Hint("hint")).(*baseError)
+	err.extras.callerSkip = 2
+	err.template = &ErrorTemplate{}
+	err.autoDone = 1
+	err.intercepting = true
+	_ = err.Error()
+
+	if jErr := json.Unmarshal([]byte(`{"id":"x","message":"new"}`), err); jErr != nil {
+		t.Fatal(jErr)
+	}
+	if err.extras != nil || err.template != nil || err.autoDone != 0 || err.intercepting || err.stack != nil {
+		t.Errorf("expected state of the previous content to be reset, got %+v", err)
+	}
+	if err.Error() != "new" {
+		t.Errorf("expected cached message to be reset, got %s", err.Error())
+	}
+}
+
+func TestCopyLayerCopiesAllFields(t *testing.T) {
+	orig := &baseError{
+		originalErr:      errors.New("original"),
+		wrappedErr:       &baseError{message: "wrapped"},
+		message:          "message",
+		interpolate:      true,
+		id:               "id",
+		class:            ClassValidation,
+		category:         CategoryDatabase,
+		severity:         SeverityHigh,
+		retryable:        true,
+		attempt:          1,
+		maxAttempts:      2,
+		fields:           []any{"key", "value"},
+		span:             &mockTraceSpan{traceID: "trace"},
+		created:          time.Now(),
+		stack:            rawStack{1},
+		formatter:        FormatErrorWithFields,
+		wholeChainFormat: true,
+		depth:            1,
+		truncated:        true,
+		template:         &ErrorTemplate{},
+		autoDone:         1,
+		intercepting:     true,
+		fieldErrors:      true,
+		extras: &errorExtras{
+			format:           "format %d",
+			hint:             "hint",
+			userMessage:      "user message",
+			docURL:           "https://example.com",
+			retryAfter:       time.Second,
+			ttl:              time.Minute,
+			stackTraceConfig: &StackTraceConfig{},
+			callerSkip:       1,
+			compressLayers:   1,
+			handoff:          &handoffInfo{},
+			goroutines:       []byte("goroutines"),
+			attachments:      []Blob{{Name: "blob"}},
+		},
+		frozen: 1,
+	}
+	orig.fullMessage.Store("cached")
+	orig.frames.Store(Stack{{Name: "frame"}})
+
+	c := orig.copyLayer()
+	ov, cv := reflect.ValueOf(orig).Elem(), reflect.ValueOf(c).Elem()
+	for i := 0; i < ov.NumField(); i++ {
+		name := ov.Type().Field(i).Name
+		if ov.Field(i).IsZero() {
+			t.Fatalf("field %s is not set in the test, add it to check that copyLayer copies it", name)
+		}
+		switch name {
+		case "fullMessage", "intercepting", "frozen":
+			if !cv.Field(i).IsZero() {
+				t.Errorf("expected %s to be reset in the copy", name)
+			}
+		case "extras":
+			if c.extras == orig.extras {
+				t.Fatal("expected extras to be copied, not shared")
+			}
+			oe, ce := ov.Field(i).Elem(), cv.Field(i).Elem()
+			for j := 0; j < oe.NumField(); j++ {
+				extra := oe.Type().Field(j).Name
+				if oe.Field(j).IsZero() {
+					t.Fatalf("extra %s is not set in the test, add it to check that copyLayer copies it", extra)
+				}
+				if got, want := fmt.Sprint(ce.Field(j)), fmt.Sprint(oe.Field(j)); got != want {
+					t.Errorf("extra %s is not copied: got %s, want %s", extra, got, want)
+				}
+			}
+		default:
+			if got, want := fmt.Sprint(cv.Field(i)), fmt.Sprint(ov.Field(i)); got != want {
+				t.Errorf("field %s is not copied: got %s, want %s", name, got, want)
+			}
+		}
+	}
+}
+
+func TestFromJSON(t *testing.T) {
+	original := New("test message", "key", "value", ClassValidation, SeverityHigh, Retryable(), Attempt(2))
+	data, jErr := json.Marshal(original)
+	if jErr != nil {
+		t.Fatalf("json.Marshal failed: %v", jErr)
+	}
+
+	err, jErr := FromJSON(data)
+	if jErr != nil {
+		t.Fatalf("FromJSON failed: %v", jErr)
+	}
+	if !IsFrozen(err) {
+		t.Errorf("Expected error from FromJSON to be frozen")
+	}
+	if err.ID() != original.ID() || err.Class() != ClassValidation || err.Severity() != SeverityHigh {
+		t.Errorf("Unexpected metadata: id=%s class=%s severity=%s", err.ID(), err.Class(), err.Severity())
+	}
+	if !err.IsRetryable() || err.Attempt() != 2 {
+		t.Errorf("Expected retry metadata to be restored")
+	}
+	if err.Error() != "test message key=value" {
+		t.Errorf("Unexpected error string: %s", err.Error())
+	}
+	if uErr := json.Unmarshal(data, err); !errors.Is(uErr, ErrFrozen) {
+		t.Errorf("Expected ErrFrozen on unmarshal into FromJSON result, got %v", uErr)
+	}
+
+	t.Run("Limits", func(t *testing.T) {
+		fields := make([]any, 0, maxPairsCount+10)
+		for i := 0; i < MaxFieldsCount+5; i++ {
+			fields = append(fields, strings.Repeat("k", MaxKeyLength+10), strings.Repeat("v", MaxValueLength+10))
+		}
+		data, _ := json.Marshal(ErrorSchema{ID: strings.Repeat("i", MaxKeyLength+10), Message: strings.Repeat("m", MaxMessageLength+10), Fields: fields})
+
+		err, jErr := FromJSON(data)
+		if jErr != nil {
+			t.Fatalf("FromJSON failed: %v", jErr)
+		}
+		if len(err.Message()) != MaxMessageLength {
+			t.Errorf("Expected message to be truncated to %d, got %d", MaxMessageLength, len(err.Message()))
+		}
+		if len(err.ID()) != MaxKeyLength {
+			t.Errorf("Expected ID to be truncated to %d, got %d", MaxKeyLength, len(err.ID()))
+		}
+		got := err.Fields()
+		if len(got) != maxPairsCount {
+			t.Fatalf("Expected %d fields, got %d", maxPairsCount, len(got))
+		}
+		if len(got[0].(string)) != MaxKeyLength || len(got[1].(string)) != MaxValueLength {
+			t.Errorf("Expected key and value to be truncated")
+		}
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		for name, data := range map[string]string{
+			"OddFields":       `{"id":"x","fields":["key"]}`,
+			"NonStringKey":    `{"id":"x","fields":[1,"value"]}`,
+			"UnknownSeverity": `{"id":"x","severity":"fatal"}`,
+		} {
+			if _, jErr := FromJSON([]byte(data)); !errors.Is(jErr, ErrInvalidSchema) {
+				t.Errorf("%s: expected ErrInvalidSchema, got %v", name, jErr)
+			}
+		}
+		if _, jErr := FromJSON([]byte(`not json`)); jErr == nil {
+			t.Errorf("Expected error for malformed JSON")
+		}
+	})
+}
+
 func TestMarshalJSONWithStack(t *testing.T) {
 	err := New("test error", StackTrace())
 
@@ -1169,3 +1344,135 @@ func TestMarshalJSONWithStack(t *testing.T) {
 		t.Errorf("Expected 'stack_trace' field to exist")
 	}
 }
+
+func TestWithSortedFields(t *testing.T) {
+	err := New("test", "b", 2, "c", 3, "a", 1)
+
+	fields := LogFields(err, WithUserFields(), WithSortedFields())
+	expected := []any{"a", 1, "b", 2, "c", 3}
+	if len(fields) != len(expected) {
+		t.Fatalf("expected %d fields, got %d: %v", len(expected), len(fields), fields)
+	}
+	for i := range expected {
+		if fields[i] != expected[i] {
+			t.Errorf("field %d: expected %v, got %v", i, expected[i], fields[i])
+		}
+	}
+
+	unsorted := LogFields(err, WithUserFields(), WithSortedFields(false))
+	if unsorted[0] != "b" {
+		t.Errorf("expected insertion order without sorting, got %v", unsorted)
+	}
+
+	msg := GetFormatErrorWithFullContext(WithSortedFields())(err)
+	if !strings.HasPrefix(msg, "test a=1 b=2 c=3") {
+		t.Errorf("unexpected sorted full context message: %s", msg)
+	}
+}
+
+type jsonPoint struct {
+	X, Y int
+}
+
+func (p jsonPoint) MarshalJSON() ([]byte, error) {
+	return []byte(`{"x":` + strconv.Itoa(p.X) + `,"y":` + strconv.Itoa(p.Y) + `}`), nil
+}
+
+func (p jsonPoint) String() string {
+	return "point"
+}
+
+func TestErrorToJSON_Options(t *testing.T) {
+	err := New("test", "point", jsonPoint{X: 1, Y: 2}, "tags", []string{"a"}, "count", 3)
+
+	raw, jErr := json.Marshal(ErrorToJSON(err, WithRawJSONValues()))
+	if jErr != nil {
+		t.Fatalf("json.Marshal failed: %v", jErr)
+	}
+	if !strings.Contains(string(raw), `"fields":["point",{"x":1,"y":2},"tags","a","count",3]`) {
+		t.Errorf("expected raw JSON values, got %s", raw)
+	}
+
+	kv, jErr := json.Marshal(ErrorToJSON(err, WithRawJSONValues(), WithKeyValueFields()))
+	if jErr != nil {
+		t.Fatalf("json.Marshal failed: %v", jErr)
+	}
+	if !strings.Contains(string(kv), `"fields":[{"key":"point","value":{"x":1,"y":2}},{"key":"tags","value":"a"},{"key":"count","value":3}]`) {
+		t.Errorf("expected key/value fields, got %s", kv)
+	}
+
+	decoded, jErr := FromJSON(kv)
+	if jErr != nil {
+		t.Fatalf("FromJSON failed: %v", jErr)
+	}
+	fields := decoded.Fields()
+	if len(fields) != 6 || fields[0] != "point" || fields[4] != "count" || fields[5] != float64(3) {
+		t.Errorf("expected key/value fields to be decoded into pairs, got %v", fields)
+	}
+
+	DefaultJSONOptions.KeyValueFields = true
+	defer func() { DefaultJSONOptions = JSONOptions{} }()
+	b, _ := json.Marshal(New("test", "key", "value"))
+	if !strings.Contains(string(b), `"fields":[{"key":"key","value":"value"}]`) {
+		t.Errorf("expected default options to be used by json.Marshal, got %s", b)
+	}
+}
+
+func TestAllFieldsAnnotated(t *testing.T) {
+	inner := New("query failed", "user_id", 1, "table", "users")
+	middle := Wrap(inner, "load profile")
+	outer := Wrap(middle, "handle request", "user_id", 2)
+
+	fields := AllFieldsAnnotated(outer)
+	expected := []FieldWithOrigin{
+		{Key: "user_id", Value: 2, LayerMessage: "handle request", Depth: 0},
+		{Key: "user_id", Value: 1, LayerMessage: "query failed", Depth: 2},
+		{Key: "table", Value: "users", LayerMessage: "query failed", Depth: 2},
+	}
+	if !reflect.DeepEqual(fields, expected) {
+		t.Errorf("unexpected annotated fields:\n got %+v\nwant %+v", fields, expected)
+	}
+	if fields := AllFieldsAnnotated(New("no fields")); fields != nil {
+		t.Errorf("expected nil for error without fields, got %v", fields)
+	}
+	if fields := AllFieldsAnnotated(errors.New("plain")); fields != nil {
+		t.Errorf("expected nil for a foreign error, got %v", fields)
+	}
+
+	m := LogFieldsMap(outer, WithUserFields())
+	if m["user_id"] != 1 {
+		t.Errorf("expected inner value to overwrite by default, got %v", m["user_id"])
+	}
+	m = LogFieldsMap(outer, WithUserFields(), WithDuplicateKeySuffix())
+	if m["user_id"] != 2 || m["user_id_2"] != 1 || m["table"] != "users" {
+		t.Errorf("expected duplicate key to be suffixed with its depth, got %v", m)
+	}
+	list := LogFields(Wrap(New("inner", "token", Redact("secret")), "outer", "token", "x"), WithUserFields(), WithDuplicateKeySuffix())
+	if !reflect.DeepEqual(list, []any{"token", "x", "token_1", redacted()}) {
+		t.Errorf("unexpected log fields: %v", list)
+	}
+}
+
+func TestFieldsAt(t *testing.T) {
+	inner := New("query failed", "user_id", 1, "table", "users")
+	outer := Wrap(Wrap(inner, "load profile"), "handle request", "user_id", 2)
+
+	if n := outer.LayerCount(); n != 3 {
+		t.Errorf("expected 3 layers, got %d", n)
+	}
+	if n := Wrap(errors.New("external"), "wrap").LayerCount(); n != 1 {
+		t.Errorf("expected external error not to be counted, got %d", n)
+	}
+	if fields := outer.FieldsAt(0); !reflect.DeepEqual(fields, []any{"user_id", 2}) {
+		t.Errorf("unexpected fields of outermost layer: %v", fields)
+	}
+	if fields := outer.FieldsAt(1); fields != nil {
+		t.Errorf("expected nil for layer without fields, got %v", fields)
+	}
+	if fields := outer.FieldsAt(2); !reflect.DeepEqual(fields, []any{"user_id", 1, "table", "users"}) {
+		t.Errorf("unexpected fields of innermost layer: %v", fields)
+	}
+	if outer.FieldsAt(3) != nil || outer.FieldsAt(-1) != nil {
+		t.Error("expected nil for out of range layers")
+	}
+}
@@ -0,0 +1,267 @@
+// Package dbx provides helpers for wrapping database errors with uniform context:
+// normalized queries, argument counts, durations and classification by SQL error codes.
+//
+// It works with database/sql and with drivers that expose SQLSTATE codes via a
+// SQLState() method (e.g. pgx's *pgconn.PgError) without depending on them.
+package dbx
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/maxbolgarin/erro"
+)
+
+// Field keys attached by [WrapQuery] and [WrapQueryDuration].
+const (
+	FieldQuery     = "db_query"
+	FieldArgsCount = "db_args_count"
+	FieldDuration  = "db_duration"
+	FieldSQLState  = "db_sqlstate"
+)
+
+// QueryFailedMessage is the message used for wrapped query errors.
+const QueryFailedMessage = "query failed"
+
+// sqlStateError is implemented by driver errors that expose a SQLSTATE code.
+type sqlStateError interface {
+	SQLState() string
+}
+
+// WrapQuery wraps a database error with a normalized (literal-stripped) query and the
+// number of arguments. Argument values are never attached. The error is classified
+// with [Classify] and gets [erro.CategoryDatabase].
+//
+// If err is nil, WrapQuery returns nil.
+//
+// Example:
+//
+//	rows, err := db.QueryContext(ctx, query, args...)
+//	if err != nil {
+//	    return dbx.WrapQuery(err, query, args, "table", "users")
+//	}
+func WrapQuery(err error, query string, args []any, fields ...any) erro.Error {
+	if err == nil {
+		return nil
+	}
+	return wrapQuery(err, query, args, fields)
+}
+
+// WrapQueryDuration is like [WrapQuery] but also attaches the duration of the query.
+//
+// Example:
+//
+//	start := time.Now()
+//	_, err := db.ExecContext(ctx, query, args...)
+//	if err != nil {
+//	    return dbx.WrapQueryDuration(err, query, args, time.Since(start))
+//	}
+func WrapQueryDuration(err error, query string, args []any, d time.Duration, fields ...any) erro.Error {
+	if err == nil {
+		return nil
+	}
+	return wrapQuery(err, query, args, append([]any{FieldDuration, d}, fields...))
+}
+
+func wrapQuery(err error, query string, args []any, fields []any) erro.Error {
+	meta := make([]any, 0, len(fields)+8)
+	meta = append(meta, FieldQuery, NormalizeQuery(query), FieldArgsCount, len(args))
+
+	var stateErr sqlStateError
+	if errors.As(err, &stateErr) {
+		if state := stateErr.SQLState(); state != "" {
+			meta = append(meta, FieldSQLState, state)
+		}
+	}
+	meta = append(meta, fields...)
+
+	meta = append(meta, erro.CategoryDatabase)
+	class, retryable := Classify(err)
+	if class != erro.ClassUnknown {
+		meta = append(meta, class)
+	}
+	if retryable {
+		meta = append(meta, erro.Retryable())
+	}
+	return erro.Wrap(err, QueryFailedMessage, meta...)
+}
+
+// Classify returns the error class of a database error and whether it is worth retrying.
+//
+// It recognizes database/sql sentinel errors, context errors and SQLSTATE codes
+// of drivers exposing a SQLState() method. Unknown errors get [erro.ClassUnknown].
+func Classify(err error) (class erro.ErrorClass, retryable bool) {
+	switch {
+	case err == nil:
+		return erro.ClassUnknown, false
+	case errors.Is(err, sql.ErrNoRows):
+		return erro.ClassNotFound, false
+	case errors.Is(err, sql.ErrConnDone):
+		return erro.ClassUnavailable, true
+	case errors.Is(err, sql.ErrTxDone):
+		return erro.ClassConflict, false
+	case errors.Is(err, context.DeadlineExceeded):
+		return erro.ClassTimeout, true
+	case errors.Is(err, context.Canceled):
+		return erro.ClassCancelled, false
+	}
+
+	var stateErr sqlStateError
+	if !errors.As(err, &stateErr) {
+		return erro.ClassUnknown, false
+	}
+	return ClassifySQLState(stateErr.SQLState())
+}
+
+// ClassifySQLState returns the error class of a SQLSTATE code and whether it is worth retrying.
+func ClassifySQLState(state string) (class erro.ErrorClass, retryable bool) {
+	switch state {
+	case "23505": // unique_violation
+		return erro.ClassAlreadyExists, false
+	case "40001", "40P01": // serialization_failure, deadlock_detected
+		return erro.ClassConflict, true
+	case "57014": // query_canceled
+		return erro.ClassTimeout, true
+	case "42501": // insufficient_privilege
+		return erro.ClassPermissionDenied, false
+	}
+	if len(state) < 2 {
+		return erro.ClassUnknown, false
+	}
+	switch state[:2] {
+	case "08", "57": // connection exception, operator intervention
+		return erro.ClassUnavailable, true
+	case "22", "23": // data exception, integrity constraint violation
+		return erro.ClassValidation, false
+	case "28": // invalid authorization specification
+		return erro.ClassUnauthenticated, false
+	case "40": // transaction rollback
+		return erro.ClassConflict, true
+	case "53": // insufficient resources
+		return erro.ClassResourceExhausted, true
+	case "42", "XX": // syntax error or access rule violation, internal error
+		return erro.ClassInternal, false
+	}
+	return erro.ClassUnknown, false
+}
+
+// NormalizeQuery strips literal values from a query and collapses whitespace.
+//
+// String literals (including E'…' strings with backslash escapes, X'…' and B'…'
+// strings and PostgreSQL dollar-quoted strings such as $$…$$ and $tag$…$tag$),
+// numbers (including hex literals such as 0xFF) and positional parameters are
+// replaced with "?", so the result is safe to log and stable across calls with
+// different values.
+//
+// Example:
+//
+//	dbx.NormalizeQuery("SELECT * FROM users WHERE id = 42 AND name = 'bob'")
+//	// "SELECT * FROM users WHERE id = ? AND name = ?"
+func NormalizeQuery(query string) string {
+	var b strings.Builder
+	b.Grow(len(query))
+
+	space := false
+	for i := 0; i < len(query); i++ {
+		c := query[i]
+		wordStart := i == 0 || !isIdentChar(query[i-1])
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			space = true
+			continue
+		case c == '\'':
+			i = skipString(query, i, false)
+			c = '?'
+		case wordStart && strings.IndexByte("EeXxBbNn", c) >= 0 && i+1 < len(query) && query[i+1] == '\'':
+			// Prefixed string literal, only E'…' strings have backslash escapes
+			i = skipString(query, i+1, c == 'E' || c == 'e')
+			c = '?'
+		case c == '$' && i+1 < len(query) && isDigit(query[i+1]):
+			for i+1 < len(query) && isDigit(query[i+1]) {
+				i++
+			}
+			c = '?'
+		case c == '$' && wordStart:
+			end, ok := skipDollarQuoted(query, i)
+			if !ok {
+				break
+			}
+			i = end
+			c = '?'
+		case c == '0' && wordStart && i+2 < len(query) && (query[i+1] == 'x' || query[i+1] == 'X') && isHexDigit(query[i+2]):
+			i += 2
+			for i+1 < len(query) && isHexDigit(query[i+1]) {
+				i++
+			}
+			c = '?'
+		case isDigit(c) && wordStart:
+			for i+1 < len(query) && (isDigit(query[i+1]) || query[i+1] == '.') {
+				i++
+			}
+			c = '?'
+		}
+		if space && b.Len() > 0 {
+			b.WriteByte(' ')
+		}
+		space = false
+		b.WriteByte(c)
+	}
+	return b.String()
+}
+
+// skipString returns the index of the quote closing the string literal that starts
+// at the quote at i, or the last index if it is not closed. A doubled quote is an
+// escaped quote, and so is a quote after a backslash if backslash is true.
+func skipString(query string, i int, backslash bool) int {
+	for i++; i < len(query); i++ {
+		switch {
+		case backslash && query[i] == '\\':
+			i++
+		case query[i] == '\'':
+			if i+1 < len(query) && query[i+1] == '\'' {
+				i++
+				continue
+			}
+			return i
+		}
+	}
+	return len(query) - 1
+}
+
+// skipDollarQuoted returns the index of the last byte of the dollar-quoted string
+// ($$…$$ or $tag$…$tag$) that starts at i, or the last index if it is not closed.
+// It returns false if there is no dollar quote at i.
+func skipDollarQuoted(query string, i int) (int, bool) {
+	j := i + 1
+	for j < len(query) && (query[j] == '_' || isLetter(query[j]) || j > i+1 && isDigit(query[j])) {
+		j++
+	}
+	if j >= len(query) || query[j] != '$' {
+		return 0, false
+	}
+	delimiter := query[i : j+1]
+	end := strings.Index(query[j+1:], delimiter)
+	if end == -1 {
+		return len(query) - 1, true
+	}
+	return j + end + len(delimiter), true
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func isLetter(c byte) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func isHexDigit(c byte) bool {
+	return isDigit(c) || (c >= 'a' && c <= 'f') || (c >= 'A' && c <= 'F')
+}
+
+func isIdentChar(c byte) bool {
+	return c == '_' || c == '$' || isDigit(c) || isLetter(c)
+}
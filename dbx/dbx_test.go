@@ -0,0 +1,113 @@
+package dbx
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/maxbolgarin/erro"
+)
+
+type pgError struct {
+	code string
+}
+
+func (e *pgError) Error() string    { return "pg error " + e.code }
+func (e *pgError) SQLState() string { return e.code }
+
+func TestNormalizeQuery(t *testing.T) {
+	tests := []struct {
+		query    string
+		expected string
+	}{
+		{"SELECT * FROM users WHERE id = 42", "SELECT * FROM users WHERE id = ?"},
+		{"SELECT *\n\tFROM users   WHERE name = 'bob''s'", "SELECT * FROM users WHERE name = ?"},
+		{"UPDATE t2 SET v = $1 WHERE id = $2", "UPDATE t2 SET v = ? WHERE id = ?"},
+		{"SELECT 1.5, col_1 FROM t", "SELECT ?, col_1 FROM t"},
+		{"  SELECT 'unterminated", "SELECT ?"},
+		{"SELECT $$secret$$, $tag$it's $$ secret$tag$ FROM t", "SELECT ?, ? FROM t"},
+		{"SELECT $body$unterminated secret", "SELECT ?"},
+		{"SELECT a$b$ FROM t WHERE x = $$s$$", "SELECT a$b$ FROM t WHERE x = ?"},
+		{`SELECT E'it\'s secret', e'a\\' FROM t`, "SELECT ?, ? FROM t"},
+		{"SELECT 'a\\', 'b' FROM t", "SELECT ?, ? FROM t"},
+		{"SELECT 0xFF, X'DEADBEEF', B'0101', N'name' FROM t", "SELECT ?, ?, ?, ? FROM t"},
+		{"SELECT col_e, type FROM t", "SELECT col_e, type FROM t"},
+	}
+	for _, tt := range tests {
+		if got := NormalizeQuery(tt.query); got != tt.expected {
+			t.Errorf("NormalizeQuery(%q) = %q, expected %q", tt.query, got, tt.expected)
+		}
+	}
+}
+
+func TestWrapQuery(t *testing.T) {
+	if WrapQuery(nil, "SELECT 1", nil) != nil {
+		t.Error("expected nil for nil error")
+	}
+
+	query := "SELECT * FROM users WHERE email = 'secret@example.com'"
+	err := WrapQuery(sql.ErrNoRows, query, []any{1, 2}, "table", "users")
+	if err.Class() != erro.ClassNotFound {
+		t.Errorf("expected not found class, got %s", err.Class())
+	}
+	if err.Category() != erro.CategoryDatabase {
+		t.Errorf("expected database category, got %s", err.Category())
+	}
+	if strings.Contains(err.Error(), "secret@example.com") {
+		t.Errorf("expected literal to be redacted, got %s", err.Error())
+	}
+	fields := erro.LogFieldsMap(err)
+	if fields[FieldQuery] != "SELECT * FROM users WHERE email = ?" || fields[FieldArgsCount] != 2 || fields["table"] != "users" {
+		t.Errorf("unexpected fields: %v", fields)
+	}
+	if !erro.Is(err, sql.ErrNoRows) {
+		t.Error("expected original error to be preserved")
+	}
+}
+
+func TestWrapQueryDuration(t *testing.T) {
+	err := WrapQueryDuration(fmt.Errorf("exec: %w", &pgError{code: "40P01"}), "DELETE FROM t", nil, 150*time.Millisecond)
+	if err.Class() != erro.ClassConflict || !err.IsRetryable() {
+		t.Errorf("expected retryable conflict, got %s %v", err.Class(), err.IsRetryable())
+	}
+	fields := erro.LogFieldsMap(err)
+	if fields[FieldDuration] != 150*time.Millisecond || fields[FieldSQLState] != "40P01" {
+		t.Errorf("unexpected fields: %v", fields)
+	}
+	if WrapQueryDuration(nil, "", nil, time.Second) != nil {
+		t.Error("expected nil for nil error")
+	}
+}
+
+func TestClassify(t *testing.T) {
+	tests := []struct {
+		err       error
+		class     erro.ErrorClass
+		retryable bool
+	}{
+		{nil, erro.ClassUnknown, false},
+		{sql.ErrConnDone, erro.ClassUnavailable, true},
+		{sql.ErrTxDone, erro.ClassConflict, false},
+		{context.DeadlineExceeded, erro.ClassTimeout, true},
+		{context.Canceled, erro.ClassCancelled, false},
+		{&pgError{code: "23505"}, erro.ClassAlreadyExists, false},
+		{&pgError{code: "23503"}, erro.ClassValidation, false},
+		{&pgError{code: "08006"}, erro.ClassUnavailable, true},
+		{&pgError{code: "28P01"}, erro.ClassUnauthenticated, false},
+		{&pgError{code: "53300"}, erro.ClassResourceExhausted, true},
+		{&pgError{code: "42P01"}, erro.ClassInternal, false},
+		{&pgError{code: "42501"}, erro.ClassPermissionDenied, false},
+		{&pgError{code: "HV000"}, erro.ClassUnknown, false},
+		{&pgError{code: ""}, erro.ClassUnknown, false},
+		{fmt.Errorf("plain"), erro.ClassUnknown, false},
+	}
+	for _, tt := range tests {
+		class, retryable := Classify(tt.err)
+		if class != tt.class || retryable != tt.retryable {
+			t.Errorf("Classify(%v) = %s, %v; expected %s, %v", tt.err, class, retryable, tt.class, tt.retryable)
+		}
+	}
+}
@@ -0,0 +1,124 @@
+package erro
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// Fields added to timeout errors created with [NewCtx] and [WrapCtx].
+const (
+	// TimeoutBudgetField is the time between the start marked with [WithStart] and the deadline.
+	TimeoutBudgetField = "timeout_budget"
+	// ElapsedField is the time between the start marked with [WithStart] and the error creation.
+	ElapsedField = "elapsed"
+	// DeadlineField is the deadline of the context.
+	DeadlineField = "deadline"
+)
+
+type startKey struct{}
+
+// WithStart returns a copy of the context that records the current time as the start of
+// the operation, so timeout errors created with [NewCtx] and [WrapCtx] get the
+// [TimeoutBudgetField] and [ElapsedField] fields. The time is taken from [SetClock].
+//
+// Example:
+//
+//	ctx, cancel := context.WithTimeout(erro.WithStart(ctx), 2*time.Second)
+//	defer cancel()
+func WithStart(ctx context.Context) context.Context {
+	return context.WithValue(ctx, startKey{}, now())
+}
+
+// Since adds a field with the time elapsed from start to the creation of the error,
+// measured with the clock of [SetClock]. The value is a [time.Duration], so it is
+// rendered the same way in messages, logs and JSON, and read back with [Field]
+// as time.Duration, also after [FromJSON].
+//
+// Example:
+//
+//	start := time.Now()
+//	rows, err := db.QueryContext(ctx, q)
+//	if err != nil {
+//	    return erro.Wrap(err, "query users", erro.Since("db_latency", start))
+//	}
+//	latency, _ := erro.Field[time.Duration](err, "db_latency")
+func Since(key string, start time.Time) errorFields {
+	return func() []any {
+		return []any{key, now().Sub(start)}
+	}
+}
+
+// Timed adds the [ElapsedField] field with the time elapsed from start to the creation
+// of the error, see [Since].
+func Timed(start time.Time) errorFields {
+	return Since(ElapsedField, start)
+}
+
+// NewCtx is like [New], but takes the context of the operation. If the error is of
+// [ClassTimeout], or has no class and the deadline of the context is exceeded, it gets
+// [ClassTimeout] and the [DeadlineField], [TimeoutBudgetField] and [ElapsedField] fields
+// of the context, see [WithStart]. Fields the context can't provide are omitted.
+//
+// Example:
+//
+//	if ctx.Err() != nil {
+//	    return erro.NewCtx(ctx, "fetch prices", "provider", name)
+//	    // fetch prices provider=acme deadline=... timeout_budget=2s elapsed=2.001s
+//	}
+func NewCtx(ctx context.Context, message string, fields ...any) Error {
+	return newCtxf(ctx, message, fields...)
+}
+
+// WrapCtx is like [Wrap], but takes the context of the operation and adds deadline fields
+// to timeout errors, see [NewCtx]. Wrapping [context.DeadlineExceeded] is a timeout.
+// It returns nil if the error is nil.
+func WrapCtx(ctx context.Context, err error, message string, fields ...any) Error {
+	if err == nil {
+		return nil
+	}
+	return wrapCtxf(ctx, err, message, fields...)
+}
+
+// newCtxf is [newf] with the context, it must keep the same call depth.
+func newCtxf(ctx context.Context, message string, meta ...any) *baseError {
+	message, meta = applyFormatVerbs(message, meta)
+	return newBaseError(message, append([]any{deadlineContext{ctx: ctx}}, meta...)...)
+}
+
+// wrapCtxf is [wrapf] with the context, it must keep the same call depth.
+func wrapCtxf(ctx context.Context, err error, message string, meta ...any) *baseError {
+	message, meta = applyFormatVerbs(message, meta)
+	return newWrapError(err, message, append([]any{deadlineContext{ctx: ctx}}, meta...)...)
+}
+
+// deadlineContext is the context passed to [NewCtx] and [WrapCtx].
+type deadlineContext struct {
+	ctx context.Context
+}
+
+// appendDeadlineFields infers [ClassTimeout] and appends the deadline fields of the context.
+func appendDeadlineFields(e *baseError, ctx context.Context, fields []any) []any {
+	if ctx == nil {
+		return fields
+	}
+	if e.Class() == "" && (errors.Is(ctx.Err(), context.DeadlineExceeded) || errors.Is(e, context.DeadlineExceeded)) {
+		e.class = ClassTimeout
+	}
+	if e.Class() != ClassTimeout {
+		return fields
+	}
+
+	deadline, hasDeadline := ctx.Deadline()
+	start, hasStart := ctx.Value(startKey{}).(time.Time)
+	if hasDeadline {
+		fields = append(fields, DeadlineField, deadline)
+	}
+	if hasDeadline && hasStart {
+		fields = append(fields, TimeoutBudgetField, deadline.Sub(start))
+	}
+	if hasStart {
+		fields = append(fields, ElapsedField, now().Sub(start))
+	}
+	return fields
+}
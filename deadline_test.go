@@ -0,0 +1,96 @@
+package erro
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNewCtx(t *testing.T) {
+	start := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	current := start
+	SetClock(func() time.Time { return current })
+	defer SetClock(nil)
+
+	ctx, cancel := context.WithDeadline(WithStart(context.Background()), start.Add(2*time.Second))
+	defer cancel()
+	current = start.Add(2500 * time.Millisecond)
+
+	err := NewCtx(ctx, "fetch prices", "provider", "acme", StackTrace())
+	if err.Class() != ClassTimeout {
+		t.Errorf("expected inferred timeout class, got %q", err.Class())
+	}
+	if v, _ := Field[time.Duration](err, TimeoutBudgetField); v != 2*time.Second {
+		t.Errorf("unexpected timeout budget: %v", v)
+	}
+	if v, _ := Field[time.Duration](err, ElapsedField); v != 2500*time.Millisecond {
+		t.Errorf("unexpected elapsed: %v", v)
+	}
+	if v, _ := Field[time.Time](err, DeadlineField); !v.Equal(start.Add(2 * time.Second)) {
+		t.Errorf("unexpected deadline: %v", v)
+	}
+	if fields := err.AllFields(); fields[0] != "provider" || fields[1] != "acme" {
+		t.Errorf("expected user fields first, got %v", fields)
+	}
+	if top := err.Stack()[0].FullName; !strings.HasSuffix(top, ".TestNewCtx") {
+		t.Errorf("expected stack to start at the caller, got %s", top)
+	}
+
+	if err := NewCtx(context.Background(), "no deadline", ClassTimeout); len(err.AllFields()) != 0 {
+		t.Errorf("expected no fields without deadline and start, got %v", err.AllFields())
+	}
+	if err := NewCtx(ctx, "validation", ClassValidation); len(err.AllFields()) != 0 || err.Class() != ClassValidation {
+		t.Errorf("expected explicit class to be kept without fields, got %s %v", err.Class(), err.AllFields())
+	}
+}
+
+func TestWrapCtx(t *testing.T) {
+	if WrapCtx(context.Background(), nil, "nil") != nil {
+		t.Error("expected nil for nil error")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Hour)
+	defer cancel()
+
+	err := WrapCtx(ctx, context.DeadlineExceeded, "call upstream")
+	if err.Class() != ClassTimeout {
+		t.Errorf("expected timeout class for a wrapped deadline error, got %q", err.Class())
+	}
+	if _, ok := Field[time.Time](err, DeadlineField); !ok {
+		t.Error("expected deadline field")
+	}
+	if _, ok := Field[time.Duration](err, ElapsedField); ok {
+		t.Error("expected no elapsed field without start")
+	}
+
+	err = WrapCtx(ctx, errors.New("refused"), "call upstream")
+	if err.Class() != "" || len(err.AllFields()) != 0 {
+		t.Errorf("expected no timeout for other errors, got %s %v", err.Class(), err.AllFields())
+	}
+}
+
+func TestSince(t *testing.T) {
+	start := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	SetClock(func() time.Time { return start.Add(1500 * time.Millisecond) })
+	defer SetClock(nil)
+
+	err := Wrap(errors.New("connection reset"), "query users", Since("db_latency", start), Timed(start.Add(time.Second)))
+	if !strings.Contains(err.Error(), "db_latency=1.5s elapsed=500ms") {
+		t.Errorf("unexpected message: %s", err.Error())
+	}
+	if v, ok := Field[time.Duration](err, "db_latency"); !ok || v != 1500*time.Millisecond {
+		t.Errorf("unexpected latency: %v %v", v, ok)
+	}
+
+	data, _ := json.Marshal(ErrorToJSON(err))
+	decoded, decodeErr := FromJSON(data)
+	if decodeErr != nil {
+		t.Fatalf("unexpected error: %v", decodeErr)
+	}
+	if v, ok := Field[time.Duration](decoded, ElapsedField); !ok || v != 500*time.Millisecond {
+		t.Errorf("unexpected decoded elapsed: %v %v", v, ok)
+	}
+}
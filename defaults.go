@@ -0,0 +1,185 @@
+package erro
+
+import (
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+)
+
+var (
+	categoryDefaults   atomicValue[map[ErrorCategory][]any]
+	categoryDefaultsMu sync.Mutex
+)
+
+// WithCategoryDefaults registers default fields for errors of the category. Any error
+// created or wrapped with the category gains the fields, unless a field with the same
+// key is set at the call site, keeping the log taxonomy consistent without repeating
+// fields. Errors that only inherit the category from a wrapped error don't get them
+// again. Calling it without fields removes the defaults.
+//
+// Example:
+//
+//	erro.Configure(erro.WithCategoryDefaults(erro.CategoryDatabase, "component", "storage"))
+//	err := erro.New("query failed", erro.CategoryDatabase) // query failed component=storage
+func WithCategoryDefaults(category ErrorCategory, fields ...any) ConfigOption {
+	defaults := append([]any(nil), fields...)
+	return func() {
+		categoryDefaultsMu.Lock()
+		defer categoryDefaultsMu.Unlock()
+
+		current := categoryDefaults.Load()
+		updated := make(map[ErrorCategory][]any, len(current)+1)
+		for k, v := range current {
+			updated[k] = v
+		}
+		if len(defaults) == 0 {
+			delete(updated, category)
+		} else {
+			fields := defaults
+			if len(fields)%2 != 0 {
+				fields = append(fields[:len(fields):len(fields)], missingField())
+			}
+			updated[category] = fields
+		}
+		categoryDefaults.Store(updated)
+	}
+}
+
+// appendCategoryDefaults appends the defaults of the category whose keys are not in fields.
+func appendCategoryDefaults(fields []any, category ErrorCategory) []any {
+	defaults := categoryDefaults.Load()[category]
+	for i := 0; i+1 < len(defaults); i += 2 {
+		if !hasFieldKey(fields, defaults[i]) {
+			fields = append(fields, defaults[i], defaults[i+1])
+		}
+	}
+	return fields
+}
+
+func hasFieldKey(fields []any, key any) bool {
+	name := valueToString(key)
+	for i := 0; i+1 < len(fields); i += 2 {
+		if k, ok := fields[i].(string); ok && k == name || !ok && valueToString(fields[i]) == name {
+			return true
+		}
+	}
+	return false
+}
+
+type packageCategory struct {
+	pattern  string
+	category ErrorCategory
+}
+
+var (
+	packageCategories   atomicValue[[]packageCategory]
+	packageCategoriesMu sync.Mutex
+)
+
+// WithPackageCategory registers the category of errors created in packages matching the
+// pattern. Errors that capture a stack trace and have no category get the category of
+// the package of their origin frame, and the defaults of [WithCategoryDefaults]. Patterns
+// are package paths as in the go tool: "github.com/app/payments/..." matches the package
+// and its subpackages, and ".../storage/..." matches packages with a "storage" element
+// anywhere in the path. The longest matching pattern wins. [CategoryUnknown] removes the pattern.
+//
+// Example:
+//
+//	erro.Configure(
+//	    erro.WithPackageCategory(".../storage/...", erro.CategoryStorage),
+//	    erro.WithPackageCategory("github.com/app/payments/...", erro.CategoryPayment),
+//	)
+func WithPackageCategory(pattern string, category ErrorCategory) ConfigOption {
+	return func() {
+		packageCategoriesMu.Lock()
+		defer packageCategoriesMu.Unlock()
+
+		current := packageCategories.Load()
+		updated := make([]packageCategory, 0, len(current)+1)
+		for _, pc := range current {
+			if pc.pattern != pattern {
+				updated = append(updated, pc)
+			}
+		}
+		if category != CategoryUnknown {
+			updated = append(updated, packageCategory{pattern: pattern, category: category})
+			sort.SliceStable(updated, func(i, j int) bool {
+				return len(updated[i].pattern) > len(updated[j].pattern)
+			})
+		}
+		packageCategories.Store(updated)
+	}
+}
+
+// inferCategory sets the category of an error with a stack trace and no category from
+// the package of its origin frame, see [WithPackageCategory].
+func inferCategory(e *baseError) {
+	patterns := packageCategories.Load()
+	if len(patterns) == 0 || e.stack == nil || e.Category() != CategoryUnknown {
+		return
+	}
+	pkg := packagePath(originFunction(e))
+	if pkg == "" {
+		return
+	}
+	for _, pc := range patterns {
+		if matchPackagePattern(pc.pattern, pkg) {
+			e.category = pc.category
+			e.fields = appendCategoryDefaults(e.fields, pc.category)
+			return
+		}
+	}
+}
+
+// originFunction returns the full function name of the top frame of the own stack of the error.
+func originFunction(e *baseError) string {
+	if frames := e.frames.Load(); len(frames) > 0 {
+		return frames[0].FullName
+	}
+	frames := runtime.CallersFrames(e.stack)
+	for {
+		frame, more := frames.Next()
+		if !isUselessRuntimeFrame(frame.Function, frame.File) {
+			return frame.Function
+		}
+		if !more {
+			return ""
+		}
+	}
+}
+
+// packagePath returns the package path of a full function name,
+// e.g. "github.com/app/storage" for "github.com/app/storage.(*DB).Query".
+func packagePath(function string) string {
+	slash := strings.LastIndexByte(function, '/')
+	if dot := strings.IndexByte(function[slash+1:], '.'); dot >= 0 {
+		return function[:slash+1+dot]
+	}
+	return function
+}
+
+// matchPackagePattern reports whether the package path matches a pattern of [WithPackageCategory].
+func matchPackagePattern(pattern, pkg string) bool {
+	anyParent := strings.HasPrefix(pattern, ".../")
+	pattern = strings.TrimPrefix(pattern, ".../")
+	anyChild := strings.HasSuffix(pattern, "/...")
+	pattern = strings.TrimSuffix(pattern, "/...")
+
+	matchesAt := func(i int) bool {
+		if i > 0 && pkg[i-1] != '/' {
+			return false
+		}
+		end := i + len(pattern)
+		return end == len(pkg) || anyChild && pkg[end] == '/'
+	}
+	if !anyParent {
+		return strings.HasPrefix(pkg, pattern) && matchesAt(0)
+	}
+	for i := 0; i+len(pattern) <= len(pkg); i++ {
+		if strings.HasPrefix(pkg[i:], pattern) && matchesAt(i) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,92 @@
+package erro
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestWithCategoryDefaults(t *testing.T) {
+	Configure(WithCategoryDefaults(CategoryDatabase, "component", "storage", "dangling"))
+	defer Configure(WithCategoryDefaults(CategoryDatabase))
+
+	err := New("query failed", CategoryDatabase, "table", "users")
+	if got := err.Error(); got != "query failed table=users component=storage dangling="+missingField() {
+		t.Errorf("unexpected Error(): %s", got)
+	}
+	if err := New("query failed", CategoryDatabase, "component", "cache"); len(err.Fields()) != 4 || err.Fields()[1] != "cache" {
+		t.Errorf("expected call site field to override the default, got %v", err.Fields())
+	}
+	if err := Wrap(errors.New("io"), "read failed", CategoryDatabase); len(err.Fields()) != 4 {
+		t.Errorf("expected defaults for wrapped errors with the category, got %v", err.Fields())
+	}
+	if outer := Wrap(err, "get user"); len(outer.Fields()) != 0 || len(outer.AllFields()) != 6 {
+		t.Errorf("expected inherited category not to add defaults again, got %v", outer.AllFields())
+	}
+	if err := New("no category", "table", "users"); len(err.Fields()) != 2 {
+		t.Errorf("expected no defaults for other categories, got %v", err.Fields())
+	}
+
+	Configure(WithCategoryDefaults(CategoryDatabase))
+	if err := New("query failed", CategoryDatabase); len(err.Fields()) != 0 {
+		t.Errorf("expected defaults to be removed, got %v", err.Fields())
+	}
+}
+
+func TestWithPackageCategory(t *testing.T) {
+	Configure(
+		WithPackageCategory(".../maxbolgarin/...", CategoryStorage),
+		WithPackageCategory("github.com/maxbolgarin/erro", CategoryPayment),
+		WithCategoryDefaults(CategoryPayment, "team", "payments"),
+	)
+	defer Configure(
+		WithPackageCategory(".../maxbolgarin/...", CategoryUnknown),
+		WithPackageCategory("github.com/maxbolgarin/erro", CategoryUnknown),
+		WithCategoryDefaults(CategoryPayment),
+	)
+
+	err := New("charge failed", StackTrace())
+	if err.Category() != CategoryPayment {
+		t.Errorf("expected the category of the longest pattern, got %q", err.Category())
+	}
+	if team, _ := Field[string](err, "team"); team != "payments" {
+		t.Errorf("expected the category defaults, got %q", team)
+	}
+	if err := New("explicit", CategoryNetwork, StackTrace()); err.Category() != CategoryNetwork {
+		t.Errorf("explicit category must win, got %q", err.Category())
+	}
+	if err := New("no stack"); err.Category() != CategoryUnknown {
+		t.Errorf("errors without a stack must not be inferred, got %q", err.Category())
+	}
+	if err := Wrap(New("inner", CategoryCache), "outer", StackTrace()); err.Category() != CategoryCache {
+		t.Errorf("inherited category must win, got %q", err.Category())
+	}
+
+	Configure(WithPackageCategory("github.com/maxbolgarin/erro", CategoryUnknown))
+	if err := New("charge failed", StackTrace()); err.Category() != CategoryStorage {
+		t.Errorf("expected the remaining pattern, got %q", err.Category())
+	}
+}
+
+func TestMatchPackagePattern(t *testing.T) {
+	tests := []struct {
+		pattern, pkg string
+		want         bool
+	}{
+		{"github.com/app/payments", "github.com/app/payments", true},
+		{"github.com/app/payments", "github.com/app/payments/stripe", false},
+		{"github.com/app/payments/...", "github.com/app/payments/stripe", true},
+		{"github.com/app/payments/...", "github.com/app/paymentsx", false},
+		{".../storage/...", "github.com/app/internal/storage/sql", true},
+		{".../storage/...", "github.com/app/objectstorage", false},
+		{".../storage", "github.com/app/storage", true},
+		{".../storage", "github.com/app/storage/sql", false},
+	}
+	for _, tt := range tests {
+		if got := matchPackagePattern(tt.pattern, tt.pkg); got != tt.want {
+			t.Errorf("matchPackagePattern(%q, %q) = %v, want %v", tt.pattern, tt.pkg, got, tt.want)
+		}
+	}
+	if pkg := packagePath("github.com/app/storage.(*DB).Query"); pkg != "github.com/app/storage" {
+		t.Errorf("unexpected package path %q", pkg)
+	}
+}
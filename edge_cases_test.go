@@ -117,6 +117,46 @@ func TestSecurityLimits_DoSProtection(t *testing.T) {
 			current = erro.Unwrap(current)
 		}
 	})
+
+	t.Run("MaxWrapDepth truncation record", func(t *testing.T) {
+		var handled []erro.Error
+		erro.SetWrapDepthExceededHandler(func(err erro.Error) { handled = append(handled, err) })
+		defer erro.SetWrapDepthExceededHandler(nil)
+
+		root := erro.New("root error", erro.ClassNotFound)
+		err := root
+		for i := 0; i < erro.MaxWrapDepth-1; i++ {
+			err = erro.Wrap(err, "layer")
+		}
+		if erro.TruncatedChain(err) || len(handled) != 0 {
+			t.Fatal("expected chain within the limit not to be truncated")
+		}
+
+		before := err
+		err = erro.Wrap(err, "overflow")
+		if !erro.TruncatedChain(err) {
+			t.Fatal("expected chain to be truncated")
+		}
+		if len(handled) != 1 || handled[0] != err {
+			t.Errorf("expected handler to be called once with the new error, got %d calls", len(handled))
+		}
+		fields := err.Fields()
+		if len(fields) != 2 || fields[0] != erro.WrapDepthExceededField || fields[1] != true {
+			t.Errorf("expected %s field, got %v", erro.WrapDepthExceededField, fields)
+		}
+		if want := "overflow wrap_depth_exceeded=true: " + strings.Repeat("layer: ", erro.MaxWrapDepth-2) + "root error"; err.Error() != want {
+			t.Errorf("expected only the oldest layer to be dropped, got %s", err.Error())
+		}
+		if erro.TruncatedChain(before) || erro.TruncatedChain(errors.New("std")) {
+			t.Error("expected the original chain not to be changed")
+		}
+		if !errors.Is(err, root) || err.Class() != erro.ClassNotFound || err.ID() != root.ID() {
+			t.Error("expected root cause and its metadata to be kept")
+		}
+		if outer := erro.Wrap(err, "outer"); !erro.TruncatedChain(outer) || outer.LayerCount() != erro.MaxWrapDepth {
+			t.Error("expected outer errors to report the truncated chain")
+		}
+	})
 }
 
 // TestMemoryExhaustion tests scenarios that could lead to memory exhaustion
@@ -2089,3 +2129,68 @@ func createDeepStackTrace(depth int) erro.Error {
 	}
 	return createDeepStackTrace(depth - 1)
 }
+
+func TestImmutabilityGuarantees(t *testing.T) {
+	t.Run("FrozenRejectsUnmarshal", func(t *testing.T) {
+		err := erro.Freeze(erro.New("original", "key", "value"))
+		if !erro.IsFrozen(err) {
+			t.Fatal("expected error to be frozen")
+		}
+		if uErr := json.Unmarshal([]byte(`{"id":"x","message":"replaced"}`), err); !errors.Is(uErr, erro.ErrFrozen) {
+			t.Errorf("expected ErrFrozen, got %v", uErr)
+		}
+		if err.Error() != "original key=value" {
+			t.Errorf("expected frozen error to be unchanged, got %s", err.Error())
+		}
+	})
+
+	t.Run("FreezeForeignError", func(t *testing.T) {
+		if erro.Freeze(nil) != nil {
+			t.Error("expected nil for nil error")
+		}
+		err := erro.Freeze(errors.New("plain"))
+		if !erro.IsFrozen(err) || erro.IsFrozen(errors.New("plain")) {
+			t.Error("expected only the converted error to be frozen")
+		}
+	})
+
+	t.Run("UnmarshalResetsCachedState", func(t *testing.T) {
+		err := erro.Wrap(erro.New("inner", erro.ClassNotFound), "outer")
+		_ = err.Error()
+		if uErr := json.Unmarshal([]byte(`{"id":"x","message":"replaced"}`), err); uErr != nil {
+			t.Fatal(uErr)
+		}
+		if err.Error() != "replaced" {
+			t.Errorf("expected cached message to be reset, got %s", err.Error())
+		}
+		if err.Class() != "" || err.Unwrap() != nil {
+			t.Errorf("expected wrapped error to be dropped, got class %q", err.Class())
+		}
+	})
+
+	t.Run("ConcurrentReadersAndUnmarshal", func(t *testing.T) {
+		err := erro.Freeze(erro.Wrap(errors.New("base"), "shared", "key", "value", erro.StackTrace()))
+		data, _ := json.Marshal(err)
+
+		var wg sync.WaitGroup
+		for i := 0; i < 20; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for j := 0; j < 100; j++ {
+					_ = err.Error()
+					_ = err.Fields()
+					_ = err.AllFields()
+					_ = err.Stack()
+					_ = err.LogFields()
+					_ = json.Unmarshal(data, err)
+				}
+			}()
+		}
+		wg.Wait()
+
+		if err.Error() != "shared key=value: base" {
+			t.Errorf("expected error to be unchanged, got %s", err.Error())
+		}
+	})
+}
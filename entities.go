@@ -10,8 +10,41 @@ import (
 var (
 	// ErrMaxWrapDepthExceeded is returned when the maximum error wrapping depth is exceeded.
 	ErrMaxWrapDepthExceeded = New("maximum wrap depth exceeded")
+
+	// ErrFrozen is returned when modifying an error that was frozen with Freeze.
+	ErrFrozen = New("error is frozen and cannot be modified")
+
+	// ErrInvalidSchema is returned by FromJSON when the decoded error does not match the schema.
+	ErrInvalidSchema = New("invalid error schema", ClassValidation)
+
+	// ErrInvalidTemplate is wrapped by the errors of [ErrorTemplate.Validate] and [ValidateTemplates].
+	ErrInvalidTemplate = New("invalid error template", ClassValidation)
+
+	// ErrFieldCollision is the panic value in the [CollisionPanic] mode of [WithCollisionDetection].
+	ErrFieldCollision = New("field shadows a different value in the wrapped error", ClassInternal)
+
+	// ErrMissingTenant is the panic value of a violated [TenantPolicy] with Panic set.
+	ErrMissingTenant = New("error has no tenant", ClassInternal)
+
+	// ErrInvalidQuery is wrapped by the errors of [ParseQuery] and [Query].
+	ErrInvalidQuery = New("invalid error query", ClassValidation)
+
+	// ErrOptionMisuse is the panic value in the [OptionDiagnosticsPanic] mode of [WithOptionDiagnostics].
+	ErrOptionMisuse = New("options are misused", ClassInternal)
+
+	// ErrPoolClosed is returned by [WorkerPool.Submit] after the pool is closed.
+	ErrPoolClosed = New("worker pool is closed", ClassUnavailable)
 )
 
+// WrapDepthExceededField is the field added to an error when wrapping exceeds [MaxWrapDepth].
+// The oldest layers above the root cause are dropped from the chain to keep it below
+// the limit and [TruncatedChain] returns true.
+const WrapDepthExceededField = "wrap_depth_exceeded"
+
+// KeyConflictField is the field added to an error that shadows a field of a wrapped
+// error with a different value in the [CollisionAnnotate] mode of [WithCollisionDetection].
+const KeyConflictField = "key_conflict"
+
 // Security configuration constants
 const (
 	// MaxMessageLength is the maximum length for error messages.
@@ -25,15 +58,15 @@ const (
 	MaxFieldsCount = 100
 	maxPairsCount  = MaxFieldsCount * 2
 
-	// MaxWrapDepth is the maximum depth of error wrapping.
+	// MaxWrapDepth is the maximum depth of error wrapping. See [WrapDepthExceededField].
 	MaxWrapDepth = 50
 
 	// MaxStackDepth is the maximum stack depth.
 	MaxStackDepth = 50
 
-	// RedactedPlaceholder is the placeholder for redacted values.
+	// RedactedPlaceholder is the default placeholder for redacted values, see [WithRedactedPlaceholder].
 	RedactedPlaceholder = "[REDACTED]"
-	// MissingFieldPlaceholder is the placeholder for missing field values.
+	// MissingFieldPlaceholder is the default placeholder for missing field values, see [WithMissingFieldPlaceholder].
 	MissingFieldPlaceholder = "<missing>"
 )
 
@@ -61,6 +94,12 @@ type Error interface {
 	Category() ErrorCategory
 	Severity() ErrorSeverity
 	IsRetryable() bool
+	Attempt() int
+	MaxAttempts() int
+	RetryAfter() (time.Duration, bool)
+	TTL() (time.Duration, bool)
+	Expired() bool
+	Tenant() string
 	Message() string
 	Fields() []any
 	Span() TraceSpan
@@ -69,11 +108,20 @@ type Error interface {
 	LogFieldsMap(opts ...LogOptions) map[string]any
 
 	// Wrapping
+	Wrap(message string, fields ...any) Error
 	BaseError() Error
 	AllFields() []any
+	LayerCount() int
+	FieldsAt(i int) []any
 
 	// Stack trace
 	Stack() Stack
+
+	// Immutability
+	Redacted() Error
+
+	// Debugging
+	DebugBundle() ([]byte, error)
 }
 
 // ErrorMetrics is an interface for recording error metrics.
@@ -93,6 +141,9 @@ type TraceSpan interface {
 	TraceID() string
 	SpanID() string
 	ParentSpanID() string
+	// IsSampled returns the sampling decision of the trace. Events of unsampled
+	// traces are dropped by [SendEvent] unless the error is of high severity.
+	IsSampled() bool
 }
 
 // ErrorSchema is a serializable representation of an error.
@@ -104,11 +155,20 @@ type ErrorSchema struct {
 	Created      time.Time      `json:"created,omitempty" bson:"created,omitempty" db:"created,omitempty"`
 	Message      string         `json:"message,omitempty" bson:"message,omitempty" db:"message,omitempty"`
 	Fields       []any          `json:"fields,omitempty" bson:"fields,omitempty" db:"fields,omitempty"`
+	FieldErrors  []FieldError   `json:"field_errors,omitempty" bson:"field_errors,omitempty" db:"field_errors,omitempty"`
 	Retryable    bool           `json:"retryable,omitempty" bson:"retryable,omitempty" db:"retryable,omitempty"`
+	Attempt      int            `json:"attempt,omitempty" bson:"attempt,omitempty" db:"attempt,omitempty"`
+	MaxAttempts  int            `json:"max_attempts,omitempty" bson:"max_attempts,omitempty" db:"max_attempts,omitempty"`
+	RetryAfter   time.Duration  `json:"retry_after,omitempty" bson:"retry_after,omitempty" db:"retry_after,omitempty"`
+	TTL          time.Duration  `json:"ttl,omitempty" bson:"ttl,omitempty" db:"ttl,omitempty"`
+	Hint         string         `json:"hint,omitempty" bson:"hint,omitempty" db:"hint,omitempty"`
+	UserMessage  string         `json:"user_message,omitempty" bson:"user_message,omitempty" db:"user_message,omitempty"`
+	DocURL       string         `json:"doc_url,omitempty" bson:"doc_url,omitempty" db:"doc_url,omitempty"`
 	StackTrace   []StackContext `json:"stack_trace,omitempty" bson:"stack_trace,omitempty" db:"stack_trace,omitempty"`
 	TraceID      string         `json:"trace_id,omitempty" bson:"trace_id,omitempty" db:"trace_id,omitempty"`
 	SpanID       string         `json:"span_id,omitempty" bson:"span_id,omitempty" db:"span_id,omitempty"`
 	ParentSpanID string         `json:"parent_span_id,omitempty" bson:"parent_span_id,omitempty" db:"parent_span_id,omitempty"`
+	Attachments  []Blob         `json:"attachments,omitempty" bson:"attachments,omitempty" db:"attachments,omitempty"`
 }
 
 // RedactedValue is a wrapper for a value that should be redacted in logs.
@@ -116,6 +176,15 @@ type RedactedValue struct {
 	Value any
 }
 
+// FieldWithOrigin is a field of an error chain with the wrap layer that added it,
+// see [AllFieldsAnnotated].
+type FieldWithOrigin struct {
+	Key          string
+	Value        any
+	LayerMessage string // Own message of the layer without fields and wrapped messages
+	Depth        int    // Position of the layer in the chain, 0 for the outermost error
+}
+
 // Redact wraps a value to mark it as sensitive. Its content will be replaced
 // with RedactedPlaceholder when the error is formatted as a string or JSON.
 func Redact(value any) RedactedValue {
@@ -152,6 +221,9 @@ var (
 		}
 		return err.Error()
 	}
+	// FingerprintKeyGetter generates a key based on the error's [Fingerprint], so errors of the
+	// same kind with different field values are deduplicated together.
+	FingerprintKeyGetter KeyGetterFunc = Fingerprint
 	// ErrorKeyGetter generates a key based on the error's string representation (err.Error()).
 	ErrorKeyGetter KeyGetterFunc = func(err error) string {
 		if err == nil {
@@ -6,9 +6,13 @@ package erro
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
 	"io"
 	"net/http"
+	"reflect"
+	"time"
 )
 
 // New creates a new [Error] with a message and optional structured metadata.
@@ -99,6 +103,8 @@ import (
 // # Thread Safety
 //
 // Individual errors are immutable after creation and safe for concurrent use.
+// The only exception is UnmarshalJSON which replaces the content of the error in place;
+// call [Freeze] on errors shared between goroutines to reject it, or decode with [FromJSON].
 // For collecting multiple errors concurrently, use [NewSafeList] or [NewSafeSet].
 func New(message string, fields ...any) Error {
 	return newf(message, fields...)
@@ -199,6 +205,195 @@ func Wrap(err error, message string, fields ...any) Error {
 	return wrapf(err, message, fields...)
 }
 
+// Wrap2 passes the value through unchanged and wraps the error if it is not nil.
+// It removes the boilerplate of returning a zero value along with a wrapped error.
+// The returned error is a nil interface if err is nil.
+//
+// Example:
+//
+//	func LoadUser(id string) (*User, error) {
+//	    user, err := db.GetUser(id)
+//	    return erro.Wrap2(user, err, "get user", "id", id)
+//	}
+func Wrap2[T any](v T, err error, message string, fields ...any) (T, error) {
+	if err == nil {
+		return v, nil
+	}
+	return v, wrapf(err, message, fields...)
+}
+
+// Wrap3 is like [Wrap2] for functions returning two values and an error.
+//
+// Example:
+//
+//	host, port, err := splitHostPort(addr)
+//	return erro.Wrap3(host, port, err, "parse address", "addr", addr)
+func Wrap3[T1, T2 any](v1 T1, v2 T2, err error, message string, fields ...any) (T1, T2, error) {
+	if err == nil {
+		return v1, v2, nil
+	}
+	return v1, v2, wrapf(err, message, fields...)
+}
+
+// NewSkip is like [New], but skips the given number of caller frames in the stack trace
+// and the origin of the error, as [StackTraceWithSkip] does. Use it in helpers that create
+// errors on behalf of their callers, so the error is attributed to the caller of the helper.
+// Skip 0 is the same as [New].
+//
+// Example:
+//
+//	func invalidArg(name string, value any) error {
+//	    return erro.NewSkip(1, "invalid argument", "name", name, "value", value, erro.StackTrace())
+//	}
+func NewSkip(skip int, message string, fields ...any) Error {
+	return newSkipf(skip, message, fields...)
+}
+
+// WrapSkip is like [Wrap], but skips the given number of caller frames in the stack trace
+// and the origin of the error, see [NewSkip]. It returns nil if the error is nil.
+//
+// Example:
+//
+//	func wrapQuery(err error, query string) error {
+//	    return erro.WrapSkip(1, err, "query failed", "query", query, erro.StackTrace())
+//	}
+func WrapSkip(skip int, err error, message string, fields ...any) Error {
+	if err == nil {
+		return nil
+	}
+	return wrapSkipf(skip, err, message, fields...)
+}
+
+// WrapOnce is like [Wrap], but returns the error as is if its outermost layer already has
+// the same message, so middlewares that wrap the same error don't produce
+// "handler failed: handler failed: ...". The fields of the skipped layer are dropped.
+// It returns nil if the error is nil.
+//
+// Example:
+//
+//	func withRecover(next Handler) Handler {
+//	    return func(r *Request) error {
+//	        return erro.WrapOnce(next(r), "handler failed", "path", r.Path)
+//	    }
+//	}
+func WrapOnce(err error, message string, fields ...any) Error {
+	if err == nil {
+		return nil
+	}
+	if e, ok := err.(*baseError); ok && e.message == truncateString(message, MaxMessageLength) {
+		return e
+	}
+	return wrapf(err, message, fields...)
+}
+
+// IsWrappedBy reports whether a layer of the error chain has the given own message,
+// i.e. the error was wrapped with op, see [WrapOnce]. Errors of a join are not searched.
+//
+// Example:
+//
+//	if !erro.IsWrappedBy(err, "handler failed") {
+//	    err = erro.Wrap(err, "handler failed")
+//	}
+func IsWrappedBy(err error, op string) bool {
+	op = truncateString(op, MaxMessageLength)
+	for depth := 0; err != nil && depth <= MaxWrapDepth; depth++ {
+		if e, ok := err.(*baseError); ok && e.message == op {
+			return true
+		}
+		err = errors.Unwrap(err)
+	}
+	return false
+}
+
+var wrapDepthExceededHandler atomicValue[func(err Error)]
+
+// SetWrapDepthExceededHandler sets a handler called with the new error every time
+// wrapping exceeds [MaxWrapDepth] and the chain is truncated. Use it to record a metric
+// or send an event to notice runaway wrapping loops. Passing nil removes the handler.
+//
+// Example:
+//
+//	erro.SetWrapDepthExceededHandler(func(err erro.Error) {
+//	    metrics.RecordError(err)
+//	})
+func SetWrapDepthExceededHandler(handler func(err Error)) {
+	wrapDepthExceededHandler.Store(handler)
+}
+
+// FromJSON creates a new error from its JSON representation produced by [ErrorToJSON]
+// or json.Marshal. Unlike calling json.Unmarshal on an existing error, it never mutates
+// shared state: the returned error is fresh and frozen (see [Freeze]).
+//
+// The data is validated against [ErrorSchema]: field keys must be strings, fields must
+// come in key-value pairs and the severity must be one of the predefined values;
+// otherwise an error wrapping [ErrInvalidSchema] is returned. The same limits as for
+// [New] are applied: the message, keys and string values are truncated and the number
+// of fields is capped at [MaxFieldsCount]. The ID is truncated to [MaxKeyLength]. Stack traces and trace spans are not restored.
+// Fields serialized with [JSONOptions.KeyValueFields] are accepted as well.
+//
+// Example:
+//
+//	err, decodeErr := erro.FromJSON(data)
+//	if decodeErr != nil {
+//	    return decodeErr
+//	}
+//	log.Println(err.ID(), err.Class())
+func FromJSON(data []byte) (Error, error) {
+	var schema ErrorSchema
+	if err := json.Unmarshal(data, &schema); err != nil {
+		return nil, Wrap(err, "decode error schema", ClassValidation)
+	}
+	if schema.Severity != "" && !schema.Severity.IsValid() {
+		return nil, Wrap(ErrInvalidSchema, "unknown severity", "severity", schema.Severity)
+	}
+	schema.Fields = fromKeyValueFields(schema.Fields)
+	if len(schema.Fields)%2 != 0 {
+		return nil, Wrap(ErrInvalidSchema, "odd number of fields", "count", len(schema.Fields))
+	}
+
+	fields := schema.Fields
+	if len(fields) > maxPairsCount {
+		fields = fields[:maxPairsCount]
+	}
+	var limited []any
+	if len(fields) > 0 {
+		limited = make([]any, 0, len(fields))
+		for i := 0; i < len(fields); i += 2 {
+			key, ok := fields[i].(string)
+			if !ok {
+				return nil, Wrap(ErrInvalidSchema, "field key is not a string", "index", i)
+			}
+			value := fields[i+1]
+			if str, ok := value.(string); ok {
+				value = truncateString(str, MaxValueLength)
+			}
+			limited = append(limited, truncateString(key, MaxKeyLength), value)
+		}
+	}
+
+	e := &baseError{
+		id:          truncateString(schema.ID, MaxKeyLength),
+		class:       truncateString(schema.Class, MaxKeyLength),
+		category:    truncateString(schema.Category, MaxKeyLength),
+		severity:    schema.Severity,
+		created:     schema.Created,
+		message:     truncateString(schema.Message, MaxMessageLength),
+		fields:      limited,
+		retryable:   schema.Retryable,
+		attempt:     nonNegative(schema.Attempt),
+		maxAttempts: nonNegative(schema.MaxAttempts),
+		formatter:   FormatErrorWithFields,
+	}
+	e.setExtras(nonNegative(schema.RetryAfter), nonNegative(schema.TTL),
+		truncateString(schema.Hint, MaxMessageLength),
+		truncateString(schema.UserMessage, MaxMessageLength),
+		truncateString(schema.DocURL, MaxValueLength))
+	for _, b := range schema.Attachments {
+		e.attach(b)
+	}
+	return e.Freeze(), nil
+}
+
 // Close is a utility function that closes an io.Closer and wraps any
 // resulting error. It is intended to be used in defer statements.
 //
@@ -254,19 +449,212 @@ func Shutdown(ctx context.Context, err *error, sd func(ctx context.Context) erro
 	}
 }
 
+// Messages and field keys of the errors of [CloseAll] and [ShutdownWithTimeout].
+const (
+	CloseFailedMessage    = "failed to close"
+	ShutdownFailedMessage = "shutdown step failed"
+	CloserField           = "closer"
+	ShutdownStepField     = "step"
+)
+
+// CloseAll closes the closers in reverse order, as deferred calls would, and
+// stores the failures in err if it is nil: a single failure as is, several ones
+// as a multi-error. Each failure is wrapped with [CloseFailedMessage] and the
+// [CloserField] field with the type of the closer. Nil closers are skipped.
+//
+// Example:
+//
+//	func run() (err error) {
+//	    db, cache, file := openDB(), openCache(), openFile()
+//	    defer erro.CloseAll(&err, db, cache, file) // closes file, cache, db
+//	    // ...
+//	}
+func CloseAll(err *error, closers ...io.Closer) {
+	list := NewList()
+	for i := len(closers) - 1; i >= 0; i-- {
+		if closers[i] == nil {
+			continue
+		}
+		if errClose := closers[i].Close(); errClose != nil {
+			list.Wrap(errClose, CloseFailedMessage, CloserField, fmt.Sprintf("%T", closers[i]))
+		}
+	}
+	if err != nil && *err == nil {
+		*err = list.Err()
+	}
+}
+
+// ShutdownWithTimeout runs the shutdown functions in order, giving each one a context
+// derived from ctx with the timeout d, and stores the failures in err if it is nil,
+// as [CloseAll] does. A function that does not return in time is abandoned with an
+// error of [ClassTimeout] and the next one is started. Each failure is wrapped with
+// [ShutdownFailedMessage] and the [ShutdownStepField] field with the 0-based index
+// of the function. A non-positive d means no per-step timeout.
+//
+// Example:
+//
+//	defer erro.ShutdownWithTimeout(ctx, 5*time.Second, &err,
+//	    httpServer.Shutdown,
+//	    worker.Stop,
+//	    func(ctx context.Context) error { return db.Close() },
+//	)
+func ShutdownWithTimeout(ctx context.Context, d time.Duration, err *error, fns ...func(ctx context.Context) error) {
+	list := NewList()
+	for i, fn := range fns {
+		if fn == nil {
+			continue
+		}
+		if errStep := shutdownStep(ctx, d, fn); errStep != nil {
+			list.Wrap(errStep, ShutdownFailedMessage, ShutdownStepField, i)
+		}
+	}
+	if err != nil && *err == nil {
+		*err = list.Err()
+	}
+}
+
+func shutdownStep(ctx context.Context, d time.Duration, fn func(ctx context.Context) error) error {
+	if d <= 0 {
+		return fn(ctx)
+	}
+	stepCtx, cancel := context.WithTimeout(ctx, d)
+	defer cancel()
+
+	done := make(chan error, 1)
+	go func() {
+		done <- fn(stepCtx)
+	}()
+	select {
+	case err := <-done:
+		return err
+	case <-stepCtx.Done():
+		// The function may have returned at the same moment
+		select {
+		case err := <-done:
+			return err
+		default:
+		}
+		return Wrap(stepCtx.Err(), "shutdown step did not return in time", "timeout", d, ClassTimeout)
+	}
+}
+
 // Is reports whether any error in err's chain matches target.
 // It is a drop-in replacement for the standard `errors.Is` function.
 func Is(err error, target error) (ok bool) {
 	return errors.Is(err, target)
 }
 
+// IsStrict reports whether any error in err's chain matches target by identity:
+// it is the same error value or, for erro errors, both have equal IDs. Unlike [Is],
+// it never matches erro errors by class, category, severity and retryable flag,
+// whatever [WithIsMode] is set to. Is methods of other error types are respected.
+//
+// Example:
+//
+//	erro.Is(err, notFoundTarget)       // Matches any error with the same metadata
+//	erro.IsStrict(err, notFoundTarget) // Matches only the target and errors with its ID
+func IsStrict(err error, target error) bool {
+	if err == nil || target == nil {
+		return err == target
+	}
+	isComparable := reflect.TypeOf(target).Comparable()
+	return isStrict(err, target, isComparable, 0)
+}
+
+func isStrict(err, target error, isComparable bool, depth int) bool {
+	for ; err != nil && depth < MaxWrapDepth; depth++ {
+		if isComparable && reflect.TypeOf(err).Comparable() && err == target {
+			return true
+		}
+		switch x := err.(type) {
+		case *baseError:
+			if x.id != "" {
+				if targetErr, ok := target.(Error); ok && targetErr.ID() == x.id {
+					return true
+				}
+			}
+		case *multiError, *multiErrorSet:
+			// Their Is methods use errors.Is for the wrapped errors
+		case interface{ Is(error) bool }:
+			if x.Is(target) {
+				return true
+			}
+		}
+
+		switch x := err.(type) {
+		case interface{ Unwrap() error }:
+			err = x.Unwrap()
+		case interface{ Unwrap() []error }:
+			for _, wrapped := range x.Unwrap() {
+				if isStrict(wrapped, target, isComparable, depth+1) {
+					return true
+				}
+			}
+			return false
+		default:
+			return false
+		}
+	}
+	return false
+}
+
 // As finds the first error in err's chain that matches target, and if so, sets
 // target to that error value and returns true.
-// It is a drop-in replacement for the standard `errors.As` function.
+// It is a drop-in replacement for the standard `errors.As` function that also accepts
+// target shapes that errors.As rejects:
+//
+//	Target                             Sets the target to
+//	*E, E implements error             the first error assignable to E, as errors.As
+//	*I, I is an interface              the first error implementing I, e.g. *Error, *any
+//	*T, only *T implements error       a copy of the first *T error
+//	**X, ***X, ...                     a pointer to the match of *X
+//
+// Like errors.As, it panics if target is nil, not a pointer or a pointer to another type.
+//
+// Example:
+//
+//	var e *erro.Error // Works like var e erro.Error
+//	if erro.As(err, &e) {
+//	    log.Println((*e).ID())
+//	}
 func As(err error, target any) (ok bool) {
+	v := reflect.ValueOf(target)
+	if err == nil || v.Kind() != reflect.Ptr || v.IsNil() {
+		return errors.As(err, target)
+	}
+	elem := v.Type().Elem()
+	switch {
+	case elem.Kind() == reflect.Interface || elem.Implements(errorType):
+		return errors.As(err, target)
+	case reflect.PtrTo(elem).Implements(errorType):
+		match := reflect.New(reflect.PtrTo(elem))
+		if !errors.As(err, match.Interface()) || match.Elem().IsNil() {
+			return false
+		}
+		v.Elem().Set(match.Elem().Elem())
+		return true
+	case elem.Kind() == reflect.Ptr && asTarget(elem.Elem()):
+		match := reflect.New(elem.Elem())
+		if !As(err, match.Interface()) {
+			return false
+		}
+		v.Elem().Set(match)
+		return true
+	}
 	return errors.As(err, target)
 }
 
+// asTarget reports whether a pointer to the type is a valid target of [As].
+func asTarget(t reflect.Type) bool {
+	switch {
+	case t.Kind() == reflect.Interface, t.Implements(errorType), reflect.PtrTo(t).Implements(errorType):
+		return true
+	case t.Kind() == reflect.Ptr:
+		return asTarget(t.Elem())
+	}
+	return false
+}
+
 // Unwrap returns the result of calling the Unwrap method on err, if err's
 // type contains an Unwrap method returning error.
 // It is a drop-in replacement for the standard `errors.Unwrap` function.
@@ -411,16 +799,9 @@ func Join(errs ...error) error {
 //	func ErrorMiddleware(next http.Handler) http.Handler {
 //	    return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 //	        defer func() {
-//	            if err := recover(); err != nil {
-//	                var httpErr error
-//	                if e, ok := err.(error); ok {
-//	                    httpErr = e
-//	                } else {
-//	                    httpErr = fmt.Errorf("panic: %v", err)
-//	                }
-//
-//	                statusCode := erro.HTTPCode(httpErr)
-//	                http.Error(w, httpErr.Error(), statusCode)
+//	            if err := erro.FromPanic(recover()); err != nil {
+//	                statusCode := erro.HTTPCode(err)
+//	                http.Error(w, err.Error(), statusCode)
 //	            }
 //	        }()
 //	        next.ServeHTTP(w, r)
@@ -438,12 +819,7 @@ func HTTPCode(err error) int {
 	}
 
 	status := http.StatusInternalServerError
-	erroErr, ok := err.(Error)
-	if !ok {
-		if !As(err, &erroErr) {
-			return status
-		}
-	}
+	erroErr := ExtractError(err)
 
 	class := erroErr.Class()
 	category := erroErr.Category()
@@ -533,11 +909,54 @@ func newf(message string, meta ...any) *baseError {
 	if len(meta) == 0 {
 		return newBaseError(message)
 	}
-	message, meta = ApplyFormatVerbs(message, meta...)
+	message, meta = applyFormatVerbs(message, meta)
 	return newBaseError(message, meta...)
 }
 
 func wrapf(err error, message string, meta ...any) *baseError {
-	message, meta = ApplyFormatVerbs(message, meta...)
+	message, meta = applyFormatVerbs(message, meta)
 	return newWrapError(err, message, meta...)
 }
+
+// newSkipf is [newf] with the caller frames skipped, it must keep the same call depth.
+func newSkipf(skip int, message string, meta ...any) *baseError {
+	message, meta = applyFormatVerbs(message, meta)
+	return newBaseError(message, withCallerSkip(skip, meta)...)
+}
+
+// wrapSkipf is [wrapf] with the caller frames skipped, it must keep the same call depth.
+func wrapSkipf(skip int, err error, message string, meta ...any) *baseError {
+	message, meta = applyFormatVerbs(message, meta)
+	return newWrapError(err, message, withCallerSkip(skip, meta)...)
+}
+
+// messageFormat is the message of an error before format verbs were applied.
+type messageFormat string
+
+// applyFormatVerbs is [ApplyFormatVerbs] that keeps the original message in the meta
+// for [Fingerprint], so errors with different arguments are of the same kind.
+func applyFormatVerbs(message string, meta []any) (string, []any) {
+	formatted, rest := ApplyFormatVerbs(message, meta...)
+	if len(rest) == len(meta) {
+		return formatted, rest
+	}
+	return formatted, append(rest[:len(rest):len(rest)], messageFormat(message))
+}
+
+// callerSkip is the number of caller frames skipped by [NewSkip] and [WrapSkip].
+type callerSkip int
+
+// withCallerSkip puts the skip before the options that capture a stack trace.
+func withCallerSkip(skip int, meta []any) []any {
+	if skip < 0 {
+		skip = 0
+	}
+	return append([]any{callerSkip(skip)}, meta...)
+}
+
+func nonNegative[T ~int | ~int64](v T) T {
+	if v < 0 {
+		return 0
+	}
+	return v
+}
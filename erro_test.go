@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"net/http"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -100,6 +101,43 @@ func TestWrapNil(t *testing.T) {
 	}
 }
 
+func TestWrap2(t *testing.T) {
+	v, err := erro.Wrap2(42, nil, "wrapped")
+	if v != 42 || err != nil {
+		t.Errorf("Expected 42 and nil interface, got %v %T", v, err)
+	}
+
+	baseErr := errors.New("base error")
+	v, err = erro.Wrap2(7, baseErr, "wrapped", "key", "value")
+	if v != 7 {
+		t.Errorf("Expected value to be passed through, got %v", v)
+	}
+	if err == nil || err.Error() != "wrapped key=value: base error" || !errors.Is(err, baseErr) {
+		t.Errorf("Unexpected wrapped error: %v", err)
+	}
+
+	e := erro.ExtractError(func() error {
+		_, err := erro.Wrap2("", baseErr, "with stack", erro.StackTrace())
+		return err
+	}())
+	if top := e.Stack().TopUserFrame(); top == nil || !strings.Contains(top.FullName, "TestWrap2") {
+		t.Errorf("Expected stack to start in the caller, got %v", top)
+	}
+}
+
+func TestWrap3(t *testing.T) {
+	a, b, err := erro.Wrap3("host", 80, nil, "wrapped")
+	if a != "host" || b != 80 || err != nil {
+		t.Errorf("Unexpected result: %v %v %v", a, b, err)
+	}
+
+	baseErr := errors.New("base error")
+	a, b, err = erro.Wrap3("host", 0, baseErr, "wrapped")
+	if a != "host" || b != 0 || !errors.Is(err, baseErr) {
+		t.Errorf("Unexpected result: %v %v %v", a, b, err)
+	}
+}
+
 func TestIs(t *testing.T) {
 	baseErr := erro.New("test error", erro.ID("test_id"))
 	wrappedErr := erro.Wrap(baseErr, "wrapped")
@@ -153,12 +191,14 @@ func TestAs(t *testing.T) {
 	}
 
 	var notAPointer customError
-	defer func() {
-		if r := recover(); r == nil {
-			t.Errorf("The code did not panic")
-		}
+	func() {
+		defer func() {
+			if r := recover(); r == nil {
+				t.Errorf("The code did not panic")
+			}
+		}()
+		erro.As(baseErr, notAPointer)
 	}()
-	erro.As(baseErr, notAPointer)
 
 	var asBaseErr *customError
 	wrappedBaseErr := erro.Wrap(erro.New("base"), "wrapped")
@@ -166,20 +206,75 @@ func TestAs(t *testing.T) {
 		t.Error("expected As to be false for wrapped base error")
 	}
 
-	var asBaseErrTarget *erro.Error
-	if erro.As(wrappedBaseErr, &asBaseErrTarget) {
+	var asBaseErrTarget3 **customError
+	if erro.As(wrappedBaseErr, &asBaseErrTarget3) {
 		t.Error("expected As to be false for wrapped base error")
 	}
+}
 
-	var asBaseErrTarget2 **erro.Error
-	if erro.As(wrappedBaseErr, &asBaseErrTarget2) {
-		t.Error("expected As to be false for wrapped base error")
+type timeoutError struct{}
+
+func (timeoutError) Error() string { return "timeout" }
+func (timeoutError) Timeout() bool { return true }
+
+func TestAsTargetShapes(t *testing.T) {
+	custom := &customError{msg: "custom"}
+	err := erro.Wrap(fmt.Errorf("query: %w", custom), "load user", "user_id", 42)
+
+	var anyTarget any
+	if !erro.As(err, &anyTarget) || anyTarget != err {
+		t.Errorf("expected *any to get the outermost error, got %v", anyTarget)
+	}
+	var errTarget erro.Error
+	if !erro.As(err, &errTarget) || errTarget != err {
+		t.Errorf("expected *Error to get the outermost error, got %v", errTarget)
+	}
+	var doubleErr *erro.Error
+	if !erro.As(err, &doubleErr) || doubleErr == nil || *doubleErr != err {
+		t.Errorf("expected **Error to point to the outermost error, got %v", doubleErr)
+	}
+	var tripleErr **erro.Error
+	if !erro.As(err, &tripleErr) || tripleErr == nil || **tripleErr != err {
+		t.Error("expected ***Error to point to the outermost error")
+	}
+	var ider interface{ ID() string }
+	if !erro.As(err, &ider) || ider.ID() != err.ID() {
+		t.Error("expected an interface target implemented by the error")
 	}
 
-	var asBaseErrTarget3 **customError
-	if erro.As(wrappedBaseErr, &asBaseErrTarget3) {
-		t.Error("expected As to be false for wrapped base error")
+	var customValue customError
+	if !erro.As(err, &customValue) || customValue.msg != "custom" {
+		t.Errorf("expected a copy of *customError, got %+v", customValue)
+	}
+	var customPtr *customError
+	if !erro.As(err, &customPtr) || customPtr != custom {
+		t.Error("expected the *customError")
+	}
+	var customDouble **customError
+	if !erro.As(err, &customDouble) || *customDouble != custom {
+		t.Error("expected a pointer to the *customError")
 	}
+
+	var timeout interface{ Timeout() bool }
+	if !erro.As(erro.Wrap(timeoutError{}, "dial"), &timeout) || !timeout.Timeout() {
+		t.Error("expected an interface implemented by a wrapped error")
+	}
+	if erro.As(erro.New("no timeout"), &timeout) {
+		t.Error("expected As to be false without a matching error")
+	}
+	if erro.As(nil, &customPtr) || erro.As(nil, &doubleErr) {
+		t.Error("expected As to be false for a nil error")
+	}
+
+	var unsupported struct{ x int }
+	func() {
+		defer func() {
+			if r := recover(); r == nil {
+				t.Error("expected As to panic for a target that is not an error")
+			}
+		}()
+		erro.As(err, &unsupported)
+	}()
 }
 
 type templateError struct {
@@ -189,18 +284,24 @@ type templateError struct {
 	retryable bool
 }
 
-func (e *templateError) Error() string                { return "" }
-func (e *templateError) Class() erro.ErrorClass       { return e.class }
-func (e *templateError) Category() erro.ErrorCategory { return e.category }
-func (e *templateError) Severity() erro.ErrorSeverity { return e.severity }
-func (e *templateError) IsRetryable() bool            { return e.retryable }
-func (e *templateError) ID() string                   { return "" }
-func (e *templateError) Message() string              { return "" }
-func (e *templateError) Fields() []any                { return nil }
-func (e *templateError) AllFields() []any             { return nil }
-func (e *templateError) Created() time.Time           { return time.Time{} }
-func (e *templateError) Span() erro.TraceSpan         { return nil }
-func (e *templateError) Stack() erro.Stack            { return nil }
+func (e *templateError) Error() string                     { return "" }
+func (e *templateError) Class() erro.ErrorClass            { return e.class }
+func (e *templateError) Category() erro.ErrorCategory      { return e.category }
+func (e *templateError) Severity() erro.ErrorSeverity      { return e.severity }
+func (e *templateError) IsRetryable() bool                 { return e.retryable }
+func (e *templateError) Redacted() erro.Error              { return e }
+func (e *templateError) Attempt() int                      { return 0 }
+func (e *templateError) MaxAttempts() int                  { return 0 }
+func (e *templateError) RetryAfter() (time.Duration, bool) { return 0, false }
+func (e *templateError) ID() string                        { return "" }
+func (e *templateError) Message() string                   { return "" }
+func (e *templateError) Fields() []any                     { return nil }
+func (e *templateError) AllFields() []any                  { return nil }
+func (e *templateError) LayerCount() int                   { return 1 }
+func (e *templateError) FieldsAt(int) []any                { return nil }
+func (e *templateError) Created() time.Time                { return time.Time{} }
+func (e *templateError) Span() erro.TraceSpan              { return nil }
+func (e *templateError) Stack() erro.Stack                 { return nil }
 func (e *templateError) LogFields(...erro.LogOptions) []any {
 	return nil
 }
@@ -216,6 +317,13 @@ func (e *templateError) As(target any) bool                       { return false
 func (e *templateError) Format(s fmt.State, verb rune)            {}
 func (e *templateError) MarshalJSON() ([]byte, error)             { return nil, nil }
 func (e *templateError) UnmarshalJSON(data []byte) error          { return nil }
+func (e *templateError) Wrap(message string, fields ...any) erro.Error {
+	return erro.Wrap(e, message, fields...)
+}
+func (e *templateError) DebugBundle() ([]byte, error) { return nil, nil }
+func (e *templateError) TTL() (time.Duration, bool)   { return 0, false }
+func (e *templateError) Expired() bool                { return false }
+func (e *templateError) Tenant() string               { return "" }
 
 func TestAsBaseError(t *testing.T) {
 	err := erro.New("test")
@@ -362,6 +470,119 @@ func TestClose(t *testing.T) {
 	}
 }
 
+type orderCloser struct {
+	name  string
+	err   error
+	order *[]string
+}
+
+func (c *orderCloser) Close() error {
+	*c.order = append(*c.order, c.name)
+	return c.err
+}
+
+func TestCloseAll(t *testing.T) {
+	var order []string
+	first := &orderCloser{name: "db", err: errors.New("db close error"), order: &order}
+	second := &orderCloser{name: "cache", order: &order}
+	third := &orderCloser{name: "file", err: errors.New("file close error"), order: &order}
+
+	var err error
+	erro.CloseAll(&err, first, nil, second, third)
+	if strings.Join(order, ",") != "file,cache,db" {
+		t.Errorf("expected reverse order, got %v", order)
+	}
+	if err == nil {
+		t.Fatal("Expected error, got nil")
+	}
+	for _, want := range []string{"db close error", "file close error", erro.CloseFailedMessage, "*erro_test.orderCloser"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("Expected error to contain %q, got %q", want, err.Error())
+		}
+	}
+	if !errors.Is(err, first.err) || !errors.Is(err, third.err) {
+		t.Error("Expected both close errors in the chain")
+	}
+
+	single := errors.New("single")
+	var err2 error
+	erro.CloseAll(&err2, &mockCloser{}, &mockCloser{err: single})
+	if e := erro.ExtractError(err2); !errors.Is(err2, single) || e.Message() == "" {
+		t.Errorf("Expected a single wrapped error, got %v", err2)
+	}
+
+	existing := errors.New("existing")
+	err3 := existing
+	erro.CloseAll(&err3, &mockCloser{err: errors.New("ignored")})
+	if err3 != existing {
+		t.Errorf("Expected the existing error to be kept, got %v", err3)
+	}
+
+	var err4 error
+	erro.CloseAll(&err4, &mockCloser{})
+	if err4 != nil {
+		t.Errorf("Expected error to be nil, got '%s'", err4.Error())
+	}
+}
+
+func TestShutdownWithTimeout(t *testing.T) {
+	var mu sync.Mutex
+	var order []int
+	step := func(i int) {
+		mu.Lock()
+		defer mu.Unlock()
+		order = append(order, i)
+	}
+	release := make(chan struct{})
+	defer close(release)
+
+	var err error
+	erro.ShutdownWithTimeout(context.Background(), 20*time.Millisecond, &err,
+		func(ctx context.Context) error {
+			step(0)
+			return nil
+		},
+		func(ctx context.Context) error {
+			step(1)
+			<-release // ignores the context
+			return nil
+		},
+		nil,
+		func(ctx context.Context) error {
+			step(3)
+			if _, ok := ctx.Deadline(); !ok {
+				return errors.New("no deadline")
+			}
+			return errors.New("stop error")
+		},
+	)
+	mu.Lock()
+	defer mu.Unlock()
+	if len(order) != 3 || order[0] != 0 || order[1] != 1 || order[2] != 3 {
+		t.Errorf("unexpected order %v", order)
+	}
+	if err == nil {
+		t.Fatal("Expected error, got nil")
+	}
+	if !errors.Is(err, context.DeadlineExceeded) || !strings.Contains(err.Error(), "stop error") {
+		t.Errorf("Expected timeout and step errors, got %q", err.Error())
+	}
+	if !strings.Contains(err.Error(), "step=1") || !strings.Contains(err.Error(), "step=3") {
+		t.Errorf("Expected step indexes, got %q", err.Error())
+	}
+
+	var err2 error
+	erro.ShutdownWithTimeout(context.Background(), 0, &err2, func(ctx context.Context) error {
+		if _, ok := ctx.Deadline(); ok {
+			return errors.New("unexpected deadline")
+		}
+		return nil
+	})
+	if err2 != nil {
+		t.Errorf("Expected error to be nil, got '%s'", err2.Error())
+	}
+}
+
 func TestShutdown(t *testing.T) {
 	var err error
 	shutdownFunc := func(ctx context.Context) error {
@@ -1748,18 +1969,24 @@ type testTemplateError struct {
 	retryable bool
 }
 
-func (e *testTemplateError) Error() string                { return "" }
-func (e *testTemplateError) Class() erro.ErrorClass       { return e.class }
-func (e *testTemplateError) Category() erro.ErrorCategory { return e.category }
-func (e *testTemplateError) Severity() erro.ErrorSeverity { return e.severity }
-func (e *testTemplateError) IsRetryable() bool            { return e.retryable }
-func (e *testTemplateError) ID() string                   { return e.id }
-func (e *testTemplateError) Message() string              { return "" }
-func (e *testTemplateError) Fields() []any                { return nil }
-func (e *testTemplateError) AllFields() []any             { return nil }
-func (e *testTemplateError) Created() time.Time           { return time.Time{} }
-func (e *testTemplateError) Span() erro.TraceSpan         { return nil }
-func (e *testTemplateError) Stack() erro.Stack            { return nil }
+func (e *testTemplateError) Error() string                     { return "" }
+func (e *testTemplateError) Class() erro.ErrorClass            { return e.class }
+func (e *testTemplateError) Category() erro.ErrorCategory      { return e.category }
+func (e *testTemplateError) Severity() erro.ErrorSeverity      { return e.severity }
+func (e *testTemplateError) IsRetryable() bool                 { return e.retryable }
+func (e *testTemplateError) Redacted() erro.Error              { return e }
+func (e *testTemplateError) Attempt() int                      { return 0 }
+func (e *testTemplateError) MaxAttempts() int                  { return 0 }
+func (e *testTemplateError) RetryAfter() (time.Duration, bool) { return 0, false }
+func (e *testTemplateError) ID() string                        { return e.id }
+func (e *testTemplateError) Message() string                   { return "" }
+func (e *testTemplateError) Fields() []any                     { return nil }
+func (e *testTemplateError) AllFields() []any                  { return nil }
+func (e *testTemplateError) LayerCount() int                   { return 1 }
+func (e *testTemplateError) FieldsAt(int) []any                { return nil }
+func (e *testTemplateError) Created() time.Time                { return time.Time{} }
+func (e *testTemplateError) Span() erro.TraceSpan              { return nil }
+func (e *testTemplateError) Stack() erro.Stack                 { return nil }
 func (e *testTemplateError) LogFields(...erro.LogOptions) []any {
 	return nil
 }
@@ -1775,6 +2002,13 @@ func (e *testTemplateError) As(target any) bool                       { return f
 func (e *testTemplateError) Format(s fmt.State, verb rune)            {}
 func (e *testTemplateError) MarshalJSON() ([]byte, error)             { return nil, nil }
 func (e *testTemplateError) UnmarshalJSON(data []byte) error          { return nil }
+func (e *testTemplateError) Wrap(message string, fields ...any) erro.Error {
+	return erro.Wrap(e, message, fields...)
+}
+func (e *testTemplateError) DebugBundle() ([]byte, error) { return nil, nil }
+func (e *testTemplateError) TTL() (time.Duration, bool)   { return 0, false }
+func (e *testTemplateError) Expired() bool                { return false }
+func (e *testTemplateError) Tenant() string               { return "" }
 
 func TestHTTPCode(t *testing.T) {
 	testCases := []struct {
@@ -1836,3 +2070,36 @@ func TestHTTPCode(t *testing.T) {
 		t.Errorf("Expected HTTP code %d, got %d", http.StatusOK, code)
 	}
 }
+
+func TestErrorWrapMethod(t *testing.T) {
+	root := erro.New("connection refused", "host", "db")
+	err := root.Wrap("query users", "table", "users").Wrap("fetch user %d", 42, "user_id", 42)
+	if err.Error() != "fetch user 42 user_id=42: query users table=users: connection refused host=db" {
+		t.Errorf("unexpected message: %q", err.Error())
+	}
+	if !errors.Is(err, root) || err.ID() != root.ID() {
+		t.Error("expected wrapped error to match the root error")
+	}
+}
+
+func TestWrapOnce(t *testing.T) {
+	if erro.WrapOnce(nil, "handler failed") != nil {
+		t.Error("expected nil for nil error")
+	}
+
+	root := errors.New("timeout")
+	err := erro.WrapOnce(erro.WrapOnce(root, "handler failed", "path", "/a"), "handler failed", "path", "/b")
+	if err.Error() != "handler failed path=/a: timeout" {
+		t.Errorf("expected a single layer, got %q", err.Error())
+	}
+	if err = erro.WrapOnce(err, "request failed"); err.Error() != "request failed: handler failed path=/a: timeout" {
+		t.Errorf("expected a new layer for another message, got %q", err.Error())
+	}
+
+	if !erro.IsWrappedBy(fmt.Errorf("serve: %w", err), "handler failed") {
+		t.Error("expected inner layer to be found")
+	}
+	if erro.IsWrappedBy(err, "timeout") || erro.IsWrappedBy(nil, "handler failed") {
+		t.Error("expected only erro layers to match")
+	}
+}
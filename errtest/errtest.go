@@ -0,0 +1,110 @@
+// Package errtest provides helpers for testing code that uses erro.
+package errtest
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/maxbolgarin/erro"
+)
+
+// Clock is a fake time source for [erro.SetClock]. It returns the same time
+// until it is moved with [Clock.Advance] or [Clock.Set]. It is safe for concurrent use.
+type Clock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+// NewClock returns a fake clock set to the given time.
+func NewClock(now time.Time) *Clock {
+	return &Clock{now: now}
+}
+
+// Now returns the current time of the clock.
+func (c *Clock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+// Advance moves the clock forward by d.
+func (c *Clock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+// Set sets the time of the clock.
+func (c *Clock) Set(now time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = now
+}
+
+// UseClock installs a fake clock started at the given time as the erro clock and
+// restores the default clock when the test finishes.
+//
+// Example:
+//
+//	clock := errtest.UseClock(t, time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+//	err := erro.New("failed")
+//	clock.Advance(time.Minute)
+func UseClock(tb testing.TB, now time.Time) *Clock {
+	tb.Helper()
+	c := NewClock(now)
+	erro.SetClock(c.Now)
+	tb.Cleanup(func() { erro.SetClock(nil) })
+	return c
+}
+
+// SeedIDs makes the IDs of errors deterministic with [erro.SeedIDs] and restores
+// the default IDs when the test finishes. Like [erro.SeedIDs], it requires the errotest
+// build tag or [erro.EnableFaultInjection].
+//
+// Example:
+//
+//	errtest.SeedIDs(t, 42)
+//	err := erro.New("failed") // err.ID() is the same on every run
+func SeedIDs(tb testing.TB, seed int64) {
+	tb.Helper()
+	erro.SeedIDs(seed)
+	tb.Cleanup(erro.ResetIDs)
+}
+
+// ValidateTemplates fails the test if a template created with [erro.NewTemplate] is
+// invalid, see [erro.ValidateTemplates].
+//
+// Example:
+//
+//	func TestTemplates(t *testing.T) {
+//	    errtest.ValidateTemplates(t)
+//	}
+func ValidateTemplates(tb testing.TB) {
+	tb.Helper()
+	if err := erro.ValidateTemplates(); err != nil {
+		tb.Errorf("invalid error templates: %v", err)
+	}
+}
+
+// LogToTesting returns a function that logs errors through tb.Log as leveled logfmt
+// lines with all fields, see [erro.FormatLogLine]. It can be passed where a handler
+// of errors is expected, e.g. to [erro.SetShouldHandler] with a conversion.
+//
+// Example:
+//
+//	logErr := errtest.LogToTesting(t)
+//	logErr(err) // level=error msg="get user" user_id=42 ...
+func LogToTesting(tb testing.TB, opts ...erro.LogOption) func(err error) {
+	tb.Helper()
+	if len(opts) == 0 {
+		opts = erro.VerboseLogOpts
+	}
+	return func(err error) {
+		if err == nil {
+			return
+		}
+		tb.Helper()
+		tb.Log(erro.FormatLogLine(err, opts...))
+	}
+}
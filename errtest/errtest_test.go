@@ -0,0 +1,98 @@
+package errtest
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/maxbolgarin/erro"
+)
+
+func TestUseClock(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	t.Run("Fake", func(t *testing.T) {
+		clock := UseClock(t, start)
+		if created := erro.New("first").Created(); !created.Equal(start) {
+			t.Errorf("expected created time %v, got %v", start, created)
+		}
+
+		clock.Advance(time.Minute)
+		if created := erro.Wrap(errors.New("base"), "second").Created(); !created.Equal(start.Add(time.Minute)) {
+			t.Errorf("expected advanced created time, got %v", created)
+		}
+
+		a, b := erro.New("a"), erro.New("b")
+		if a.ID() == "" || a.ID() == b.ID() {
+			t.Errorf("expected unique IDs with a frozen clock, got %q and %q", a.ID(), b.ID())
+		}
+	})
+
+	if created := erro.New("after").Created(); created.Equal(start) || created.Before(start) {
+		t.Errorf("expected default clock to be restored, got %v", created)
+	}
+}
+
+func TestSeedIDs(t *testing.T) {
+	erro.EnableFaultInjection()
+	var first string
+	t.Run("Seeded", func(t *testing.T) {
+		SeedIDs(t, 1)
+		first = erro.New("failed").ID()
+	})
+	t.Run("Again", func(t *testing.T) {
+		SeedIDs(t, 1)
+		if id := erro.New("failed").ID(); id != first {
+			t.Errorf("expected reproducible ID %q, got %q", first, id)
+		}
+	})
+
+	if id := erro.New("failed").ID(); id == first {
+		t.Errorf("expected default IDs to be restored, got %q", id)
+	}
+}
+
+func TestValidateTemplates(t *testing.T) {
+	ValidateTemplates(t)
+
+	erro.NewTemplate("%d items %s").WithArgs("count")
+	tb := &failingTB{TB: t}
+	ValidateTemplates(tb)
+	if len(tb.failures) != 1 || !strings.Contains(tb.failures[0], "format verbs do not match declared args") {
+		t.Errorf("expected an invalid template to fail the test, got %v", tb.failures)
+	}
+}
+
+type recordingTB struct {
+	testing.TB
+	lines []string
+}
+
+func (r *recordingTB) Helper() {}
+
+func (r *recordingTB) Log(args ...any) {
+	r.lines = append(r.lines, fmt.Sprint(args...))
+}
+
+func TestLogToTesting(t *testing.T) {
+	tb := &recordingTB{TB: t}
+	logErr := LogToTesting(tb)
+	logErr(nil)
+	logErr(erro.New("get user", "user_id", 42, erro.SeverityHigh))
+	if len(tb.lines) != 1 {
+		t.Fatalf("expected one line, got %v", tb.lines)
+	}
+	if line := tb.lines[0]; !strings.HasPrefix(line, `level=error msg="get user" user_id=42`) || !strings.Contains(line, "severity=high") {
+		t.Errorf("unexpected line: %s", line)
+	}
+
+	tb.lines = nil
+	LogToTesting(tb, erro.WithUserFields())(erro.New("minimal", "key", "value"))
+	if len(tb.lines) != 1 || tb.lines[0] != `level=error msg="minimal" key=value` {
+		t.Errorf("unexpected line with options: %v", tb.lines)
+	}
+
+	LogToTesting(t)(erro.New("surfaced through t.Log", "key", "value"))
+}
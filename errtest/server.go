@@ -0,0 +1,130 @@
+package errtest
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/maxbolgarin/erro"
+)
+
+// Server is a test HTTP server for checking how handlers render errors on the wire,
+// e.g. with [erro.WriteHTTPError] and middlewares.
+type Server struct {
+	*httptest.Server
+	tb testing.TB
+}
+
+// Response is a response of a [Server] with the body read.
+type Response struct {
+	StatusCode int
+	Header     http.Header
+	Body       []byte
+}
+
+// NewServer starts a test server with the handler and closes it when the test finishes.
+//
+// Example:
+//
+//	srv := errtest.NewServer(t, middleware(handler))
+//	resp := srv.Get("/orders/42")
+//	errtest.AssertStatus(t, resp, http.StatusNotFound)
+//	if pd := errtest.AssertProblemJSON(t, resp); pd.Class != erro.ClassNotFound {
+//	    t.Errorf("unexpected class %s", pd.Class)
+//	}
+//	errtest.AssertErrorID(t, resp, "")
+func NewServer(tb testing.TB, handler http.Handler) *Server {
+	tb.Helper()
+	s := &Server{Server: httptest.NewServer(handler), tb: tb}
+	tb.Cleanup(s.Close)
+	return s
+}
+
+// Get sends a GET request to the path of the server.
+func (s *Server) Get(path string) *Response {
+	s.tb.Helper()
+	return s.Do(http.MethodGet, path, nil)
+}
+
+// Do sends a request to the path of the server and reads the response.
+// It fails the test if the request cannot be sent.
+func (s *Server) Do(method, path string, body io.Reader, header ...http.Header) *Response {
+	s.tb.Helper()
+	req, err := http.NewRequest(method, s.URL+path, body)
+	if err != nil {
+		s.tb.Fatalf("errtest: create request: %v", err)
+	}
+	for _, h := range header {
+		for key, values := range h {
+			req.Header[key] = append(req.Header[key], values...)
+		}
+	}
+	resp, err := s.Client().Do(req)
+	if err != nil {
+		s.tb.Fatalf("errtest: %s %s: %v", method, path, err)
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		s.tb.Fatalf("errtest: read response of %s %s: %v", method, path, err)
+	}
+	return &Response{StatusCode: resp.StatusCode, Header: resp.Header, Body: data}
+}
+
+// FromRecorder returns the response recorded by an [httptest.ResponseRecorder],
+// to use the assertions without a server.
+func FromRecorder(rec *httptest.ResponseRecorder) *Response {
+	return &Response{StatusCode: rec.Code, Header: rec.Header(), Body: rec.Body.Bytes()}
+}
+
+// AssertStatus checks the status code of the response and reports whether it matches.
+func AssertStatus(tb testing.TB, resp *Response, status int) bool {
+	tb.Helper()
+	if resp.StatusCode != status {
+		tb.Errorf("errtest: expected status %d, got %d: %s", status, resp.StatusCode, resp.Body)
+		return false
+	}
+	return true
+}
+
+// AssertProblemJSON checks that the response is [erro.ProblemDetails] JSON with the
+// [erro.ProblemDetailsContentType] content type and the status of the response, and
+// returns the decoded problem details.
+func AssertProblemJSON(tb testing.TB, resp *Response) erro.ProblemDetails {
+	tb.Helper()
+	var pd erro.ProblemDetails
+	if contentType := resp.Header.Get("Content-Type"); contentType != erro.ProblemDetailsContentType {
+		tb.Errorf("errtest: expected content type %q, got %q", erro.ProblemDetailsContentType, contentType)
+	}
+	if err := json.Unmarshal(resp.Body, &pd); err != nil {
+		tb.Errorf("errtest: response is not problem details JSON: %v: %s", err, resp.Body)
+		return pd
+	}
+	if pd.Status != resp.StatusCode {
+		tb.Errorf("errtest: expected problem status %d to match the response, got %d", resp.StatusCode, pd.Status)
+	}
+	return pd
+}
+
+// AssertErrorID checks that the response carries the error ID in the [erro.ErrorIDHeader]
+// header and in the problem details body if it has one, and returns the ID. An empty id
+// checks only that an ID is present.
+func AssertErrorID(tb testing.TB, resp *Response, id string) string {
+	tb.Helper()
+	got := resp.Header.Get(erro.ErrorIDHeader)
+	switch {
+	case got == "":
+		tb.Errorf("errtest: expected the %s header", erro.ErrorIDHeader)
+		return ""
+	case id != "" && got != id:
+		tb.Errorf("errtest: expected error ID %q, got %q", id, got)
+	}
+
+	var pd erro.ProblemDetails
+	if json.Unmarshal(resp.Body, &pd) == nil && pd.ID != "" && pd.ID != got {
+		tb.Errorf("errtest: error ID %q of the body does not match the header %q", pd.ID, got)
+	}
+	return got
+}
@@ -0,0 +1,69 @@
+package errtest
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/maxbolgarin/erro"
+)
+
+type failingTB struct {
+	testing.TB
+	failures []string
+}
+
+func (f *failingTB) Helper() {}
+
+func (f *failingTB) Errorf(format string, args ...any) {
+	f.failures = append(f.failures, fmt.Sprintf(format, args...))
+}
+
+func TestServer(t *testing.T) {
+	srv := NewServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/missing":
+			erro.WriteHTTPError(w, erro.New("order not found", erro.ClassNotFound, erro.ID("ORD-404"), erro.RequestID(r)))
+		case "/plain":
+			http.Error(w, "boom", http.StatusInternalServerError)
+		default:
+			w.WriteHeader(http.StatusOK)
+		}
+	}))
+
+	resp := srv.Do(http.MethodGet, "/missing", nil, http.Header{erro.RequestIDHeader: {"req-1"}})
+	AssertStatus(t, resp, http.StatusNotFound)
+	pd := AssertProblemJSON(t, resp)
+	if pd.Class != erro.ClassNotFound || pd.RequestID != "req-1" {
+		t.Errorf("unexpected problem details: %+v", pd)
+	}
+	if id := AssertErrorID(t, resp, "ORD-404"); id != "ORD-404" {
+		t.Errorf("unexpected id %q", id)
+	}
+	AssertErrorID(t, resp, "")
+	AssertStatus(t, srv.Get("/"), http.StatusOK)
+
+	tb := &failingTB{TB: t}
+	if AssertStatus(tb, resp, http.StatusOK) {
+		t.Error("expected a status mismatch")
+	}
+	AssertErrorID(tb, resp, "other")
+	plain := srv.Get("/plain")
+	AssertProblemJSON(tb, plain)
+	AssertErrorID(tb, plain, "")
+	if len(tb.failures) != 5 {
+		t.Errorf("expected 5 failures, got %d: %v", len(tb.failures), tb.failures)
+	}
+}
+
+func TestFromRecorder(t *testing.T) {
+	rec := httptest.NewRecorder()
+	erro.WriteHTTPError(rec, erro.New("slow down", erro.ClassRateLimited))
+	resp := FromRecorder(rec)
+	AssertStatus(t, resp, http.StatusTooManyRequests)
+	if pd := AssertProblemJSON(t, resp); pd.Detail != "slow down" {
+		t.Errorf("unexpected detail %q", pd.Detail)
+	}
+	AssertErrorID(t, resp, "")
+}
@@ -0,0 +1,52 @@
+package errtest
+
+import (
+	_ "embed"
+	"encoding/json"
+)
+
+// WireVector is a payload of the JSON wire format of errors ([erro.ErrorSchema]) with the
+// expected result of decoding it with [erro.FromJSON]. The vectors are published in the
+// wire_vectors.json file of the package, so implementations in other languages can check
+// their compatibility with the same payloads.
+type WireVector struct {
+	// Name is a unique name of the vector.
+	Name string `json:"name"`
+	// Description explains what the vector checks.
+	Description string `json:"description"`
+	// Payload is the input of the decoder. It may be malformed JSON.
+	Payload string `json:"payload"`
+	// Valid is true if the payload must decode successfully.
+	Valid bool `json:"valid"`
+	// Message is the expected message of a valid payload.
+	Message string `json:"message,omitempty"`
+	// Class is the expected class of a valid payload.
+	Class string `json:"class,omitempty"`
+	// Severity is the expected severity of a valid payload.
+	Severity string `json:"severity,omitempty"`
+	// FieldsCount is the expected number of key-value fields of a valid payload.
+	FieldsCount int `json:"fields_count,omitempty"`
+}
+
+//go:embed wire_vectors.json
+var wireVectorsJSON []byte
+
+// WireVectors returns the valid and adversarial payloads of the JSON wire format.
+//
+// Example:
+//
+//	for _, v := range errtest.WireVectors() {
+//	    _, err := erro.FromJSON([]byte(v.Payload))
+//	    if (err == nil) != v.Valid {
+//	        t.Errorf("%s: unexpected result %v", v.Name, err)
+//	    }
+//	}
+func WireVectors() []WireVector {
+	var file struct {
+		Vectors []WireVector `json:"vectors"`
+	}
+	if err := json.Unmarshal(wireVectorsJSON, &file); err != nil {
+		panic("errtest: invalid wire vectors: " + err.Error())
+	}
+	return file.Vectors
+}
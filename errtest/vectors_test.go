@@ -0,0 +1,42 @@
+package errtest
+
+import (
+	"testing"
+
+	"github.com/maxbolgarin/erro"
+)
+
+func TestWireVectors(t *testing.T) {
+	vectors := WireVectors()
+	if len(vectors) == 0 {
+		t.Fatal("expected wire vectors")
+	}
+	names := make(map[string]bool, len(vectors))
+	for _, v := range vectors {
+		if names[v.Name] {
+			t.Errorf("duplicate vector %s", v.Name)
+		}
+		names[v.Name] = true
+
+		err, decodeErr := erro.FromJSON([]byte(v.Payload))
+		if !v.Valid {
+			if decodeErr == nil {
+				t.Errorf("%s: expected a decoding error", v.Name)
+			}
+			continue
+		}
+		if decodeErr != nil {
+			t.Errorf("%s: unexpected error: %v", v.Name, decodeErr)
+			continue
+		}
+		if err.Message() != v.Message {
+			t.Errorf("%s: expected message %q, got %q", v.Name, v.Message, err.Message())
+		}
+		if string(err.Class()) != v.Class || string(err.Severity()) != v.Severity {
+			t.Errorf("%s: expected class %q and severity %q, got %q and %q", v.Name, v.Class, v.Severity, err.Class(), err.Severity())
+		}
+		if n := len(err.Fields()) / 2; n != v.FieldsCount {
+			t.Errorf("%s: expected %d fields, got %d", v.Name, v.FieldsCount, n)
+		}
+	}
+}
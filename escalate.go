@@ -0,0 +1,151 @@
+package erro
+
+import (
+	"sync"
+	"time"
+)
+
+// DefaultEscalationKeys is the number of distinct keys tracked per rule of an [Escalator].
+const DefaultEscalationKeys = 10000
+
+// EscalationRule raises the severity of errors of the same kind that occur too often.
+type EscalationRule struct {
+	// Threshold is the number of occurrences within Window starting from which errors are escalated.
+	Threshold int
+	// Window is the time window of counting, it starts with the first occurrence of a key.
+	// Zero means occurrences are counted forever.
+	Window time.Duration
+	// Severity is the severity of escalated errors. Errors with a higher severity are kept as is.
+	Severity ErrorSeverity
+	// KeyGetter groups errors of the same kind. Default is [FingerprintKeyGetter].
+	KeyGetter KeyGetterFunc
+	// MaxKeys limits the number of tracked keys, see [Set.WithMaxKeys]. Default is [DefaultEscalationKeys].
+	MaxKeys int
+	// OnEscalate is called once per key and window when the threshold is reached,
+	// with the escalated error and the number of occurrences.
+	OnEscalate func(err Error, count int)
+}
+
+// Escalator counts errors by key and returns escalated copies of errors that match
+// an [EscalationRule]. It is safe for concurrent use.
+type Escalator struct {
+	mu    sync.Mutex
+	rules []escalationState
+}
+
+type escalationState struct {
+	rule  EscalationRule
+	set   *Set
+	fired map[string]bool
+}
+
+// NewEscalator creates a new [Escalator] with the given rules.
+//
+// Example:
+//
+//	esc := erro.NewEscalator(erro.EscalationRule{
+//	    Threshold:  100,
+//	    Window:     5 * time.Minute,
+//	    Severity:   erro.SeverityHigh,
+//	    OnEscalate: func(err erro.Error, count int) { alerts.Page(err) },
+//	})
+//	err = esc.Observe(err)
+func NewEscalator(rules ...EscalationRule) *Escalator {
+	x := &Escalator{rules: make([]escalationState, 0, len(rules))}
+	for _, rule := range rules {
+		if rule.KeyGetter == nil {
+			rule.KeyGetter = FingerprintKeyGetter
+		}
+		if rule.MaxKeys <= 0 {
+			rule.MaxKeys = DefaultEscalationKeys
+		}
+		set := NewSet().WithKeyGetter(rule.KeyGetter).WithMaxKeys(rule.MaxKeys)
+		set.setTTL(rule.Window)
+		x.rules = append(x.rules, escalationState{rule: rule, set: set, fired: make(map[string]bool)})
+	}
+	return x
+}
+
+// Observe counts the error and returns it with the highest severity of the rules whose
+// threshold is reached. The original error is not modified: an escalated error is
+// a copy with the same ID, fields and chain. Errors below all thresholds are returned
+// as is. It returns nil for nil.
+func (x *Escalator) Observe(err error) Error {
+	if err == nil {
+		return nil
+	}
+	e := ExtractError(err)
+
+	var hooks []func(err Error, count int)
+	var counts []int
+	severity := e.Severity()
+
+	x.mu.Lock()
+	for i := range x.rules {
+		state := &x.rules[i]
+		key := state.rule.KeyGetter(e)
+		if key == "" {
+			continue
+		}
+		count := state.set.addKey(e, key)
+		if count == 1 {
+			delete(state.fired, key)
+		}
+		if count < state.rule.Threshold {
+			continue
+		}
+		if state.rule.Severity.rank() > severity.rank() {
+			severity = state.rule.Severity
+		}
+		if state.rule.OnEscalate != nil && !state.fired[key] {
+			if len(state.fired) >= state.rule.MaxKeys {
+				state.pruneFired()
+			}
+			state.fired[key] = true
+			hooks = append(hooks, state.rule.OnEscalate)
+			counts = append(counts, count)
+		}
+	}
+	x.mu.Unlock()
+
+	escalated := e
+	if severity != e.Severity() {
+		escalated = withSeverity(e, severity)
+	}
+	for i, hook := range hooks {
+		hook(escalated, counts[i])
+	}
+	return escalated
+}
+
+// pruneFired forgets the fired keys that are no longer tracked.
+func (s *escalationState) pruneFired() {
+	for key := range s.fired {
+		if _, ok := s.set.seen[key]; !ok {
+			delete(s.fired, key)
+		}
+	}
+}
+
+// Counts returns the number of occurrences per key in the current windows of the rule with the index.
+func (x *Escalator) Counts(rule int) map[string]int {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	if rule < 0 || rule >= len(x.rules) {
+		return nil
+	}
+	set := x.rules[rule].set
+	set.expire()
+	return set.Counts()
+}
+
+// withSeverity returns a copy of the error with the severity.
+func withSeverity(err Error, severity ErrorSeverity) Error {
+	e, ok := err.(*baseError)
+	if !ok {
+		return newWrapError(err, err.Message(), severity)
+	}
+	c := e.copyLayer()
+	c.severity = severity
+	return c
+}
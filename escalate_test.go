@@ -0,0 +1,113 @@
+package erro
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestEscalator(t *testing.T) {
+	current := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	SetClock(func() time.Time { return current })
+	defer SetClock(nil)
+
+	var fired []int
+	esc := NewEscalator(EscalationRule{
+		Threshold:  3,
+		Window:     5 * time.Minute,
+		Severity:   SeverityHigh,
+		KeyGetter:  MessageKeyGetter,
+		OnEscalate: func(err Error, count int) { fired = append(fired, count) },
+	})
+
+	var last Error
+	for i := 0; i < 4; i++ {
+		original := New("db timeout", "attempt", i, SeverityLow)
+		last = esc.Observe(original)
+		if original.Severity() != SeverityLow {
+			t.Fatal("original error must not be modified")
+		}
+		if i < 2 && last != original {
+			t.Errorf("expected the error as is below the threshold, got %v", last.Severity())
+		}
+	}
+	if last.Severity() != SeverityHigh {
+		t.Errorf("expected escalated severity, got %q", last.Severity())
+	}
+	if last.Error() != "db timeout attempt=3" || last.ID() == "" {
+		t.Errorf("escalated copy must keep the error, got %q", last.Error())
+	}
+	if len(fired) != 1 || fired[0] != 3 {
+		t.Errorf("expected the hook once at the threshold, got %v", fired)
+	}
+	if counts := esc.Counts(0); counts["db timeout"] != 4 {
+		t.Errorf("unexpected counts %v", counts)
+	}
+
+	// A new window starts counting again and fires again
+	current = current.Add(5 * time.Minute)
+	if got := esc.Observe(New("db timeout", SeverityLow)); got.Severity() != SeverityLow {
+		t.Errorf("expected a new window, got %q", got.Severity())
+	}
+	esc.Observe(New("db timeout"))
+	esc.Observe(New("db timeout"))
+	if len(fired) != 2 {
+		t.Errorf("expected the hook to fire in the new window, got %v", fired)
+	}
+}
+
+func TestEscalatorRules(t *testing.T) {
+	esc := NewEscalator(
+		EscalationRule{Threshold: 2, Severity: SeverityMedium, KeyGetter: MessageKeyGetter},
+		EscalationRule{Threshold: 3, Severity: SeverityCritical, KeyGetter: MessageKeyGetter},
+	)
+	severities := make([]ErrorSeverity, 0, 3)
+	for i := 0; i < 3; i++ {
+		severities = append(severities, esc.Observe(New("fail")).Severity())
+	}
+	want := []ErrorSeverity{SeverityUnknown, SeverityMedium, SeverityCritical}
+	for i := range want {
+		if severities[i] != want[i] {
+			t.Errorf("occurrence %d: expected %q, got %q", i+1, want[i], severities[i])
+		}
+	}
+
+	// Errors with a higher severity are not downgraded
+	if got := esc.Observe(New("fail", SeverityCritical)); got.Severity() != SeverityCritical {
+		t.Errorf("unexpected severity %q", got.Severity())
+	}
+	if esc.Observe(nil) != nil {
+		t.Error("expected nil for nil")
+	}
+	if esc.Counts(5) != nil {
+		t.Error("expected nil counts for an unknown rule")
+	}
+}
+
+func TestEscalatorConcurrent(t *testing.T) {
+	var mu sync.Mutex
+	calls := 0
+	esc := NewEscalator(EscalationRule{
+		Threshold: 10,
+		Severity:  SeverityHigh,
+		OnEscalate: func(err Error, count int) {
+			mu.Lock()
+			calls++
+			mu.Unlock()
+		},
+	})
+
+	base := New("same place")
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			esc.Observe(Wrap(base, "layer"))
+		}()
+	}
+	wg.Wait()
+	if calls != 1 {
+		t.Errorf("expected a single escalation, got %d", calls)
+	}
+}
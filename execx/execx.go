@@ -0,0 +1,262 @@
+// Package execx provides helpers for wrapping errors of os/exec commands with uniform
+// context: the command name, exit code or terminating signal, a redacted snippet of
+// standard error and classification of the failure.
+package execx
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"unicode/utf8"
+
+	"github.com/maxbolgarin/erro"
+)
+
+// Field keys attached by [WrapCmd] and [WrapCmdContext].
+const (
+	FieldCommand   = "cmd"
+	FieldArgsCount = "cmd_args_count"
+	FieldExitCode  = "cmd_exit_code"
+	FieldSignal    = "cmd_signal"
+	FieldStderr    = "cmd_stderr"
+)
+
+// CommandFailedMessage is the message used for wrapped command errors.
+const CommandFailedMessage = "command failed"
+
+// DefaultMaxStderrLength is the default maximum length of the stderr snippet, see [SetMaxStderrLength].
+const DefaultMaxStderrLength = 512
+
+var maxStderrLength int64 = DefaultMaxStderrLength
+
+// SetMaxStderrLength sets the maximum length of the stderr snippet; the end of the output
+// is kept. A non-positive length disables the limit. It is safe for concurrent use.
+func SetMaxStderrLength(n int) {
+	atomic.StoreInt64(&maxStderrLength, int64(n))
+}
+
+// WrapCmd wraps an error returned by running cmd with the command name, the number
+// of arguments, the exit code or the signal that terminated the process, and the end
+// of its standard error. Argument values are never attached, and values of secret-like
+// keys in stderr (see [erro.DefaultScrubKeys]) are redacted.
+//
+// Stderr is taken from [exec.ExitError] when the command was run with Output, or from
+// cmd.Stderr if it has a Bytes() or String() method, e.g. [bytes.Buffer].
+//
+// Non-zero exits and signals get [erro.ClassExternal], context deadlines get
+// [erro.ClassTimeout] and are retryable, a missing executable gets [erro.ClassNotFound].
+// All errors get [erro.CategoryOS]. Use [WrapCmdContext] for commands created with
+// [exec.CommandContext] to classify processes killed by the context.
+//
+// If err is nil, WrapCmd returns nil.
+//
+// Example:
+//
+//	out, err := cmd.Output()
+//	if err != nil {
+//	    return execx.WrapCmd(err, cmd, "repo", repo)
+//	}
+func WrapCmd(err error, cmd *exec.Cmd, fields ...any) erro.Error {
+	if err == nil {
+		return nil
+	}
+	return wrapCmd(err, cmd, Classify(err), fields)
+}
+
+// WrapCmdContext is like [WrapCmd] but classifies a process killed because ctx
+// is done: [erro.ClassTimeout] for an exceeded deadline, [erro.ClassCancelled] for
+// a cancelled context.
+//
+// Example:
+//
+//	cmd := exec.CommandContext(ctx, "git", "fetch")
+//	if err := cmd.Run(); err != nil {
+//	    return execx.WrapCmdContext(ctx, err, cmd)
+//	}
+func WrapCmdContext(ctx context.Context, err error, cmd *exec.Cmd, fields ...any) erro.Error {
+	if err == nil {
+		return nil
+	}
+	class := Classify(err)
+	if ctx != nil {
+		switch ctx.Err() {
+		case context.DeadlineExceeded:
+			class = erro.ClassTimeout
+		case context.Canceled:
+			class = erro.ClassCancelled
+		}
+	}
+	return wrapCmd(err, cmd, class, fields)
+}
+
+func wrapCmd(err error, cmd *exec.Cmd, class erro.ErrorClass, fields []any) erro.Error {
+	meta := make([]any, 0, len(fields)+14)
+	if cmd != nil {
+		meta = append(meta, FieldCommand, commandName(cmd))
+		if len(cmd.Args) > 1 {
+			meta = append(meta, FieldArgsCount, len(cmd.Args)-1)
+		}
+	}
+
+	var stderr []byte
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		if code := exitErr.ExitCode(); code >= 0 {
+			meta = append(meta, FieldExitCode, code)
+		} else if state := exitErr.String(); strings.HasPrefix(state, "signal: ") {
+			meta = append(meta, FieldSignal, strings.TrimPrefix(state, "signal: "))
+		}
+		stderr = exitErr.Stderr
+	}
+	if len(stderr) == 0 && cmd != nil {
+		stderr = capturedStderr(cmd)
+	}
+	if snippet := StderrSnippet(stderr); snippet != "" {
+		meta = append(meta, FieldStderr, snippet)
+	}
+	meta = append(meta, fields...)
+
+	meta = append(meta, erro.CategoryOS)
+	if class != erro.ClassUnknown {
+		meta = append(meta, class)
+	}
+	if class == erro.ClassTimeout {
+		meta = append(meta, erro.Retryable())
+	}
+	return erro.Wrap(err, CommandFailedMessage, meta...)
+}
+
+// Classify returns the error class of a command error.
+//
+// It recognizes context errors, a missing executable, permission errors and
+// unsuccessful exits. Unknown errors get [erro.ClassUnknown].
+func Classify(err error) erro.ErrorClass {
+	var exitErr *exec.ExitError
+	switch {
+	case err == nil:
+		return erro.ClassUnknown
+	case errors.Is(err, context.DeadlineExceeded):
+		return erro.ClassTimeout
+	case errors.Is(err, context.Canceled):
+		return erro.ClassCancelled
+	case errors.Is(err, exec.ErrNotFound), errors.Is(err, fs.ErrNotExist):
+		return erro.ClassNotFound
+	case errors.Is(err, fs.ErrPermission):
+		return erro.ClassPermissionDenied
+	case errors.As(err, &exitErr):
+		return erro.ClassExternal
+	}
+	return erro.ClassUnknown
+}
+
+// StderrSnippet returns the end of the output limited by [SetMaxStderrLength], with
+// surrounding whitespace trimmed and values of secret-like keys redacted.
+// A truncated snippet starts with "...".
+func StderrSnippet(stderr []byte) string {
+	s := strings.TrimSpace(string(stderr))
+	if s == "" {
+		return ""
+	}
+	s = redactSecrets(s)
+	if limit := int(atomic.LoadInt64(&maxStderrLength)); limit > 0 && len(s) > limit {
+		cut := len(s) - limit
+		for cut < len(s) && !utf8.RuneStart(s[cut]) {
+			cut++
+		}
+		s = "..." + s[cut:]
+	}
+	return s
+}
+
+func commandName(cmd *exec.Cmd) string {
+	if cmd.Path != "" {
+		return filepath.Base(cmd.Path)
+	}
+	if len(cmd.Args) > 0 {
+		return filepath.Base(cmd.Args[0])
+	}
+	return ""
+}
+
+func capturedStderr(cmd *exec.Cmd) []byte {
+	switch w := cmd.Stderr.(type) {
+	case interface{ Bytes() []byte }:
+		return w.Bytes()
+	case interface{ String() string }:
+		return []byte(w.String())
+	}
+	return nil
+}
+
+// redactSecrets replaces values after secret-like keys followed by '=' or ':',
+// e.g. "token=abc" becomes "token=[REDACTED]".
+func redactSecrets(s string) string {
+	var b strings.Builder
+	last := 0
+	for i := 0; i < len(s); i++ {
+		key := secretKeyAt(s, i)
+		if key == 0 {
+			continue
+		}
+		j := i + key
+		for j < len(s) && s[j] == ' ' {
+			j++
+		}
+		if j >= len(s) || (s[j] != '=' && s[j] != ':') {
+			continue
+		}
+		j++
+		for j < len(s) && s[j] == ' ' {
+			j++
+		}
+		end := valueEnd(s, j)
+		if scheme := strings.ToLower(s[j:end]); (scheme == "bearer" || scheme == "basic") && end < len(s) && s[end] == ' ' {
+			end = valueEnd(s, end+1)
+		}
+		if end == j {
+			continue
+		}
+		b.WriteString(s[last:j])
+		b.WriteString(erro.GetRedactedPlaceholder())
+		last = end
+		i = end - 1
+	}
+	if last == 0 {
+		return s
+	}
+	b.WriteString(s[last:])
+	return b.String()
+}
+
+// secretKeyAt returns the length of a secret key starting at i, or zero.
+func secretKeyAt(s string, i int) int {
+	if i > 0 && isKeyChar(s[i-1]) {
+		return 0
+	}
+	for _, key := range erro.DefaultScrubKeys {
+		if len(s)-i >= len(key) && strings.EqualFold(s[i:i+len(key)], key) {
+			return len(key)
+		}
+	}
+	return 0
+}
+
+func isKeyChar(c byte) bool {
+	return c == '_' || c == '-' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9')
+}
+
+// valueEnd returns the end of the value starting at i.
+func valueEnd(s string, i int) int {
+	for i < len(s) && !isValueEnd(s[i]) {
+		i++
+	}
+	return i
+}
+
+func isValueEnd(c byte) bool {
+	return c == ' ' || c == '\n' || c == '\r' || c == '\t' || c == '"' || c == '\'' || c == ',' || c == '&'
+}
@@ -0,0 +1,134 @@
+package execx
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/maxbolgarin/erro"
+)
+
+func shell(t *testing.T, ctx context.Context, script string) *exec.Cmd {
+	t.Helper()
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh is not available")
+	}
+	return exec.CommandContext(ctx, "sh", "-c", script)
+}
+
+func fieldValue(err erro.Error, key string) (any, bool) {
+	fields := err.AllFields()
+	for i := 0; i+1 < len(fields); i += 2 {
+		if fields[i] == key {
+			return fields[i+1], true
+		}
+	}
+	return nil, false
+}
+
+func TestWrapCmdExitCode(t *testing.T) {
+	cmd := shell(t, context.Background(), "echo 'fatal: auth failed token=abc123' >&2; exit 3")
+	_, runErr := cmd.Output()
+
+	err := WrapCmd(runErr, cmd, "repo", "erro")
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	if err.Class() != erro.ClassExternal || err.Category() != erro.CategoryOS {
+		t.Errorf("unexpected class %q and category %q", err.Class(), err.Category())
+	}
+	if v, _ := fieldValue(err, FieldExitCode); v != 3 {
+		t.Errorf("expected exit code 3, got %v", v)
+	}
+	if v, _ := fieldValue(err, FieldCommand); v != "sh" {
+		t.Errorf("expected command sh, got %v", v)
+	}
+	if v, _ := fieldValue(err, FieldArgsCount); v != 2 {
+		t.Errorf("expected 2 args, got %v", v)
+	}
+	stderr, _ := fieldValue(err, FieldStderr)
+	if stderr != "fatal: auth failed token="+erro.RedactedPlaceholder {
+		t.Errorf("unexpected stderr %q", stderr)
+	}
+	if strings.Contains(err.Error(), "abc123") || !strings.Contains(err.Error(), "exit status 3") {
+		t.Errorf("unexpected message %q", err.Error())
+	}
+	if !errors.Is(err, runErr) {
+		t.Error("expected the original error in the chain")
+	}
+}
+
+func TestWrapCmdStderrBuffer(t *testing.T) {
+	cmd := shell(t, context.Background(), "echo oops >&2; exit 1")
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	err := WrapCmd(cmd.Run(), cmd)
+	if v, _ := fieldValue(err, FieldStderr); v != "oops" {
+		t.Errorf("expected stderr from the buffer, got %v", v)
+	}
+}
+
+func TestWrapCmdContextDeadline(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	cmd := shell(t, ctx, "sleep 5")
+
+	err := WrapCmdContext(ctx, cmd.Run(), cmd)
+	if err.Class() != erro.ClassTimeout || !err.IsRetryable() {
+		t.Errorf("expected retryable timeout, got %q", err.Class())
+	}
+	if v, ok := fieldValue(err, FieldSignal); !ok || v != "killed" {
+		t.Errorf("expected killed signal, got %v", v)
+	}
+	if _, ok := fieldValue(err, FieldExitCode); ok {
+		t.Error("unexpected exit code for a killed process")
+	}
+}
+
+func TestWrapCmdNotFound(t *testing.T) {
+	cmd := exec.Command("erro-command-that-does-not-exist")
+	err := WrapCmd(cmd.Run(), cmd)
+	if err.Class() != erro.ClassNotFound {
+		t.Errorf("expected not found, got %q", err.Class())
+	}
+	if WrapCmd(nil, cmd) != nil || WrapCmdContext(context.Background(), nil, cmd) != nil {
+		t.Error("expected nil for nil error")
+	}
+}
+
+func TestStderrSnippet(t *testing.T) {
+	tests := []struct {
+		stderr   string
+		expected string
+	}{
+		{"", ""},
+		{"  plain error\n", "plain error"},
+		{"password: hunter2 and api_key=xyz", "password: [REDACTED] and api_key=[REDACTED]"},
+		{"Authorization: Bearer abc.def", "Authorization: [REDACTED]"},
+		{"password_hash=kept tokens are fine", "password_hash=kept tokens are fine"},
+		{"my_token=kept", "my_token=kept"},
+	}
+	for _, tt := range tests {
+		if got := StderrSnippet([]byte(tt.stderr)); got != tt.expected {
+			t.Errorf("StderrSnippet(%q) = %q, want %q", tt.stderr, got, tt.expected)
+		}
+	}
+
+	erro.Configure(erro.WithRedactedPlaceholder("<hidden>"))
+	got := StderrSnippet([]byte("password=hunter2"))
+	erro.Configure(erro.WithRedactedPlaceholder(""))
+	if got != "password=<hidden>" {
+		t.Errorf("expected the configured placeholder, got %q", got)
+	}
+
+	SetMaxStderrLength(5)
+	defer SetMaxStderrLength(DefaultMaxStderrLength)
+	if got := StderrSnippet([]byte("first line\nпоследняя")); got != "...яя" {
+		t.Errorf("unexpected truncated snippet %q", got)
+	}
+}
@@ -0,0 +1,172 @@
+package erro
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// FaultMatcher selects errors whose cause is substituted by [InjectFault].
+// It gets the error as created at the call site.
+type FaultMatcher func(err Error) bool
+
+type fault struct {
+	matcher     FaultMatcher
+	replacement error
+}
+
+var (
+	faultsEnabled atomicValue[bool]
+	faultCount    int32 // Number of injected faults, checked before taking the lock
+
+	faultsMu sync.RWMutex
+	faults   []*fault
+)
+
+// EnableFaultInjection allows the test hooks [InjectFault] and [SeedIDs] in a build
+// without the errotest build tag. Call it from TestMain of integration tests.
+func EnableFaultInjection() {
+	faultsEnabled.Store(true)
+}
+
+// testHooksEnabled returns true if the test hooks are allowed, see [EnableFaultInjection].
+func testHooksEnabled() bool {
+	return faultsByBuildTag || faultsEnabled.Load()
+}
+
+// InjectFault makes errors created by [New], [Wrap], templates and other constructors
+// that match the matcher wrap the replacement instead of their original cause, so
+// integration tests can force a call site to fail with a predetermined error: the
+// message, fields and options of the call site are kept, and [errors.Is] reports the
+// replacement. Options that run on creation, like [RecordMetrics], see the original error.
+//
+// Fault injection is a test hook: it panics unless the binary is built with the
+// errotest build tag or [EnableFaultInjection] was called. It returns a function
+// that removes the fault.
+//
+// Example:
+//
+//	// go test -tags errotest ./...
+//	remove := erro.InjectFault(erro.MatchTemplate(ErrPaymentDeclined), context.DeadlineExceeded)
+//	defer remove()
+func InjectFault(matcher FaultMatcher, replacement error) (remove func()) {
+	if !testHooksEnabled() {
+		panic("erro: fault injection is disabled, build with -tags errotest or call EnableFaultInjection")
+	}
+	if matcher == nil || replacement == nil {
+		return func() {}
+	}
+	f := &fault{matcher: matcher, replacement: replacement}
+
+	faultsMu.Lock()
+	faults = append(faults, f)
+	atomic.StoreInt32(&faultCount, int32(len(faults)))
+	faultsMu.Unlock()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() { removeFault(f) })
+	}
+}
+
+// ClearFaults removes all faults added with [InjectFault].
+func ClearFaults() {
+	faultsMu.Lock()
+	faults = nil
+	atomic.StoreInt32(&faultCount, 0)
+	faultsMu.Unlock()
+}
+
+func removeFault(f *fault) {
+	faultsMu.Lock()
+	defer faultsMu.Unlock()
+	for i, existing := range faults {
+		if existing == f {
+			faults = append(faults[:i:i], faults[i+1:]...)
+			break
+		}
+	}
+	atomic.StoreInt32(&faultCount, int32(len(faults)))
+}
+
+// MatchMessage matches errors created with the message, before format verbs are applied for templates.
+func MatchMessage(message string) FaultMatcher {
+	return func(err Error) bool {
+		e, ok := err.(*baseError)
+		if !ok {
+			return false
+		}
+		if e.template != nil && e.template.messageTemplate == message {
+			return true
+		}
+		return e.message == message
+	}
+}
+
+// MatchTemplate matches errors created from the template.
+func MatchTemplate(t *ErrorTemplate) FaultMatcher {
+	return func(err Error) bool {
+		e, ok := err.(*baseError)
+		return ok && t != nil && e.template == t
+	}
+}
+
+// MatchID matches errors created with the [ID] option, e.g. an error code.
+func MatchID(id string) FaultMatcher {
+	return func(err Error) bool {
+		e, ok := err.(*baseError)
+		return ok && e.id == id
+	}
+}
+
+// MatchClass matches errors with the class, including the class of their cause.
+func MatchClass(class ErrorClass) FaultMatcher {
+	return func(err Error) bool {
+		return err.Class() == class
+	}
+}
+
+// injectFault substitutes the cause of the created error if a fault matches it.
+// It must be called after applyMeta returns to keep the caller frame depth of [StackTrace].
+func injectFault(e *baseError) *baseError {
+	if atomic.LoadInt32(&faultCount) == 0 {
+		return e
+	}
+	faultsMu.RLock()
+	var replacement error
+	for _, f := range faults {
+		if f.matcher(e) {
+			replacement = f.replacement
+			break
+		}
+	}
+	faultsMu.RUnlock()
+	if replacement != nil {
+		substituteCause(e, replacement)
+	}
+	return e
+}
+
+// substituteCause replaces the wrapped errors of the layer with the cause.
+func substituteCause(e *baseError, cause error) {
+	e.wrappedErr, e.originalErr = nil, nil
+	e.truncated = false
+	e.depth = 0
+
+	if !As(cause, &e.wrappedErr) {
+		e.originalErr = cause
+
+		var sentinel baseError
+		inheritSentinel(&sentinel, cause)
+		if e.class == "" {
+			e.class = sentinel.class
+		}
+		if e.category == "" {
+			e.category = sentinel.category
+		}
+		if e.severity == "" {
+			e.severity = sentinel.severity
+		}
+		return
+	}
+	e.depth = e.wrappedErr.depth + 1
+}
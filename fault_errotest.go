@@ -0,0 +1,6 @@
+//go:build errotest
+
+package erro
+
+// faultsByBuildTag allows [InjectFault] and [SeedIDs] without [EnableFaultInjection].
+const faultsByBuildTag = true
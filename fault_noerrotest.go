@@ -0,0 +1,5 @@
+//go:build !errotest
+
+package erro
+
+const faultsByBuildTag = false
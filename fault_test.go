@@ -0,0 +1,78 @@
+package erro
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+)
+
+func TestInjectFault(t *testing.T) {
+	if !faultsByBuildTag {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Error("expected a panic while fault injection is disabled")
+				}
+			}()
+			InjectFault(MatchMessage("read config"), io.EOF)
+		}()
+	}
+	EnableFaultInjection()
+	defer ClearFaults()
+
+	t.Run("message", func(t *testing.T) {
+		remove := InjectFault(MatchMessage("read config"), io.ErrUnexpectedEOF)
+		err := Wrap(errors.New("permission denied"), "read config", "path", "/etc/app")
+		if !errors.Is(err, io.ErrUnexpectedEOF) {
+			t.Fatalf("expected the injected cause, got %v", err)
+		}
+		if err.Error() != "read config path=/etc/app: unexpected EOF" {
+			t.Errorf("unexpected message %q", err.Error())
+		}
+		if New("other").Unwrap() != nil {
+			t.Error("errors that do not match must not be changed")
+		}
+
+		remove()
+		remove()
+		if errors.Is(New("read config"), io.ErrUnexpectedEOF) {
+			t.Error("fault must be removed")
+		}
+	})
+
+	t.Run("template", func(t *testing.T) {
+		tmpl := NewTemplate("charge %s failed", ClassExternal)
+		defer InjectFault(MatchTemplate(tmpl), context.DeadlineExceeded)()
+
+		err := tmpl.New("order-1")
+		if !errors.Is(err, context.DeadlineExceeded) || err.Class() != ClassExternal {
+			t.Errorf("expected the injected cause and the template class, got %v %s", err, err.Class())
+		}
+		if errors.Is(New("charge order-1 failed"), context.DeadlineExceeded) {
+			t.Error("only errors of the template must match")
+		}
+		if !errors.Is(tmpl.Wrap(io.EOF, "order-2"), context.DeadlineExceeded) {
+			t.Error("wrapped errors of the template must match")
+		}
+	})
+
+	t.Run("id and class", func(t *testing.T) {
+		cause := New("db down", ClassUnavailable, CategoryDatabase)
+		defer InjectFault(MatchID("PAY-42"), cause)()
+		defer InjectFault(MatchClass(ClassConflict), Sentinel("stale", ClassConflict, SeverityLow))()
+
+		err := New("pay", ID("PAY-42"))
+		if !errors.Is(err, cause) || err.Class() != ClassUnavailable || err.Category() != CategoryDatabase {
+			t.Errorf("expected the cause with its metadata, got %v %s %s", err, err.Class(), err.Category())
+		}
+		if err.Error() != "pay: db down" {
+			t.Errorf("unexpected message %q", err.Error())
+		}
+
+		err = New("update", ClassConflict)
+		if err.Error() != "update: stale" || err.Severity() != SeverityLow {
+			t.Errorf("expected the sentinel cause, got %q %s", err.Error(), err.Severity())
+		}
+	})
+}
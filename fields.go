@@ -0,0 +1,222 @@
+package erro
+
+import (
+	"reflect"
+	"strconv"
+	"time"
+)
+
+var (
+	durationType = reflect.TypeOf(time.Duration(0))
+	timeType     = reflect.TypeOf(time.Time{})
+)
+
+// Field returns the value of the field with the key from the error chain as T; the
+// outermost field with the key wins. Values are converted from the representations
+// they get after serialization: numbers between numeric types when they fit, numbers
+// and booleans from strings, durations from strings and nanoseconds, and times from
+// RFC 3339 strings. Any value converts to a string. It returns false if the field is
+// missing, redacted or cannot be converted.
+//
+// Example:
+//
+//	attempt, ok := erro.Field[int](err, "attempt")
+func Field[T any](err error, key string) (T, bool) {
+	var zero T
+	e := ExtractError(err)
+	if e == nil {
+		return zero, false
+	}
+	fields := e.AllFields()
+	for i := 0; i+1 < len(fields); i += 2 {
+		if valueToString(fields[i]) != key {
+			continue
+		}
+		if value, ok := fields[i+1].(T); ok {
+			return value, true
+		}
+		converted, ok := convertField(fields[i+1], reflect.TypeOf(&zero).Elem())
+		if !ok {
+			return zero, false
+		}
+		return converted.Interface().(T), true
+	}
+	return zero, false
+}
+
+// FieldOr returns the value of the field with the key as T, or the fallback, see [Field].
+//
+// Example:
+//
+//	tenant := erro.FieldOr(err, "tenant", "unknown")
+func FieldOr[T any](err error, key string, fallback T) T {
+	if value, ok := Field[T](err, key); ok {
+		return value
+	}
+	return fallback
+}
+
+// convertField converts a field value to the type t.
+func convertField(value any, t reflect.Type) (reflect.Value, bool) {
+	if value == nil {
+		return reflect.Value{}, false
+	}
+	value = resolveSensitive(value, 0)
+	if _, ok := value.(RedactedValue); ok {
+		return reflect.Value{}, false
+	}
+	v := reflect.ValueOf(value)
+	var str string
+	isString := v.Kind() == reflect.String
+	if isString {
+		str = v.String()
+	}
+
+	switch {
+	case t == durationType && isString:
+		d, err := time.ParseDuration(str)
+		if err != nil {
+			return reflect.Value{}, false
+		}
+		return reflect.ValueOf(d), true
+	case t == timeType:
+		if !isString {
+			return reflect.Value{}, false
+		}
+		parsed, err := time.Parse(time.RFC3339Nano, str)
+		if err != nil {
+			return reflect.Value{}, false
+		}
+		return reflect.ValueOf(parsed), true
+	}
+
+	out := reflect.New(t).Elem()
+	switch t.Kind() {
+	case reflect.String:
+		out.SetString(valueToString(value))
+		return out, true
+
+	case reflect.Bool:
+		switch {
+		case v.Kind() == reflect.Bool:
+			out.SetBool(v.Bool())
+		case isString:
+			b, err := strconv.ParseBool(str)
+			if err != nil {
+				return reflect.Value{}, false
+			}
+			out.SetBool(b)
+		default:
+			return reflect.Value{}, false
+		}
+		return out, true
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		var n int64
+		switch {
+		case isInt(v):
+			n = v.Int()
+		case isUint(v):
+			if v.Uint() > 1<<63-1 {
+				return reflect.Value{}, false
+			}
+			n = int64(v.Uint())
+		case isFloat(v):
+			f := v.Float()
+			if f != float64(int64(f)) {
+				return reflect.Value{}, false
+			}
+			n = int64(f)
+		case isString:
+			var err error
+			if n, err = strconv.ParseInt(str, 10, 64); err != nil {
+				return reflect.Value{}, false
+			}
+		default:
+			return reflect.Value{}, false
+		}
+		if out.OverflowInt(n) {
+			return reflect.Value{}, false
+		}
+		out.SetInt(n)
+		return out, true
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		var n uint64
+		switch {
+		case isUint(v):
+			n = v.Uint()
+		case isInt(v):
+			if v.Int() < 0 {
+				return reflect.Value{}, false
+			}
+			n = uint64(v.Int())
+		case isFloat(v):
+			f := v.Float()
+			if f < 0 || f != float64(uint64(f)) {
+				return reflect.Value{}, false
+			}
+			n = uint64(f)
+		case isString:
+			var err error
+			if n, err = strconv.ParseUint(str, 10, 64); err != nil {
+				return reflect.Value{}, false
+			}
+		default:
+			return reflect.Value{}, false
+		}
+		if out.OverflowUint(n) {
+			return reflect.Value{}, false
+		}
+		out.SetUint(n)
+		return out, true
+
+	case reflect.Float32, reflect.Float64:
+		var f float64
+		switch {
+		case isFloat(v):
+			f = v.Float()
+		case isInt(v):
+			f = float64(v.Int())
+		case isUint(v):
+			f = float64(v.Uint())
+		case isString:
+			var err error
+			if f, err = strconv.ParseFloat(str, 64); err != nil {
+				return reflect.Value{}, false
+			}
+		default:
+			return reflect.Value{}, false
+		}
+		if out.OverflowFloat(f) {
+			return reflect.Value{}, false
+		}
+		out.SetFloat(f)
+		return out, true
+	}
+
+	if v.Type().ConvertibleTo(t) && v.Kind() == t.Kind() {
+		return v.Convert(t), true
+	}
+	return reflect.Value{}, false
+}
+
+func isInt(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return true
+	}
+	return false
+}
+
+func isUint(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return true
+	}
+	return false
+}
+
+func isFloat(v reflect.Value) bool {
+	return v.Kind() == reflect.Float32 || v.Kind() == reflect.Float64
+}
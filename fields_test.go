@@ -0,0 +1,69 @@
+package erro
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestField(t *testing.T) {
+	err := Wrap(New("request failed", "attempt", 3, "tenant", "acme", "took", 1500*time.Millisecond),
+		"handle", "attempt", int64(4), "ratio", 0.5, "password", Redact("secret"), "retry", "true")
+
+	if attempt, ok := Field[int](err, "attempt"); !ok || attempt != 4 {
+		t.Errorf("expected the outermost attempt converted to int, got %d %v", attempt, ok)
+	}
+	if tenant := FieldOr(err, "tenant", "unknown"); tenant != "acme" {
+		t.Errorf("unexpected tenant %q", tenant)
+	}
+	if tenant := FieldOr(New("x"), "tenant", "unknown"); tenant != "unknown" {
+		t.Errorf("expected the fallback, got %q", tenant)
+	}
+	if took, ok := Field[time.Duration](err, "took"); !ok || took != 1500*time.Millisecond {
+		t.Errorf("unexpected duration %v", took)
+	}
+	if retry, ok := Field[bool](err, "retry"); !ok || !retry {
+		t.Error("expected a bool parsed from the string")
+	}
+	if s, ok := Field[string](err, "attempt"); !ok || s != "4" {
+		t.Errorf("expected any value as a string, got %q", s)
+	}
+	if _, ok := Field[string](err, "password"); ok {
+		t.Error("redacted fields must not be returned")
+	}
+	if _, ok := Field[int](err, "ratio"); ok {
+		t.Error("fractional numbers must not convert to int")
+	}
+	if _, ok := Field[int8](New("x", "n", 300), "n"); ok {
+		t.Error("overflowing numbers must not convert")
+	}
+	if _, ok := Field[uint](New("x", "n", -1), "n"); ok {
+		t.Error("negative numbers must not convert to uint")
+	}
+	if class, ok := Field[ErrorClass](New("x", "class", "timeout"), "class"); !ok || class != ClassTimeout {
+		t.Errorf("expected a named string type, got %q", class)
+	}
+	if _, ok := Field[int](nil, "attempt"); ok {
+		t.Error("expected false for nil")
+	}
+
+	// Values after a JSON round trip
+	created := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	data, _ := json.Marshal(New("x", "attempt", 7, "took", time.Second, "at", created, "big", uint64(1)<<40))
+	restored, jsonErr := FromJSON(data)
+	if jsonErr != nil {
+		t.Fatal(jsonErr)
+	}
+	if attempt := FieldOr(restored, "attempt", 0); attempt != 7 {
+		t.Errorf("expected int from float64, got %d", attempt)
+	}
+	if took := FieldOr[time.Duration](restored, "took", 0); took != time.Second {
+		t.Errorf("expected duration from nanoseconds, got %v", took)
+	}
+	if at := FieldOr(restored, "at", time.Time{}); !at.Equal(created) {
+		t.Errorf("expected time from RFC 3339, got %v", at)
+	}
+	if big := FieldOr[uint64](restored, "big", 0); big != 1<<40 {
+		t.Errorf("unexpected uint64 %d", big)
+	}
+}
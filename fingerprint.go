@@ -0,0 +1,187 @@
+package erro
+
+import (
+	"errors"
+	"hash/fnv"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// fingerprintSeparator separates the parts of a fingerprint or a fields key, it never appears in messages.
+const fingerprintSeparator = "\x1f"
+
+// Fingerprint returns a stable hash identifying the kind of the error rather than its instance.
+//
+// It is built from the class, category, own messages of the wrapped errors without
+// field values and format arguments (the unformatted message or the message of the
+// template the error was created from), the type and text of a standard root error, and the top user frame
+// of the stack trace if it was captured. The ID, creation time and field values do
+// not affect it, so errors created at the same place with different field values
+// share the fingerprint. It returns an empty string for nil.
+//
+// Example:
+//
+//	set := erro.NewSet().WithKeyGetter(erro.FingerprintKeyGetter)
+func Fingerprint(err error) string {
+	if err == nil {
+		return ""
+	}
+	h := fnv.New64a()
+
+	e := ExtractError(err)
+	h.Write([]byte(e.Class()))
+	h.Write([]byte(fingerprintSeparator))
+	h.Write([]byte(e.Category()))
+
+	base, ok := e.(*baseError)
+	if !ok {
+		h.Write([]byte(fingerprintSeparator))
+		h.Write([]byte(e.Message()))
+	}
+	for ; base != nil; base = base.wrappedErr {
+		h.Write([]byte(fingerprintSeparator))
+		h.Write([]byte(base.messageKind()))
+		if base.originalErr != nil {
+			h.Write([]byte(fingerprintSeparator))
+			h.Write([]byte(reflect.TypeOf(base.originalErr).String()))
+			h.Write([]byte(fingerprintSeparator))
+			h.Write([]byte(base.originalErr.Error()))
+		}
+	}
+
+	if frame := e.Stack().TopUserFrame(); frame != nil {
+		h.Write([]byte(fingerprintSeparator))
+		h.Write([]byte(frame.FullName))
+		h.Write([]byte(fingerprintSeparator))
+		h.Write([]byte(frame.File))
+	}
+
+	return strconv.FormatUint(h.Sum64(), 16)
+}
+
+// messageKind returns the own message of the layer before format verbs were applied.
+func (e *baseError) messageKind() string {
+	if e.template != nil {
+		return e.template.messageTemplate
+	}
+	if format := e.ext().format; format != "" {
+		return format
+	}
+	return e.message
+}
+
+// SameCause reports whether two errors likely represent the same underlying failure,
+// e.g. for a retry or queue system to decide that a repeated failure should back off
+// harder. Errors are the same if they share the [Fingerprint] or their root causes
+// match: the same value, the same type and text of a standard error, or the same
+// fingerprint of an erro root error. With keys, the values of these fields in the chains
+// must be equal too, see [FieldsKeyGetter]. It returns false if either error is nil.
+//
+// Example:
+//
+//	if erro.SameCause(err, lastErr, "endpoint") {
+//	    backoff *= 2
+//	}
+func SameCause(a, b error, keys ...string) bool {
+	if a == nil || b == nil {
+		return false
+	}
+	if len(keys) > 0 {
+		getKey := FieldsKeyGetter(keys...)
+		if getKey(a) != getKey(b) {
+			return false
+		}
+	}
+	if Fingerprint(a) == Fingerprint(b) {
+		return true
+	}
+	return sameRoot(rootCause(a), rootCause(b))
+}
+
+// rootCause returns the innermost error of the chain: the root erro layer, or the
+// deepest standard error that does not wrap a single error.
+func rootCause(err error) error {
+	for {
+		if e, ok := err.(*baseError); ok {
+			for e.wrappedErr != nil {
+				e = e.wrappedErr
+			}
+			if e.originalErr == nil {
+				return e
+			}
+			err = e.originalErr
+			continue
+		}
+		next := errors.Unwrap(err)
+		if next == nil {
+			return err
+		}
+		err = next
+	}
+}
+
+// sameRoot compares root causes by value, type and text, or by fingerprint for erro errors.
+func sameRoot(a, b error) bool {
+	if ea, ok := a.(*baseError); ok {
+		eb, ok := b.(*baseError)
+		return ok && Fingerprint(ea) == Fingerprint(eb)
+	}
+	typ := reflect.TypeOf(a)
+	if typ != reflect.TypeOf(b) {
+		return false
+	}
+	if typ.Comparable() && a == b {
+		return true
+	}
+	return a.Error() == b.Error()
+}
+
+// FieldsKeyGetter returns a [KeyGetterFunc] that builds deduplication keys from the
+// values of the given fields, e.g. "endpoint", so errors with parameterized messages
+// are aggregated by what matters. The keys "class", "category" and "severity" fall back
+// to the error metadata if there is no such field. A missing field has an empty value;
+// the outermost value is used if a key repeats in the chain.
+//
+// Example:
+//
+//	set := erro.NewSet().WithKeyGetter(erro.FieldsKeyGetter("endpoint", "class"))
+func FieldsKeyGetter(keys ...string) KeyGetterFunc {
+	return func(err error) string {
+		if err == nil {
+			return ""
+		}
+		e := ExtractError(err)
+		fields := e.AllFields()
+
+		var b strings.Builder
+		for i, key := range keys {
+			if i > 0 {
+				b.WriteString(fingerprintSeparator)
+			}
+			b.WriteString(keyFieldValue(e, fields, key))
+		}
+		return b.String()
+	}
+}
+
+func keyFieldValue(e Error, fields []any, key string) string {
+	for i := 0; i+1 < len(fields); i += 2 {
+		if valueToString(fields[i]) != key {
+			continue
+		}
+		if _, ok := fields[i+1].(RedactedValue); ok {
+			return redacted()
+		}
+		return valueToString(fields[i+1])
+	}
+	switch key {
+	case "class":
+		return e.Class().String()
+	case "category":
+		return e.Category().String()
+	case "severity":
+		return e.Severity().String()
+	}
+	return ""
+}
@@ -0,0 +1,131 @@
+package erro
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"testing"
+)
+
+func TestFingerprint(t *testing.T) {
+	if Fingerprint(nil) != "" {
+		t.Error("expected empty fingerprint for nil")
+	}
+
+	newErr := func(userID int) Error {
+		return Wrap(New("user not found", ClassNotFound, "user_id", userID), "get profile", "request", userID*10)
+	}
+	a, b := newErr(1), newErr(2)
+	if Fingerprint(a) != Fingerprint(b) {
+		t.Error("expected errors differing only by field values to share a fingerprint")
+	}
+	if Fingerprint(a) == Fingerprint(New("user not found", ClassNotFound)) {
+		t.Error("expected different chains to have different fingerprints")
+	}
+	if Fingerprint(New("user not found", ClassNotFound)) == Fingerprint(New("user not found", ClassInternal)) {
+		t.Error("expected class to affect the fingerprint")
+	}
+	if Fingerprint(errors.New("a")) == Fingerprint(errors.New("b")) {
+		t.Error("expected standard errors with different text to have different fingerprints")
+	}
+	if Fingerprint(Wrap(errors.New("a"), "x")) != Fingerprint(Wrap(errors.New("a"), "x", "k", "v")) {
+		t.Error("expected wrapped standard errors to share a fingerprint")
+	}
+
+	if Fingerprint(New("user %d not found", 1, "k", "v")) != Fingerprint(New("user %d not found", 2)) ||
+		Fingerprint(Wrap(a, "retry %d of %d", 1, 3)) != Fingerprint(Wrap(b, "retry %d of %d", 2, 3)) {
+		t.Error("expected errors differing only by format arguments to share a fingerprint")
+	}
+	tmpl := NewTemplate("order %s is %s")
+	if Fingerprint(tmpl.New("A-1", "paid")) != Fingerprint(tmpl.New("B-2", "shipped")) {
+		t.Error("expected errors of a template to share a fingerprint")
+	}
+	if Fingerprint(New("user %d not found", 1)) == Fingerprint(New("user 1 not found")) {
+		t.Error("expected the unformatted message to be used")
+	}
+
+	set := NewSet().WithKeyGetter(FingerprintKeyGetter)
+	set.Add(a)
+	set.Add(b)
+	if set.Len() != 1 {
+		t.Errorf("expected errors to be deduplicated by fingerprint, got %d", set.Len())
+	}
+}
+
+func TestFieldsKeyGetter(t *testing.T) {
+	getter := FieldsKeyGetter("endpoint", "class")
+	if getter(nil) != "" {
+		t.Error("expected empty key for nil")
+	}
+
+	a := New("request to {endpoint} failed for {user}", ClassTimeout, "endpoint", "/orders", "user", 1)
+	b := New("request to {endpoint} failed for {user}", ClassTimeout, "endpoint", "/orders", "user", 2)
+	c := New("request to {endpoint} failed for {user}", ClassTimeout, "endpoint", "/users", "user", 1)
+	d := New("request to {endpoint} failed for {user}", ClassUnavailable, "endpoint", "/orders", "user", 1)
+	if getter(a) != getter(b) {
+		t.Error("expected errors with the same selected fields to share a key")
+	}
+	if getter(a) == getter(c) || getter(a) == getter(d) {
+		t.Error("expected different endpoints or classes to have different keys")
+	}
+	if key := getter(Wrap(a, "outer", "endpoint", "/outer")); key != "/outer"+fingerprintSeparator+"timeout" {
+		t.Errorf("expected outermost field value, got %q", key)
+	}
+	if key := FieldsKeyGetter("missing")(a); key != "" {
+		t.Errorf("expected empty value for missing field, got %q", key)
+	}
+	if key := FieldsKeyGetter("token")(New("x", "token", Redact("secret"))); key != redacted() {
+		t.Errorf("expected redacted value, got %q", key)
+	}
+
+	set := NewSet().WithKeyGetter(getter)
+	for _, err := range []error{a, b, c, d} {
+		set.Add(err)
+	}
+	if set.Len() != 3 {
+		t.Errorf("expected 3 unique errors, got %d", set.Len())
+	}
+}
+
+type statusError struct{ code int }
+
+func (e statusError) Error() string { return "status " + strconv.Itoa(e.code) }
+
+func TestSameCause(t *testing.T) {
+	newErr := func(userID int) Error {
+		return Wrap(New("user not found", ClassNotFound, "user_id", userID), "get profile")
+	}
+	if !SameCause(newErr(1), newErr(2)) {
+		t.Error("errors of the same kind must have the same cause")
+	}
+	if SameCause(newErr(1), newErr(2), "user_id") || !SameCause(newErr(1), newErr(1), "user_id") {
+		t.Error("key fields must be compared")
+	}
+	if SameCause(newErr(1), nil) || SameCause(nil, nil) {
+		t.Error("nil errors have no cause")
+	}
+
+	// Different wrapping of the same root cause
+	first := Wrap(context.DeadlineExceeded, "call billing", "attempt", 1)
+	second := fmt.Errorf("retry: %w", Wrap(Wrap(context.DeadlineExceeded, "call billing"), "attempt 2"))
+	if !SameCause(first, second) {
+		t.Error("errors with the same sentinel root must have the same cause")
+	}
+	if SameCause(first, Wrap(context.Canceled, "call billing")) {
+		t.Error("different sentinels are different causes")
+	}
+	if !SameCause(Wrap(statusError{503}, "a"), Wrap(statusError{503}, "b")) ||
+		SameCause(Wrap(statusError{503}, "a"), Wrap(statusError{500}, "a")) {
+		t.Error("standard roots must be compared by type and text")
+	}
+	if !SameCause(errors.New("disk full"), fmt.Errorf("write: %w", errors.New("disk full"))) {
+		t.Error("standard errors with the same text must have the same cause")
+	}
+	if !SameCause(Wrap(New("db down", ClassUnavailable), "query users"), Wrap(New("db down", ClassUnavailable), "query orders")) {
+		t.Error("erro roots must be compared by fingerprint")
+	}
+	if SameCause(New("db down", ClassUnavailable), New("db down", ClassTimeout)) {
+		t.Error("roots with different classes are different causes")
+	}
+}
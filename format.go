@@ -0,0 +1,207 @@
+package erro
+
+import (
+	"bufio"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// FormatTo writes the error to w without building the whole text in memory first,
+// so huge errors (deep chains, big stacks) are streamed layer by layer. It is used by
+// Error() and by fmt verbs.
+//
+// Without options it writes the same text as Error(). With options it writes the
+// message followed by the log fields selected by the options, as the formatter of
+// [GetFormatErrorWithFullContext] does. It returns the first write error.
+//
+// Example:
+//
+//	erro.FormatTo(os.Stderr, err)
+//	erro.FormatTo(w, err, erro.WithUserFields(), erro.WithStack())
+func FormatTo(w io.Writer, err Error, optFuncs ...LogOption) error {
+	if err == nil || w == nil {
+		return nil
+	}
+	var buffered *bufio.Writer
+	var sw io.StringWriter
+	switch v := w.(type) {
+	case *strings.Builder:
+		sw = v
+	case *bufio.Writer:
+		sw = v
+	default:
+		buffered = bufio.NewWriter(w)
+		sw = buffered
+	}
+
+	c := &chainWriter{w: sw}
+	if len(optFuncs) > 0 {
+		fields := getLogFields(err, DefaultLogOptions.ApplyOptions(optFuncs...))
+		writeFieldsMessage(c, buildMessage(err), fields)
+	} else {
+		writeErrorChain(c, err, false)
+	}
+	if c.err != nil {
+		return c.err
+	}
+	if buffered != nil {
+		return buffered.Flush()
+	}
+	return nil
+}
+
+// chainWriter writes the ": " separator between layers only when both are non-empty,
+// as Error() joins the layers, and keeps the first write error.
+type chainWriter struct {
+	w       io.StringWriter
+	pending bool // Separator is written before the next non-empty text
+	wrote   bool // Something was written since the last call to startLayer
+	err     error
+}
+
+func (c *chainWriter) WriteString(s string) {
+	if s == "" || c.err != nil {
+		return
+	}
+	if c.pending {
+		c.pending = false
+		if _, c.err = c.w.WriteString(": "); c.err != nil {
+			return
+		}
+	}
+	_, c.err = c.w.WriteString(s)
+	c.wrote = true
+}
+
+// startLayer prepares for the next layer: the separator is written if the previous layer wrote something.
+func (c *chainWriter) startLayer() {
+	if c.wrote {
+		c.pending = true
+	}
+	c.wrote = false
+}
+
+// writeErrorChain writes the layers of the chain, limited by [MaxWrapDepth]. With full,
+// chains are not compressed, see [CompressChain].
+func writeErrorChain(c *chainWriter, err error, full bool) {
+	for depth := 0; ; depth++ {
+		e, ok := err.(*baseError)
+		if !ok {
+			c.WriteString(validUTF8(err.Error()))
+			return
+		}
+		// The cached text may contain a compressed part of the chain
+		if !full {
+			if cached := e.fullMessage.Load(); cached != "" {
+				c.WriteString(cached)
+				return
+			}
+		}
+		if !full && e.ext().compressLayers > 0 && writeCompressedChain(c, e) {
+			return
+		}
+
+		writeLayer(c, e)
+		if e.wholeChainFormat {
+			return
+		}
+		unwrapped := e.Unwrap()
+		if unwrapped == nil || depth >= MaxWrapDepth {
+			return
+		}
+		c.startLayer()
+		err = unwrapped
+	}
+}
+
+// writeLayer writes the own part of the layer without the wrapped errors.
+func writeLayer(c *chainWriter, e *baseError) {
+	c.WriteString(validUTF8(layerText(e)))
+}
+
+func layerText(e *baseError) string {
+	var out string
+	if formatter := e.Formatter(); formatter != nil {
+		out = formatter(e)
+	}
+	if out == "" {
+		out = FormatErrorMessage(e)
+	}
+	return out
+}
+
+// CompressChain makes Error() and the %v and %s verbs render only the outermost and
+// innermost layers of a chain longer than maxLayers and summarize the middle ones,
+// e.g. "get order: … 4 more layers …: connection refused". Empty layers are not
+// counted. The full chain is still written by %+v and kept in JSON. The option
+// applies to the chain of the error it is passed to, also when it is wrapped further.
+// A maxLayers below 2 is treated as 2.
+//
+// Example:
+//
+//	err := erro.Wrap(err, "handle request", erro.CompressChain(3))
+func CompressChain(maxLayers int) errorOpt {
+	return func(e *baseError) {
+		if maxLayers < 2 {
+			maxLayers = 2
+		}
+		e.setExt().compressLayers = maxLayers
+	}
+}
+
+// writeCompressedChain writes the compressed chain starting at e, it returns false
+// if the chain is short enough to be written as is.
+func writeCompressedChain(c *chainWriter, e *baseError) bool {
+	var layers []string
+	var err error = e
+	for depth := 0; err != nil && depth <= MaxWrapDepth; depth++ {
+		layer, ok := err.(*baseError)
+		if !ok {
+			layers = appendLayer(layers, validUTF8(err.Error()))
+			break
+		}
+		layers = appendLayer(layers, validUTF8(layerText(layer)))
+		if layer.wholeChainFormat {
+			break
+		}
+		if unwrapped := layer.Unwrap(); unwrapped != nil {
+			err = unwrapped
+		} else {
+			err = nil
+		}
+	}
+	maxLayers := e.ext().compressLayers
+	if len(layers) <= maxLayers {
+		return false
+	}
+
+	head := (maxLayers + 1) / 2
+	tail := maxLayers / 2
+	for i, layer := range layers[:head] {
+		if i > 0 {
+			c.startLayer()
+		}
+		c.WriteString(layer)
+	}
+	c.startLayer()
+	c.WriteString("… " + strconv.Itoa(len(layers)-head-tail) + " more layers …")
+	for _, layer := range layers[len(layers)-tail:] {
+		c.startLayer()
+		c.WriteString(layer)
+	}
+	return true
+}
+
+func appendLayer(layers []string, layer string) []string {
+	if layer == "" {
+		return layers
+	}
+	return append(layers, layer)
+}
+
+// writeFieldsMessage writes the message with the fields as [buildFieldsMessage] does,
+// so the output is the same as of Error().
+func writeFieldsMessage(c *chainWriter, message string, fields []any) {
+	c.WriteString(validUTF8(buildFieldsMessage(message, fields)))
+}
@@ -0,0 +1,148 @@
+package erro
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+type badStringer struct{}
+
+func (badStringer) String() string { panic("boom") }
+
+type failingWriter struct {
+	err error
+}
+
+func (w failingWriter) Write(p []byte) (int, error) {
+	return 0, w.err
+}
+
+func TestFormatTo(t *testing.T) {
+	base := errors.New("connection refused")
+	tests := []struct {
+		name string
+		err  Error
+	}{
+		{"new", New("not found", "id", 42, "name", "John Doe")},
+		{"wrap std", Wrap(base, "query failed", "table", "users")},
+		{"deep chain", Wrap(Wrap(Wrap(base, "db"), "repo", "user_id", 1), "service")},
+		{"empty layer", Wrap(New("inner", "k", "v"), "")},
+		{"custom formatter", Wrap(base, "custom", Formatter(func(err Error) string { return "custom!" }))},
+		{"strict logfmt", Wrap(Wrap(base, "db", "a", 1), "outer", "b", 2, StrictLogfmt())},
+		{"panicking stringer", New("msg", "bad", badStringer{})},
+		{"panicking stringer after fields", Wrap(base, "msg", "a", 1, "bad", badStringer{})},
+		{"invalid utf8", Wrap(errors.New("bad \xff cause"), "msg \xfe", "k\xff", "v\xfe")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Streamed before Error() caches the text
+			var buf bytes.Buffer
+			if err := FormatTo(&buf, tt.err); err != nil {
+				t.Fatalf("FormatTo: %v", err)
+			}
+			want := tt.err.Error()
+			if buf.String() != want {
+				t.Errorf("FormatTo = %q, Error() = %q", buf.String(), want)
+			}
+
+			var b strings.Builder
+			if err := FormatTo(&b, tt.err); err != nil || b.String() != want {
+				t.Errorf("FormatTo to builder = %q, %v", b.String(), err)
+			}
+		})
+	}
+}
+
+func TestFormatToOptions(t *testing.T) {
+	err := New("failed", "key", "value", CategoryDatabase)
+
+	var buf bytes.Buffer
+	if e := FormatTo(&buf, err, WithUserFields(), WithCategory()); e != nil {
+		t.Fatalf("FormatTo: %v", e)
+	}
+	want := GetFormatErrorWithFullContext(WithUserFields(), WithCategory())(err)
+	if buf.String() != want {
+		t.Errorf("FormatTo = %q, want %q", buf.String(), want)
+	}
+	if !strings.Contains(want, "key=value") || !strings.Contains(want, "category=database") {
+		t.Errorf("unexpected output %q", want)
+	}
+}
+
+func TestFormatToErrors(t *testing.T) {
+	writeErr := errors.New("disk full")
+	if err := FormatTo(failingWriter{err: writeErr}, New("test")); !errors.Is(err, writeErr) {
+		t.Errorf("expected write error, got %v", err)
+	}
+	if err := FormatTo(&bytes.Buffer{}, nil); err != nil {
+		t.Errorf("expected nil for nil error, got %v", err)
+	}
+}
+
+func TestFormatVerbsStreaming(t *testing.T) {
+	err := Wrap(New("inner", "a", 1, Hint("retry later")), "outer", "b", 2, StackTrace())
+
+	if got := fmt.Sprintf("%s", err); got != err.Error() {
+		t.Errorf("%%s = %q, want %q", got, err.Error())
+	}
+	if got := fmt.Sprintf("%v", err); got != err.Error() {
+		t.Errorf("%%v = %q, want %q", got, err.Error())
+	}
+
+	full := fmt.Sprintf("%+v", err)
+	if !strings.HasPrefix(full, err.Error()+"\nHint: retry later\nStack trace:\n") {
+		t.Errorf("unexpected %%+v prefix: %q", full)
+	}
+	if !strings.HasSuffix(full, err.Stack().FormatFull()) {
+		t.Errorf("%%+v does not end with the stack trace: %q", full)
+	}
+}
+
+func TestCompressChain(t *testing.T) {
+	base := errors.New("connection refused")
+	var err Error = Wrap(base, "dial")
+	for _, msg := range []string{"query", "repo", "service", "usecase"} {
+		err = Wrap(err, msg)
+	}
+	full := Wrap(err, "handle request", "id", 7)
+	compressed := Wrap(err, "handle request", "id", 7, CompressChain(3))
+
+	want := "handle request id=7: usecase: … 4 more layers …: connection refused"
+	if got := compressed.Error(); got != want {
+		t.Errorf("Error() = %q, want %q", got, want)
+	}
+	if got := fmt.Sprintf("%v", compressed); got != want {
+		t.Errorf("%%v = %q, want %q", got, want)
+	}
+	if got := fmt.Sprintf("%+v", compressed); got != full.Error() {
+		t.Errorf("%%+v = %q, want the full chain %q", got, full.Error())
+	}
+	if got := ErrorToJSON(compressed).Message; strings.Contains(got, "more layers") {
+		t.Errorf("JSON must keep the full chain, got %q", got)
+	}
+
+	// Wrapped further, the compressed part is kept
+	outer := Wrap(compressed, "")
+	if got := outer.Error(); got != want {
+		t.Errorf("wrapped Error() = %q, want %q", got, want)
+	}
+	named := Wrap(compressed, "outer")
+	if got := named.Error(); got != "outer: "+want {
+		t.Errorf("wrapped Error() = %q, want %q", got, "outer: "+want)
+	}
+	if got := fmt.Sprintf("%+v", named); got != "outer: "+full.Error() {
+		t.Errorf("%%+v after Error() = %q, want the full chain", got)
+	}
+
+	// Short chains and empty layers
+	short := Wrap(Wrap(base, ""), "outer", CompressChain(2))
+	if got := short.Error(); got != "outer: connection refused" {
+		t.Errorf("unexpected short chain %q", got)
+	}
+	if got := Wrap(err, "top", CompressChain(0)).Error(); got != "top: … 5 more layers …: connection refused" {
+		t.Errorf("unexpected chain with minimum layers %q", got)
+	}
+}
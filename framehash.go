@@ -0,0 +1,123 @@
+package erro
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// FrameHashField is the log field with frame hashes, see [WithFrameHashes].
+const FrameHashField = "frame_hash"
+
+// Hash returns a salted hash of the function and the file of the frame, so frames can
+// be grouped without shipping their names. The line is not hashed, see [Stack.Hashes].
+// Resolve hashes back to names with a [FrameHashIndex] built with the same salt.
+func (f StackFrame) Hash(salt string) string {
+	return hashFrame(salt, f.FullName, f.File)
+}
+
+// Hashes returns "<hash>:<line>" for every frame of the stack, see [StackFrame.Hash].
+//
+// Example:
+//
+//	err.Stack().Hashes(salt) // ["3f1c9a0e5b7d2c41:42", "a07e4b1d9c3f5e28:118"]
+func (s Stack) Hashes(salt string) []string {
+	if len(s) == 0 {
+		return nil
+	}
+	hashes := make([]string, len(s))
+	for i, frame := range s {
+		hashes[i] = frame.Hash(salt) + ":" + strconv.Itoa(frame.Line)
+	}
+	return hashes
+}
+
+func hashFrame(salt, function, file string) string {
+	mac := hmac.New(sha256.New, []byte(salt))
+	mac.Write([]byte(function))
+	mac.Write([]byte(fingerprintSeparator))
+	mac.Write([]byte(file))
+	return hex.EncodeToString(mac.Sum(nil)[:8])
+}
+
+// FrameHashIndex maps frame hashes back to functions and files during incident
+// response. Fill it from the binary that produced the hashes, e.g. with the
+// cmd/framehash tool, or with frames of a local build. It is safe for concurrent use.
+type FrameHashIndex struct {
+	salt string
+
+	mu     sync.RWMutex
+	frames map[string]StackFrame
+}
+
+// NewFrameHashIndex creates an empty [FrameHashIndex] for hashes made with the salt.
+func NewFrameHashIndex(salt string) *FrameHashIndex {
+	return &FrameHashIndex{salt: salt, frames: make(map[string]StackFrame)}
+}
+
+// Add adds a function and its file to the index.
+func (x *FrameHashIndex) Add(function, file string) {
+	frame := StackFrame{
+		Name:     extractShortName(function),
+		FullName: function,
+		Package:  extractPackageFromFunction(function),
+		File:     file,
+	}
+	if i := strings.LastIndexAny(file, `/\`); i >= 0 {
+		frame.FileName = file[i+1:]
+	} else {
+		frame.FileName = file
+	}
+
+	x.mu.Lock()
+	x.frames[hashFrame(x.salt, function, file)] = frame
+	x.mu.Unlock()
+}
+
+// AddStack adds the frames of the stack to the index.
+func (x *FrameHashIndex) AddStack(stack Stack) {
+	for _, frame := range stack {
+		x.Add(frame.FullName, frame.File)
+	}
+}
+
+// Len returns the number of indexed frames.
+func (x *FrameHashIndex) Len() int {
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+	return len(x.frames)
+}
+
+// Resolve returns the frame of a hash from [StackFrame.Hash] or [Stack.Hashes].
+// The line is set if the hash has the ":<line>" suffix.
+func (x *FrameHashIndex) Resolve(hash string) (StackFrame, bool) {
+	var line int
+	if i := strings.IndexByte(hash, ':'); i >= 0 {
+		line, _ = strconv.Atoi(hash[i+1:])
+		hash = hash[:i]
+	}
+
+	x.mu.RLock()
+	frame, ok := x.frames[hash]
+	x.mu.RUnlock()
+	frame.Line = line
+	return frame, ok
+}
+
+// ResolveStack resolves the hashes to a stack; unknown hashes become frames with the
+// hash as their name.
+func (x *FrameHashIndex) ResolveStack(hashes []string) Stack {
+	stack := make(Stack, len(hashes))
+	for i, hash := range hashes {
+		frame, ok := x.Resolve(hash)
+		if !ok {
+			frame.Name = hash
+			frame.FullName = hash
+		}
+		stack[i] = frame
+	}
+	return stack
+}
@@ -0,0 +1,96 @@
+package erro
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestFrameHash(t *testing.T) {
+	frame := StackFrame{FullName: "github.com/app/payment.process", File: "/src/app/payment/process.go", Line: 42}
+	hash := frame.Hash("salt")
+	if len(hash) != 16 {
+		t.Errorf("unexpected hash %q", hash)
+	}
+	if frame.Hash("salt") != hash {
+		t.Error("hash must be stable")
+	}
+	if frame.Hash("other") == hash {
+		t.Error("hash must depend on the salt")
+	}
+	moved := frame
+	moved.Line = 43
+	if moved.Hash("salt") != hash {
+		t.Error("hash must not depend on the line")
+	}
+
+	hashes := Stack{frame, moved}.Hashes("salt")
+	if len(hashes) != 2 || hashes[0] != hash+":42" || hashes[1] != hash+":43" {
+		t.Errorf("unexpected hashes %v", hashes)
+	}
+	if Stack(nil).Hashes("salt") != nil {
+		t.Error("expected nil for an empty stack")
+	}
+}
+
+func TestFrameHashIndex(t *testing.T) {
+	err := New("failed", StackTrace())
+	stack := err.Stack()
+
+	index := NewFrameHashIndex("salt")
+	index.AddStack(stack)
+	if index.Len() == 0 {
+		t.Fatal("expected indexed frames")
+	}
+
+	top := stack.TopUserFrame()
+	frame, ok := index.Resolve(top.Hash("salt") + ":" + strconv.Itoa(top.Line))
+	if !ok || frame.FullName != top.FullName || frame.File != top.File || frame.Line != top.Line {
+		t.Errorf("unexpected frame %+v for %+v", frame, *top)
+	}
+	if frame.Name != top.Name || frame.FileName != top.FileName || frame.Package != top.Package {
+		t.Errorf("unexpected names %+v for %+v", frame, *top)
+	}
+
+	resolved := index.ResolveStack(append(stack.Hashes("salt"), "unknown:1"))
+	if resolved[0].FullName != stack[0].FullName || resolved[len(resolved)-1].Name != "unknown:1" {
+		t.Errorf("unexpected resolved stack %v", resolved)
+	}
+	if _, ok := NewFrameHashIndex("other").Resolve(top.Hash("salt")); ok {
+		t.Error("expected unknown hash in an empty index")
+	}
+}
+
+func TestWithFrameHashes(t *testing.T) {
+	err := New("failed", StackTrace())
+	top := err.Stack().TopUserFrame()
+
+	fields := LogFieldsMap(err, WithFunction(), WithFile(), WithFrameHashes("salt"))
+	if _, ok := fields["function"]; ok {
+		t.Error("function name must not be logged")
+	}
+	if _, ok := fields["file"]; ok {
+		t.Error("file name must not be logged")
+	}
+	hashes, ok := fields[FrameHashField].([]string)
+	if !ok || len(hashes) != 1 || hashes[0] != top.Hash("salt")+":"+strconv.Itoa(top.Line) {
+		t.Errorf("unexpected frame hashes %v", fields[FrameHashField])
+	}
+
+	fields = LogFieldsMap(err, WithStack(), WithFrameHashes("salt"))
+	if _, ok := fields["stack"]; ok {
+		t.Error("stack must not be logged")
+	}
+	if hashes, _ := fields[FrameHashField].([]string); len(hashes) != len(err.Stack()) {
+		t.Errorf("expected a hash per frame, got %v", hashes)
+	}
+	for _, v := range LogFields(err, WithStack(), WithFrameHashes("salt")) {
+		if s, ok := v.(string); ok && strings.Contains(s, "TestWithFrameHashes") {
+			t.Errorf("function name leaked: %q", s)
+		}
+	}
+
+	if fields := LogFieldsMap(New("no stack"), WithStack(), WithFrameHashes("salt")); fields[FrameHashField] != nil {
+		t.Error("unexpected frame hashes without a stack")
+	}
+}
@@ -0,0 +1,48 @@
+package erro_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/maxbolgarin/erro"
+	"github.com/maxbolgarin/erro/errtest"
+)
+
+// FuzzUnmarshalJSON checks that decoding arbitrary payloads never panics and that
+// decoded errors survive another round trip of the wire format.
+func FuzzUnmarshalJSON(f *testing.F) {
+	for _, v := range errtest.WireVectors() {
+		f.Add([]byte(v.Payload))
+	}
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		var target erro.Error = erro.New("")
+		if target.UnmarshalJSON(data) == nil {
+			_ = target.Error()
+			_ = target.LogFields()
+			if _, err := json.Marshal(target); err != nil {
+				t.Fatalf("marshal the unmarshaled error: %v", err)
+			}
+		}
+
+		err, decodeErr := erro.FromJSON(data)
+		if decodeErr != nil {
+			return
+		}
+		if len(err.Message()) > erro.MaxMessageLength+len("...") || len(err.Fields()) > 2*erro.MaxFieldsCount {
+			t.Fatalf("limits are not applied: message of %d bytes and %d fields", len(err.Message()), len(err.Fields()))
+		}
+		_ = err.Error()
+		encoded, encodeErr := json.Marshal(err)
+		if encodeErr != nil {
+			t.Fatalf("marshal the decoded error: %v", encodeErr)
+		}
+		again, decodeErr := erro.FromJSON(encoded)
+		if decodeErr != nil {
+			t.Fatalf("decode the encoded error: %v: %s", decodeErr, encoded)
+		}
+		if again.Message() != err.Message() || len(again.Fields()) != len(err.Fields()) {
+			t.Fatalf("round trip changed the error: %q %v, %q %v", err.Message(), err.Fields(), again.Message(), again.Fields())
+		}
+	})
+}
@@ -0,0 +1,39 @@
+package erro
+
+import "encoding/gob"
+
+func init() {
+	// Errors sent as the error or Error interface need a registered concrete type
+	gob.RegisterName("github.com/maxbolgarin/erro.Error", &baseError{})
+}
+
+// MarshalBinary implements [encoding.BinaryMarshaler] with the JSON representation
+// of the error, see [ErrorToJSON].
+func (e *baseError) MarshalBinary() ([]byte, error) {
+	return e.MarshalJSON()
+}
+
+// UnmarshalBinary implements [encoding.BinaryUnmarshaler] with the JSON representation
+// of the error, see [FromJSON].
+func (e *baseError) UnmarshalBinary(data []byte) error {
+	return e.UnmarshalJSON(data)
+}
+
+// GobEncode implements [gob.GobEncoder], so errors can be sent through encoding/gob,
+// including as values of the error and [Error] interfaces. Like JSON, it keeps the
+// metadata, the message and the fields of the chain, but not the stack trace and the span.
+//
+// Example:
+//
+//	type Reply struct {
+//	    Err error
+//	}
+//	err := gob.NewEncoder(conn).Encode(Reply{Err: erro.New("not found", erro.ClassNotFound)})
+func (e *baseError) GobEncode() ([]byte, error) {
+	return e.MarshalBinary()
+}
+
+// GobDecode implements [gob.GobDecoder], see GobEncode.
+func (e *baseError) GobDecode(data []byte) error {
+	return e.UnmarshalBinary(data)
+}
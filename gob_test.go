@@ -0,0 +1,37 @@
+package erro
+
+import (
+	"bytes"
+	"encoding/gob"
+	"testing"
+)
+
+func TestGob(t *testing.T) {
+	type reply struct {
+		Err   error
+		Typed Error
+	}
+	err := Wrap(New("user not found", "user_id", 42, ClassNotFound), "get user", Retryable())
+
+	var buf bytes.Buffer
+	if encodeErr := gob.NewEncoder(&buf).Encode(reply{Err: err, Typed: err}); encodeErr != nil {
+		t.Fatalf("encode: %v", encodeErr)
+	}
+	var decoded reply
+	if decodeErr := gob.NewDecoder(&buf).Decode(&decoded); decodeErr != nil {
+		t.Fatalf("decode: %v", decodeErr)
+	}
+
+	for _, got := range []error{decoded.Err, decoded.Typed} {
+		e, ok := got.(Error)
+		if !ok {
+			t.Fatalf("expected erro error, got %T", got)
+		}
+		if e.ID() != err.ID() || e.Class() != ClassNotFound || !e.IsRetryable() {
+			t.Errorf("expected metadata to be preserved, got %s %s %v", e.ID(), e.Class(), e.IsRetryable())
+		}
+		if e.Error() != "get user: user not found user_id=42" {
+			t.Errorf("unexpected message: %q", e.Error())
+		}
+	}
+}
@@ -0,0 +1,52 @@
+package erro
+
+import "runtime"
+
+// MaxGoroutineDumpSize is the maximum size in bytes of a goroutine dump captured with [AllGoroutines].
+const MaxGoroutineDumpSize = 64 << 10
+
+// goroutineDumpTruncated is appended to a goroutine dump that didn't fit into [MaxGoroutineDumpSize].
+const goroutineDumpTruncated = "\n... goroutine dump truncated"
+
+// AllGoroutines captures the stacks of all goroutines if the error has [SeverityCritical].
+// It helps to debug deadlocks and stuck workers at the moment of failure. The dump is
+// capped at [MaxGoroutineDumpSize], stored separately from fields and formatted only
+// when it is requested with [GoroutineDump].
+//
+// Capturing all goroutines stops the world for a short time, so use it for rare critical errors only.
+//
+// Example:
+//
+//	err := erro.New("worker pool stuck", erro.SeverityCritical, erro.AllGoroutines())
+//	log.Println(erro.GoroutineDump(err))
+func AllGoroutines() errorWork {
+	return func(err Error) {
+		base, ok := err.(*baseError)
+		if !ok || base.Severity() != SeverityCritical {
+			return
+		}
+		buf := make([]byte, MaxGoroutineDumpSize)
+		n := runtime.Stack(buf, true)
+		// Copy the dump, so the error doesn't retain the whole buffer
+		base.setExt().goroutines = append([]byte(nil), buf[:n]...)
+	}
+}
+
+// GoroutineDump returns the goroutine dump captured with [AllGoroutines] in the
+// chain of the error, or an empty string if there is none.
+func GoroutineDump(err error) string {
+	var base *baseError
+	if !As(err, &base) {
+		return ""
+	}
+	for depth := 0; base != nil && depth < MaxWrapDepth; depth++ {
+		if dump := base.ext().goroutines; len(dump) > 0 {
+			if len(dump) == MaxGoroutineDumpSize {
+				return string(dump) + goroutineDumpTruncated
+			}
+			return string(dump)
+		}
+		base = base.wrappedErr
+	}
+	return ""
+}
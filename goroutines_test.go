@@ -0,0 +1,45 @@
+package erro
+
+import (
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestAllGoroutines(t *testing.T) {
+	if err := New("minor", SeverityLow, AllGoroutines()); GoroutineDump(err) != "" {
+		t.Error("expected no dump for non-critical errors")
+	}
+
+	var ready, release sync.WaitGroup
+	ready.Add(1)
+	release.Add(1)
+	go func() {
+		ready.Done()
+		release.Wait()
+	}()
+	ready.Wait()
+	defer release.Done()
+
+	err := New("worker pool stuck", AllGoroutines(), SeverityCritical)
+	dump := GoroutineDump(err)
+	if !strings.Contains(dump, "TestAllGoroutines") || strings.Count(dump, "goroutine ") < 2 {
+		t.Errorf("expected dump of all goroutines, got:\n%s", dump)
+	}
+	if len(dump) > MaxGoroutineDumpSize+len(goroutineDumpTruncated) {
+		t.Errorf("expected dump to be capped, got %d bytes", len(dump))
+	}
+	if captured := err.(*baseError).ext().goroutines; cap(captured) >= MaxGoroutineDumpSize && len(captured) < MaxGoroutineDumpSize {
+		t.Errorf("expected dump not to retain the capture buffer, got cap %d for %d bytes", cap(captured), len(captured))
+	}
+	if strings.Contains(err.Error(), "goroutine") {
+		t.Error("expected dump not to be part of the message")
+	}
+
+	if wrapped := Wrap(err, "outer"); GoroutineDump(wrapped) != dump {
+		t.Error("expected dump to be found through the chain")
+	}
+	if GoroutineDump(nil) != "" {
+		t.Error("expected empty dump for nil")
+	}
+}
@@ -0,0 +1,193 @@
+package erro
+
+import (
+	"strconv"
+	"strings"
+)
+
+// graphEdgeKind describes how two errors in a cause graph are connected.
+type graphEdgeKind string
+
+const (
+	// graphEdgeWrap connects an error to the error it wraps.
+	graphEdgeWrap graphEdgeKind = "wraps"
+	// graphEdgeJoin connects a multi-error to one of its elements.
+	graphEdgeJoin graphEdgeKind = "joins"
+)
+
+// errorGraphNode is a single error in the cause graph.
+type errorGraphNode struct {
+	id       int
+	message  string
+	class    ErrorClass
+	severity ErrorSeverity
+}
+
+// errorGraphEdge connects two nodes of the cause graph.
+type errorGraphEdge struct {
+	from int
+	to   int
+	kind graphEdgeKind
+}
+
+// errorGraph is the cause graph of an error: wrapped and joined errors as nodes and edges.
+type errorGraph struct {
+	nodes []errorGraphNode
+	edges []errorGraphEdge
+}
+
+// ToDOT renders the cause graph of the error in Graphviz DOT format.
+//
+// Nodes are the errors of the chain labeled with their own message, class and
+// severity; edges are wrap and join relations. It returns an empty string for nil.
+//
+// Example:
+//
+//	fmt.Println(erro.ToDOT(err)) // paste into `dot -Tsvg`
+func ToDOT(err error) string {
+	if err == nil {
+		return ""
+	}
+	g := buildErrorGraph(err)
+
+	var b strings.Builder
+	b.WriteString("digraph errors {\n")
+	b.WriteString("\tnode [shape=box];\n")
+	for _, n := range g.nodes {
+		b.WriteString("\tn")
+		b.WriteString(strconv.Itoa(n.id))
+		b.WriteString(" [label=\"")
+		b.WriteString(n.label("\\n", escapeDOT))
+		b.WriteString("\"];\n")
+	}
+	for _, e := range g.edges {
+		b.WriteString("\tn")
+		b.WriteString(strconv.Itoa(e.from))
+		b.WriteString(" -> n")
+		b.WriteString(strconv.Itoa(e.to))
+		b.WriteString(" [label=\"")
+		b.WriteString(string(e.kind))
+		b.WriteString("\"];\n")
+	}
+	b.WriteString("}\n")
+	return b.String()
+}
+
+// ToMermaid renders the cause graph of the error as a Mermaid flowchart.
+//
+// Nodes are the errors of the chain labeled with their own message, class and
+// severity; edges are wrap and join relations. It returns an empty string for nil.
+//
+// Example:
+//
+//	fmt.Println(erro.ToMermaid(err)) // paste into a ```mermaid block
+func ToMermaid(err error) string {
+	if err == nil {
+		return ""
+	}
+	g := buildErrorGraph(err)
+
+	var b strings.Builder
+	b.WriteString("flowchart TD\n")
+	for _, n := range g.nodes {
+		b.WriteString("\tn")
+		b.WriteString(strconv.Itoa(n.id))
+		b.WriteString("[\"")
+		b.WriteString(n.label("<br/>", escapeMermaid))
+		b.WriteString("\"]\n")
+	}
+	for _, e := range g.edges {
+		b.WriteString("\tn")
+		b.WriteString(strconv.Itoa(e.from))
+		b.WriteString(" -->|")
+		b.WriteString(string(e.kind))
+		b.WriteString("| n")
+		b.WriteString(strconv.Itoa(e.to))
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+func (n errorGraphNode) label(newline string, escape func(string) string) string {
+	label := escape(n.message)
+	if label == "" {
+		label = "error"
+	}
+	if n.class != "" {
+		label += newline + "class: " + escape(n.class.String())
+	}
+	if n.severity != "" {
+		label += newline + "severity: " + escape(n.severity.String())
+	}
+	return label
+}
+
+// buildErrorGraph traverses wrapped and joined errors, limited by [MaxWrapDepth].
+func buildErrorGraph(err error) errorGraph {
+	var g errorGraph
+	g.addNode(err, 0)
+	return g
+}
+
+func (g *errorGraph) addNode(err error, depth int) int {
+	id := len(g.nodes)
+	node := errorGraphNode{id: id}
+
+	var children []error
+	kind := graphEdgeWrap
+	switch e := err.(type) {
+	case *baseError:
+		node.message = buildMessage(e)
+		node.class = e.class
+		node.severity = e.severity
+		if unwrapped := e.Unwrap(); unwrapped != nil {
+			children = []error{unwrapped}
+		}
+	case interface{ Unwrap() []error }:
+		node.message = "multiple errors (" + strconv.Itoa(len(e.Unwrap())) + ")"
+		children = e.Unwrap()
+		kind = graphEdgeJoin
+	case interface{ Unwrap() error }:
+		node.message = truncateString(err.Error(), MaxMessageLength)
+		if unwrapped := e.Unwrap(); unwrapped != nil {
+			children = []error{unwrapped}
+		}
+	default:
+		node.message = truncateString(err.Error(), MaxMessageLength)
+	}
+	g.nodes = append(g.nodes, node)
+
+	if depth >= MaxWrapDepth {
+		return id
+	}
+	for _, child := range children {
+		if child == nil {
+			continue
+		}
+		childID := g.addNode(child, depth+1)
+		g.edges = append(g.edges, errorGraphEdge{from: id, to: childID, kind: kind})
+	}
+	return id
+}
+
+func escapeDOT(s string) string {
+	var b strings.Builder
+	b.Grow(len(s))
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '"':
+			b.WriteString("\\\"")
+		case '\n':
+			b.WriteString("\\n")
+		case '\\':
+			b.WriteString("\\\\")
+		default:
+			b.WriteByte(s[i])
+		}
+	}
+	return b.String()
+}
+
+func escapeMermaid(s string) string {
+	return strings.NewReplacer("\"", "#quot;", "\n", "<br/>").Replace(s)
+}
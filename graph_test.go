@@ -0,0 +1,65 @@
+package erro
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestToDOT(t *testing.T) {
+	if ToDOT(nil) != "" {
+		t.Error("expected empty output for nil error")
+	}
+
+	base := New("db \"down\"", ClassUnavailable, SeverityHigh)
+	err := Join(Wrap(base, "handler failed", ClassInternal), errors.New("std error"))
+
+	dot := ToDOT(err)
+	if !strings.HasPrefix(dot, "digraph errors {") {
+		t.Errorf("unexpected DOT header: %s", dot)
+	}
+	for _, want := range []string{
+		`n0 [label="multiple errors (2)"];`,
+		`n1 [label="handler failed\nclass: internal"];`,
+		`n2 [label="db \"down\"\nclass: unavailable\nseverity: high"];`,
+		`n3 [label="std error"];`,
+		`n0 -> n1 [label="joins"];`,
+		`n0 -> n3 [label="joins"];`,
+		`n1 -> n2 [label="wraps"];`,
+	} {
+		if !strings.Contains(dot, want) {
+			t.Errorf("expected DOT output to contain %q, got:\n%s", want, dot)
+		}
+	}
+}
+
+func TestToMermaid(t *testing.T) {
+	if ToMermaid(nil) != "" {
+		t.Error("expected empty output for nil error")
+	}
+
+	err := Wrap(errors.New(`bad "input"`), "validate", ClassValidation)
+	out := ToMermaid(err)
+	for _, want := range []string{
+		"flowchart TD",
+		`n0["validate<br/>class: validation"]`,
+		`n1["bad #quot;input#quot;"]`,
+		"n0 -->|wraps| n1",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected Mermaid output to contain %q, got:\n%s", want, out)
+		}
+	}
+}
+
+func TestToDOT_DepthLimit(t *testing.T) {
+	var err error = errors.New("root")
+	for i := 0; i < MaxWrapDepth+10; i++ {
+		err = fmt.Errorf("layer: %w", err)
+	}
+	g := buildErrorGraph(err)
+	if len(g.nodes) != MaxWrapDepth+1 {
+		t.Errorf("expected %d nodes, got %d", MaxWrapDepth+1, len(g.nodes))
+	}
+}
@@ -0,0 +1,162 @@
+package erro
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// Field keys attached by [WrapGRPC].
+const (
+	GRPCCodeField    = "grpc_code"
+	GRPCMessageField = "grpc_message"
+	GRPCDetailsField = "grpc_details"
+)
+
+// GRPCStatus is the status of a gRPC error, see [GRPCStatusOf].
+type GRPCStatus struct {
+	// Code is the numeric status code, e.g. 5 for NotFound.
+	Code uint32
+	// CodeName is the name of the code, e.g. "NotFound".
+	CodeName string
+	// Message is the status message of the upstream service.
+	Message string
+	// Details are the status details, usually proto messages.
+	Details []any
+}
+
+// WrapGRPC wraps an error of a gRPC client call. If the chain has a gRPC status, the
+// error gets the [GRPCCodeField], [GRPCMessageField] and [GRPCDetailsField] fields, the
+// class of the code from [ClassifyGRPCCode] and [CategoryExternal]; a class or category
+// in meta overrides them. The wrapped error stays in the chain, so status.FromError,
+// status.Code and errors.As still see the original status.
+//
+// It detects the status by the GRPCStatus() method of grpc-go errors without depending
+// on grpc-go. If err is nil, WrapGRPC returns nil.
+//
+// Example:
+//
+//	resp, err := client.GetUser(ctx, req)
+//	if err != nil {
+//	    return erro.WrapGRPC(err, "get user", "user_id", req.Id)
+//	}
+func WrapGRPC(err error, message string, meta ...any) Error {
+	if err == nil {
+		return nil
+	}
+	st, ok := GRPCStatusOf(err)
+	if !ok {
+		return Wrap(err, message, meta...)
+	}
+
+	opts := make([]any, 0, len(meta)+9)
+	opts = append(opts, GRPCCodeField, st.CodeName)
+	if st.Message != "" {
+		opts = append(opts, GRPCMessageField, st.Message)
+	}
+	if len(st.Details) > 0 {
+		opts = append(opts, GRPCDetailsField, st.Details)
+	}
+	opts = append(opts, CategoryExternal)
+	class, retryable := ClassifyGRPCCode(st.Code)
+	if class != ClassUnknown {
+		opts = append(opts, class)
+	}
+	if retryable {
+		opts = append(opts, Retryable())
+	}
+	return Wrap(err, message, append(opts, meta...)...)
+}
+
+// ClassifyGRPCCode returns the error class of a gRPC status code and whether it is worth retrying.
+func ClassifyGRPCCode(code uint32) (class ErrorClass, retryable bool) {
+	switch code {
+	case 1: // Canceled
+		return ClassCancelled, false
+	case 3, 11: // InvalidArgument, OutOfRange
+		return ClassValidation, false
+	case 4: // DeadlineExceeded
+		return ClassTimeout, true
+	case 5: // NotFound
+		return ClassNotFound, false
+	case 6: // AlreadyExists
+		return ClassAlreadyExists, false
+	case 7: // PermissionDenied
+		return ClassPermissionDenied, false
+	case 8: // ResourceExhausted
+		return ClassResourceExhausted, true
+	case 9: // FailedPrecondition
+		return ClassConflict, false
+	case 10: // Aborted
+		return ClassConflict, true
+	case 12: // Unimplemented
+		return ClassNotImplemented, false
+	case 13: // Internal
+		return ClassInternal, false
+	case 14: // Unavailable
+		return ClassUnavailable, true
+	case 15: // DataLoss
+		return ClassDataLoss, false
+	case 16: // Unauthenticated
+		return ClassUnauthenticated, false
+	}
+	return ClassUnknown, false
+}
+
+// GRPCStatusOf returns the gRPC status of the first error in the chain with a
+// GRPCStatus() method, as grpc-go status errors have. It returns false if there is
+// none or the status is OK.
+func GRPCStatusOf(err error) (GRPCStatus, bool) {
+	for depth := 0; err != nil && depth <= MaxWrapDepth; depth++ {
+		if st, ok := grpcStatus(err); ok {
+			return st, st.Code != 0
+		}
+		unwrapper, ok := err.(interface{ Unwrap() error })
+		if !ok {
+			break
+		}
+		err = unwrapper.Unwrap()
+	}
+	return GRPCStatus{}, false
+}
+
+// grpcStatus reads the status returned by the GRPCStatus method of the error with reflection.
+func grpcStatus(err error) (GRPCStatus, bool) {
+	method := reflect.ValueOf(err).MethodByName("GRPCStatus")
+	if !method.IsValid() || method.Type().NumIn() != 0 || method.Type().NumOut() != 1 {
+		return GRPCStatus{}, false
+	}
+	status := method.Call(nil)[0]
+	if status.Kind() == reflect.Ptr && status.IsNil() {
+		return GRPCStatus{}, false
+	}
+
+	var st GRPCStatus
+	if out, ok := callGRPCMethod(status, "Code"); ok && out.CanUint() {
+		st.Code = uint32(out.Uint())
+		if stringer, ok := out.Interface().(fmt.Stringer); ok {
+			st.CodeName = stringer.String()
+		}
+	} else {
+		return GRPCStatus{}, false
+	}
+	if st.CodeName == "" {
+		st.CodeName = fmt.Sprintf("Code(%d)", st.Code)
+	}
+	if out, ok := callGRPCMethod(status, "Message"); ok && out.Kind() == reflect.String {
+		st.Message = out.String()
+	}
+	if out, ok := callGRPCMethod(status, "Details"); ok && out.Kind() == reflect.Slice {
+		for i := 0; i < out.Len(); i++ {
+			st.Details = append(st.Details, out.Index(i).Interface())
+		}
+	}
+	return st, true
+}
+
+func callGRPCMethod(v reflect.Value, name string) (reflect.Value, bool) {
+	method := v.MethodByName(name)
+	if !method.IsValid() || method.Type().NumIn() != 0 || method.Type().NumOut() != 1 {
+		return reflect.Value{}, false
+	}
+	return method.Call(nil)[0], true
+}
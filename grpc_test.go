@@ -0,0 +1,82 @@
+package erro
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+type grpcCode uint32
+
+func (c grpcCode) String() string {
+	if c == 5 {
+		return "NotFound"
+	}
+	return fmt.Sprintf("Code(%d)", uint32(c))
+}
+
+type grpcStatusValue struct {
+	code    grpcCode
+	message string
+	details []any
+}
+
+func (s *grpcStatusValue) Code() grpcCode  { return s.code }
+func (s *grpcStatusValue) Message() string { return s.message }
+func (s *grpcStatusValue) Details() []any  { return s.details }
+
+type grpcStatusError struct {
+	status *grpcStatusValue
+}
+
+func (e *grpcStatusError) Error() string {
+	return "rpc error: code = " + e.status.code.String() + " desc = " + e.status.message
+}
+
+func (e *grpcStatusError) GRPCStatus() *grpcStatusValue { return e.status }
+
+func TestWrapGRPC(t *testing.T) {
+	if WrapGRPC(nil, "get user") != nil {
+		t.Error("expected nil for nil")
+	}
+
+	upstream := &grpcStatusError{status: &grpcStatusValue{code: 5, message: "user 42 not found", details: []any{"detail"}}}
+	err := WrapGRPC(fmt.Errorf("call: %w", upstream), "get user", "user_id", 42)
+	if err.Class() != ClassNotFound || err.Category() != CategoryExternal || err.IsRetryable() {
+		t.Errorf("unexpected classification %s %s %v", err.Class(), err.Category(), err.IsRetryable())
+	}
+	if code, _ := Field[string](err, GRPCCodeField); code != "NotFound" {
+		t.Errorf("unexpected code field %q", code)
+	}
+	if message, _ := Field[string](err, GRPCMessageField); message != "user 42 not found" {
+		t.Errorf("unexpected message field %q", message)
+	}
+	if details, _ := Field[[]any](err, GRPCDetailsField); len(details) != 1 || details[0] != "detail" {
+		t.Errorf("unexpected details field %v", details)
+	}
+	var target *grpcStatusError
+	if !errors.As(err, &target) || target != upstream {
+		t.Error("expected the original status error in the chain")
+	}
+
+	unavailable := &grpcStatusError{status: &grpcStatusValue{code: 14}}
+	err = WrapGRPC(unavailable, "get user", ClassTemporary)
+	if err.Class() != ClassTemporary || !err.IsRetryable() {
+		t.Errorf("expected the class override and retryable, got %s %v", err.Class(), err.IsRetryable())
+	}
+	if code, _ := Field[string](err, GRPCCodeField); code != "Code(14)" {
+		t.Errorf("unexpected code field %q", code)
+	}
+
+	plain := WrapGRPC(errors.New("dial failed"), "get user")
+	if plain.Class() != ClassUnknown || len(plain.Fields()) != 0 {
+		t.Errorf("expected a plain wrap without a status, got %s %v", plain.Class(), plain.Fields())
+	}
+	ok := &grpcStatusError{status: &grpcStatusValue{code: 0}}
+	if _, found := GRPCStatusOf(ok); found {
+		t.Error("expected no status for OK")
+	}
+	if _, found := GRPCStatusOf(&grpcStatusError{}); found {
+		t.Error("expected no status for a nil status")
+	}
+}
@@ -0,0 +1,147 @@
+package erro
+
+import (
+	"path/filepath"
+	"runtime"
+	"strconv"
+)
+
+// Field keys attached by [Handoff] and [Receive].
+const (
+	HandoffGoroutineField = "handoff_goroutine"
+	HandoffSiteField      = "handoff_site"
+	ReceiveGoroutineField = "receive_goroutine"
+	ReceiveSiteField      = "receive_site"
+)
+
+// transportedFrameName is the name of the marker frame separating receiver and sender stacks.
+const transportedFrameName = "[transported]"
+
+// Handoff annotates an error before it is sent to another goroutine (e.g. via a channel).
+//
+// It records the goroutine and call site where the error was handed off and
+// captures the sender's stack. Use [Receive] on the receiving side.
+// If err is nil, Handoff returns nil.
+//
+// Example:
+//
+//	go func() {
+//	    if err := work(); err != nil {
+//	        errCh <- erro.Handoff(err)
+//	    }
+//	}()
+//	err := erro.Receive(<-errCh)
+func Handoff(err error) Error {
+	if err == nil {
+		return nil
+	}
+	e := newWrapError(err, "")
+	e.formatter = FormatErrorMessage
+	e.stack = captureStack(2)
+	handoff := newHandoffInfo(1)
+	e.setExt().handoff = handoff
+	e.fields = []any{
+		HandoffGoroutineField, handoff.goroutine,
+		HandoffSiteField, handoff.site(),
+	}
+	return e
+}
+
+// Receive annotates an error received from another goroutine.
+//
+// It records the goroutine and call site where the error was received. The stack
+// of the result starts with the receiver's frames, followed by a "[transported]"
+// marker frame and the sender's frames captured by [Handoff] (or the original
+// stack of the error, if it has one).
+// If err is nil, Receive returns nil.
+func Receive(err error) Error {
+	if err == nil {
+		return nil
+	}
+	e := newWrapError(err, "")
+	e.formatter = FormatErrorMessage
+	e.stack = captureStack(2)
+	receive := newHandoffInfo(1)
+	e.fields = []any{
+		ReceiveGoroutineField, receive.goroutine,
+		ReceiveSiteField, receive.site(),
+	}
+
+	cfg := e.StackTraceConfig()
+	frames := e.stack.toFrames(cfg)
+	if e.wrappedErr != nil {
+		sender := e.wrappedErr.getStack(cfg)
+		marker := StackFrame{
+			Name:             transportedFrameName,
+			FullName:         transportedFrameName,
+			StackTraceConfig: cfg,
+		}
+		if handoff := findHandoff(e.wrappedErr); handoff != nil {
+			marker.FullName = transportedFrameName + " from goroutine " + strconv.FormatUint(handoff.goroutine, 10)
+			marker.File = handoff.file
+			marker.FileName = filepath.Base(handoff.file)
+			marker.Line = handoff.line
+		}
+		if len(sender) > 0 {
+			frames = append(append(frames, marker), sender...)
+		}
+	}
+	e.frames.Store(frames)
+	return e
+}
+
+// handoffInfo describes the goroutine and call site of a hand-off.
+type handoffInfo struct {
+	goroutine uint64
+	function  string
+	file      string
+	line      int
+}
+
+// newHandoffInfo captures the current goroutine and the caller skip frames above the caller.
+func newHandoffInfo(skip int) *handoffInfo {
+	info := &handoffInfo{goroutine: goroutineID()}
+	pc, file, line, ok := runtime.Caller(skip + 1)
+	if !ok {
+		return info
+	}
+	if fn := runtime.FuncForPC(pc); fn != nil {
+		info.function = fn.Name()
+	}
+	info.file = file
+	info.line = line
+	return info
+}
+
+// site returns "function (file:line)" of the hand-off.
+func (h *handoffInfo) site() string {
+	return h.function + " (" + h.file + ":" + strconv.Itoa(h.line) + ")"
+}
+
+// findHandoff returns the hand-off info of the closest layer of the chain created by [Handoff].
+func findHandoff(e *baseError) *handoffInfo {
+	for depth := 0; e != nil && depth < MaxWrapDepth; depth++ {
+		if handoff := e.ext().handoff; handoff != nil {
+			return handoff
+		}
+		e = e.wrappedErr
+	}
+	return nil
+}
+
+// goroutineID returns the ID of the current goroutine parsed from its stack header.
+func goroutineID() uint64 {
+	var buf [64]byte
+	n := runtime.Stack(buf[:], false)
+	// Header looks like "goroutine 18 [running]:"
+	const prefix = len("goroutine ")
+	if n <= prefix {
+		return 0
+	}
+	end := prefix
+	for end < n && buf[end] >= '0' && buf[end] <= '9' {
+		end++
+	}
+	id, _ := strconv.ParseUint(string(buf[prefix:end]), 10, 64)
+	return id
+}
@@ -0,0 +1,78 @@
+package erro
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestHandoffReceive(t *testing.T) {
+	if Handoff(nil) != nil || Receive(nil) != nil {
+		t.Error("expected nil for nil error")
+	}
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- Handoff(New("worker failed", "job", 7, StackTrace()))
+	}()
+	err := Receive(<-errCh)
+
+	if err.Error() != "worker failed job=7" {
+		t.Errorf("expected hand-off layers to keep the message, got %q", err.Error())
+	}
+
+	fields := err.LogFieldsMap()
+	for _, key := range []string{HandoffGoroutineField, HandoffSiteField, ReceiveGoroutineField, ReceiveSiteField} {
+		if _, ok := fields[key]; !ok {
+			t.Errorf("expected field %s, got %v", key, fields)
+		}
+	}
+	if fields[HandoffGoroutineField] == fields[ReceiveGoroutineField] {
+		t.Errorf("expected different goroutines, got %v", fields[HandoffGoroutineField])
+	}
+	if !strings.Contains(fields[ReceiveSiteField].(string), "handoff_test.go") {
+		t.Errorf("unexpected receive site: %v", fields[ReceiveSiteField])
+	}
+
+	stack := err.Stack()
+	markerIdx := -1
+	for i, frame := range stack {
+		if frame.Name == transportedFrameName {
+			markerIdx = i
+			if !strings.HasSuffix(frame.File, "handoff_test.go") || frame.Line == 0 {
+				t.Errorf("expected marker to point to hand-off site, got %s:%d", frame.File, frame.Line)
+			}
+		}
+	}
+	if markerIdx <= 0 || markerIdx == len(stack)-1 {
+		t.Fatalf("expected marker frame between receiver and sender frames, got %d in %v", markerIdx, stack)
+	}
+	if !stack[:markerIdx].ContainsFunction("TestHandoffReceive") {
+		t.Errorf("expected receiver frames before marker, got %v", stack[:markerIdx])
+	}
+	if !stack[markerIdx+1:].ContainsFunction("func1") {
+		t.Errorf("expected sender frames after marker, got %v", stack[markerIdx+1:])
+	}
+}
+
+func TestReceive_StandardError(t *testing.T) {
+	base := errors.New("plain")
+	err := Receive(base)
+	if err.Error() != "plain" {
+		t.Errorf("unexpected message: %q", err.Error())
+	}
+	if !errors.Is(err, base) {
+		t.Error("expected original error to be preserved")
+	}
+	for _, frame := range err.Stack() {
+		if frame.Name == transportedFrameName {
+			t.Error("expected no marker frame without hand-off")
+		}
+	}
+}
+
+func TestGoroutineID(t *testing.T) {
+	if goroutineID() == 0 {
+		t.Error("expected non-zero goroutine ID")
+	}
+}
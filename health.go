@@ -0,0 +1,199 @@
+package erro
+
+import (
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Defaults of [HealthConfig].
+const (
+	DefaultHealthWindow    = 30 * time.Second
+	DefaultHealthThreshold = 3
+	DefaultHealthErrors    = 5
+)
+
+// Statuses of [HealthStatus].
+const (
+	HealthOK   = "ok"
+	HealthFail = "fail"
+)
+
+// HealthConfig configures a [HealthTracker].
+type HealthConfig struct {
+	// Window is the time window in which persistent errors are counted.
+	// Default is [DefaultHealthWindow].
+	Window time.Duration
+	// Threshold is the number of persistent errors within Window that mark the service
+	// as not ready. Default is [DefaultHealthThreshold].
+	Threshold int
+	// Persistent reports whether an error means the service cannot serve requests.
+	// Default matches errors with [ClassUnavailable] or [CategoryDatabase] that are
+	// not retryable, so transient failures don't flap the readiness.
+	Persistent func(err Error) bool
+	// Fatal reports whether an error means the process must be restarted: the first
+	// such error fails the liveness probe for good. Default never fails it.
+	Fatal func(err Error) bool
+	// MaxErrors is the number of the latest errors in the health responses.
+	// Default is [DefaultHealthErrors].
+	MaxErrors int
+}
+
+// HealthStatus is the JSON body of the handlers of a [HealthTracker].
+type HealthStatus struct {
+	Status string        `json:"status"`
+	Errors []HealthError `json:"errors,omitempty"`
+}
+
+// HealthError is a summary of an error recorded by a [HealthTracker].
+type HealthError struct {
+	ID         string        `json:"id,omitempty"`
+	Class      ErrorClass    `json:"class,omitempty"`
+	Category   ErrorCategory `json:"category,omitempty"`
+	Severity   ErrorSeverity `json:"severity,omitempty"`
+	Message    string        `json:"message,omitempty"`
+	Created    time.Time     `json:"created,omitempty"`
+	Persistent bool          `json:"persistent,omitempty"`
+}
+
+// HealthTracker maps classified errors to the semantics of Kubernetes probes: it
+// records errors as [ErrorMetrics], marks the service as not ready while persistent
+// errors keep occurring and serves liveness and readiness endpoints with the latest
+// error summaries. It is safe for concurrent use.
+type HealthTracker struct {
+	cfg HealthConfig
+
+	mu         sync.Mutex
+	persistent []time.Time // Times of the latest persistent errors, at most Threshold
+	fatal      bool
+	latest     []HealthError
+}
+
+// NewHealthTracker creates a new [HealthTracker].
+//
+// Example:
+//
+//	health := erro.NewHealthTracker(erro.HealthConfig{})
+//	mux.Handle("/healthz", health.LivenessHandler())
+//	mux.Handle("/readyz", health.ReadinessHandler())
+//
+//	erro.Configure(erro.WithAutoMetrics(health, erro.MinSeverity(erro.SeverityMedium)))
+func NewHealthTracker(cfg HealthConfig) *HealthTracker {
+	if cfg.Window <= 0 {
+		cfg.Window = DefaultHealthWindow
+	}
+	if cfg.Threshold <= 0 {
+		cfg.Threshold = DefaultHealthThreshold
+	}
+	if cfg.MaxErrors <= 0 {
+		cfg.MaxErrors = DefaultHealthErrors
+	}
+	if cfg.Persistent == nil {
+		cfg.Persistent = func(err Error) bool {
+			return (err.Class() == ClassUnavailable || err.Category() == CategoryDatabase) && !err.IsRetryable()
+		}
+	}
+	if cfg.Fatal == nil {
+		cfg.Fatal = func(Error) bool { return false }
+	}
+	return &HealthTracker{cfg: cfg}
+}
+
+// RecordError implements [ErrorMetrics]: it records the error in the health of the service.
+func (h *HealthTracker) RecordError(err Error) {
+	if err == nil {
+		return
+	}
+	persistent := h.cfg.Persistent(err)
+	fatal := h.cfg.Fatal(err)
+	summary := HealthError{
+		ID:         err.ID(),
+		Class:      err.Class(),
+		Category:   err.Category(),
+		Severity:   err.Severity(),
+		Message:    err.Message(),
+		Created:    err.Created(),
+		Persistent: persistent,
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if persistent {
+		if len(h.persistent) == h.cfg.Threshold {
+			h.persistent = h.persistent[1:]
+		}
+		h.persistent = append(h.persistent, now())
+	}
+	h.fatal = h.fatal || fatal
+	if len(h.latest) == h.cfg.MaxErrors {
+		h.latest = h.latest[1:]
+	}
+	h.latest = append(h.latest, summary)
+}
+
+// Ready reports whether fewer than Threshold persistent errors occurred within the window.
+func (h *HealthTracker) Ready() bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.ready(now())
+}
+
+// Live reports whether no fatal error was recorded.
+func (h *HealthTracker) Live() bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return !h.fatal
+}
+
+// ReadinessHandler returns a handler for the readiness probe: 200 OK while the service
+// is ready and 503 Service Unavailable otherwise, with a [HealthStatus] body.
+func (h *HealthTracker) ReadinessHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		h.mu.Lock()
+		ok := h.ready(now())
+		status := h.status(ok)
+		h.mu.Unlock()
+		writeHealth(w, status)
+	})
+}
+
+// LivenessHandler returns a handler for the liveness probe: 200 OK until a fatal error
+// is recorded and 503 Service Unavailable after it, with a [HealthStatus] body.
+func (h *HealthTracker) LivenessHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		h.mu.Lock()
+		status := h.status(!h.fatal)
+		h.mu.Unlock()
+		writeHealth(w, status)
+	})
+}
+
+func (h *HealthTracker) ready(t time.Time) bool {
+	return len(h.persistent) < h.cfg.Threshold || t.Sub(h.persistent[0]) >= h.cfg.Window
+}
+
+func (h *HealthTracker) status(ok bool) HealthStatus {
+	status := HealthStatus{Status: HealthOK}
+	if !ok {
+		status.Status = HealthFail
+	}
+	if len(h.latest) > 0 {
+		status.Errors = make([]HealthError, 0, len(h.latest))
+		for i := len(h.latest) - 1; i >= 0; i-- {
+			status.Errors = append(status.Errors, h.latest[i])
+		}
+	}
+	return status
+}
+
+func writeHealth(w http.ResponseWriter, status HealthStatus) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	if status.Status == HealthOK {
+		w.WriteHeader(http.StatusOK)
+	} else {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	_ = json.NewEncoder(w).Encode(status)
+}
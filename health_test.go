@@ -0,0 +1,64 @@
+package erro
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestHealthTracker(t *testing.T) {
+	current := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	SetClock(func() time.Time { return current })
+	defer SetClock(nil)
+
+	health := NewHealthTracker(HealthConfig{
+		Threshold: 2,
+		Window:    10 * time.Second,
+		Fatal:     func(err Error) bool { return err.Class() == ClassDataLoss },
+	})
+	probe := func(h http.Handler) (int, HealthStatus) {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+		var status HealthStatus
+		if err := json.Unmarshal(rec.Body.Bytes(), &status); err != nil {
+			t.Fatalf("decode health status: %v", err)
+		}
+		return rec.Code, status
+	}
+
+	for i := 0; i < 5; i++ {
+		New("db busy", CategoryDatabase, Retryable(), RecordMetrics(health))
+	}
+	New("db down", CategoryDatabase, RecordMetrics(health))
+	if !health.Ready() {
+		t.Fatal("expected retryable errors and a single persistent error not to fail readiness")
+	}
+
+	current = current.Add(time.Second)
+	New("cache unavailable", ClassUnavailable, RecordMetrics(health))
+	code, status := probe(health.ReadinessHandler())
+	if code != http.StatusServiceUnavailable || status.Status != HealthFail {
+		t.Fatalf("expected readiness to fail, got %d %+v", code, status)
+	}
+	if len(status.Errors) != DefaultHealthErrors || status.Errors[0].Message != "cache unavailable" || !status.Errors[0].Persistent {
+		t.Errorf("expected latest errors first, got %+v", status.Errors)
+	}
+	if code, _ := probe(health.LivenessHandler()); code != http.StatusOK {
+		t.Errorf("expected liveness to pass, got %d", code)
+	}
+
+	current = current.Add(10 * time.Second)
+	if code, status := probe(health.ReadinessHandler()); code != http.StatusOK || status.Status != HealthOK {
+		t.Errorf("expected readiness to recover after the window, got %d %+v", code, status)
+	}
+
+	New("rows lost", ClassDataLoss, RecordMetrics(health))
+	if health.Live() {
+		t.Error("expected fatal error to fail liveness")
+	}
+	if code, _ := probe(health.LivenessHandler()); code != http.StatusServiceUnavailable {
+		t.Errorf("expected liveness to fail, got %d", code)
+	}
+}
@@ -0,0 +1,189 @@
+package erro
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// ProblemDetailsContentType is the media type of [ProblemDetails] responses (RFC 9457).
+const ProblemDetailsContentType = "application/problem+json"
+
+// Headers set by [WriteHTTPError] and read by [RequestID].
+const (
+	ErrorIDHeader   = "X-Error-ID"
+	TraceIDHeader   = "X-Trace-ID"
+	RequestIDHeader = "X-Request-ID"
+)
+
+// RequestIDField is the field key used by [RequestID].
+const RequestIDField = "request_id"
+
+// ProblemDetails is an RFC 9457 (formerly RFC 7807) representation of an error
+// for HTTP API responses, extended with error metadata.
+type ProblemDetails struct {
+	Type     string `json:"type,omitempty"`
+	Title    string `json:"title,omitempty"`
+	Status   int    `json:"status,omitempty"`
+	Detail   string `json:"detail,omitempty"`
+	Instance string `json:"instance,omitempty"`
+	Hint     string `json:"hint,omitempty"`
+
+	// TechnicalDetail is the error message when Detail is the [UserMsg] of the error,
+	// set only with [WithBilingual].
+	TechnicalDetail string `json:"technical_detail,omitempty"`
+
+	ID         string        `json:"id,omitempty"`
+	TraceID    string        `json:"trace_id,omitempty"`
+	RequestID  string        `json:"request_id,omitempty"`
+	Class      ErrorClass    `json:"class,omitempty"`
+	Category   ErrorCategory `json:"category,omitempty"`
+	Retryable  bool          `json:"retryable,omitempty"`
+	RetryAfter int           `json:"retry_after,omitempty"` // Seconds, matches the Retry-After header
+
+	// Errors are the field errors of [ValidationErrors].
+	Errors []FieldError `json:"errors,omitempty"`
+}
+
+// ToProblemDetails converts an error to [ProblemDetails].
+//
+// The status is taken from [HTTPCode] and the detail is the error message, so
+// consider calling [Sanitize] first for errors returned to external clients.
+// If the error has a [UserMsg], it is the detail instead and the error message is
+// added as the technical detail only with [WithBilingual].
+// The type is the [DocURL] of the error if it is set. The trace ID is taken from the span of the error and the request ID from the
+// [RequestIDField] field, both left empty if absent. Note that [Sanitize] drops
+// the span, so keep [RequestIDField] in [SanitizePolicy.AllowedFields] to correlate
+// sanitized errors. It returns an empty [ProblemDetails] for nil.
+//
+// Example:
+//
+//	pd := erro.ToProblemDetails(erro.Sanitize(err, policy))
+func ToProblemDetails(err error, optFuncs ...JSONOption) ProblemDetails {
+	if err == nil {
+		return ProblemDetails{}
+	}
+	var opts JSONOptions
+	for _, opt := range optFuncs {
+		opt(&opts)
+	}
+	status := HTTPCode(err)
+	pd := ProblemDetails{
+		Type:   "about:blank",
+		Title:  http.StatusText(status),
+		Status: status,
+	}
+
+	e := ExtractError(err)
+	pd.Detail = e.Message()
+	if userMessage := UserMessageOf(e); userMessage != "" {
+		pd.Detail = userMessage
+		if opts.Bilingual {
+			pd.TechnicalDetail = e.Message()
+		}
+	}
+	pd.Hint = HintOf(e)
+	if docURL := DocURLOf(e); docURL != "" {
+		pd.Type = docURL
+	}
+	pd.ID = e.ID()
+	pd.Class = e.Class()
+	pd.Category = e.Category()
+	pd.Retryable = e.IsRetryable()
+	if span := e.Span(); span != nil {
+		pd.TraceID = span.TraceID()
+	}
+	fields := e.AllFields()
+	pd.RequestID = requestIDFromFields(fields)
+	pd.Errors = fieldErrorsFromFields(fields)
+	if retryAfter, ok := e.RetryAfter(); ok {
+		pd.RetryAfter = retryAfterSeconds(retryAfter)
+	}
+	return pd
+}
+
+// WriteHTTPError writes the error to the response as [ProblemDetails] JSON with
+// the status code from [HTTPCode]. The error ID, trace ID and request ID are set
+// in the [ErrorIDHeader], [TraceIDHeader] and [RequestIDHeader] headers if present.
+// If the error carries a [RetryAfter] delay, the Retry-After header is set.
+// Options are passed to [ToProblemDetails]. It does nothing for nil errors.
+//
+// Example:
+//
+//	func handler(w http.ResponseWriter, r *http.Request) {
+//	    if err := process(r); err != nil {
+//	        erro.WriteHTTPError(w, err)
+//	        return
+//	    }
+//	}
+func WriteHTTPError(w http.ResponseWriter, err error, optFuncs ...JSONOption) {
+	if err == nil || w == nil {
+		return
+	}
+	pd := ToProblemDetails(err, optFuncs...)
+
+	header := w.Header()
+	header.Set("Content-Type", ProblemDetailsContentType)
+	if pd.ID != "" {
+		header.Set(ErrorIDHeader, pd.ID)
+	}
+	if pd.TraceID != "" {
+		header.Set(TraceIDHeader, pd.TraceID)
+	}
+	if pd.RequestID != "" {
+		header.Set(RequestIDHeader, pd.RequestID)
+	}
+	if pd.RetryAfter > 0 {
+		header.Set("Retry-After", strconv.Itoa(pd.RetryAfter))
+	}
+	w.WriteHeader(pd.Status)
+	_ = json.NewEncoder(w).Encode(pd)
+}
+
+// RequestID adds the X-Request-ID header of the incoming request to the error
+// as the [RequestIDField] field, so the error can be correlated with request logs.
+// [ToProblemDetails] and [WriteHTTPError] return it to the client. It adds nothing
+// if the request is nil or has no request ID.
+//
+// Example:
+//
+//	err := erro.Wrap(err, "failed to create order", erro.RequestID(r))
+func RequestID(r *http.Request) errorFields {
+	return func() []any {
+		if r == nil {
+			return nil
+		}
+		id := r.Header.Get(RequestIDHeader)
+		if id == "" {
+			return nil
+		}
+		return []any{RequestIDField, id}
+	}
+}
+
+// requestIDFromFields returns the outermost non-redacted [RequestIDField] value.
+func requestIDFromFields(fields []any) string {
+	for i := 0; i+1 < len(fields); i += 2 {
+		if valueToString(fields[i]) != RequestIDField {
+			continue
+		}
+		value := resolveSensitive(fields[i+1], SensitivityPublic)
+		if _, ok := value.(RedactedValue); ok {
+			continue
+		}
+		if id := valueToString(value); id != "" && id != redacted() {
+			return id
+		}
+	}
+	return ""
+}
+
+// retryAfterSeconds rounds the delay up to whole seconds as required by the Retry-After header.
+func retryAfterSeconds(d time.Duration) int {
+	seconds := int(d / time.Second)
+	if d%time.Second != 0 {
+		seconds++
+	}
+	return seconds
+}
@@ -0,0 +1,108 @@
+package erro
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestToProblemDetails(t *testing.T) {
+	if pd := ToProblemDetails(nil); pd.Status != 0 {
+		t.Errorf("expected empty problem details for nil, got %+v", pd)
+	}
+
+	err := New("too many requests", ClassRateLimited, ID("rl-1"), Retryable(), RetryAfter(1500*time.Millisecond))
+	pd := ToProblemDetails(err)
+	if pd.Status != http.StatusTooManyRequests || pd.Title != "Too Many Requests" {
+		t.Errorf("unexpected status: %d %s", pd.Status, pd.Title)
+	}
+	if pd.Detail != "too many requests" || pd.ID != "rl-1" || pd.Class != ClassRateLimited || !pd.Retryable {
+		t.Errorf("unexpected problem details: %+v", pd)
+	}
+	if pd.RetryAfter != 2 {
+		t.Errorf("expected retry after to be rounded up to 2 seconds, got %d", pd.RetryAfter)
+	}
+
+	pd = ToProblemDetails(errors.New("plain"))
+	if pd.Status != http.StatusInternalServerError || pd.Detail != "plain" {
+		t.Errorf("unexpected problem details for standard error: %+v", pd)
+	}
+}
+
+func TestWriteHTTPError(t *testing.T) {
+	rec := httptest.NewRecorder()
+	WriteHTTPError(rec, nil)
+	if rec.Body.Len() != 0 {
+		t.Error("expected nothing to be written for nil error")
+	}
+
+	rec = httptest.NewRecorder()
+	WriteHTTPError(rec, New("maintenance", ClassUnavailable, RetryAfter(30*time.Second)))
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("unexpected status: %d", rec.Code)
+	}
+	if rec.Header().Get("Retry-After") != "30" {
+		t.Errorf("unexpected Retry-After header: %q", rec.Header().Get("Retry-After"))
+	}
+	if rec.Header().Get("Content-Type") != ProblemDetailsContentType {
+		t.Errorf("unexpected content type: %q", rec.Header().Get("Content-Type"))
+	}
+	var pd ProblemDetails
+	if err := json.Unmarshal(rec.Body.Bytes(), &pd); err != nil {
+		t.Fatalf("failed to decode body: %v", err)
+	}
+	if pd.RetryAfter != 30 || pd.Detail != "maintenance" {
+		t.Errorf("unexpected body: %+v", pd)
+	}
+}
+
+func TestWriteHTTPErrorCorrelation(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/orders", nil)
+	req.Header.Set(RequestIDHeader, "req-42")
+
+	span := &mockTraceSpan{traceID: "trace-1", spanID: "span-1"}
+	err := Wrap(New("not found", ClassNotFound, ID("err-1"), RecordSpan(span)), "get order", RequestID(req))
+
+	rec := httptest.NewRecorder()
+	WriteHTTPError(rec, err)
+	if rec.Header().Get(ErrorIDHeader) == "" {
+		t.Error("expected error ID header")
+	}
+	if rec.Header().Get(TraceIDHeader) != span.TraceID() {
+		t.Errorf("unexpected trace ID header: %q", rec.Header().Get(TraceIDHeader))
+	}
+	if rec.Header().Get(RequestIDHeader) != "req-42" {
+		t.Errorf("unexpected request ID header: %q", rec.Header().Get(RequestIDHeader))
+	}
+
+	var pd ProblemDetails
+	if err := json.Unmarshal(rec.Body.Bytes(), &pd); err != nil {
+		t.Fatalf("failed to decode body: %v", err)
+	}
+	if pd.ID != err.ID() || pd.TraceID != span.TraceID() || pd.RequestID != "req-42" {
+		t.Errorf("unexpected body: %+v", pd)
+	}
+
+	if fields := LogFieldsMap(err, WithUserFields()); fields[RequestIDField] != "req-42" {
+		t.Errorf("expected request ID in log fields, got %v", fields)
+	}
+
+	sanitized := Sanitize(err, SanitizePolicy{})
+	if pd := ToProblemDetails(sanitized); pd.RequestID != "" || pd.TraceID != "" {
+		t.Errorf("expected redacted request ID to be dropped, got %+v", pd)
+	}
+	sanitized = Sanitize(err, SanitizePolicy{AllowedFields: []string{RequestIDField}})
+	if pd := ToProblemDetails(sanitized); pd.RequestID != "req-42" {
+		t.Errorf("expected allowed request ID to be kept, got %+v", pd)
+	}
+
+	if fields := RequestID(httptest.NewRequest(http.MethodGet, "/", nil))(); len(fields) != 0 {
+		t.Errorf("expected no fields without header, got %v", fields)
+	}
+	if fields := RequestID(nil)(); len(fields) != 0 {
+		t.Errorf("expected no fields for nil request, got %v", fields)
+	}
+}
@@ -0,0 +1,52 @@
+package erro
+
+import (
+	"math/rand"
+	"sync"
+)
+
+// idSource is the generator of error IDs set with [SeedIDs].
+type idSource struct {
+	mu  sync.Mutex
+	rnd *rand.Rand
+}
+
+var seededIDs atomicValue[*idSource]
+
+// SeedIDs makes the IDs of new errors ([Error.ID]) a pseudo-random sequence determined
+// by the seed, so tests that create errors in the same order get the same IDs on every
+// run and golden files can contain them. Call [ResetIDs] to restore the default
+// time-based IDs, or use errtest.SeedIDs to reset them when the test finishes.
+//
+// Seeded IDs are not unique across processes, so it is a test hook like [InjectFault]:
+// it panics unless the binary is built with the errotest build tag or
+// [EnableFaultInjection] was called.
+//
+// Example:
+//
+//	// go test -tags errotest ./...
+//	erro.SeedIDs(42)
+//	defer erro.ResetIDs()
+func SeedIDs(seed int64) {
+	if !testHooksEnabled() {
+		panic("erro: seeded IDs are disabled, build with -tags errotest or call EnableFaultInjection")
+	}
+	seededIDs.Store(&idSource{rnd: rand.New(rand.NewSource(seed))})
+}
+
+// ResetIDs restores the default IDs of errors after [SeedIDs].
+func ResetIDs() {
+	seededIDs.Store(nil)
+}
+
+// seededID returns the next ID of the generator set with [SeedIDs].
+func seededID() (string, bool) {
+	src := seededIDs.Load()
+	if src == nil {
+		return "", false
+	}
+	src.mu.Lock()
+	n := src.rnd.Uint64()
+	src.mu.Unlock()
+	return encodeCompact(n), true
+}
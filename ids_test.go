@@ -0,0 +1,47 @@
+package erro
+
+import "testing"
+
+func TestSeedIDs(t *testing.T) {
+	enabled := faultsEnabled.Load()
+	defer faultsEnabled.Store(enabled)
+	if !faultsByBuildTag {
+		faultsEnabled.Store(false)
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Error("expected SeedIDs to panic without enabled test hooks")
+				}
+			}()
+			SeedIDs(42)
+		}()
+	}
+	EnableFaultInjection()
+	defer ResetIDs()
+
+	ids := func() []string {
+		SeedIDs(42)
+		return []string{New("first").ID(), Wrap(New("base"), "second").ID(), New("third").ID()}
+	}
+	first, second := ids(), ids()
+	for i := range first {
+		if first[i] == "" || first[i] != second[i] {
+			t.Errorf("expected reproducible ID %d, got %q and %q", i, first[i], second[i])
+		}
+	}
+	if first[0] == first[1] {
+		t.Errorf("expected distinct seeded IDs, got %q", first[0])
+	}
+
+	SeedIDs(7)
+	if id := New("first").ID(); id == first[0] {
+		t.Errorf("expected another sequence for another seed, got %q", id)
+	}
+
+	ResetIDs()
+	SeedIDs(42)
+	ResetIDs()
+	if id := New("first").ID(); id == first[0] {
+		t.Errorf("expected default IDs after reset, got %q", id)
+	}
+}
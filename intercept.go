@@ -0,0 +1,116 @@
+package erro
+
+import "sync"
+
+// Interceptor is called with every error created with [New], [Wrap] and the other
+// constructors of the package, see [WithInterceptor]. It returns the error to use
+// instead, usually a copy with more fields made with [Enrich]. Returning nil or an
+// error not created by the package keeps the error.
+type Interceptor func(e Error) Error
+
+var (
+	interceptors   atomicValue[[]Interceptor]
+	interceptorsMu sync.Mutex // Serializes updates of interceptors
+)
+
+// Enrich returns a copy of the outermost layer of the error with the fields and the
+// class, category, severity and other options applied, without adding a layer. It is
+// intended for interceptors, see [WithInterceptor]. Options that record or send the
+// error, such as [RecordMetrics], are ignored. An error that is not created by the
+// package is wrapped with an empty message without calling the interceptors again.
+// It returns nil if the error is nil.
+//
+// Example:
+//
+//	erro.Configure(erro.WithInterceptor(func(e erro.Error) erro.Error {
+//	    return erro.Enrich(e, "region", region, "pod", podName)
+//	}))
+func Enrich(err error, meta ...any) Error {
+	if err == nil {
+		return nil
+	}
+	e, ok := err.(*baseError)
+	if !ok {
+		return newWrapError(err, "", append([]any{SkipInterceptors()}, meta...)...)
+	}
+
+	c := e.copyLayer()
+	var fields []any
+	for _, f := range expandProfiles(meta) {
+		if isNilOption(f) {
+			continue
+		}
+		switch val := f.(type) {
+		case errorOpt:
+			val(c)
+		case errorFields:
+			fields = append(fields, val()...)
+		case ErrorClass:
+			c.class = val
+		case ErrorCategory:
+			c.category = val
+		case ErrorSeverity:
+			c.severity = val
+		case errorWork, spanWork, callerSkip, deadlineContext, messageFormat:
+			continue
+		default:
+			fields = append(fields, val)
+		}
+	}
+	if len(fields)%2 != 0 {
+		fields = append(fields, missingField())
+	}
+	c.fields = append(c.fields, fields...)
+	if len(c.fields) > maxPairsCount {
+		c.fields = c.fields[:maxPairsCount]
+	}
+	c.intercepting = false // Set by [SkipInterceptors], meaningful only for new errors
+	return c
+}
+
+// SkipInterceptors creates the error without calling the interceptors added with
+// [WithInterceptor]. Pass it to [New] or [Wrap] in an interceptor to create an error
+// there without calling the interceptor again. Wrapping the error later is intercepted
+// as usual.
+//
+// Example:
+//
+//	erro.Configure(erro.WithInterceptor(func(e erro.Error) erro.Error {
+//	    if e.Class() == "" {
+//	        return erro.New("unclassified error", erro.SkipInterceptors(), "cause", e.Error())
+//	    }
+//	    return nil
+//	}))
+func SkipInterceptors() errorOpt {
+	return func(err *baseError) {
+		err.intercepting = true
+	}
+}
+
+// intercept runs the interceptors on a new error and returns the error to use.
+// Errors wrapping the error being intercepted, e.g. with [Error.Wrap] in an
+// interceptor, and errors created with [SkipInterceptors] are not intercepted.
+func intercept(e *baseError) *baseError {
+	if e.intercepting {
+		e.intercepting = false
+		return e
+	}
+	fns := interceptors.Load()
+	if len(fns) == 0 || (e.wrappedErr != nil && e.wrappedErr.intercepting) {
+		return e
+	}
+	e.intercepting = true
+	for _, fn := range fns {
+		out, ok := fn(e).(*baseError)
+		if !ok || out == nil || out == e {
+			continue
+		}
+		// The returned error may be shared, e.g. a cached error, so mark a copy
+		// to keep the next interceptors from intercepting its wraps again
+		e.intercepting = false
+		e = out.copyLayer()
+		e.intercepting = true
+	}
+	e.intercepting = false
+	return e
+}
@@ -0,0 +1,129 @@
+package erro
+
+import (
+	"errors"
+	"sync"
+	"testing"
+)
+
+func TestWithInterceptor(t *testing.T) {
+	Configure(
+		WithInterceptor(func(e Error) Error { return Enrich(e, "region", "eu-west-1") }),
+		WithInterceptor(func(e Error) Error {
+			if e.Class() == "" {
+				return Enrich(e, ClassInternal, "pod", "api-0")
+			}
+			return nil
+		}),
+	)
+	defer Configure(WithInterceptor(nil))
+
+	err := New("disk full", "path", "/data")
+	if err.Error() != "disk full path=/data region=eu-west-1 pod=api-0" || err.Class() != ClassInternal {
+		t.Errorf("unexpected intercepted error: %q %q", err.Error(), err.Class())
+	}
+
+	wrapped := Wrap(err, "save", ClassUnavailable)
+	if fields := wrapped.Fields(); len(fields) != 2 || fields[1] != "eu-west-1" {
+		t.Errorf("expected interceptors in order on wrap, got %v", fields)
+	}
+	if !errors.Is(wrapped, err) {
+		t.Error("expected the chain to be kept")
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if v, _ := Field[string](New("concurrent"), "region"); v != "eu-west-1" {
+				t.Errorf("expected interceptor to run concurrently, got %q", v)
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestWithInterceptor_Wrap(t *testing.T) {
+	calls := 0
+	Configure(WithInterceptor(func(e Error) Error {
+		calls++
+		return e.Wrap("intercepted")
+	}))
+	defer Configure(WithInterceptor(nil))
+
+	err := New("root")
+	if err.Error() != "intercepted: root" || calls != 1 {
+		t.Errorf("expected a single interceptor call, got %q after %d calls", err.Error(), calls)
+	}
+}
+
+func TestWithInterceptor_Reentrant(t *testing.T) {
+	calls := 0
+	Configure(WithInterceptor(func(e Error) Error {
+		calls++
+		if e.Class() == "" {
+			return New("replaced", SkipInterceptors(), ClassInternal, "cause", e.Message())
+		}
+		return Enrich(errors.New("foreign"), "pod", "api-0")
+	}))
+	defer Configure(WithInterceptor(nil))
+
+	err := New("root")
+	if err.Error() != "replaced cause=root" || err.Class() != ClassInternal || calls != 1 {
+		t.Errorf("expected a single interceptor call, got %q after %d calls", err.Error(), calls)
+	}
+
+	calls = 0
+	err = New("classified", ClassValidation)
+	if err.Error() != "error pod=api-0: foreign" || calls != 1 {
+		t.Errorf("expected Enrich on a foreign error not to be intercepted, got %q after %d calls", err.Error(), calls)
+	}
+	_ = Wrap(err, "outer")
+	if calls != 2 {
+		t.Errorf("expected errors wrapping a skipped error to be intercepted, got %d calls", calls)
+	}
+}
+
+func TestWithInterceptor_SharedResult(t *testing.T) {
+	shared := New("shared", "key", "value").(*baseError)
+	Configure(WithInterceptor(func(e Error) Error { return shared }))
+	defer Configure(WithInterceptor(nil))
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := New("replaced"); err.Error() != "shared key=value" {
+				t.Errorf("expected the interceptor result, got %q", err.Error())
+			}
+		}()
+	}
+	wg.Wait()
+	if shared.intercepting {
+		t.Error("expected the shared error not to be marked")
+	}
+	if err := ExtractError(errors.New("plain")); err.Error() != "plain" {
+		t.Errorf("expected inspected errors not to be intercepted, got %q", err.Error())
+	}
+}
+
+func TestEnrich(t *testing.T) {
+	if Enrich(nil, "key", "value") != nil {
+		t.Error("expected nil for nil error")
+	}
+
+	root := Freeze(New("root", "a", 1))
+	enriched := Enrich(root, "b", 2, "c", SeverityHigh, RecordMetrics(nil))
+	if enriched.Error() != "root a=1 b=2 c="+MissingFieldPlaceholder || enriched.Severity() != SeverityHigh {
+		t.Errorf("unexpected enriched error: %q %q", enriched.Error(), enriched.Severity())
+	}
+	if root.Error() != "root a=1" || enriched.ID() != root.ID() {
+		t.Errorf("expected the original error to be kept, got %q", root.Error())
+	}
+	plain := errors.New("plain")
+	if e := Enrich(plain, "key", "value"); !errors.Is(e, plain) || len(e.Fields()) != 2 {
+		t.Errorf("expected plain error to be wrapped with the fields, got %q", e.Error())
+	}
+}
@@ -0,0 +1,78 @@
+//go:build go1.23
+
+package erro
+
+import "iter"
+
+// All returns an iterator over the errors of the list in order.
+// The list must not be modified during iteration; use [SafeList] or [List.Snapshot] for that.
+//
+// Example:
+//
+//	for err := range list.All() {
+//	    log.Println(err)
+//	}
+func (g *List) All() iter.Seq[error] {
+	return func(yield func(error) bool) {
+		for _, err := range g.errors {
+			if !yield(err) {
+				return
+			}
+		}
+	}
+}
+
+// All returns an iterator over the errors of the snapshot in order.
+func (s Snapshot) All() iter.Seq[error] {
+	return func(yield func(error) bool) {
+		for _, err := range s.errors {
+			if !yield(err) {
+				return
+			}
+		}
+	}
+}
+
+// All returns an iterator over the unique errors of the set in order of first
+// occurrence with their occurrence counts, see [List.All] for the semantics.
+//
+// Example:
+//
+//	for err, count := range set.All() {
+//	    log.Printf("%v (x%d)", err, count)
+//	}
+func (s *Set) All() iter.Seq2[error, int] {
+	return func(yield func(error, int) bool) {
+		for _, err := range s.List.errors {
+			if !yield(err, s.count(err)) {
+				return
+			}
+		}
+	}
+}
+
+// All returns an iterator over a snapshot of the errors, so the list can be
+// modified during iteration, also from other goroutines.
+func (sl *SafeList) All() iter.Seq[error] {
+	return sl.Snapshot().All()
+}
+
+// All returns an iterator over a snapshot of the errors with their counts, so the set
+// can be modified during iteration, also from other goroutines.
+func (ss *SafeSet) All() iter.Seq2[error, int] {
+	ss.mu.RLock()
+	errs := append([]Error(nil), ss.set.List.errors...)
+	counts := make([]int, len(errs))
+	for i, err := range errs {
+		counts[i] = ss.set.count(err)
+	}
+	ss.mu.RUnlock()
+
+	return func(yield func(error, int) bool) {
+		for i, err := range errs {
+			if !yield(err, counts[i]) {
+				return
+			}
+		}
+	}
+}
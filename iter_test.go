@@ -0,0 +1,48 @@
+//go:build go1.23
+
+package erro
+
+import "testing"
+
+func TestAll(t *testing.T) {
+	list := NewList().New("a").New("b").New("c")
+	var got []string
+	for err := range list.All() {
+		got = append(got, err.Error())
+		if len(got) == 2 {
+			break
+		}
+	}
+	if len(got) != 2 || got[0] != "a" || got[1] != "b" {
+		t.Errorf("unexpected errors %v", got)
+	}
+
+	set := NewSet().New("a").New("b").New("a")
+	counts := map[string]int{}
+	for err, count := range set.All() {
+		counts[err.Error()] = count
+	}
+	if len(counts) != 2 || counts["a"] != 2 || counts["b"] != 1 {
+		t.Errorf("unexpected counts %v", counts)
+	}
+
+	safe := NewSafeSet()
+	safe.New("a").New("b")
+	n := 0
+	for range safe.All() {
+		safe.New("c")
+		n++
+	}
+	if n != 2 || safe.Len() != 3 {
+		t.Errorf("expected iteration over a snapshot, got %d iterations and %d errors", n, safe.Len())
+	}
+
+	safeList := NewSafeList()
+	safeList.New("a")
+	for range safeList.All() {
+		safeList.New("b")
+	}
+	if safeList.Len() != 2 {
+		t.Errorf("expected 2 errors, got %d", safeList.Len())
+	}
+}
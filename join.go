@@ -0,0 +1,62 @@
+//go:build go1.20
+
+package erro
+
+import (
+	"errors"
+	"reflect"
+)
+
+// joinErrorType is the type of errors returned by [errors.Join].
+var joinErrorType = reflect.TypeOf(errors.Join(errors.New("")))
+
+// ToJoined converts a multi-error of a [List] or [Set] into a plain [errors.Join] error,
+// for libraries that type-switch on the standard library join type. Nested multi-errors
+// are converted too. Other errors are returned as is.
+//
+// Example:
+//
+//	return erro.ToJoined(list.Err())
+func ToJoined(err error) error {
+	errs, ok := multiErrors(err)
+	if !ok {
+		return err
+	}
+	joined := make([]error, len(errs))
+	for i, e := range errs {
+		joined[i] = ToJoined(e)
+	}
+	return errors.Join(joined...)
+}
+
+// FromJoined converts an [errors.Join] error into a multi-error of a [List], see [List.Err].
+// Nested joined errors are converted too. Other errors are returned as is.
+//
+// Example:
+//
+//	err := erro.FromJoined(errors.Join(errA, errB))
+//	erro.LogFields(err)
+func FromJoined(err error) error {
+	if err == nil || reflect.TypeOf(err) != joinErrorType {
+		return err
+	}
+	joined, ok := err.(interface{ Unwrap() []error })
+	if !ok {
+		return err
+	}
+	list := NewList(len(joined.Unwrap()))
+	for _, e := range joined.Unwrap() {
+		list.Add(FromJoined(e))
+	}
+	return list.Err()
+}
+
+func multiErrors(err error) ([]error, bool) {
+	switch m := err.(type) {
+	case *multiError:
+		return m.errors, true
+	case *multiErrorSet:
+		return m.errors, true
+	}
+	return nil, false
+}
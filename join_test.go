@@ -0,0 +1,65 @@
+//go:build go1.20
+
+package erro
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestToJoined(t *testing.T) {
+	if ToJoined(nil) != nil {
+		t.Error("expected nil for nil")
+	}
+	single := New("single")
+	if ToJoined(single) != single {
+		t.Error("expected non multi-error to be returned as is")
+	}
+
+	a, b := New("a"), New("b")
+	inner := NewList().Add(a).Add(b).Err()
+	c := errors.New("c")
+	err := ToJoined(&multiError{errors: []error{inner, c}})
+
+	joined, ok := err.(interface{ Unwrap() []error })
+	if !ok || len(joined.Unwrap()) != 2 {
+		t.Fatalf("expected joined error with 2 elements, got %T", err)
+	}
+	if _, ok := joined.Unwrap()[0].(interface{ Unwrap() []error }); !ok {
+		t.Errorf("expected nested multi-error to be joined, got %T", joined.Unwrap()[0])
+	}
+	if !errors.Is(err, a) || !errors.Is(err, b) || !errors.Is(err, c) {
+		t.Error("expected all errors to be reachable")
+	}
+	if set := ToJoined(NewSet().Add(a).Add(b).Err()); set.Error() != "a\nb" {
+		t.Errorf("unexpected joined set: %q", set.Error())
+	}
+}
+
+func TestFromJoined(t *testing.T) {
+	if FromJoined(nil) != nil {
+		t.Error("expected nil for nil")
+	}
+	plain := errors.New("plain")
+	if FromJoined(plain) != plain {
+		t.Error("expected non joined error to be returned as is")
+	}
+
+	a, b := New("a", "key", "value"), errors.New("b")
+	err := FromJoined(errors.Join(a, errors.Join(b)))
+	m, ok := err.(*multiError)
+	if !ok || len(m.errors) != 2 {
+		t.Fatalf("expected multi-error with 2 elements, got %T %v", err, err)
+	}
+	if m.errors[0] != a || m.errors[1].(Error).Unwrap() != b {
+		t.Errorf("unexpected elements: %v", m.errors)
+	}
+	if !errors.Is(err, a) || !errors.Is(err, b) {
+		t.Error("expected all errors to be reachable")
+	}
+
+	roundTrip := ToJoined(FromJoined(errors.Join(a, b)))
+	if !errors.Is(roundTrip, a) || !errors.Is(roundTrip, b) {
+		t.Error("expected round trip to keep errors")
+	}
+}
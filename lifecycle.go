@@ -0,0 +1,72 @@
+package erro
+
+// LifecycleStage is a stage in the life of an error reported to the hook of [WithLifecycleHook].
+type LifecycleStage string
+
+// Stages of [LifecycleEvent].
+const (
+	// StageCreated is reported when an error is created with [New] or a template.
+	StageCreated LifecycleStage = "created"
+	// StageWrapped is reported when an error is wrapped with [Wrap] or a similar function.
+	StageWrapped LifecycleStage = "wrapped"
+	// StageSerialized is reported when an error is converted with [ErrorToJSON] or json.Marshal.
+	StageSerialized LifecycleStage = "serialized"
+	// StageLogged is reported when an error is logged with [LogError] or a function built on it.
+	StageLogged LifecycleStage = "logged"
+	// StageRecorded is reported when an error is recorded with [RecordMetrics], [RecordSpan]
+	// or [WithAutoMetrics].
+	StageRecorded LifecycleStage = "recorded"
+	// StageDispatched is reported when an error is sent with [SendEvent] or [WithAutoEvents].
+	StageDispatched LifecycleStage = "dispatched"
+)
+
+// LifecycleEvent is a stage in the life of an error, see [WithLifecycleHook].
+type LifecycleEvent struct {
+	Stage    LifecycleStage
+	ID       string
+	Severity ErrorSeverity
+	Err      Error
+}
+
+var lifecycleHook atomicValue[func(event LifecycleEvent)]
+
+// WithLifecycleHook sets a hook called synchronously at every stage in the life of an
+// error: creation, wrapping, serialization, logging, recording and dispatching. Use it
+// to audit that high-severity errors are actually reported somewhere, e.g. by matching
+// created and dispatched events by ID. The hook must be fast and must not create errors
+// itself. Nil removes the hook.
+//
+// Example:
+//
+//	erro.Configure(erro.WithLifecycleHook(func(event erro.LifecycleEvent) {
+//	    if event.Severity.IsCritical() {
+//	        audit.Track(event.ID, string(event.Stage))
+//	    }
+//	}))
+func WithLifecycleHook(hook func(event LifecycleEvent)) ConfigOption {
+	return func() {
+		lifecycleHook.Store(hook)
+	}
+}
+
+// lifecycle reports the stage of the error to the hook of [WithLifecycleHook].
+func lifecycle(stage LifecycleStage, err Error) {
+	hook := lifecycleHook.Load()
+	if hook == nil || err == nil {
+		return
+	}
+	hook(LifecycleEvent{Stage: stage, ID: err.ID(), Severity: err.Severity(), Err: err})
+}
+
+// lifecycleCreated reports the creation or wrapping of a new error. It is not called
+// for the adapter layers built by [ExtractError], which are not new errors.
+func lifecycleCreated(e *baseError) {
+	if lifecycleHook.Load() == nil {
+		return
+	}
+	if e.wrappedErr != nil || e.originalErr != nil {
+		lifecycle(StageWrapped, e)
+		return
+	}
+	lifecycle(StageCreated, e)
+}
@@ -0,0 +1,61 @@
+package erro
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"reflect"
+	"testing"
+)
+
+type eventsRecorder struct{ count int }
+
+func (r *eventsRecorder) SendEvent(context.Context, Error) { r.count++ }
+
+func TestLifecycleHook(t *testing.T) {
+	var stages []LifecycleStage
+	var ids []string
+	Configure(WithLifecycleHook(func(event LifecycleEvent) {
+		stages = append(stages, event.Stage)
+		ids = append(ids, event.ID)
+	}))
+	defer Configure(WithLifecycleHook(nil))
+
+	err := New("payment failed", SeverityCritical, ID("pay-1"), SendEvent(context.Background(), &eventsRecorder{}))
+	wrapped := Wrap(err, "checkout")
+	_, _ = json.Marshal(wrapped)
+	LogError(wrapped, func(string, ...any) {})
+
+	expected := []LifecycleStage{StageCreated, StageDispatched, StageWrapped, StageSerialized, StageLogged}
+	if !reflect.DeepEqual(stages, expected) {
+		t.Errorf("unexpected stages:\n got %v\nwant %v", stages, expected)
+	}
+	for _, id := range ids {
+		if id != "pay-1" {
+			t.Errorf("expected all events to have the error ID, got %v", ids)
+			break
+		}
+	}
+
+	Configure(WithLifecycleHook(nil))
+	stages = nil
+	_ = New("no hook")
+	if len(stages) != 0 {
+		t.Errorf("expected no events without a hook, got %v", stages)
+	}
+}
+
+func TestLifecycleHookSkipsInspection(t *testing.T) {
+	var stages []LifecycleStage
+	Configure(WithLifecycleHook(func(event LifecycleEvent) {
+		stages = append(stages, event.Stage)
+	}))
+	defer Configure(WithLifecycleHook(nil))
+
+	_ = ExtractError(io.EOF)
+	_ = HTTPCode(io.EOF)
+	_ = LogFields(io.EOF)
+	if len(stages) != 0 {
+		t.Errorf("expected no events for inspected errors, got %v", stages)
+	}
+}
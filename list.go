@@ -1,10 +1,17 @@
 package erro
 
 import (
+	"bufio"
+	"bytes"
+	"container/list"
+	"encoding/json"
 	"errors"
+	"io"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
+	"time"
 )
 
 // --- Base Implementation: List ---
@@ -12,7 +19,8 @@ import (
 // List collects multiple errors and provides a fluent API for adding and configuring them.
 // It is not thread-safe. For a thread-safe version, see [SafeList].
 type List struct {
-	errors []Error
+	errors    []Error
+	formatter MultiErrorFormatter
 }
 
 // NewList creates a new error list with an optional initial capacity.
@@ -55,6 +63,8 @@ func (g *List) Wrap(err error, message string, meta ...any) *List {
 // If the list is empty, it returns nil.
 // If the list contains a single error, that error is returned.
 // If the list contains multiple errors, they are combined into a multi-error.
+// The multi-error is serialized to JSON as {"errors": [...]} with the errors in the
+// [ErrorSchema] format; see [MultiErrorEncoder] to stream it.
 func (g *List) Err() error {
 	if len(g.errors) == 0 {
 		return nil
@@ -66,7 +76,7 @@ func (g *List) Err() error {
 	for i, err := range g.errors {
 		errorsCopy[i] = err
 	}
-	return &multiError{errors: errorsCopy}
+	return &multiError{errors: errorsCopy, formatter: g.formatter}
 }
 
 // Remove removes an error at the specified index from the list.
@@ -114,9 +124,33 @@ func (g *List) Clear() *List {
 func (g *List) Copy() *List {
 	clone := NewList(cap(g.errors))
 	clone.errors = append(make([]Error, 0, len(g.errors)), g.errors...)
+	clone.formatter = g.formatter
 	return clone
 }
 
+// Merge appends the errors of the other list to the list.
+func (g *List) Merge(other *List) *List {
+	if other != nil {
+		g.errors = append(g.errors, other.errors...)
+	}
+	return g
+}
+
+// WithFormatter sets the formatter of the message of the multi-error returned by
+// [List.Err]. Nil restores the formatter set with [WithMultiErrorFormatter].
+//
+// Example:
+//
+//	list := erro.NewList().WithFormatter(erro.MultiErrorFormat{
+//	    Header:      "%d validation errors:\n",
+//	    Enumeration: "  %d. ",
+//	    Separator:   "\n",
+//	}.Formatter())
+func (g *List) WithFormatter(f MultiErrorFormatter) *List {
+	g.formatter = f
+	return g
+}
+
 // --- List Accessors ---
 
 // Errors returns a slice of all errors in the list as standard `error` interfaces.
@@ -156,6 +190,158 @@ func (g *List) Last() Error {
 	return g.errors[len(g.errors)-1]
 }
 
+// Range calls f for each error in the list in order until f returns false.
+// The list must not be modified by f; use [List.Snapshot] to iterate while modifying.
+func (g *List) Range(f func(err Error) bool) {
+	for _, err := range g.errors {
+		if !f(err) {
+			return
+		}
+	}
+}
+
+// Iterator returns an iterator over the errors of the list, see [List.Range] for the semantics.
+func (g *List) Iterator() *Iterator {
+	return &Iterator{errors: g.errors, i: -1}
+}
+
+// Snapshot returns an immutable view of the errors currently in the list.
+// Later changes to the list are not visible in the snapshot.
+func (g *List) Snapshot() Snapshot {
+	return Snapshot{errors: append([]Error(nil), g.errors...)}
+}
+
+// WriteTo writes the errors to w as JSON Lines: one serialized error per line in
+// the [ErrorSchema] format. It implements [io.WriterTo]. The errors are encoded one
+// by one, so memory usage doesn't depend on the size of the list.
+//
+// Example:
+//
+//	f, _ := os.Create("errors.jsonl")
+//	defer f.Close()
+//	_, err := list.WriteTo(f)
+func (g *List) WriteTo(w io.Writer) (int64, error) {
+	cw := &countingWriter{w: w}
+	enc := json.NewEncoder(cw)
+	for i, err := range g.errors {
+		if encErr := enc.Encode(err); encErr != nil {
+			return cw.n, Wrap(encErr, "encode error", "index", i)
+		}
+	}
+	return cw.n, nil
+}
+
+// ReadListFrom reads errors written by [List.WriteTo] from r, decoding them one by one
+// with [FromJSON]. Blank lines are skipped. On failure it returns the errors read so far.
+//
+// Example:
+//
+//	f, _ := os.Open("errors.jsonl")
+//	defer f.Close()
+//	list, err := erro.ReadListFrom(f)
+func ReadListFrom(r io.Reader) (*List, error) {
+	result := NewList()
+	dec := json.NewDecoder(r)
+	for i := 0; ; i++ {
+		var raw json.RawMessage
+		if err := dec.Decode(&raw); err != nil {
+			if errors.Is(err, io.EOF) {
+				return result, nil
+			}
+			return result, Wrap(err, "decode error", "index", i)
+		}
+		err, decErr := FromJSON(raw)
+		if decErr != nil {
+			return result, Wrap(decErr, "decode error", "index", i)
+		}
+		result.add(err)
+	}
+}
+
+// countingWriter counts the bytes written to the underlying writer.
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
+
+// --- Read-Only Views ---
+
+// Snapshot is an immutable view of the errors of a collection at the moment it was
+// taken, see [List.Snapshot]. It is safe for concurrent use.
+type Snapshot struct {
+	errors []Error
+}
+
+// Len returns the number of errors in the snapshot.
+func (s Snapshot) Len() int { return len(s.errors) }
+
+// At returns the error at the index, or nil if the index is out of range.
+func (s Snapshot) At(i int) Error {
+	if i < 0 || i >= len(s.errors) {
+		return nil
+	}
+	return s.errors[i]
+}
+
+// Errors returns a copy of the errors in the snapshot as standard `error` interfaces.
+func (s Snapshot) Errors() []error {
+	result := make([]error, len(s.errors))
+	for i, err := range s.errors {
+		result[i] = err
+	}
+	return result
+}
+
+// Range calls f for each error in the snapshot in order until f returns false.
+func (s Snapshot) Range(f func(err Error) bool) {
+	for _, err := range s.errors {
+		if !f(err) {
+			return
+		}
+	}
+}
+
+// Iterator returns an iterator over the errors of the snapshot.
+func (s Snapshot) Iterator() *Iterator {
+	return &Iterator{errors: s.errors, i: -1}
+}
+
+// Iterator iterates over the errors of a collection.
+//
+// Example:
+//
+//	for it := list.Iterator(); it.Next(); {
+//	    log.Println(it.Error())
+//	}
+type Iterator struct {
+	errors []Error
+	i      int
+}
+
+// Next advances the iterator and reports whether there is an error to read.
+func (it *Iterator) Next() bool {
+	if it.i+1 >= len(it.errors) {
+		it.i = len(it.errors)
+		return false
+	}
+	it.i++
+	return true
+}
+
+// Error returns the current error, or nil if [Iterator.Next] wasn't called or returned false.
+func (it *Iterator) Error() Error {
+	if it.i < 0 || it.i >= len(it.errors) {
+		return nil
+	}
+	return it.errors[it.i]
+}
+
 // --- Deduplicating Implementation: Set ---
 
 // Set collects unique errors, deduplicating them based on a configurable key.
@@ -164,6 +350,16 @@ type Set struct {
 	*List
 	seen      map[string]int
 	keyGetter KeyGetterFunc
+
+	// Bounded memory mode, see [Set.WithMaxKeys]
+	maxKeys    int
+	others     int
+	recency    *list.List // keys from most to least recently seen
+	recencyIdx map[string]*list.Element
+
+	// Expiration mode, see [SafeSet.WithTTL]
+	ttl       time.Duration
+	firstSeen map[string]time.Time
 }
 
 // NewSet creates a new error set that stores only unique errors, with an
@@ -183,11 +379,117 @@ func (s *Set) add(err Error) {
 		// Do not add errors that produce an empty key.
 		return
 	}
-	if count, ok := s.seen[key]; ok {
-		s.seen[key] = count + 1
-	} else {
-		s.seen[key] = 1
+	s.addKey(err, key)
+}
+
+// addKey adds the error with the precomputed key and returns the count of the key.
+func (s *Set) addKey(err Error, key string) int {
+	return s.addKeyCount(err, key, 1)
+}
+
+// addKeyCount adds n occurrences of the error with the precomputed key and returns the count of the key.
+func (s *Set) addKeyCount(err Error, key string, n int) int {
+	if s.ttl > 0 && s.expired(key, now()) {
+		s.removeKey(key)
+	}
+	count, ok := s.seen[key]
+	if !ok {
+		if s.maxKeys > 0 && len(s.seen) >= s.maxKeys {
+			s.evictOldest()
+		}
 		s.List.errors = append(s.List.errors, err)
+		if s.ttl > 0 {
+			s.firstSeen[key] = now()
+		}
+	}
+	s.seen[key] = count + n
+	s.touch(key)
+	return count + n
+}
+
+// touch marks the key as the most recently seen one in bounded memory mode.
+func (s *Set) touch(key string) {
+	if s.maxKeys <= 0 {
+		return
+	}
+	if el, ok := s.recencyIdx[key]; ok {
+		s.recency.MoveToFront(el)
+		return
+	}
+	s.recencyIdx[key] = s.recency.PushFront(key)
+}
+
+// evictOldest removes the least recently seen error and moves its count to the others bucket.
+func (s *Set) evictOldest() {
+	el := s.recency.Back()
+	if el == nil {
+		return
+	}
+	key := el.Value.(string)
+	s.others += s.seen[key]
+	s.removeKey(key)
+}
+
+// removeKey removes the error with the key and forgets the key.
+func (s *Set) removeKey(key string) {
+	s.forget(key)
+	for i, e := range s.List.errors {
+		if s.keyGetter(e) == key {
+			s.List.Remove(i)
+			break
+		}
+	}
+}
+
+// setTTL enables expiration of keys after the given time since they were first seen.
+// Keys that are already tracked are considered first seen now.
+func (s *Set) setTTL(ttl time.Duration) {
+	if ttl <= 0 {
+		s.ttl = 0
+		s.firstSeen = nil
+		return
+	}
+	if s.firstSeen == nil {
+		s.firstSeen = make(map[string]time.Time, len(s.seen))
+		t := now()
+		for key := range s.seen {
+			s.firstSeen[key] = t
+		}
+	}
+	s.ttl = ttl
+}
+
+// expired reports whether the tracked key was first seen at least ttl ago.
+func (s *Set) expired(key string, t time.Time) bool {
+	first, ok := s.firstSeen[key]
+	return ok && t.Sub(first) >= s.ttl
+}
+
+// expire removes the expired errors and returns their number.
+func (s *Set) expire() int {
+	if s.ttl <= 0 {
+		return 0
+	}
+	t := now()
+	var expired []string
+	for key := range s.firstSeen {
+		if s.expired(key, t) {
+			expired = append(expired, key)
+		}
+	}
+	for _, key := range expired {
+		s.removeKey(key)
+	}
+	return len(expired)
+}
+
+// forget removes the key from the seen map and the recency list.
+func (s *Set) forget(key string) {
+	delete(s.seen, key)
+	delete(s.firstSeen, key)
+	if el, ok := s.recencyIdx[key]; ok {
+		s.recency.Remove(el)
+		delete(s.recencyIdx, key)
 	}
 }
 
@@ -215,6 +517,12 @@ func (s *Set) Wrap(err error, message string, meta ...any) *Set {
 
 // --- Set Overridden Methods ---
 
+// Merge adds the errors of the other set with their counts, see [Set.Union]. It overrides
+// [List.Merge], which would append the errors without deduplication.
+func (s *Set) Merge(other *Set) *Set {
+	return s.Union(other)
+}
+
 // WithKeyGetter sets the function used to generate deduplication keys for errors.
 func (s *Set) WithKeyGetter(keyGetter KeyGetterFunc) *Set {
 	if keyGetter != nil {
@@ -223,10 +531,114 @@ func (s *Set) WithKeyGetter(keyGetter KeyGetterFunc) *Set {
 	return s
 }
 
+// WithFormatter sets the formatter of the message of the multi-error returned by
+// [Set.Err], see [List.WithFormatter].
+func (s *Set) WithFormatter(f MultiErrorFormatter) *Set {
+	s.List.WithFormatter(f)
+	return s
+}
+
+// WithMaxKeys bounds the number of unique keys tracked by the set.
+//
+// When a new unique error arrives and the limit is reached, the least recently
+// seen error is evicted and its count is added to the others bucket, see [Set.Others].
+// A limit of zero or less disables the bound.
+func (s *Set) WithMaxKeys(maxKeys int) *Set {
+	if maxKeys <= 0 {
+		s.maxKeys = 0
+		s.recency = nil
+		s.recencyIdx = nil
+		return s
+	}
+	s.maxKeys = maxKeys
+	s.recency = list.New()
+	s.recencyIdx = make(map[string]*list.Element, maxKeys)
+	for _, err := range s.List.errors {
+		s.touch(s.keyGetter(err))
+	}
+	for len(s.seen) > s.maxKeys {
+		s.evictOldest()
+	}
+	return s
+}
+
+// Counts returns a copy of the occurrence counts of the tracked errors by their keys.
+func (s *Set) Counts() map[string]int {
+	counts := make(map[string]int, len(s.seen))
+	for k, v := range s.seen {
+		counts[k] = v
+	}
+	return counts
+}
+
+// Others returns the number of occurrences of errors evicted in bounded memory mode.
+func (s *Set) Others() int { return s.others }
+
+// ErrorCount is an error of a [Set] with the number of its occurrences, see [Set.TopN].
+type ErrorCount struct {
+	Err   Error
+	Count int
+}
+
+// TopN returns the n most frequent errors of the set in descending order of counts,
+// errors with equal counts in the order they were added. Zero or less returns all errors.
+//
+// Example:
+//
+//	for _, ec := range set.TopN(5) {
+//	    log.Printf("%5.1f%% %d times: %s", set.PercentOfTotal(ec.Err), ec.Count, ec.Err.Message())
+//	}
+func (s *Set) TopN(n int) []ErrorCount {
+	out := make([]ErrorCount, len(s.List.errors))
+	for i, err := range s.List.errors {
+		out[i] = ErrorCount{Err: err, Count: s.count(err)}
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].Count > out[j].Count })
+	if n > 0 && n < len(out) {
+		out = out[:n]
+	}
+	return out
+}
+
+// Count returns the number of occurrences of errors with the key of err in the set,
+// zero if the set has none.
+func (s *Set) Count(err error) int {
+	if err == nil {
+		return 0
+	}
+	key := s.keyGetter(err)
+	if key == "" {
+		return 0
+	}
+	return s.seen[key]
+}
+
+// Total returns the number of occurrences of all errors added to the set, including
+// the others bucket of evicted errors.
+func (s *Set) Total() int {
+	total := s.others
+	for _, n := range s.seen {
+		total += n
+	}
+	return total
+}
+
+// PercentOfTotal returns the occurrences of errors with the key of err as a percentage
+// of [Set.Total], zero for an empty set.
+func (s *Set) PercentOfTotal(err error) float64 {
+	total := s.Total()
+	if total == 0 {
+		return 0
+	}
+	return float64(s.Count(err)) * 100 / float64(total)
+}
+
 // Err returns a combined error that includes deduplication counts.
 // If the set is empty, it returns nil.
 // If the set contains a single error, that error is returned.
 // If the set contains multiple errors, they are combined into a multi-error.
+// The multi-error is serialized to JSON like the one of [List.Err] with the "counts"
+// of the errors and the number of evicted "others".
 func (s *Set) Err() error {
 	if s.Len() == 0 {
 		return nil
@@ -236,13 +648,22 @@ func (s *Set) Err() error {
 	}
 	errorsCopy := make([]error, s.Len())
 	copy(errorsCopy, s.Errors())
-	return &multiErrorSet{errors: errorsCopy, counter: s.seen, keyGetter: s.keyGetter}
+	return &multiErrorSet{errors: errorsCopy, counter: s.Counts(), keyGetter: s.keyGetter, others: s.others,
+		formatter: s.List.formatter}
 }
 
 // Clear removes all errors and resets the deduplication map.
 func (s *Set) Clear() *Set {
 	s.List.Clear()
 	s.seen = make(map[string]int, cap(s.List.errors))
+	s.others = 0
+	if s.ttl > 0 {
+		s.firstSeen = make(map[string]time.Time)
+	}
+	if s.maxKeys > 0 {
+		s.recency = list.New()
+		s.recencyIdx = make(map[string]*list.Element, s.maxKeys)
+	}
 	return s
 }
 
@@ -254,6 +675,22 @@ func (s *Set) Copy() *Set {
 	for k, v := range s.seen {
 		clone.seen[k] = v
 	}
+	clone.others = s.others
+	if s.ttl > 0 {
+		clone.ttl = s.ttl
+		clone.firstSeen = make(map[string]time.Time, len(s.firstSeen))
+		for k, v := range s.firstSeen {
+			clone.firstSeen[k] = v
+		}
+	}
+	if s.maxKeys > 0 {
+		clone.maxKeys = s.maxKeys
+		clone.recency = list.New()
+		clone.recencyIdx = make(map[string]*list.Element, s.maxKeys)
+		for el := s.recency.Back(); el != nil; el = el.Prev() {
+			clone.touch(el.Value.(string))
+		}
+	}
 	return clone
 }
 
@@ -267,7 +704,7 @@ func (s *Set) Remove(i int) bool {
 	if s.List.Remove(i) {
 		key := s.keyGetter(err)
 		if key != "" {
-			delete(s.seen, key)
+			s.forget(key)
 		}
 		return true
 	}
@@ -292,6 +729,94 @@ func (s *Set) RemoveError(err error) bool {
 	return false
 }
 
+// --- Set Algebra ---
+
+// Union adds the errors of the other set to the set with their counts, as if they were
+// added to it one by one, and adds the others bucket of the other set. Keys are computed
+// with the key getter of the set, so errors of sets with different key getters are
+// matched the same way as errors added directly. Use [Set.Copy] to keep the set unchanged.
+//
+// Example:
+//
+//	total := erro.NewSet()
+//	for _, shard := range shards {
+//	    total.Union(shard)
+//	}
+func (s *Set) Union(other *Set) *Set {
+	if other == nil {
+		return s
+	}
+	if other == s {
+		other = s.Copy()
+	}
+	for _, err := range other.List.errors {
+		key := s.keyGetter(err)
+		if key == "" {
+			continue
+		}
+		s.addKeyCount(err, key, other.count(err))
+	}
+	s.others += other.others
+	return s
+}
+
+// Difference removes the errors whose keys are in the other set, see [Set.Union] for
+// how keys are computed.
+func (s *Set) Difference(other *Set) *Set {
+	if other == nil {
+		return s
+	}
+	if other == s {
+		return s.Clear()
+	}
+	counts := s.countsOf(other)
+	for i := len(s.List.errors) - 1; i >= 0; i-- {
+		if _, ok := counts[s.keyGetter(s.List.errors[i])]; ok {
+			s.Remove(i)
+		}
+	}
+	return s
+}
+
+// Intersect keeps only the errors whose keys are in the other set and adds the counts
+// of the other set to them, so the counts are the occurrences in both sets. See
+// [Set.Union] for how keys are computed.
+func (s *Set) Intersect(other *Set) *Set {
+	if other == nil {
+		return s.Clear()
+	}
+	counts := s.countsOf(other)
+	for i := len(s.List.errors) - 1; i >= 0; i-- {
+		key := s.keyGetter(s.List.errors[i])
+		n, ok := counts[key]
+		if !ok {
+			s.Remove(i)
+			continue
+		}
+		s.seen[key] += n
+	}
+	return s
+}
+
+// count returns the count of the error in the set, at least one.
+func (s *Set) count(err Error) int {
+	if n := s.seen[s.keyGetter(err)]; n > 0 {
+		return n
+	}
+	return 1
+}
+
+// countsOf returns the counts of the errors of the other set by keys of the set.
+func (s *Set) countsOf(other *Set) map[string]int {
+	counts := make(map[string]int, len(other.List.errors))
+	for _, err := range other.List.errors {
+		if key := s.keyGetter(err); key != "" {
+			counts[key] += other.count(err)
+		}
+	}
+	return counts
+}
+
 // --- Thread-Safe Wrapper: SafeList ---
 
 // SafeList is a thread-safe version of [List].
@@ -365,6 +890,26 @@ func (sl *SafeList) Copy() *SafeList {
 	return &SafeList{list: sl.list.Copy()}
 }
 
+// WithFormatter sets the formatter of the multi-error of the list in a thread-safe manner.
+func (sl *SafeList) WithFormatter(f MultiErrorFormatter) *SafeList {
+	sl.mu.Lock()
+	defer sl.mu.Unlock()
+	sl.list.WithFormatter(f)
+	return sl
+}
+
+// Merge appends the errors of the other list in a thread-safe manner, see [List.Merge].
+func (sl *SafeList) Merge(other *SafeList) *SafeList {
+	if other == nil {
+		return sl
+	}
+	errs := other.Errs()
+	sl.mu.Lock()
+	defer sl.mu.Unlock()
+	sl.list.errors = append(sl.list.errors, errs...)
+	return sl
+}
+
 // Errors returns a slice of all errors in the list as standard `error` interfaces in a thread-safe manner.
 func (sl *SafeList) Errors() []error {
 	sl.mu.RLock()
@@ -372,11 +917,30 @@ func (sl *SafeList) Errors() []error {
 	return sl.list.Errors()
 }
 
-// Errs returns a slice of all errors in the list as `erro.Error` interfaces in a thread-safe manner.
+// Errs returns a copy of all errors in the list as `erro.Error` interfaces in a thread-safe manner.
 func (sl *SafeList) Errs() []Error {
 	sl.mu.RLock()
 	defer sl.mu.RUnlock()
-	return sl.list.Errs()
+	return append([]Error(nil), sl.list.Errs()...)
+}
+
+// Range calls f for each error in the list in order until f returns false.
+// It iterates over a snapshot taken under the read lock, so f may modify the list;
+// errors added or removed during the iteration don't affect it.
+func (sl *SafeList) Range(f func(err Error) bool) {
+	sl.Snapshot().Range(f)
+}
+
+// Iterator returns an iterator over a snapshot of the errors, see [SafeList.Range] for the semantics.
+func (sl *SafeList) Iterator() *Iterator {
+	return sl.Snapshot().Iterator()
+}
+
+// Snapshot returns an immutable view of the errors currently in the list in a thread-safe manner.
+func (sl *SafeList) Snapshot() Snapshot {
+	sl.mu.RLock()
+	defer sl.mu.RUnlock()
+	return sl.list.Snapshot()
 }
 
 // Len returns the number of errors in the list in a thread-safe manner.
@@ -414,12 +978,22 @@ func (sl *SafeList) Last() Error {
 	return sl.list.Last()
 }
 
+// WriteTo writes the errors to w as JSON Lines in a thread-safe manner. See [List.WriteTo].
+func (sl *SafeList) WriteTo(w io.Writer) (int64, error) {
+	sl.mu.RLock()
+	defer sl.mu.RUnlock()
+	return sl.list.WriteTo(w)
+}
+
 // --- Thread-Safe Wrapper: SafeSet ---
 
 // SafeSet is a thread-safe version of [Set].
 type SafeSet struct {
 	mu  sync.RWMutex
 	set *Set
+
+	cleanupInterval time.Duration // Interval of the cleanup goroutine of [SafeSet.WithTTL], 0 if disabled
+	janitor         chan struct{} // Closed to stop the running cleanup goroutine, nil if not running
 }
 
 // NewSafeSet creates a new thread-safe error set.
@@ -432,6 +1006,7 @@ func (ss *SafeSet) Add(err error) *SafeSet {
 	ss.mu.Lock()
 	defer ss.mu.Unlock()
 	ss.set.Add(err)
+	ss.startJanitor()
 	return ss
 }
 
@@ -440,6 +1015,7 @@ func (ss *SafeSet) New(message string, meta ...any) *SafeSet {
 	ss.mu.Lock()
 	defer ss.mu.Unlock()
 	ss.set.New(message, meta...)
+	ss.startJanitor()
 	return ss
 }
 
@@ -448,6 +1024,7 @@ func (ss *SafeSet) Wrap(err error, message string, meta ...any) *SafeSet {
 	ss.mu.Lock()
 	defer ss.mu.Unlock()
 	ss.set.Wrap(err, message, meta...)
+	ss.startJanitor()
 	return ss
 }
 
@@ -481,12 +1058,42 @@ func (ss *SafeSet) Clear() *SafeSet {
 }
 
 // Copy returns a shallow copy of the set in a thread-safe manner.
+// The copy keeps the TTL but has no cleanup goroutine.
 func (ss *SafeSet) Copy() *SafeSet {
 	ss.mu.RLock()
 	defer ss.mu.RUnlock()
 	return &SafeSet{set: ss.set.Copy()}
 }
 
+// Union adds the errors of the other set with their counts in a thread-safe manner, see [Set.Union].
+func (ss *SafeSet) Union(other *SafeSet) *SafeSet {
+	return ss.combine(other, (*Set).Union)
+}
+
+// Difference removes the errors whose keys are in the other set in a thread-safe manner, see [Set.Difference].
+func (ss *SafeSet) Difference(other *SafeSet) *SafeSet {
+	return ss.combine(other, (*Set).Difference)
+}
+
+// Intersect keeps only the errors whose keys are in the other set in a thread-safe manner, see [Set.Intersect].
+func (ss *SafeSet) Intersect(other *SafeSet) *SafeSet {
+	return ss.combine(other, (*Set).Intersect)
+}
+
+// combine applies the operation to the set and a copy of the other set, so the two
+// locks are never held at the same time.
+func (ss *SafeSet) combine(other *SafeSet, op func(s, other *Set) *Set) *SafeSet {
+	var otherSet *Set
+	if other != nil {
+		otherSet = other.Copy().set
+	}
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+	op(ss.set, otherSet)
+	ss.startJanitor()
+	return ss
+}
+
 // Errors returns a slice of all errors in the set as standard `error` interfaces in a thread-safe manner.
 func (ss *SafeSet) Errors() []error {
 	ss.mu.RLock()
@@ -494,11 +1101,30 @@ func (ss *SafeSet) Errors() []error {
 	return ss.set.Errors()
 }
 
-// Errs returns a slice of all errors in the set as `erro.Error` interfaces in a thread-safe manner.
+// Errs returns a copy of all errors in the set as `erro.Error` interfaces in a thread-safe manner.
 func (ss *SafeSet) Errs() []Error {
 	ss.mu.RLock()
 	defer ss.mu.RUnlock()
-	return ss.set.Errs()
+	return append([]Error(nil), ss.set.Errs()...)
+}
+
+// Range calls f for each error in the set in order until f returns false.
+// It iterates over a snapshot taken under the read lock, so f may modify the set;
+// errors added or removed during the iteration don't affect it.
+func (ss *SafeSet) Range(f func(err Error) bool) {
+	ss.Snapshot().Range(f)
+}
+
+// Iterator returns an iterator over a snapshot of the errors, see [SafeSet.Range] for the semantics.
+func (ss *SafeSet) Iterator() *Iterator {
+	return ss.Snapshot().Iterator()
+}
+
+// Snapshot returns an immutable view of the errors currently in the set in a thread-safe manner.
+func (ss *SafeSet) Snapshot() Snapshot {
+	ss.mu.RLock()
+	defer ss.mu.RUnlock()
+	return ss.set.Snapshot()
 }
 
 // Len returns the number of errors in the set in a thread-safe manner.
@@ -544,15 +1170,245 @@ func (ss *SafeSet) WithKeyGetter(keyGetter KeyGetterFunc) *SafeSet {
 	return ss
 }
 
+// WithFormatter sets the formatter of the multi-error of the set in a thread-safe manner.
+func (ss *SafeSet) WithFormatter(f MultiErrorFormatter) *SafeSet {
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+	ss.set.WithFormatter(f)
+	return ss
+}
+
+// WithMaxKeys bounds the number of unique keys tracked by the set in a thread-safe manner.
+func (ss *SafeSet) WithMaxKeys(maxKeys int) *SafeSet {
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+	ss.set.WithMaxKeys(maxKeys)
+	return ss
+}
+
+// WithTTL makes errors expire after the given time since they were first seen, so the
+// same error can re-surface (and be re-alerted) after the window. An expired error is
+// replaced when it is added again; expired errors that are not added again are removed
+// by a background goroutine every cleanupInterval (default is the TTL). The goroutine is
+// started when an error is added and exits once the set is empty, so an abandoned set
+// does not keep it running longer than its errors live. Use [SafeSet.Stop] to stop it
+// earlier. A TTL of zero or less disables expiration.
+//
+// Expiration uses the clock set with [SetClock], so it can be tested with a fake clock
+// and [SafeSet.Expire].
+//
+// Example:
+//
+//	alerts := erro.NewSafeSet().WithTTL(10 * time.Minute)
+//	defer alerts.Stop()
+func (ss *SafeSet) WithTTL(ttl time.Duration, cleanupInterval ...time.Duration) *SafeSet {
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+	ss.set.setTTL(ttl)
+	ss.stopJanitor()
+	ss.cleanupInterval = 0
+	if ttl <= 0 {
+		return ss
+	}
+
+	ss.cleanupInterval = ttl
+	if len(cleanupInterval) > 0 && cleanupInterval[0] > 0 {
+		ss.cleanupInterval = cleanupInterval[0]
+	}
+	ss.startJanitor()
+	return ss
+}
+
+// startJanitor starts the cleanup goroutine of [SafeSet.WithTTL] if it is enabled,
+// not running and the set has errors to expire. It must be called with the lock held.
+func (ss *SafeSet) startJanitor() {
+	if ss.cleanupInterval <= 0 || ss.janitor != nil || ss.set.Len() == 0 {
+		return
+	}
+	stop, interval := make(chan struct{}), ss.cleanupInterval
+	ss.janitor = stop
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				if !ss.cleanup(stop) {
+					return
+				}
+			case <-stop:
+				return
+			}
+		}
+	}()
+}
+
+// cleanup removes the expired errors and returns false if the set is empty and the
+// cleanup goroutine identified by stop should exit.
+func (ss *SafeSet) cleanup(stop chan struct{}) bool {
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+	ss.set.expire()
+	if ss.set.Len() > 0 {
+		return true
+	}
+	if ss.janitor == stop {
+		ss.janitor = nil
+	}
+	return false
+}
+
+// Expire removes the errors whose TTL has passed and returns their number. It is called
+// periodically by the goroutine of [SafeSet.WithTTL] and can be called directly.
+func (ss *SafeSet) Expire() int {
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+	return ss.set.expire()
+}
+
+// Stop stops the cleanup goroutine of [SafeSet.WithTTL] and does not start it again until
+// WithTTL is called. Errors still expire when they are added again. It is safe to call
+// Stop multiple times.
+func (ss *SafeSet) Stop() {
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+	ss.stopJanitor()
+	ss.cleanupInterval = 0
+}
+
+func (ss *SafeSet) stopJanitor() {
+	if ss.janitor != nil {
+		close(ss.janitor)
+		ss.janitor = nil
+	}
+}
+
+// Counts returns a copy of the occurrence counts by keys in a thread-safe manner.
+func (ss *SafeSet) Counts() map[string]int {
+	ss.mu.RLock()
+	defer ss.mu.RUnlock()
+	return ss.set.Counts()
+}
+
+// Others returns the number of occurrences of evicted errors in a thread-safe manner.
+func (ss *SafeSet) Others() int {
+	ss.mu.RLock()
+	defer ss.mu.RUnlock()
+	return ss.set.Others()
+}
+
+// TopN returns the n most frequent errors in a thread-safe manner, see [Set.TopN].
+func (ss *SafeSet) TopN(n int) []ErrorCount {
+	ss.mu.RLock()
+	defer ss.mu.RUnlock()
+	return ss.set.TopN(n)
+}
+
+// Count returns the number of occurrences of errors with the key of err in a thread-safe manner.
+func (ss *SafeSet) Count(err error) int {
+	ss.mu.RLock()
+	defer ss.mu.RUnlock()
+	return ss.set.Count(err)
+}
+
+// Total returns the number of occurrences of all errors in a thread-safe manner.
+func (ss *SafeSet) Total() int {
+	ss.mu.RLock()
+	defer ss.mu.RUnlock()
+	return ss.set.Total()
+}
+
+// PercentOfTotal returns the share of errors with the key of err in a thread-safe manner,
+// see [Set.PercentOfTotal].
+func (ss *SafeSet) PercentOfTotal(err error) float64 {
+	ss.mu.RLock()
+	defer ss.mu.RUnlock()
+	return ss.set.PercentOfTotal(err)
+}
+
 // --- Multi-Error Types ---
 
+// MultiErrorFormatter formats the message of an error combining multiple errors, returned
+// by [List.Err], [Set.Err] and [Join]. Counts are the occurrences of the errors in a
+// [Set] and nil for a [List], others is the number of occurrences of errors evicted
+// from a bounded set, see [Set.WithMaxKeys]. Build one with [MultiErrorFormat.Formatter].
+type MultiErrorFormatter func(errs []error, counts []int, others int) string
+
+// MultiErrorFormat describes the message of a multi-error, see [MultiErrorFormat.Formatter].
+type MultiErrorFormat struct {
+	// Header is written before the errors, with %d replaced by the number of errors,
+	// e.g. "multiple errors (%d): ".
+	Header string
+	// Enumeration is written before every error, with %d replaced by its 1-based
+	// index, e.g. "[%d] " or "- ".
+	Enumeration string
+	// Separator is written between errors. Default is "; ".
+	Separator string
+	// ShowCounts appends the number of occurrences to errors of a [Set] seen more than
+	// once, " (3 times)", and the occurrences of evicted errors, "(2 others)".
+	ShowCounts bool
+}
+
+// Formatter returns a [MultiErrorFormatter] of the format.
+func (f MultiErrorFormat) Formatter() MultiErrorFormatter {
+	separator := f.Separator
+	if separator == "" {
+		separator = "; "
+	}
+	return func(errs []error, counts []int, others int) string {
+		var builder strings.Builder
+		builder.WriteString(formatCount(f.Header, len(errs)))
+		for i, err := range errs {
+			if i > 0 {
+				builder.WriteString(separator)
+			}
+			builder.WriteString(formatCount(f.Enumeration, i+1))
+			builder.WriteString(err.Error())
+			if f.ShowCounts && i < len(counts) && counts[i] > 1 {
+				builder.WriteString(" (")
+				builder.WriteString(strconv.Itoa(counts[i]))
+				builder.WriteString(" times)")
+			}
+		}
+		if f.ShowCounts && others > 0 {
+			if len(errs) > 0 {
+				builder.WriteString(separator)
+			}
+			builder.WriteString("(")
+			builder.WriteString(strconv.Itoa(others))
+			builder.WriteString(" others)")
+		}
+		return builder.String()
+	}
+}
+
+// formatCount replaces the first %d of the format with n.
+func formatCount(format string, n int) string {
+	if !strings.Contains(format, "%d") {
+		return format
+	}
+	return strings.Replace(format, "%d", strconv.Itoa(n), 1)
+}
+
+// multiErrorFormatterOf returns the formatter of a multi-error or the configured one.
+func multiErrorFormatterOf(f MultiErrorFormatter) MultiErrorFormatter {
+	if f != nil {
+		return f
+	}
+	return multiErrorFormatter.Load()
+}
+
 // multiError represents multiple errors combined into one.
 // It is compatible with Go 1.20's multi-error unwrapping.
 type multiError struct {
-	errors []error
+	errors    []error
+	formatter MultiErrorFormatter
 }
 
 func (m *multiError) Error() string {
+	if f := multiErrorFormatterOf(m.formatter); f != nil {
+		return f(m.errors, nil, 0)
+	}
 	var builder strings.Builder
 	builder.WriteString("multiple errors (")
 	builder.WriteString(strconv.Itoa(len(m.errors)))
@@ -599,9 +1455,21 @@ type multiErrorSet struct {
 	errors    []error
 	counter   map[string]int
 	keyGetter func(error) string
+	others    int
+	formatter MultiErrorFormatter
 }
 
 func (m *multiErrorSet) Error() string {
+	if f := multiErrorFormatterOf(m.formatter); f != nil {
+		counts := make([]int, len(m.errors))
+		for i, err := range m.errors {
+			counts[i] = m.counter[m.keyGetter(err)]
+			if counts[i] < 1 {
+				counts[i] = 1
+			}
+		}
+		return f(m.errors, counts, m.others)
+	}
 	var builder strings.Builder
 	builder.WriteString("multiple unique errors (")
 	builder.WriteString(strconv.Itoa(len(m.errors)))
@@ -620,6 +1488,11 @@ func (m *multiErrorSet) Error() string {
 			builder.WriteString(" times)")
 		}
 	}
+	if m.others > 0 {
+		builder.WriteString("; (")
+		builder.WriteString(strconv.Itoa(m.others))
+		builder.WriteString(" others)")
+	}
 	return builder.String()
 }
 
@@ -648,6 +1521,129 @@ func (m *multiErrorSet) As(target any) bool {
 	return false
 }
 
+// MultiErrorEncoder is implemented by the multi-errors of [List.Err] and [Set.Err].
+//
+// EncodeJSON writes the same JSON as json.Marshal, encoding the errors one by one with
+// bounded buffering, so memory usage doesn't depend on the number of errors. MarshalJSON
+// uses it for multi-errors with more than [MultiErrorStreamThreshold] errors.
+//
+// Example:
+//
+//	if enc, ok := err.(erro.MultiErrorEncoder); ok {
+//	    return enc.EncodeJSON(w)
+//	}
+type MultiErrorEncoder interface {
+	error
+	EncodeJSON(w io.Writer) error
+}
+
+// MultiErrorStreamThreshold is the number of errors of a multi-error above which
+// MarshalJSON encodes the errors incrementally, see [MultiErrorEncoder].
+const MultiErrorStreamThreshold = 256
+
+// multiErrorEncodeBufferSize is the size of the buffer of [MultiErrorEncoder.EncodeJSON].
+const multiErrorEncodeBufferSize = 32 << 10
+
+// multiErrorJSON is the JSON form of a multi-error.
+type multiErrorJSON struct {
+	Errors []any `json:"errors"`
+	Counts []int `json:"counts,omitempty"`
+	Others int   `json:"others,omitempty"`
+}
+
+// MarshalJSON implements the [json.Marshaler] interface.
+func (m *multiError) MarshalJSON() ([]byte, error) {
+	return marshalMultiError(m.errors, nil, 0)
+}
+
+// EncodeJSON implements [MultiErrorEncoder].
+func (m *multiError) EncodeJSON(w io.Writer) error {
+	return encodeMultiError(w, m.errors, nil, 0)
+}
+
+// MarshalJSON implements the [json.Marshaler] interface.
+func (m *multiErrorSet) MarshalJSON() ([]byte, error) {
+	return marshalMultiError(m.errors, m.counts(), m.others)
+}
+
+// EncodeJSON implements [MultiErrorEncoder].
+func (m *multiErrorSet) EncodeJSON(w io.Writer) error {
+	return encodeMultiError(w, m.errors, m.counts(), m.others)
+}
+
+// counts returns the occurrence counts of the errors, at least one.
+func (m *multiErrorSet) counts() []int {
+	counts := make([]int, len(m.errors))
+	for i, err := range m.errors {
+		counts[i] = m.counter[m.keyGetter(err)]
+		if counts[i] < 1 {
+			counts[i] = 1
+		}
+	}
+	return counts
+}
+
+func marshalMultiError(errs []error, counts []int, others int) ([]byte, error) {
+	if len(errs) > MultiErrorStreamThreshold {
+		var buf bytes.Buffer
+		if err := encodeMultiError(&buf, errs, counts, others); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	}
+	elements := make([]any, len(errs))
+	for i, err := range errs {
+		elements[i] = multiErrorElement(err)
+	}
+	return json.Marshal(multiErrorJSON{Errors: elements, Counts: counts, Others: others})
+}
+
+func encodeMultiError(w io.Writer, errs []error, counts []int, others int) error {
+	bw := bufio.NewWriterSize(w, multiErrorEncodeBufferSize)
+	bw.WriteString(`{"errors":[`)
+	for i, err := range errs {
+		data, encErr := json.Marshal(multiErrorElement(err))
+		if encErr != nil {
+			return Wrap(encErr, "encode error", "index", i)
+		}
+		if i > 0 {
+			bw.WriteByte(',')
+		}
+		if _, writeErr := bw.Write(data); writeErr != nil {
+			return writeErr
+		}
+	}
+	bw.WriteByte(']')
+	if len(counts) > 0 {
+		bw.WriteString(`,"counts":[`)
+		for i, count := range counts {
+			if i > 0 {
+				bw.WriteByte(',')
+			}
+			bw.WriteString(strconv.Itoa(count))
+		}
+		bw.WriteByte(']')
+	}
+	if others != 0 {
+		bw.WriteString(`,"others":`)
+		bw.WriteString(strconv.Itoa(others))
+	}
+	bw.WriteByte('}')
+	return bw.Flush()
+}
+
+// multiErrorElement returns the value to serialize for an error of a multi-error.
+func multiErrorElement(err error) any {
+	switch v := err.(type) {
+	case json.Marshaler:
+		return v
+	case Error:
+		return ErrorToJSON(v)
+	default:
+		return ErrorSchema{Message: validUTF8(err.Error())}
+	}
+}
+
 // addNew creates a new formatted error and adds it to the list.
 func addNew[T interface{ add(Error) }](g T, message string, meta ...any) T {
 	g.add(newf(message, meta...))
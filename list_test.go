@@ -1,6 +1,8 @@
 package erro
 
 import (
+	"bytes"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"strings"
@@ -459,6 +461,105 @@ func TestMultiErrorSet_Error(t *testing.T) {
 	}
 }
 
+func TestMultiError_EncodeJSON(t *testing.T) {
+	set := NewSet().New("a", "key", "value").New("b").New("a", "key", "value")
+	set.Add(errors.New("plain"))
+	err := set.Err()
+
+	marshaled, marshalErr := json.Marshal(err)
+	if marshalErr != nil {
+		t.Fatal(marshalErr)
+	}
+	var buf bytes.Buffer
+	if encErr := err.(MultiErrorEncoder).EncodeJSON(&buf); encErr != nil {
+		t.Fatal(encErr)
+	}
+	if !bytes.Equal(marshaled, buf.Bytes()) {
+		t.Errorf("expected the same JSON:\n%s\n%s", marshaled, buf.Bytes())
+	}
+
+	var decoded struct {
+		Errors []ErrorSchema `json:"errors"`
+		Counts []int         `json:"counts"`
+	}
+	if decErr := json.Unmarshal(marshaled, &decoded); decErr != nil {
+		t.Fatal(decErr)
+	}
+	if len(decoded.Errors) != 3 || decoded.Errors[0].Message != "a" || decoded.Errors[2].Message != "plain" {
+		t.Errorf("unexpected errors %+v", decoded.Errors)
+	}
+	if len(decoded.Counts) != 3 || decoded.Counts[0] != 2 || decoded.Counts[1] != 1 {
+		t.Errorf("unexpected counts %v", decoded.Counts)
+	}
+
+	list := NewList()
+	for i := 0; i < MultiErrorStreamThreshold+10; i++ {
+		list.New("failed", "index", i)
+	}
+	streamed, marshalErr := json.Marshal(list.Err())
+	if marshalErr != nil {
+		t.Fatal(marshalErr)
+	}
+	buf.Reset()
+	if encErr := list.Err().(MultiErrorEncoder).EncodeJSON(&buf); encErr != nil {
+		t.Fatal(encErr)
+	}
+	if !bytes.Equal(streamed, buf.Bytes()) {
+		t.Error("expected the same JSON for a streamed multi-error")
+	}
+	decoded.Errors = nil
+	if decErr := json.Unmarshal(streamed, &decoded); decErr != nil {
+		t.Fatal(decErr)
+	}
+	if len(decoded.Errors) != MultiErrorStreamThreshold+10 {
+		t.Errorf("expected %d errors, got %d", MultiErrorStreamThreshold+10, len(decoded.Errors))
+	}
+}
+
+func TestMultiErrorFormatter(t *testing.T) {
+	bullets := MultiErrorFormat{Header: "%d errors:\n", Enumeration: "- ", Separator: "\n", ShowCounts: true}.Formatter()
+
+	list := NewList().WithFormatter(bullets)
+	list.New("first").New("second")
+	if expected := "2 errors:\n- first\n- second"; list.Err().Error() != expected {
+		t.Errorf("expected %q, got %q", expected, list.Err().Error())
+	}
+	if list.Copy().Err().Error() != list.Err().Error() {
+		t.Error("Copy must keep the formatter")
+	}
+
+	set := NewSet().WithMaxKeys(2).WithFormatter(MultiErrorFormat{Enumeration: "%d) ", ShowCounts: true}.Formatter())
+	set.New("a").New("a").New("b").New("c").New("c")
+	if expected := "1) b; 2) c (2 times); (2 others)"; set.Err().Error() != expected {
+		t.Errorf("expected %q, got %q", expected, set.Err().Error())
+	}
+	noCounts := NewSafeSet().WithFormatter(MultiErrorFormat{Separator: " | "}.Formatter())
+	noCounts.New("a").New("a").New("b")
+	if expected := "a | b"; noCounts.Err().Error() != expected {
+		t.Errorf("expected %q, got %q", expected, noCounts.Err().Error())
+	}
+
+	// The configured formatter applies to multi-errors without their own
+	defer Configure(WithMultiErrorFormatter(nil))
+	Configure(WithMultiErrorFormatter(func(errs []error, counts []int, others int) string {
+		return fmt.Sprintf("%d errors, counts %v", len(errs), counts)
+	}))
+	if msg := Join(errors.New("a"), errors.New("b")).Error(); msg != "2 errors, counts []" {
+		t.Errorf("unexpected Join message %q", msg)
+	}
+	if msg := NewSet().New("a").New("a").New("b").Err().Error(); msg != "2 errors, counts [2 1]" {
+		t.Errorf("unexpected set message %q", msg)
+	}
+	if msg := NewSafeList().WithFormatter(bullets).New("a").New("b").Err().Error(); msg != "2 errors:\n- a\n- b" {
+		t.Errorf("own formatter must win, got %q", msg)
+	}
+
+	Configure(WithMultiErrorFormatter(nil))
+	if msg := Join(errors.New("a"), errors.New("b")).Error(); msg != "multiple errors (2): [1] a; [2] b" {
+		t.Errorf("unexpected default message %q", msg)
+	}
+}
+
 func TestSafeList_ConcurrentAddRemove(t *testing.T) {
 	safeList := NewSafeList()
 	var wg sync.WaitGroup
@@ -1193,3 +1294,471 @@ func TestDebug_IDKeyGetter_WrappedErrors(t *testing.T) {
 	t.Logf("IDKeyGetter(wrapped2): %s", IDKeyGetter(wrapped2))
 	t.Logf("IDKeyGetter(wrapped3): %s", IDKeyGetter(wrapped3))
 }
+
+func TestSet_WithMaxKeys(t *testing.T) {
+	set := NewSet().WithMaxKeys(2)
+	set.New("a").New("b").New("a").New("c")
+
+	// "b" is the least recently seen key and must be evicted
+	if set.Len() != 2 {
+		t.Fatalf("expected 2 errors, got %d", set.Len())
+	}
+	counts := set.Counts()
+	if counts["a"] != 2 || counts["c"] != 1 {
+		t.Errorf("unexpected counts: %v", counts)
+	}
+	if _, ok := counts["b"]; ok {
+		t.Error("expected b to be evicted")
+	}
+	if set.Others() != 1 {
+		t.Errorf("expected 1 in others bucket, got %d", set.Others())
+	}
+	if !strings.Contains(set.Err().Error(), "(1 others)") {
+		t.Errorf("expected others bucket in error message, got %s", set.Err().Error())
+	}
+
+	// Counts returns a copy
+	counts["a"] = 100
+	if set.Counts()["a"] != 2 {
+		t.Error("expected Counts to return a copy")
+	}
+
+	clone := set.Copy()
+	clone.New("d")
+	if clone.Len() != 2 || clone.Others() != 3 {
+		t.Errorf("unexpected clone state: len=%d others=%d", clone.Len(), clone.Others())
+	}
+	if set.Others() != 1 {
+		t.Error("expected original set to be unaffected by clone")
+	}
+
+	set.Clear()
+	if set.Others() != 0 || len(set.Counts()) != 0 {
+		t.Error("expected Clear to reset counters")
+	}
+}
+
+func TestSet_WithMaxKeys_ShrinkAndDisable(t *testing.T) {
+	set := NewSet()
+	for i := 0; i < 5; i++ {
+		set.New("error %d", i)
+	}
+	set.WithMaxKeys(3)
+	if set.Len() != 3 || set.Others() != 2 {
+		t.Fatalf("unexpected state after shrinking: len=%d others=%d", set.Len(), set.Others())
+	}
+	if set.First().Message() != "error 2" {
+		t.Errorf("expected oldest errors to be evicted, first is %s", set.First().Message())
+	}
+
+	set.Remove(0)
+	if len(set.Counts()) != 2 {
+		t.Errorf("expected removed key to be forgotten, got %v", set.Counts())
+	}
+
+	set.WithMaxKeys(0)
+	for i := 0; i < 10; i++ {
+		set.New("more %d", i)
+	}
+	if set.Len() != 12 {
+		t.Errorf("expected unbounded set, got %d errors", set.Len())
+	}
+}
+
+func TestSafeSet_WithMaxKeys(t *testing.T) {
+	set := NewSafeSet().WithMaxKeys(1)
+	set.New("a").New("b")
+	if set.Len() != 1 || set.Others() != 1 || set.Counts()["b"] != 1 {
+		t.Errorf("unexpected state: len=%d others=%d counts=%v", set.Len(), set.Others(), set.Counts())
+	}
+}
+
+func TestList_WriteToReadListFrom(t *testing.T) {
+	list := NewList()
+	list.New("first error", "key", "value", ClassValidation)
+	list.Wrap(errors.New("base"), "second error", SeverityHigh)
+
+	var buf strings.Builder
+	n, err := list.WriteTo(&buf)
+	if err != nil {
+		t.Fatalf("WriteTo failed: %v", err)
+	}
+	if n != int64(buf.Len()) {
+		t.Errorf("expected %d bytes written, got %d", buf.Len(), n)
+	}
+	if lines := strings.Count(buf.String(), "\n"); lines != 2 {
+		t.Errorf("expected 2 lines, got %d: %s", lines, buf.String())
+	}
+
+	loaded, err := ReadListFrom(strings.NewReader(buf.String() + "\n"))
+	if err != nil {
+		t.Fatalf("ReadListFrom failed: %v", err)
+	}
+	if loaded.Len() != 2 {
+		t.Fatalf("expected 2 errors, got %d", loaded.Len())
+	}
+	for i, e := range loaded.Errs() {
+		orig := list.Errs()[i]
+		if e.ID() != orig.ID() || e.Message() != orig.Message() || e.Class() != orig.Class() || e.Severity() != orig.Severity() {
+			t.Errorf("error %d: unexpected round trip %v, expected %v", i, e, orig)
+		}
+	}
+
+	partial, err := ReadListFrom(strings.NewReader(`{"id":"a","message":"ok"}` + "\n" + `{"id":`))
+	if err == nil {
+		t.Error("expected error for truncated input")
+	}
+	if partial.Len() != 1 {
+		t.Errorf("expected errors read before the failure to be returned, got %d", partial.Len())
+	}
+}
+
+func TestSafeList_WriteTo(t *testing.T) {
+	list := NewSafeList()
+	list.New("error")
+
+	var buf strings.Builder
+	if _, err := list.WriteTo(&buf); err != nil {
+		t.Fatalf("WriteTo failed: %v", err)
+	}
+	loaded, err := ReadListFrom(strings.NewReader(buf.String()))
+	if err != nil || loaded.Len() != 1 || loaded.First().Message() != "error" {
+		t.Errorf("unexpected round trip: %v %v", loaded.Errs(), err)
+	}
+}
+
+func TestList_RangeIteratorSnapshot(t *testing.T) {
+	list := NewList()
+	list.New("first").New("second").New("third")
+
+	var messages []string
+	list.Range(func(err Error) bool {
+		messages = append(messages, err.Message())
+		return len(messages) < 2
+	})
+	if strings.Join(messages, ",") != "first,second" {
+		t.Errorf("expected Range to stop early, got %v", messages)
+	}
+
+	snapshot := list.Snapshot()
+	list.Remove(0)
+	list.New("fourth")
+	if snapshot.Len() != 3 || snapshot.At(0).Message() != "first" || snapshot.At(3) != nil || snapshot.At(-1) != nil {
+		t.Errorf("expected snapshot to be unaffected by changes, got %v", snapshot.Errors())
+	}
+
+	messages = nil
+	for it := snapshot.Iterator(); it.Next(); {
+		messages = append(messages, it.Error().Message())
+	}
+	if strings.Join(messages, ",") != "first,second,third" {
+		t.Errorf("unexpected iterator result: %v", messages)
+	}
+
+	it := list.Iterator()
+	if it.Error() != nil {
+		t.Error("expected nil before Next")
+	}
+	count := 0
+	for it.Next() {
+		count++
+	}
+	if count != 3 || it.Error() != nil || it.Next() {
+		t.Errorf("unexpected iterator state after the end: count %d", count)
+	}
+}
+
+func TestSafeList_RangeDuringModification(t *testing.T) {
+	list := NewSafeList()
+	list.New("a").New("b")
+
+	visited := 0
+	list.Range(func(err Error) bool {
+		visited++
+		list.New("added") // must not deadlock
+		return true
+	})
+	if visited != 2 || list.Len() != 4 {
+		t.Errorf("expected Range over a snapshot, visited %d, len %d", visited, list.Len())
+	}
+
+	errs := list.Errs()
+	errs[0] = nil
+	if list.First() == nil {
+		t.Error("expected Errs to return a copy")
+	}
+
+	set := NewSafeSet()
+	set.New("a").New("a").New("b")
+	visited = 0
+	for it := set.Iterator(); it.Next(); {
+		visited++
+	}
+	set.Range(func(err Error) bool {
+		set.New("c")
+		return true
+	})
+	if visited != 2 || set.Snapshot().Len() != 3 {
+		t.Errorf("unexpected set iteration: visited %d, len %d", visited, set.Len())
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				list.New("concurrent")
+				list.Range(func(err Error) bool { return err != nil })
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestSafeSet_WithTTL(t *testing.T) {
+	var mu sync.Mutex
+	current := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	advance := func(d time.Duration) {
+		mu.Lock()
+		defer mu.Unlock()
+		current = current.Add(d)
+	}
+	SetClock(func() time.Time {
+		mu.Lock()
+		defer mu.Unlock()
+		return current
+	})
+	defer SetClock(nil)
+
+	set := NewSafeSet()
+	set.New("old")
+	set.WithTTL(time.Minute, time.Hour)
+	defer set.Stop()
+
+	set.New("timeout").New("timeout")
+	if counts := set.Counts(); counts["timeout"] != 2 {
+		t.Errorf("expected deduplication within the window, got %v", counts)
+	}
+
+	advance(30 * time.Second)
+	set.New("timeout")
+	if counts := set.Counts(); counts["timeout"] != 3 {
+		t.Errorf("expected error to stay within the window, got %v", counts)
+	}
+
+	advance(30 * time.Second)
+	previous := set.Last()
+	set.New("timeout")
+	if counts := set.Counts(); counts["timeout"] != 1 || set.Len() != 2 {
+		t.Errorf("expected expired error to re-surface, got %v with %d errors", counts, set.Len())
+	}
+	if set.Last().Message() != "timeout" || set.Last() == previous {
+		t.Error("expected re-surfaced error to be added as new")
+	}
+
+	advance(time.Minute)
+	if n := set.Expire(); n != 2 || set.Len() != 0 {
+		t.Errorf("expected all errors to expire, removed %d, left %d", n, set.Len())
+	}
+
+	copied := set.New("copy").Copy()
+	advance(time.Minute)
+	if n := copied.Expire(); n != 1 {
+		t.Errorf("expected copy to keep the TTL, removed %d", n)
+	}
+
+	set.WithTTL(0)
+	set.New("forever")
+	advance(time.Hour)
+	if n := set.Expire(); n != 0 || set.Len() != 2 {
+		t.Errorf("expected no expiration after disabling TTL, removed %d, left %d", n, set.Len())
+	}
+	set.Stop()
+	set.Stop() // should not panic
+}
+
+func TestSafeSet_WithTTLJanitor(t *testing.T) {
+	set := NewSafeSet().WithTTL(time.Millisecond, time.Millisecond)
+	defer set.Stop()
+	set.New("short-lived")
+
+	deadline := time.Now().Add(time.Second)
+	for set.Len() > 0 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if set.Len() != 0 {
+		t.Error("expected janitor to remove the expired error")
+	}
+}
+
+func TestSafeSet_WithTTLJanitorLifetime(t *testing.T) {
+	running := func(set *SafeSet) bool {
+		set.mu.RLock()
+		defer set.mu.RUnlock()
+		return set.janitor != nil
+	}
+
+	set := NewSafeSet().WithTTL(time.Millisecond, time.Millisecond)
+	if running(set) {
+		t.Fatal("expected janitor not to start for an empty set")
+	}
+	set.New("short-lived")
+	if !running(set) {
+		t.Fatal("expected janitor to start when an error is added")
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for running(set) && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if running(set) || set.Len() != 0 {
+		t.Fatal("expected janitor to exit once the set is empty")
+	}
+
+	set.New("again")
+	if !running(set) {
+		t.Error("expected janitor to start again")
+	}
+	set.Stop()
+	set.New("stopped")
+	if running(set) {
+		t.Error("expected janitor not to start after Stop")
+	}
+}
+
+func TestSafeSet_ErrWithJanitor(t *testing.T) {
+	set := NewSafeSet().WithTTL(time.Millisecond, time.Millisecond)
+	defer set.Stop()
+	set.New("first").New("first").New("second")
+	err := set.Err()
+
+	// The janitor removes expired keys while the combined error is formatted
+	deadline := time.Now().Add(50 * time.Millisecond)
+	for time.Now().Before(deadline) {
+		set.New("first").New("second")
+		_ = err.Error()
+		_, _ = json.Marshal(err)
+	}
+	for set.Len() > 0 && time.Now().Before(deadline.Add(time.Second)) {
+		time.Sleep(time.Millisecond)
+	}
+	if msg := err.Error(); !strings.Contains(msg, "first (2 times)") {
+		t.Errorf("expected counts to be kept after the errors expired, got %q", msg)
+	}
+}
+
+func TestList_Merge(t *testing.T) {
+	a := NewList().New("a1").New("a2")
+	b := NewList().New("b1")
+	if a.Merge(b).Merge(nil).Len() != 3 || a.Last().Message() != "b1" || b.Len() != 1 {
+		t.Fatalf("unexpected merge result: %v", a.Errs())
+	}
+	if a.Merge(a).Len() != 6 {
+		t.Errorf("expected self merge to double the list, got %d", a.Len())
+	}
+
+	sa := NewSafeList().New("a")
+	sa.Merge(NewSafeList().New("b").New("c")).Merge(sa)
+	if sa.Len() != 6 {
+		t.Errorf("expected 6 errors, got %d", sa.Len())
+	}
+}
+
+func TestSet_Merge(t *testing.T) {
+	err := New("timeout")
+	set := NewSet().Add(err)
+	set.Merge(NewSet().Add(err).Add(err)).Merge(nil)
+	if set.Len() != 1 || set.Count(err) != 3 {
+		t.Errorf("expected merge to deduplicate and count, got %d errors with count %d", set.Len(), set.Count(err))
+	}
+	if set.Merge(set).Count(err) != 6 || set.Len() != 1 {
+		t.Errorf("expected self merge to double the counts, got %v", set.Counts())
+	}
+}
+
+func TestSet_Algebra(t *testing.T) {
+	shard := func(messages ...string) *Set {
+		s := NewSet()
+		for _, message := range messages {
+			s.New(message)
+		}
+		return s
+	}
+
+	union := shard("timeout", "timeout", "refused").Union(shard("timeout", "denied"))
+	counts := union.Counts()
+	if union.Len() != 3 || counts["timeout"] != 3 || counts["refused"] != 1 || counts["denied"] != 1 {
+		t.Errorf("unexpected union: %v", counts)
+	}
+
+	diff := shard("timeout", "refused", "denied").Difference(shard("refused"))
+	if diff.Len() != 2 || diff.Counts()["refused"] != 0 || diff.First().Message() != "timeout" || diff.Last().Message() != "denied" {
+		t.Errorf("unexpected difference: %v", diff.Counts())
+	}
+
+	inter := shard("timeout", "timeout", "refused").Intersect(shard("timeout", "denied"))
+	if inter.Len() != 1 || inter.Counts()["timeout"] != 3 {
+		t.Errorf("unexpected intersection: %v", inter.Counts())
+	}
+	if shard("a").Intersect(nil).Len() != 0 || shard("a").Difference(nil).Len() != 1 || shard("a").Union(nil).Len() != 1 {
+		t.Error("unexpected operation with nil")
+	}
+	self := shard("a", "a")
+	if self.Union(self).Counts()["a"] != 4 || self.Difference(self).Len() != 0 {
+		t.Error("unexpected operation with the set itself")
+	}
+
+	// Keys are computed with the key getter of the receiver
+	classKey := func(err error) string { return string(ExtractError(err).Class()) }
+	byClass := NewSet().WithKeyGetter(classKey).New("timeout", ClassTimeout)
+	byClass.Union(shard("deadline", "other").WithKeyGetter(MessageKeyGetter))
+	if byClass.Len() != 1 || byClass.Counts()[string(ClassTimeout)] != 1 {
+		t.Errorf("expected errors without a class key to be skipped, got %v", byClass.Counts())
+	}
+
+	bounded := NewSet().WithMaxKeys(1).New("a")
+	bounded.Union(NewSet().WithMaxKeys(1).New("b").New("c"))
+	if bounded.Len() != 1 || bounded.Last().Message() != "c" || bounded.Others() != 2 {
+		t.Errorf("expected evicted counts in others, got %d %d", bounded.Len(), bounded.Others())
+	}
+
+	safe := NewSafeSet().New("timeout").New("refused")
+	safe.Union(NewSafeSet().New("timeout")).Intersect(NewSafeSet().New("timeout")).Difference(safe)
+	if safe.Len() != 0 {
+		t.Errorf("expected an empty set, got %v", safe.Counts())
+	}
+}
+
+func TestSet_TopN(t *testing.T) {
+	set := NewSet()
+	for _, msg := range []string{"db", "cache", "db", "auth", "cache", "db"} {
+		set.New(msg)
+	}
+	top := set.TopN(2)
+	if len(top) != 2 || top[0].Err.Message() != "db" || top[0].Count != 3 || top[1].Err.Message() != "cache" || top[1].Count != 2 {
+		t.Fatalf("unexpected top errors %+v", top)
+	}
+	if all := set.TopN(0); len(all) != 3 || all[2].Err.Message() != "auth" || all[2].Count != 1 {
+		t.Errorf("unexpected all errors %+v", all)
+	}
+	if set.Count(New("db")) != 3 || set.Count(errors.New("auth")) != 1 || set.Count(New("other")) != 0 || set.Count(nil) != 0 {
+		t.Error("unexpected counts")
+	}
+	if set.Total() != 6 || set.PercentOfTotal(New("db")) != 50 {
+		t.Errorf("unexpected total %d and percent %v", set.Total(), set.PercentOfTotal(New("db")))
+	}
+
+	// Ties keep the order of addition, evicted errors count in the total
+	bounded := NewSafeSet().WithMaxKeys(2)
+	bounded.New("a").New("b").New("c").New("c")
+	if top := bounded.TopN(5); len(top) != 2 || top[0].Err.Message() != "c" || top[1].Err.Message() != "b" {
+		t.Errorf("unexpected bounded top errors %+v", top)
+	}
+	if bounded.Total() != 4 || bounded.Count(New("c")) != 2 || bounded.PercentOfTotal(New("b")) != 25 {
+		t.Errorf("unexpected bounded stats %d %d", bounded.Total(), bounded.Count(New("c")))
+	}
+	if NewSet().PercentOfTotal(New("x")) != 0 || len(NewSet().TopN(3)) != 0 {
+		t.Error("an empty set must have no stats")
+	}
+}
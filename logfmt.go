@@ -0,0 +1,123 @@
+package erro
+
+import (
+	"log"
+	"strconv"
+	"strings"
+)
+
+// FormatErrorLogfmt formats the whole error chain as a single strict logfmt line:
+// the messages of the chain go to the msg key, followed by the fields of all layers.
+// Keys are sanitized (spaces, quotes and '=' are replaced with '_'), values are
+// quoted when needed, including empty ones, so the output is machine-parseable.
+//
+// Use it directly or set it as the formatter of the outermost error with [StrictLogfmt].
+//
+// Example:
+//
+//	err := erro.Wrap(dbErr, "get user", "user_id", 42, "name", "John Doe")
+//	erro.FormatErrorLogfmt(err) // msg="get user: connection refused" user_id=42 name="John Doe"
+func FormatErrorLogfmt(err Error) string {
+	if err == nil {
+		return ""
+	}
+	fields := err.AllFields()
+
+	var b strings.Builder
+	b.Grow(len(fields)*20 + 32)
+	b.WriteString("msg=")
+	b.WriteString(strconv.Quote(truncateString(err.Message(), MaxMessageLength)))
+	for i := 0; i+1 < len(fields); i += 2 {
+		b.WriteByte(' ')
+		appendLogfmtKey(&b, truncateString(valueToString(fields[i]), MaxKeyLength))
+		b.WriteByte('=')
+		appendFieldValue(&b, fields[i+1], MaxValueLength, true)
+	}
+	return b.String()
+}
+
+// StrictLogfmt makes Error() return the whole error chain formatted with [FormatErrorLogfmt].
+// Set it on the outermost error: errors wrapping it use their own formatters.
+//
+// Example:
+//
+//	err := erro.Wrap(dbErr, "get user", "user_id", 42, erro.StrictLogfmt())
+//	err.Error() // msg="get user: connection refused" user_id=42
+func StrictLogfmt() errorOpt {
+	return func(err *baseError) {
+		err.formatter = FormatErrorLogfmt
+		err.wholeChainFormat = true
+	}
+}
+
+// FormatLogLine formats the error as a leveled logfmt line with the structured fields
+// of [LogFields]: level=error msg="..." key=value. The level is "error" for critical,
+// high and unknown severity, "warn" for medium and "info" for low and info severity.
+// Options work as in [LogError]. It returns an empty string for nil.
+//
+// Example:
+//
+//	fmt.Fprintln(os.Stderr, erro.FormatLogLine(err))
+func FormatLogLine(err error, optFuncs ...LogOption) string {
+	if err == nil {
+		return ""
+	}
+	var b strings.Builder
+	LogError(err, func(message string, fields ...any) {
+		b.Grow(len(fields)*20 + len(message) + 24)
+		b.WriteString("level=")
+		b.WriteString(logLevel(ExtractError(err).Severity()))
+		b.WriteString(" msg=")
+		b.WriteString(strconv.Quote(truncateString(message, MaxMessageLength)))
+		for i := 0; i+1 < len(fields); i += 2 {
+			b.WriteByte(' ')
+			appendLogfmtKey(&b, truncateString(valueToString(fields[i]), MaxKeyLength))
+			b.WriteByte('=')
+			appendFieldValue(&b, fields[i+1], MaxValueLength, true)
+		}
+	}, optFuncs...)
+	return b.String()
+}
+
+// LogErrorStd writes the error to a standard [log.Logger] as a line of [FormatLogLine],
+// for code that doesn't use structured logging. A nil logger writes to [log.Default].
+//
+// Example:
+//
+//	erro.LogErrorStd(err, log.New(os.Stderr, "", log.LstdFlags))
+//	// 2024/01/01 00:00:00 level=error msg="get user" user_id=42 error="get user" error_severity=high
+func LogErrorStd(err error, logger *log.Logger, optFuncs ...LogOption) {
+	if err == nil {
+		return
+	}
+	if logger == nil {
+		logger = log.Default()
+	}
+	logger.Print(FormatLogLine(err, optFuncs...))
+}
+
+// logLevel maps the severity of an error to a log level.
+func logLevel(severity ErrorSeverity) string {
+	switch severity {
+	case SeverityMedium:
+		return "warn"
+	case SeverityLow, SeverityInfo:
+		return "info"
+	default:
+		return "error"
+	}
+}
+
+func appendLogfmtKey(b *strings.Builder, key string) {
+	if key == "" {
+		b.WriteByte('_')
+		return
+	}
+	for i := 0; i < len(key); i++ {
+		c := key[i]
+		if c <= ' ' || c == '=' || c == '"' || c == 0x7f {
+			c = '_'
+		}
+		b.WriteByte(c)
+	}
+}
@@ -0,0 +1,92 @@
+package erro
+
+import (
+	"bytes"
+	"errors"
+	"log"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFormatErrorWithFields_Quoting(t *testing.T) {
+	tests := []struct {
+		value    any
+		expected string
+	}{
+		{"simple", "test key=simple"},
+		{"John Doe", `test key="John Doe"`},
+		{"a=b", `test key="a=b"`},
+		{"host:5432", `test key="host:5432"`},
+		{`say "hi"`, `test key="say \"hi\""`},
+		{"line\nbreak", `test key="line\nbreak"`},
+		{"", "test key="},
+		{42, "test key=42"},
+		{time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), `test key="2024-01-01T00:00:00Z"`},
+		{errors.New("conn refused"), `test key="conn refused"`},
+		{Redact("secret value"), "test key=" + RedactedPlaceholder},
+	}
+	for _, tt := range tests {
+		if got := New("test", "key", tt.value).Error(); got != tt.expected {
+			t.Errorf("value %#v: expected %s, got %s", tt.value, tt.expected, got)
+		}
+	}
+}
+
+func TestFormatErrorLogfmt(t *testing.T) {
+	inner := New("connection refused", "host", "db:5432")
+	err := Wrap(inner, "get user", "user_id", 42, "bad key", "", "name", "John Doe")
+
+	expected := `msg="get user: connection refused" user_id=42 bad_key="" name="John Doe" host="db:5432"`
+	if got := FormatErrorLogfmt(err); got != expected {
+		t.Errorf("expected %s, got %s", expected, got)
+	}
+
+	strict := Wrap(inner, "get user", "user_id", 42, StrictLogfmt())
+	if got := strict.Error(); got != `msg="get user: connection refused" user_id=42 host="db:5432"` {
+		t.Errorf("expected strict logfmt Error(), got %s", got)
+	}
+	if got := Wrap(errors.New("base"), "plain").Error(); got != "plain: base" {
+		t.Errorf("expected default formatting to be unchanged, got %s", got)
+	}
+	if FormatErrorLogfmt(nil) != "" {
+		t.Error("expected empty string for nil")
+	}
+}
+
+func TestFormatLogLine(t *testing.T) {
+	if FormatLogLine(nil) != "" {
+		t.Error("expected empty line for nil")
+	}
+
+	err := Wrap(New("connection refused", CategoryDatabase), "get user", "user_id", 42, "name", "John Doe")
+	line := FormatLogLine(err, WithUserFields(), WithCategory())
+	expected := `level=error msg="get user: connection refused" user_id=42 name="John Doe" category=database`
+	if line != expected {
+		t.Errorf("unexpected line:\n got %s\nwant %s", line, expected)
+	}
+
+	if line := FormatLogLine(New("slow query", SeverityMedium), WithSeverity()); line != `level=warn msg="slow query" severity=medium` {
+		t.Errorf("unexpected medium line: %s", line)
+	}
+	if line := FormatLogLine(New("cache miss", SeverityLow)); !strings.HasPrefix(line, "level=info ") {
+		t.Errorf("unexpected low line: %s", line)
+	}
+	if line := FormatLogLine(errors.New("plain")); line != `level=error msg="plain"` {
+		t.Errorf("unexpected line for standard error: %s", line)
+	}
+}
+
+func TestLogErrorStd(t *testing.T) {
+	var buf bytes.Buffer
+	logger := log.New(&buf, "", 0)
+
+	LogErrorStd(nil, logger)
+	if buf.Len() != 0 {
+		t.Error("expected nothing to be logged for nil")
+	}
+	LogErrorStd(New("failed", "key", "some value"), logger, WithUserFields())
+	if got := buf.String(); got != "level=error msg=\"failed\" key=\"some value\"\n" {
+		t.Errorf("unexpected output: %q", got)
+	}
+}
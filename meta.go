@@ -1,11 +1,18 @@
 package erro
 
-import "context"
+import (
+	"context"
+	"time"
+)
 
 type (
 	errorOpt    func(err *baseError)
 	errorWork   func(err Error)
 	errorFields func() []any
+
+	// spanWork is an errorWork that runs before all others,
+	// so they can rely on the span of the error (e.g. its sampling decision).
+	spanWork func(err Error)
 )
 
 // ID sets a custom identifier for the error.
@@ -22,6 +29,125 @@ func Retryable() errorOpt {
 	}
 }
 
+// Attempt sets the retry attempt number of the failed operation.
+func Attempt(n int) errorOpt {
+	return func(err *baseError) {
+		err.attempt = n
+	}
+}
+
+// MaxAttempts sets the maximum number of retry attempts of the failed operation.
+func MaxAttempts(n int) errorOpt {
+	return func(err *baseError) {
+		err.maxAttempts = n
+	}
+}
+
+// RetryAfter sets the delay after which the failed operation may be retried.
+// It is emitted as the Retry-After header by [WriteHTTPError].
+func RetryAfter(d time.Duration) errorOpt {
+	return func(err *baseError) {
+		if d > 0 {
+			err.setExt().retryAfter = d
+		}
+	}
+}
+
+// TTL sets the time the error stays fresh after its creation, see [Error.Expired].
+// It lets caches that store errors, e.g. negative caching of lookups, retry upstream
+// once the error expires. The TTL of the outermost error wins.
+//
+// Example:
+//
+//	err := erro.Wrap(err, "lookup user", erro.TTL(30*time.Second))
+//	cache.Set(key, err)
+//	// Later
+//	if cached, ok := cache.Get(key); ok && !cached.Expired() {
+//	    return cached
+//	}
+func TTL(d time.Duration) errorOpt {
+	return func(err *baseError) {
+		if d > 0 {
+			err.setExt().ttl = d
+		}
+	}
+}
+
+// Hint adds remediation guidance for developers and on-call engineers to the error.
+// It is shown in %+v, JSON and [ProblemDetails]. The hint of the outermost error wins.
+//
+// Example:
+//
+//	err := erro.New("upload failed", erro.ClassPermissionDenied, erro.Hint("check that the API key has write scope"))
+func Hint(hint string) errorOpt {
+	return func(err *baseError) {
+		err.setExt().hint = truncateString(hint, MaxMessageLength)
+	}
+}
+
+// HintOf returns the hint of the error chain set with [Hint], the outermost one wins.
+func HintOf(err error) string {
+	var e interface{ Hint() string }
+	if !As(err, &e) {
+		return ""
+	}
+	return e.Hint()
+}
+
+// UserMsg adds a message that is safe to show to end users, e.g. "Payment was declined,
+// try another card", next to the technical message of the error. [ToProblemDetails]
+// returns it as the detail; add the technical message with [WithBilingual].
+// The user message of the outermost error wins.
+func UserMsg(message string) errorOpt {
+	return func(err *baseError) {
+		err.setExt().userMessage = truncateString(message, MaxMessageLength)
+	}
+}
+
+// UserMessageOf returns the end-user message of the error chain set with [UserMsg],
+// the outermost one wins.
+func UserMessageOf(err error) string {
+	var e interface{ UserMessage() string }
+	if !As(err, &e) {
+		return ""
+	}
+	return e.UserMessage()
+}
+
+// DocURL adds a link to the documentation of the error. It is shown in %+v and JSON,
+// and used as the type of [ProblemDetails]. The link of the outermost error wins.
+func DocURL(url string) errorOpt {
+	return func(err *baseError) {
+		err.setExt().docURL = truncateString(url, MaxValueLength)
+	}
+}
+
+// DocURLOf returns the documentation link of the error chain set with [DocURL], the
+// outermost one wins.
+func DocURLOf(err error) string {
+	var e interface{ DocURL() string }
+	if !As(err, &e) {
+		return ""
+	}
+	return e.DocURL()
+}
+
+// Interpolate makes {key} placeholders in the message of the error be replaced with the
+// values of the fields with these keys when the message is rendered, in Error(), [Error.Message]
+// and JSON. Fields of the error win over fields of the wrapped errors; placeholders without
+// a field are kept. The raw message is kept for [Fingerprint], so errors with different
+// values are of the same kind, and the values stay available as structured fields.
+//
+// Example:
+//
+//	err := erro.New("user {user_id} not found", "user_id", 42, erro.Interpolate())
+//	// user 42 not found user_id=42
+func Interpolate() errorOpt {
+	return func(err *baseError) {
+		err.interpolate = true
+	}
+}
+
 // Fields adds structured data to the error.
 func Fields(fields ...any) errorFields {
 	return func() []any {
@@ -29,6 +155,32 @@ func Fields(fields ...any) errorFields {
 	}
 }
 
+// GroupSeparator separates the group name and the key of fields added with [Group].
+const GroupSeparator = "."
+
+// Group adds structured data to the error with keys prefixed by the group name,
+// e.g. "db.query". Groups can be nested. It keeps field names collision-free across
+// layers that attach similar keys; use [WithNestedGroups] to log groups as nested maps.
+//
+// Example:
+//
+//	err := erro.Wrap(err, "query failed", erro.Group("db", "query", q, "rows", n))
+//	// query failed db.query=... db.rows=...
+func Group(name string, fields ...any) errorFields {
+	return func() []any {
+		fields := collectFields(fields)
+		if len(fields)%2 != 0 {
+			fields = append(fields, missingField())
+		}
+		prefixed := make([]any, len(fields))
+		for i := 0; i < len(fields); i += 2 {
+			prefixed[i] = name + GroupSeparator + valueToString(fields[i])
+			prefixed[i+1] = fields[i+1]
+		}
+		return prefixed
+	}
+}
+
 // Formatter sets a custom error message formatter.
 func Formatter(f FormatErrorFunc) errorOpt {
 	return func(err *baseError) {
@@ -41,12 +193,29 @@ var defaultSkipFrames = 6
 // StackTrace captures a stack trace for the error.
 func StackTrace(c ...*StackTraceConfig) errorOpt {
 	return func(err *baseError) {
-		err.stack = captureStack(defaultSkipFrames)
-		if len(c) > 0 {
-			err.stackTraceConfig = c[0]
-		} else {
-			err.stackTraceConfig = nil
+		setStackTraceConfig(err, c)
+		captureErrorStack(err, defaultSkipFrames)
+	}
+}
+
+// AttachStack sets a stack built earlier as the stack trace of the error instead of
+// capturing one, see [NewStackFromFrames]. It does nothing for an empty stack.
+func AttachStack(stack Stack) errorOpt {
+	return func(err *baseError) {
+		if len(stack) == 0 {
+			return
 		}
+		err.stack = rawStack{}
+		err.frames.Store(append(Stack(nil), stack...))
+	}
+}
+
+// setStackTraceConfig sets the first config or resets it if there is none.
+func setStackTraceConfig(err *baseError, c []*StackTraceConfig) {
+	if len(c) > 0 && c[0] != nil {
+		err.setExt().stackTraceConfig = c[0]
+	} else if err.extras != nil {
+		err.extras.stackTraceConfig = nil
 	}
 }
 
@@ -56,22 +225,19 @@ func StackTraceWithSkip(skip int, c ...*StackTraceConfig) errorOpt {
 		if skip < 0 {
 			skip = 0
 		}
-		err.stack = captureStack(defaultSkipFrames + skip)
-		if len(c) > 0 {
-			err.stackTraceConfig = c[0]
-		} else {
-			err.stackTraceConfig = nil
-		}
+		setStackTraceConfig(err, c)
+		captureErrorStack(err, defaultSkipFrames+skip)
 	}
 }
 
 // RecordSpan records the error in a tracing span.
-func RecordSpan(s TraceSpan) errorWork {
+func RecordSpan(s TraceSpan) spanWork {
 	return func(err Error) {
 		if s == nil {
 			return
 		}
 		s.RecordError(err)
+		lifecycle(StageRecorded, err)
 		if base, ok := err.(*baseError); ok {
 			s.SetAttributes(base.fields...)
 			base.span = s
@@ -88,19 +254,35 @@ func RecordMetrics(m ErrorMetrics) errorWork {
 			return
 		}
 		m.RecordError(err)
+		lifecycle(StageRecorded, err)
 	}
 }
 
 // SendEvent sends the error to an event dispatcher.
+//
+// If the error is recorded in a span (see [RecordSpan]) that is not sampled,
+// the event is dispatched only for errors with [SeverityHigh] or [SeverityCritical],
+// so unsampled traces don't flood the event pipeline.
 func SendEvent(ctx context.Context, d EventDispatcher) errorWork {
 	return func(err Error) {
-		if d == nil {
+		if d == nil || !isEventSampled(err) {
 			return
 		}
 		d.SendEvent(ctx, err)
+		lifecycle(StageDispatched, err)
 	}
 }
 
+// isEventSampled returns false if the sampling decision of the error's span drops the event.
+func isEventSampled(err Error) bool {
+	span := err.Span()
+	if span == nil || span.IsSampled() {
+		return true
+	}
+	severity := err.Severity()
+	return severity == SeverityCritical || severity == SeverityHigh
+}
+
 // ErrorClass represents the class of an error.
 type ErrorClass string
 
@@ -204,6 +386,24 @@ const (
 	SeverityUnknown  ErrorSeverity = ""
 )
 
+// rank orders severities from [SeverityUnknown] (0) to [SeverityCritical].
+func (s ErrorSeverity) rank() int {
+	switch s {
+	case SeverityCritical:
+		return 5
+	case SeverityHigh:
+		return 4
+	case SeverityMedium:
+		return 3
+	case SeverityLow:
+		return 2
+	case SeverityInfo:
+		return 1
+	default:
+		return 0
+	}
+}
+
 // String returns the string representation of ErrorSeverity.
 func (s ErrorSeverity) String() string {
 	return string(s)
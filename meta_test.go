@@ -2,7 +2,11 @@ package erro
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
 	"testing"
+	"time"
 )
 
 func TestID(t *testing.T) {
@@ -185,3 +189,267 @@ func TestErrorCategory_String(t *testing.T) {
 		t.Errorf("unexpected string for category")
 	}
 }
+
+func TestRetryMetadata(t *testing.T) {
+	err := New("test", Attempt(3), MaxAttempts(5), RetryAfter(2*time.Second))
+	if err.Attempt() != 3 || err.MaxAttempts() != 5 {
+		t.Errorf("unexpected attempts: %d/%d", err.Attempt(), err.MaxAttempts())
+	}
+	if d, ok := err.RetryAfter(); !ok || d != 2*time.Second {
+		t.Errorf("unexpected retry after: %v %v", d, ok)
+	}
+
+	wrapped := Wrap(err, "outer", Attempt(4))
+	if wrapped.Attempt() != 4 || wrapped.MaxAttempts() != 5 {
+		t.Errorf("expected attempts to be inherited, got %d/%d", wrapped.Attempt(), wrapped.MaxAttempts())
+	}
+	if d, ok := wrapped.RetryAfter(); !ok || d != 2*time.Second {
+		t.Errorf("expected retry after to be inherited, got %v %v", d, ok)
+	}
+
+	if _, ok := New("test", RetryAfter(-time.Second)).RetryAfter(); ok {
+		t.Error("expected non-positive retry after to be ignored")
+	}
+
+	fields := LogFieldsMap(err)
+	if fields["error_attempt"] != 3 || fields["error_max_attempts"] != 5 || fields["error_retry_after"] != 2*time.Second {
+		t.Errorf("unexpected log fields: %v", fields)
+	}
+
+	data, jErr := json.Marshal(err)
+	if jErr != nil {
+		t.Fatal(jErr)
+	}
+	restored := New("")
+	if jErr := json.Unmarshal(data, restored); jErr != nil {
+		t.Fatal(jErr)
+	}
+	if d, _ := restored.RetryAfter(); restored.Attempt() != 3 || restored.MaxAttempts() != 5 || d != 2*time.Second {
+		t.Errorf("expected retry metadata to survive JSON round trip, got %d %d %v", restored.Attempt(), restored.MaxAttempts(), d)
+	}
+}
+
+type countingDispatcher struct {
+	events int
+}
+
+func (d *countingDispatcher) SendEvent(ctx context.Context, err Error) {
+	d.events++
+}
+
+func TestSendEventSampling(t *testing.T) {
+	sampled := &mockTraceSpan{traceID: "trace"}
+	unsampled := &mockTraceSpan{traceID: "trace", unsampled: true}
+
+	tests := []struct {
+		name     string
+		meta     func(d EventDispatcher) []any
+		expected int
+	}{
+		{"NoSpan", func(d EventDispatcher) []any { return []any{SendEvent(context.Background(), d)} }, 1},
+		{"Sampled", func(d EventDispatcher) []any {
+			return []any{SendEvent(context.Background(), d), RecordSpan(sampled)}
+		}, 1},
+		{"Unsampled", func(d EventDispatcher) []any {
+			return []any{SendEvent(context.Background(), d), RecordSpan(unsampled), SeverityMedium}
+		}, 0},
+		{"UnsampledHighSeverity", func(d EventDispatcher) []any {
+			return []any{RecordSpan(unsampled), SendEvent(context.Background(), d), SeverityHigh}
+		}, 1},
+		{"UnsampledCritical", func(d EventDispatcher) []any {
+			return []any{SendEvent(context.Background(), d), RecordSpan(unsampled), SeverityCritical}
+		}, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := &countingDispatcher{}
+			_ = New("test", tt.meta(d)...)
+			if d.events != tt.expected {
+				t.Errorf("expected %d events, got %d", tt.expected, d.events)
+			}
+		})
+	}
+
+	d := &countingDispatcher{}
+	inner := New("inner", RecordSpan(unsampled))
+	_ = Wrap(inner, "outer", SendEvent(context.Background(), d))
+	if d.events != 0 {
+		t.Errorf("expected span of the wrapped error to be respected, got %d events", d.events)
+	}
+}
+
+func TestGroup(t *testing.T) {
+	err := Wrap(New("connection refused", Group("db", "host", "db1")),
+		"query failed", Group("db", "query", "SELECT 1", "rows", 0, Group("pool", "size", 4)), Group("http", "status"))
+
+	if got := err.Error(); got != `query failed db.query="SELECT 1" db.rows=0 db.pool.size=4 http.status=<missing>: connection refused db.host=db1` {
+		t.Errorf("unexpected Error(): %s", got)
+	}
+
+	flat := LogFieldsMap(err, WithUserFields())
+	if flat["db.query"] != "SELECT 1" || flat["db.host"] != "db1" {
+		t.Errorf("expected dotted keys by default, got %v", flat)
+	}
+
+	nested := LogFieldsMap(err, WithUserFields(), WithNestedGroups())
+	db, ok := nested["db"].(map[string]any)
+	if !ok {
+		t.Fatalf("expected nested db group, got %v", nested)
+	}
+	if db["query"] != "SELECT 1" || db["rows"] != 0 || db["host"] != "db1" {
+		t.Errorf("unexpected db group: %v", db)
+	}
+	if pool, ok := db["pool"].(map[string]any); !ok || pool["size"] != 4 {
+		t.Errorf("expected nested pool group, got %v", db["pool"])
+	}
+
+	collision := LogFieldsMap(New("test", "db", "plain", Group("db", "query", "q")), WithUserFields(), WithNestedGroups())
+	if collision["db"] != "plain" || collision["db.query"] != "q" {
+		t.Errorf("expected dotted key on collision with a plain value, got %v", collision)
+	}
+}
+
+func TestHintAndDocURL(t *testing.T) {
+	inner := New("write denied", ClassPermissionDenied, Hint("check that the API key has write scope"), DocURL("https://docs.example.com/errors/write-denied"))
+	err := Wrap(inner, "upload failed")
+	if HintOf(err) != "check that the API key has write scope" || DocURLOf(err) != "https://docs.example.com/errors/write-denied" {
+		t.Errorf("expected hint and doc URL from the wrapped error, got %q %q", HintOf(err), DocURLOf(err))
+	}
+	if outer := Wrap(err, "sync failed", Hint("retry later")); HintOf(outer) != "retry later" {
+		t.Errorf("expected outer hint to win, got %q", HintOf(outer))
+	}
+
+	verbose := fmt.Sprintf("%+v", err)
+	if !strings.Contains(verbose, "\nHint: check that the API key has write scope\nDocs: https://docs.example.com/errors/write-denied") {
+		t.Errorf("expected hint and docs in %%+v, got %s", verbose)
+	}
+	if strings.Contains(fmt.Sprintf("%v", err), "Hint") {
+		t.Error("expected no hint without the + flag")
+	}
+
+	pd := ToProblemDetails(err)
+	if pd.Hint != HintOf(err) || pd.Type != DocURLOf(err) {
+		t.Errorf("unexpected problem details: %+v", pd)
+	}
+	if pd := ToProblemDetails(New("no docs")); pd.Type != "about:blank" || pd.Hint != "" {
+		t.Errorf("unexpected problem details without docs: %+v", pd)
+	}
+	if sanitized := Sanitize(err, SanitizePolicy{}); HintOf(sanitized) != "" || DocURLOf(sanitized) != DocURLOf(err) {
+		t.Errorf("expected sanitized error to keep only the doc URL, got %q %q", HintOf(sanitized), DocURLOf(sanitized))
+	}
+
+	data, jsonErr := json.Marshal(err)
+	if jsonErr != nil {
+		t.Fatal(jsonErr)
+	}
+	restored, jsonErr := FromJSON(data)
+	if jsonErr != nil {
+		t.Fatal(jsonErr)
+	}
+	if HintOf(restored) != HintOf(err) || DocURLOf(restored) != DocURLOf(err) {
+		t.Errorf("expected hint and doc URL to survive JSON, got %q %q", HintOf(restored), DocURLOf(restored))
+	}
+
+	tmpl := NewTemplate("quota exceeded", ClassRateLimited, Hint("raise the quota"), DocURL("https://docs.example.com/quota"))
+	if tmpl.Hint() != "raise the quota" || tmpl.DocURL() != "https://docs.example.com/quota" || HintOf(tmpl.New()) != "raise the quota" {
+		t.Errorf("unexpected template metadata: %q %q", tmpl.Hint(), tmpl.DocURL())
+	}
+}
+
+func TestUserMsg(t *testing.T) {
+	inner := New("card 4242 declined by issuer: insufficient funds", ClassValidation, UserMsg("Payment was declined, try another card"))
+	err := Wrap(inner, "charge failed")
+	if UserMessageOf(err) != "Payment was declined, try another card" {
+		t.Errorf("expected the user message from the wrapped error, got %q", UserMessageOf(err))
+	}
+	if outer := Wrap(err, "checkout failed", UserMsg("Checkout failed")); UserMessageOf(outer) != "Checkout failed" {
+		t.Errorf("expected outer user message to win, got %q", UserMessageOf(outer))
+	}
+
+	pd := ToProblemDetails(err)
+	if pd.Detail != UserMessageOf(err) || pd.TechnicalDetail != "" {
+		t.Errorf("public problem details must show only the user message: %+v", pd)
+	}
+	pd = ToProblemDetails(err, WithBilingual())
+	if pd.Detail != UserMessageOf(err) || pd.TechnicalDetail != err.Message() {
+		t.Errorf("expected both messages: %+v", pd)
+	}
+	if pd := ToProblemDetails(New("plain"), WithBilingual()); pd.Detail != "plain" || pd.TechnicalDetail != "" {
+		t.Errorf("unexpected problem details without a user message: %+v", pd)
+	}
+
+	if schema := ErrorToJSON(err); schema.UserMessage != "" {
+		t.Errorf("expected no user message without the option, got %q", schema.UserMessage)
+	}
+	schema := ErrorToJSON(err, WithBilingual())
+	if schema.Message != err.Message() || schema.UserMessage != UserMessageOf(err) {
+		t.Errorf("expected both messages in the schema: %q %q", schema.Message, schema.UserMessage)
+	}
+	data, jsonErr := json.Marshal(schema)
+	if jsonErr != nil {
+		t.Fatal(jsonErr)
+	}
+	restored, jsonErr := FromJSON(data)
+	if jsonErr != nil {
+		t.Fatal(jsonErr)
+	}
+	if UserMessageOf(restored) != UserMessageOf(err) {
+		t.Errorf("expected the user message to survive JSON, got %q", UserMessageOf(restored))
+	}
+
+	sanitized := Sanitize(New("db pool exhausted", ClassInternal, UserMsg("Try again later")), SanitizePolicy{})
+	if sanitized.Message() != "Try again later" || UserMessageOf(sanitized) != "Try again later" {
+		t.Errorf("expected the user message instead of the generic one, got %q", sanitized.Message())
+	}
+}
+
+func TestInterpolate(t *testing.T) {
+	err := New("user {user_id} not found in {table}", "user_id", 42, "token", Redact("secret"), Interpolate())
+	if err.Error() != "user 42 not found in {table} user_id=42 token=[REDACTED]" {
+		t.Errorf("unexpected error: %q", err.Error())
+	}
+	wrapped := Wrap(err, "load {user_id} for {token}", Interpolate())
+	if msg := wrapped.Message(); msg != "load 42 for [REDACTED]: user 42 not found in {table}" {
+		t.Errorf("expected placeholders to be filled from wrapped fields, got %q", msg)
+	}
+	other := New("user {user_id} not found in {table}", "user_id", 7, "token", Redact("secret"), Interpolate())
+	if Fingerprint(err) != Fingerprint(other) {
+		t.Error("expected fingerprint to use the raw message")
+	}
+	if err := New("user {user_id}", "user_id", 42); err.Message() != "user {user_id}" {
+		t.Errorf("expected no interpolation without the option, got %q", err.Message())
+	}
+}
+
+func TestTTL(t *testing.T) {
+	start := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	current := start
+	SetClock(func() time.Time { return current })
+	defer SetClock(nil)
+
+	root := New("user not found", ClassNotFound, TTL(30*time.Second))
+	err := Wrap(root, "lookup user")
+	if ttl, ok := err.TTL(); !ok || ttl != 30*time.Second {
+		t.Errorf("expected inherited TTL, got %v %v", ttl, ok)
+	}
+	if New("no ttl").Expired() || err.Expired() {
+		t.Error("expected fresh errors")
+	}
+
+	current = start.Add(30 * time.Second)
+	if !err.Expired() {
+		t.Error("expected error to expire after its TTL")
+	}
+	if Wrap(root, "cached", TTL(time.Minute)).Expired() {
+		t.Error("expected the TTL of the outermost error to win")
+	}
+
+	data, _ := json.Marshal(err)
+	decoded, decodeErr := FromJSON(data)
+	if decodeErr != nil {
+		t.Fatalf("unexpected error: %v", decodeErr)
+	}
+	if ttl, _ := decoded.TTL(); ttl != 30*time.Second || !decoded.Expired() {
+		t.Errorf("expected TTL to survive JSON, got %v", ttl)
+	}
+}
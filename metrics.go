@@ -0,0 +1,212 @@
+package erro
+
+import (
+	"context"
+	"sync"
+)
+
+const (
+	// DefaultOverflowLabel replaces label values over the limit of a [CardinalityGuard].
+	DefaultOverflowLabel = "other"
+	// DefaultMaxLabelValues is the default number of distinct values per label key of a [CardinalityGuard].
+	DefaultMaxLabelValues = 100
+)
+
+// LabeledErrorMetrics is an interface for recording error metrics with labels built from fields.
+type LabeledErrorMetrics interface {
+	RecordErrorWithLabels(err Error, labels map[string]string)
+}
+
+// CardinalityConfig configures a [CardinalityGuard].
+type CardinalityConfig struct {
+	// AllowedKeys is a list of field keys used as labels. Other fields never become labels.
+	// The keys "class", "category" and "severity" fall back to the error metadata.
+	AllowedKeys []string
+	// MaxValuesPerKey is the number of distinct values kept per key. Further values are
+	// replaced with OverflowLabel. Default is [DefaultMaxLabelValues].
+	MaxValuesPerKey int
+	// OverflowLabel replaces values over the limit. Default is [DefaultOverflowLabel].
+	OverflowLabel string
+}
+
+// CardinalityGuard builds metric labels from error fields with bounded cardinality.
+//
+// Only allowed keys become labels, so every error has the same label set; a missing
+// field has an empty value. The first MaxValuesPerKey distinct values of a key are
+// kept, later ones go to an overflow bucket and are counted, see [CardinalityGuard.Dropped].
+// It is safe for concurrent use.
+type CardinalityGuard struct {
+	keys     []string
+	max      int
+	overflow string
+
+	mu      sync.Mutex
+	values  map[string]map[string]struct{}
+	dropped map[string]int64
+}
+
+// NewCardinalityGuard creates a new [CardinalityGuard].
+//
+// Example:
+//
+//	guard := erro.NewCardinalityGuard(erro.CardinalityConfig{AllowedKeys: []string{"endpoint", "class"}})
+//	err := erro.New("request failed", "endpoint", path, erro.RecordMetrics(guard.Metrics(recorder)))
+func NewCardinalityGuard(cfg CardinalityConfig) *CardinalityGuard {
+	g := &CardinalityGuard{
+		keys:     append([]string(nil), cfg.AllowedKeys...),
+		max:      cfg.MaxValuesPerKey,
+		overflow: cfg.OverflowLabel,
+		values:   make(map[string]map[string]struct{}, len(cfg.AllowedKeys)),
+		dropped:  make(map[string]int64),
+	}
+	if g.max <= 0 {
+		g.max = DefaultMaxLabelValues
+	}
+	if g.overflow == "" {
+		g.overflow = DefaultOverflowLabel
+	}
+	return g
+}
+
+// Labels returns the labels of the error for the allowed keys. It returns nil for nil.
+func (g *CardinalityGuard) Labels(err error) map[string]string {
+	if err == nil {
+		return nil
+	}
+	e := ExtractError(err)
+	fields := e.AllFields()
+
+	labels := make(map[string]string, len(g.keys))
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	for _, key := range g.keys {
+		labels[key] = g.admit(key, keyFieldValue(e, fields, key))
+	}
+	return labels
+}
+
+// admit returns the value or the overflow label if the key has too many distinct values.
+func (g *CardinalityGuard) admit(key, value string) string {
+	seen, ok := g.values[key]
+	if !ok {
+		seen = make(map[string]struct{})
+		g.values[key] = seen
+	}
+	if _, ok := seen[value]; ok {
+		return value
+	}
+	if len(seen) >= g.max {
+		g.dropped[key]++
+		return g.overflow
+	}
+	seen[value] = struct{}{}
+	return value
+}
+
+// Dropped returns the number of label values replaced with the overflow label per key.
+func (g *CardinalityGuard) Dropped() map[string]int64 {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	dropped := make(map[string]int64, len(g.dropped))
+	for k, v := range g.dropped {
+		dropped[k] = v
+	}
+	return dropped
+}
+
+// Metrics returns [ErrorMetrics] that records errors to m with the labels of the guard,
+// to be used with [RecordMetrics].
+func (g *CardinalityGuard) Metrics(m LabeledErrorMetrics) ErrorMetrics {
+	return guardedMetrics{guard: g, metrics: m}
+}
+
+type guardedMetrics struct {
+	guard   *CardinalityGuard
+	metrics LabeledErrorMetrics
+}
+
+func (m guardedMetrics) RecordError(err Error) {
+	if m.metrics == nil || err == nil {
+		return
+	}
+	m.metrics.RecordErrorWithLabels(err, m.guard.Labels(err))
+}
+
+// AutoRule selects errors for [WithAutoMetrics] and [WithAutoEvents].
+type AutoRule func(err Error) bool
+
+// MinSeverity selects errors with the severity or higher.
+func MinSeverity(severity ErrorSeverity) AutoRule {
+	return func(err Error) bool {
+		return err.Severity().rank() >= severity.rank()
+	}
+}
+
+// ExceptClasses selects errors with a class other than the given ones.
+func ExceptClasses(classes ...ErrorClass) AutoRule {
+	return func(err Error) bool {
+		class := err.Class()
+		for _, c := range classes {
+			if c == class {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+// Categories selects errors of one of the categories.
+func Categories(categories ...ErrorCategory) AutoRule {
+	return func(err Error) bool {
+		category := err.Category()
+		for _, c := range categories {
+			if c == category {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// Flags of [baseError.autoDone].
+const (
+	autoMetricsDone uint8 = 1 << iota
+	autoEventsDone
+)
+
+type autoPolicy struct {
+	rules   []AutoRule
+	metrics ErrorMetrics
+	events  EventDispatcher
+}
+
+func (p *autoPolicy) matches(err Error) bool {
+	for _, rule := range p.rules {
+		if rule != nil && !rule(err) {
+			return false
+		}
+	}
+	return true
+}
+
+// runAutoPolicies records a new error with [WithAutoMetrics] and [WithAutoEvents]
+// unless a wrapped layer of the chain was already recorded.
+func runAutoPolicies(e *baseError) {
+	m, d := autoMetrics.Load(), autoEvents.Load()
+	if m == nil && d == nil {
+		return
+	}
+	if e.wrappedErr != nil {
+		e.autoDone = e.wrappedErr.autoDone
+	}
+	if m != nil && e.autoDone&autoMetricsDone == 0 && m.matches(e) {
+		e.autoDone |= autoMetricsDone
+		m.metrics.RecordError(e)
+		lifecycle(StageRecorded, e)
+	}
+	if d != nil && e.autoDone&autoEventsDone == 0 && isEventSampled(e) && d.matches(e) {
+		e.autoDone |= autoEventsDone
+		d.events.SendEvent(context.Background(), e)
+		lifecycle(StageDispatched, e)
+	}
+}
@@ -0,0 +1,115 @@
+package erro
+
+import (
+	"context"
+	"io"
+	"reflect"
+	"strconv"
+	"sync"
+	"testing"
+)
+
+type labeledMetrics struct {
+	mu     sync.Mutex
+	labels []map[string]string
+}
+
+func (m *labeledMetrics) RecordErrorWithLabels(_ Error, labels map[string]string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.labels = append(m.labels, labels)
+}
+
+func TestCardinalityGuard(t *testing.T) {
+	guard := NewCardinalityGuard(CardinalityConfig{AllowedKeys: []string{"endpoint", "class"}, MaxValuesPerKey: 2})
+	if guard.Labels(nil) != nil {
+		t.Error("expected nil labels for nil")
+	}
+
+	labels := guard.Labels(New("failed", ClassTimeout, "endpoint", "/a", "user_id", 1))
+	if !reflect.DeepEqual(labels, map[string]string{"endpoint": "/a", "class": "timeout"}) {
+		t.Errorf("unexpected labels: %v", labels)
+	}
+	if labels := guard.Labels(New("failed")); labels["endpoint"] != "" || len(labels) != 2 {
+		t.Errorf("expected empty value for missing field, got %v", labels)
+	}
+
+	for i := 0; i < 3; i++ {
+		labels = guard.Labels(New("failed", "endpoint", "/user/"+strconv.Itoa(i)))
+	}
+	if labels["endpoint"] != DefaultOverflowLabel {
+		t.Errorf("expected overflow label, got %v", labels)
+	}
+	if labels := guard.Labels(New("failed", "endpoint", "/a")); labels["endpoint"] != "/a" {
+		t.Errorf("expected known value to be kept after overflow, got %v", labels)
+	}
+	if dropped := guard.Dropped(); dropped["endpoint"] != 3 || dropped["class"] != 0 {
+		t.Errorf("unexpected dropped counts: %v", dropped)
+	}
+
+	recorder := &labeledMetrics{}
+	guard = NewCardinalityGuard(CardinalityConfig{AllowedKeys: []string{"code"}, OverflowLabel: "overflow"})
+	New("failed", "code", 500, RecordMetrics(guard.Metrics(recorder)))
+	if len(recorder.labels) != 1 || recorder.labels[0]["code"] != "500" {
+		t.Errorf("unexpected recorded labels: %v", recorder.labels)
+	}
+	guard.Metrics(nil).RecordError(New("no recorder")) // should not panic
+}
+
+type messageMetrics struct {
+	messages []string
+}
+
+func (m *messageMetrics) RecordError(err Error) {
+	m.messages = append(m.messages, err.Message())
+}
+
+func TestAutoMetricsAndEvents(t *testing.T) {
+	metrics := &messageMetrics{}
+	dispatcher := &countingDispatcher{}
+	Configure(
+		WithAutoMetrics(metrics, ExceptClasses(ClassValidation)),
+		WithAutoEvents(dispatcher, MinSeverity(SeverityHigh)),
+	)
+	defer Configure(WithAutoMetrics(nil), WithAutoEvents(nil))
+
+	root := New("db down")
+	err := Wrap(root, "load user", SeverityHigh)
+	_ = Wrap(err, "handle request", SeverityCritical)
+	_ = New("bad input", ClassValidation, SeverityCritical)
+	_ = Wrap(context.Canceled, "wait")
+
+	if !reflect.DeepEqual(metrics.messages, []string{"db down", "wait: context canceled"}) {
+		t.Errorf("expected every chain recorded once, got %v", metrics.messages)
+	}
+	if dispatcher.events != 2 {
+		t.Errorf("expected events for the first high severity layer of each chain, got %d", dispatcher.events)
+	}
+
+	Configure(WithAutoMetrics(nil), WithAutoEvents(nil))
+	_ = New("not recorded", SeverityCritical)
+	if len(metrics.messages) != 2 || dispatcher.events != 2 {
+		t.Error("expected nothing recorded after disabling")
+	}
+}
+
+func TestAutoMetricsSkipInspection(t *testing.T) {
+	metrics := &messageMetrics{}
+	dispatcher := &countingDispatcher{}
+	Configure(WithAutoMetrics(metrics), WithAutoEvents(dispatcher))
+	defer Configure(WithAutoMetrics(nil), WithAutoEvents(nil))
+
+	for i := 0; i < 3; i++ {
+		_ = HTTPCode(io.EOF)
+		_ = LogFields(io.EOF)
+		_ = ExtractError(io.EOF)
+	}
+	if len(metrics.messages) != 0 || dispatcher.events != 0 {
+		t.Errorf("expected no metrics and events for inspected errors, got %v and %d events", metrics.messages, dispatcher.events)
+	}
+
+	_ = Wrap(io.EOF, "read")
+	if len(metrics.messages) != 1 {
+		t.Errorf("expected wrapped error to be recorded, got %v", metrics.messages)
+	}
+}
@@ -0,0 +1,189 @@
+// Package mq provides a consumer helper for message queues (Kafka, NATS, etc.) that
+// turns handler failures into classified errors and decides whether a message
+// should be retried or sent to a dead-letter queue.
+//
+// It does not depend on any broker client: adapt the client's message to [Message]
+// and act on the returned [Result].
+package mq
+
+import (
+	"context"
+	"errors"
+
+	"github.com/maxbolgarin/erro"
+)
+
+// Field keys attached by [WrapHandler].
+const (
+	FieldSubject     = "mq_subject"
+	FieldPartition   = "mq_partition"
+	FieldOffset      = "mq_offset"
+	FieldDisposition = "mq_disposition"
+)
+
+// Messages used for wrapped handler errors.
+const (
+	HandleFailedMessage = "handle message"
+	PanicMessage        = "message handler panicked"
+)
+
+// Message is the broker-independent metadata of a consumed message.
+type Message struct {
+	// Subject is a Kafka topic, NATS subject or a queue name.
+	Subject string
+	// Partition is a Kafka partition (zero for brokers without partitions).
+	Partition int32
+	// Offset is a Kafka offset or a stream sequence number.
+	Offset int64
+	// Attempt is the 1-based delivery attempt, zero if the broker does not track it.
+	Attempt int
+}
+
+// Handler processes a single message.
+type Handler func(ctx context.Context, msg Message) error
+
+// Disposition tells the consumer what to do with a message after handling.
+type Disposition string
+
+const (
+	// DispositionAck means the message was handled and should be acknowledged.
+	DispositionAck Disposition = "ack"
+	// DispositionRetry means the failure is transient and the message should be redelivered.
+	DispositionRetry Disposition = "retry"
+	// DispositionDeadLetter means the message is poison or out of attempts and should be
+	// moved to a dead-letter queue.
+	DispositionDeadLetter Disposition = "dead_letter"
+)
+
+// Kind is the kind of a handler failure.
+type Kind string
+
+const (
+	// KindTransient is a failure that may succeed on redelivery.
+	KindTransient Kind = "transient"
+	// KindPoison is a failure that will repeat for the same message.
+	KindPoison Kind = "poison"
+)
+
+// Result is the outcome of handling a message.
+type Result struct {
+	Disposition Disposition
+	// Err is the classified error, nil for [DispositionAck].
+	Err erro.Error
+}
+
+// Option configures [WrapHandler].
+type Option func(*options)
+
+type options struct {
+	metrics     erro.ErrorMetrics
+	maxAttempts int
+}
+
+// WithMetrics reports every failure to the metrics recorder.
+func WithMetrics(m erro.ErrorMetrics) Option {
+	return func(o *options) {
+		o.metrics = m
+	}
+}
+
+// WithMaxAttempts sends transient failures to the dead-letter queue once
+// [Message.Attempt] reaches n. Zero means unlimited retries.
+func WithMaxAttempts(n int) Option {
+	return func(o *options) {
+		if n > 0 {
+			o.maxAttempts = n
+		}
+	}
+}
+
+// WrapHandler returns a handler that recovers panics, classifies failures with
+// [Classify] and decides what to do with the message: transient failures are
+// retried until the attempts are exhausted, poison messages and panics go to the
+// dead-letter queue. Panics are converted with [erro.FromPanic]. Errors get subject,
+// partition, offset and disposition fields.
+//
+// Example:
+//
+//	handle := mq.WrapHandler(process, mq.WithMetrics(metrics), mq.WithMaxAttempts(5))
+//	for record := range records {
+//	    res := handle(ctx, mq.Message{Subject: record.Topic, Partition: record.Partition, Offset: record.Offset})
+//	    switch res.Disposition {
+//	    case mq.DispositionRetry:
+//	        // do not commit the offset
+//	    case mq.DispositionDeadLetter:
+//	        // publish to the DLQ, then commit
+//	    }
+//	}
+func WrapHandler(h Handler, opts ...Option) func(ctx context.Context, msg Message) Result {
+	var o options
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return func(ctx context.Context, msg Message) Result {
+		err, panicked := handle(ctx, h, msg)
+		if err == nil {
+			return Result{Disposition: DispositionAck}
+		}
+
+		disposition := DispositionDeadLetter
+		if !panicked && Classify(err) == KindTransient &&
+			(o.maxAttempts == 0 || msg.Attempt < o.maxAttempts) {
+			disposition = DispositionRetry
+		}
+
+		meta := []any{
+			FieldSubject, msg.Subject,
+			FieldPartition, msg.Partition,
+			FieldOffset, msg.Offset,
+			FieldDisposition, string(disposition),
+		}
+		if msg.Attempt > 0 {
+			meta = append(meta, erro.Attempt(msg.Attempt))
+		}
+		if o.maxAttempts > 0 {
+			meta = append(meta, erro.MaxAttempts(o.maxAttempts))
+		}
+		if disposition == DispositionRetry {
+			meta = append(meta, erro.Retryable())
+		}
+		if o.metrics != nil {
+			meta = append(meta, erro.RecordMetrics(o.metrics))
+		}
+		return Result{Disposition: disposition, Err: erro.Wrap(err, HandleFailedMessage, meta...)}
+	}
+}
+
+// Classify returns whether a handler failure is transient or poison.
+//
+// Retryable errors, context errors and errors of transient classes (timeout,
+// temporary, unavailable, rate limited, resource exhausted) are transient;
+// everything else is poison.
+func Classify(err error) Kind {
+	if err == nil {
+		return KindPoison
+	}
+	if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, context.Canceled) {
+		return KindTransient
+	}
+	e := erro.ExtractError(err)
+	if e.IsRetryable() {
+		return KindTransient
+	}
+	switch e.Class() {
+	case erro.ClassTimeout, erro.ClassTemporary, erro.ClassUnavailable,
+		erro.ClassRateLimited, erro.ClassResourceExhausted:
+		return KindTransient
+	}
+	return KindPoison
+}
+
+func handle(ctx context.Context, h Handler, msg Message) (err error, panicked bool) {
+	defer func() {
+		if r := recover(); r != nil {
+			panicked = true
+			err = erro.Wrap(erro.FromPanic(r), PanicMessage)
+		}
+	}()
+	return h(ctx, msg), false
+}
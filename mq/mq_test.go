@@ -0,0 +1,116 @@
+package mq
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/maxbolgarin/erro"
+)
+
+type countingMetrics struct {
+	errors []erro.Error
+}
+
+func (m *countingMetrics) RecordError(err erro.Error) {
+	m.errors = append(m.errors, err)
+}
+
+func TestWrapHandlerAck(t *testing.T) {
+	handle := WrapHandler(func(ctx context.Context, msg Message) error { return nil })
+	res := handle(context.Background(), Message{Subject: "orders"})
+	if res.Disposition != DispositionAck || res.Err != nil {
+		t.Errorf("expected ack without error, got %v %v", res.Disposition, res.Err)
+	}
+}
+
+func TestWrapHandlerDisposition(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		attempt  int
+		expected Disposition
+	}{
+		{"Retryable", erro.New("broker busy", erro.Retryable()), 1, DispositionRetry},
+		{"TransientClass", erro.New("timeout", erro.ClassTimeout), 1, DispositionRetry},
+		{"Context", context.DeadlineExceeded, 1, DispositionRetry},
+		{"Poison", erro.New("bad payload", erro.ClassValidation), 1, DispositionDeadLetter},
+		{"Plain", errors.New("unknown"), 1, DispositionDeadLetter},
+		{"AttemptsExhausted", erro.New("broker busy", erro.Retryable()), 3, DispositionDeadLetter},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handle := WrapHandler(func(ctx context.Context, msg Message) error { return tt.err }, WithMaxAttempts(3))
+			res := handle(context.Background(), Message{Subject: "orders", Attempt: tt.attempt})
+			if res.Disposition != tt.expected {
+				t.Errorf("expected %s, got %s", tt.expected, res.Disposition)
+			}
+			if !errors.Is(res.Err, tt.err) {
+				t.Errorf("expected result error to wrap the handler error")
+			}
+			if tt.expected == DispositionRetry && !res.Err.IsRetryable() {
+				t.Errorf("expected retried error to be retryable")
+			}
+		})
+	}
+}
+
+func TestWrapHandlerFields(t *testing.T) {
+	metrics := &countingMetrics{}
+	handle := WrapHandler(func(ctx context.Context, msg Message) error {
+		return erro.New("bad payload")
+	}, WithMetrics(metrics), WithMaxAttempts(5))
+
+	res := handle(context.Background(), Message{Subject: "orders", Partition: 3, Offset: 42, Attempt: 2})
+	fields := res.Err.Fields()
+	expected := map[string]any{
+		FieldSubject:     "orders",
+		FieldPartition:   int32(3),
+		FieldOffset:      int64(42),
+		FieldDisposition: string(DispositionDeadLetter),
+	}
+	for i := 0; i+1 < len(fields); i += 2 {
+		if want, ok := expected[fields[i].(string)]; ok {
+			if fields[i+1] != want {
+				t.Errorf("field %s: expected %v, got %v", fields[i], want, fields[i+1])
+			}
+			delete(expected, fields[i].(string))
+		}
+	}
+	if len(expected) > 0 {
+		t.Errorf("missing fields: %v", expected)
+	}
+	if res.Err.Attempt() != 2 || res.Err.MaxAttempts() != 5 {
+		t.Errorf("expected attempt 2 of 5, got %d of %d", res.Err.Attempt(), res.Err.MaxAttempts())
+	}
+	if len(metrics.errors) != 1 || metrics.errors[0] != res.Err {
+		t.Errorf("expected failure to be recorded once, got %d", len(metrics.errors))
+	}
+}
+
+func TestWrapHandlerPanic(t *testing.T) {
+	handle := WrapHandler(func(ctx context.Context, msg Message) error {
+		panic("nil map")
+	})
+	res := handle(context.Background(), Message{Subject: "orders"})
+	if res.Disposition != DispositionDeadLetter {
+		t.Errorf("expected panic to be dead-lettered, got %s", res.Disposition)
+	}
+	if res.Err.Class() != erro.ClassCritical || res.Err.Severity() != erro.SeverityCritical {
+		t.Errorf("unexpected panic classification: %s %s", res.Err.Class(), res.Err.Severity())
+	}
+	if res.Err.Stack() == nil {
+		t.Errorf("expected panic error to have a stack trace")
+	}
+	if v, _ := erro.Field[string](res.Err, erro.PanicValueField); v != "nil map" {
+		t.Errorf("expected panic value field, got %q", v)
+	}
+
+	panicErr := errors.New("boom")
+	handle = WrapHandler(func(ctx context.Context, msg Message) error {
+		panic(panicErr)
+	})
+	if res := handle(context.Background(), Message{}); !errors.Is(res.Err, panicErr) {
+		t.Errorf("expected panic error value to be wrapped")
+	}
+}
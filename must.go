@@ -0,0 +1,91 @@
+package erro
+
+import "log"
+
+// MustFailedMessage is the message of errors wrapped by [Must] and [Should].
+const MustFailedMessage = "unexpected error"
+
+// PanicError is the value [Must] panics with. It wraps the classified error, so the
+// metadata is kept for code that recovers the panic.
+type PanicError struct {
+	Err Error
+}
+
+// Error returns the message of the wrapped error.
+func (p PanicError) Error() string {
+	return p.Err.Error()
+}
+
+// Unwrap returns the wrapped error.
+func (p PanicError) Unwrap() error {
+	return p.Err
+}
+
+var shouldHandler atomicValue[func(err Error)]
+
+// SetShouldHandler sets the handler called by [Should] for non-nil errors.
+// By default errors are printed with the standard log package. Passing nil restores the default.
+//
+// Example:
+//
+//	erro.SetShouldHandler(func(err erro.Error) {
+//	    erro.LogError(err, logger.Warn)
+//	})
+func SetShouldHandler(handler func(err Error)) {
+	if handler == nil {
+		handler = defaultShouldHandler
+	}
+	shouldHandler.Store(handler)
+}
+
+// Must returns the value if err is nil and panics with a [PanicError] otherwise.
+// The error keeps its class and severity; errors without them are classified as
+// [ClassInternal] and [SeverityCritical]. A stack trace is captured at the call site.
+// It is intended for initialization code where an error is a programming mistake.
+//
+// Example:
+//
+//	var tmpl = erro.Must(template.New("page").Parse(page))
+func Must[T any](v T, err error) T {
+	if err != nil {
+		panic(PanicError{Err: wrapf(err, MustFailedMessage, classifyMust(err, SeverityCritical)...)})
+	}
+	return v
+}
+
+// Should returns the value (zero or partial) and reports a non-nil error to the
+// handler set with [SetShouldHandler]. The error is classified as in [Must], except that
+// errors without a severity get [SeverityHigh], since the program keeps running.
+// It is intended for tooling and best-effort code where an error should not stop the program.
+//
+// Example:
+//
+//	cfg := erro.Should(loadOptionalConfig(path))
+func Should[T any](v T, err error) T {
+	if err != nil {
+		handler := shouldHandler.Load()
+		if handler == nil {
+			handler = defaultShouldHandler
+		}
+		handler(wrapf(err, MustFailedMessage, classifyMust(err, SeverityHigh)...))
+	}
+	return v
+}
+
+// classifyMust returns the options for an error of [Must] and [Should]: a stack trace,
+// [ClassInternal] and the given severity if the error has no class or severity.
+func classifyMust(err error, severity ErrorSeverity) []any {
+	meta := []any{StackTrace()}
+	e := ExtractError(err)
+	if e.Class() == ClassUnknown {
+		meta = append(meta, ClassInternal)
+	}
+	if e.Severity() == SeverityUnknown {
+		meta = append(meta, severity)
+	}
+	return meta
+}
+
+func defaultShouldHandler(err Error) {
+	log.Print(err.Error())
+}
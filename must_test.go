@@ -0,0 +1,52 @@
+package erro
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestMust(t *testing.T) {
+	if v := Must(42, nil); v != 42 {
+		t.Errorf("expected 42, got %d", v)
+	}
+
+	baseErr := New("not found", ClassNotFound)
+	defer func() {
+		pe, ok := recover().(PanicError)
+		if !ok {
+			t.Fatal("expected Must to panic with PanicError")
+		}
+		if !errors.Is(pe, baseErr) {
+			t.Errorf("expected panic error to wrap the original error")
+		}
+		if pe.Err.Class() != ClassNotFound || pe.Err.Severity() != SeverityCritical {
+			t.Errorf("unexpected classification: %s %s", pe.Err.Class(), pe.Err.Severity())
+		}
+		if top := pe.Err.Stack().TopUserFrame(); top == nil || !strings.Contains(top.FullName, "TestMust") {
+			t.Errorf("expected stack to start in the caller, got %v", top)
+		}
+	}()
+	Must(0, baseErr)
+}
+
+func TestShould(t *testing.T) {
+	var handled []Error
+	SetShouldHandler(func(err Error) { handled = append(handled, err) })
+	defer SetShouldHandler(nil)
+
+	if v := Should("value", nil); v != "value" || len(handled) != 0 {
+		t.Errorf("expected value without calling the handler, got %q", v)
+	}
+
+	baseErr := errors.New("partial read")
+	if v := Should("partial", baseErr); v != "partial" {
+		t.Errorf("expected partial value to be returned, got %q", v)
+	}
+	if len(handled) != 1 || !errors.Is(handled[0], baseErr) {
+		t.Fatalf("expected handler to be called with the error, got %v", handled)
+	}
+	if handled[0].Class() != ClassInternal || handled[0].Severity() != SeverityHigh {
+		t.Errorf("unexpected classification: %s %s", handled[0].Class(), handled[0].Severity())
+	}
+}
@@ -0,0 +1,160 @@
+package erro
+
+import (
+	"fmt"
+	"log"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// OptionDiagnosticsMode defines what happens when options are misused at a call site of
+// [New], [Wrap] and other constructors, see [WithOptionDiagnostics].
+type OptionDiagnosticsMode int32
+
+const (
+	// OptionDiagnosticsOff disables the checks of options. It is the default.
+	OptionDiagnosticsOff OptionDiagnosticsMode = iota
+	// OptionDiagnosticsLog prints misused options with the standard log package.
+	OptionDiagnosticsLog
+	// OptionDiagnosticsPanic panics with an error wrapping [ErrOptionMisuse].
+	OptionDiagnosticsPanic
+)
+
+// idUnset marks the id of an error while an option is applied, to notice [ID] options.
+const idUnset = "\x00"
+
+// optionDiagnostics collects misused options of one constructor call.
+type optionDiagnostics struct {
+	problems   []string
+	ids        int
+	classes    int
+	categories int
+	severities int
+}
+
+// newOptionDiagnostics returns nil if the diagnostics are disabled.
+func newOptionDiagnostics() *optionDiagnostics {
+	if optionDiagnosticsMode.Load() == OptionDiagnosticsOff {
+		return nil
+	}
+	return &optionDiagnostics{}
+}
+
+// checkPosition reports an option passed in place of a field value.
+func (d *optionDiagnostics) checkPosition(option any, preparedFields []any) {
+	if d == nil || len(preparedFields)%2 == 0 {
+		return
+	}
+	d.problems = append(d.problems, fmt.Sprintf("option %s is passed as the value of field %q",
+		optionName(option), valueToString(preparedFields[len(preparedFields)-1])))
+}
+
+// countID counts an option that set the id of the error and restores the previous id
+// otherwise. The id must be set to idUnset before the option is applied.
+func (d *optionDiagnostics) countID(e *baseError, previous string) {
+	if e.id == idUnset {
+		e.id = previous
+		return
+	}
+	d.ids++
+}
+
+// count counts the metadata option.
+func (d *optionDiagnostics) count(option any) {
+	if d == nil {
+		return
+	}
+	switch option.(type) {
+	case ErrorClass:
+		d.classes++
+	case ErrorCategory:
+		d.categories++
+	case ErrorSeverity:
+		d.severities++
+	}
+}
+
+// report logs or panics with the problems found in one constructor call.
+func (d *optionDiagnostics) report(e *baseError) {
+	if d == nil {
+		return
+	}
+	for _, c := range []struct {
+		name  string
+		count int
+	}{{"ID", d.ids}, {"class", d.classes}, {"category", d.categories}, {"severity", d.severities}} {
+		if c.count > 1 {
+			d.problems = append(d.problems, fmt.Sprintf("%s is set %d times", c.name, c.count))
+		}
+	}
+	if len(d.problems) == 0 {
+		return
+	}
+	reportOptionMisuse(e, optionCallSite(), strings.Join(d.problems, "; "))
+}
+
+// reportOptionMisuse is set in init, as [ErrOptionMisuse] itself is created with New.
+var reportOptionMisuse func(e *baseError, site, problems string)
+
+func init() {
+	reportOptionMisuse = func(e *baseError, site, problems string) {
+		if optionDiagnosticsMode.Load() == OptionDiagnosticsPanic {
+			panic(Wrap(ErrOptionMisuse, buildMessage(e), "problems", problems, "call_site", site))
+		}
+		log.Printf("erro: misused options of %q at %s: %s", buildMessage(e), site, problems)
+	}
+}
+
+// isNilOption reports whether the value is nil or a nil option function.
+func isNilOption(option any) bool {
+	switch val := option.(type) {
+	case nil:
+		return true
+	case errorOpt:
+		return val == nil
+	case errorFields:
+		return val == nil
+	case errorWork:
+		return val == nil
+	case spanWork:
+		return val == nil
+	}
+	return false
+}
+
+// optionName returns a readable name of an option for the diagnostics.
+func optionName(option any) string {
+	switch val := option.(type) {
+	case ErrorClass:
+		return "class " + string(val)
+	case ErrorCategory:
+		return "category " + string(val)
+	case ErrorSeverity:
+		return "severity " + string(val)
+	default:
+		return fmt.Sprintf("%T", option)
+	}
+}
+
+var erroSourceDir = func() string {
+	_, file, _, _ := runtime.Caller(0)
+	return filepath.Dir(file)
+}()
+
+// optionCallSite returns the file and line of the first caller outside of the package.
+func optionCallSite() string {
+	var pcs [32]uintptr
+	n := runtime.Callers(2, pcs[:])
+	frames := runtime.CallersFrames(pcs[:n])
+	for {
+		frame, more := frames.Next()
+		internal := filepath.Dir(frame.File) == erroSourceDir && !strings.HasSuffix(frame.File, "_test.go")
+		if !internal && !isUselessRuntimeFrame(frame.Function, frame.File) {
+			return fmt.Sprintf("%s:%d", frame.File, frame.Line)
+		}
+		if !more {
+			return "unknown"
+		}
+	}
+}
@@ -0,0 +1,68 @@
+package erro
+
+import (
+	"bytes"
+	"errors"
+	"log"
+	"strings"
+	"testing"
+)
+
+func TestOptionDiagnostics(t *testing.T) {
+	var nilOpt errorOpt
+	var nilFields errorFields
+	err := New("nil options", nilOpt, nilFields, RecordMetrics(nil), "key", "value")
+	if fields := err.Fields(); len(fields) != 2 || fields[1] != "value" {
+		t.Errorf("expected nil options to be skipped, got %v", fields)
+	}
+	if err := New("misused", "id", ID("x")); err.ID() != "x" {
+		t.Errorf("expected no diagnostics by default, got id %q", err.ID())
+	}
+
+	t.Run("Log", func(t *testing.T) {
+		var buf bytes.Buffer
+		defer log.SetOutput(log.Writer())
+		log.SetOutput(&buf)
+		Configure(WithOptionDiagnostics(OptionDiagnosticsLog))
+		defer Configure(WithOptionDiagnostics(OptionDiagnosticsOff))
+
+		err := New("misused", "id", ID("a"), ClassInternal, ClassTimeout, ID("b"))
+		if err.ID() != "b" || err.Class() != ClassTimeout {
+			t.Errorf("expected options to be applied, got id %q and class %q", err.ID(), err.Class())
+		}
+		out := buf.String()
+		for _, want := range []string{
+			`option erro.errorOpt is passed as the value of field "id"`,
+			"ID is set 2 times", "class is set 2 times", "options_test.go:",
+		} {
+			if !strings.Contains(out, want) {
+				t.Errorf("expected log output to contain %q, got %q", want, out)
+			}
+		}
+
+		buf.Reset()
+		New("fine", "key", "value", ID("a"), ClassInternal)
+		Wrap(New("inner", ID("a")), "outer", ID("b"))
+		if buf.Len() != 0 {
+			t.Errorf("expected no diagnostics for correct options, got %q", buf.String())
+		}
+	})
+
+	t.Run("Panic", func(t *testing.T) {
+		Configure(WithOptionDiagnostics(OptionDiagnosticsPanic))
+		defer Configure(WithOptionDiagnostics(OptionDiagnosticsOff))
+
+		defer func() {
+			err, ok := recover().(error)
+			if !ok || !errors.Is(err, ErrOptionMisuse) {
+				t.Fatalf("expected panic with ErrOptionMisuse, got %v", err)
+			}
+			m := LogFieldsMap(err, WithUserFields())
+			if !strings.Contains(m["problems"].(string), "severity is set 2 times") ||
+				!strings.Contains(m["call_site"].(string), "options_test.go:") {
+				t.Errorf("unexpected panic fields: %v", m)
+			}
+		}()
+		New("misused", SeverityHigh, SeverityLow)
+	})
+}
@@ -0,0 +1,496 @@
+// Package otlp exports errors as OpenTelemetry log records over OTLP/HTTP with the
+// JSON encoding, so a collector pipeline receives structured error telemetry without
+// scraping stdout.
+//
+// It does not depend on the OpenTelemetry SDK: an [Exporter] is an [erro.EventDispatcher]
+// and an [erro.ErrorMetrics] that batches records and posts them to the logs endpoint
+// of a collector.
+package otlp
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"reflect"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/maxbolgarin/erro"
+)
+
+// Defaults of [NewExporter].
+const (
+	DefaultEndpoint      = "http://localhost:4318/v1/logs"
+	DefaultBatchSize     = 100
+	DefaultMaxQueueSize  = 2048
+	DefaultFlushInterval = 5 * time.Second
+)
+
+// ScopeName is the instrumentation scope of exported records.
+const ScopeName = "github.com/maxbolgarin/erro"
+
+// ExportFailedMessage is the message of errors returned when a collector rejects a batch.
+const ExportFailedMessage = "export logs"
+
+// Option configures [NewExporter].
+type Option func(*options)
+
+type options struct {
+	client        *http.Client
+	header        http.Header
+	resource      []KeyValue
+	logOptions    []erro.LogOption
+	batchSize     int
+	maxQueueSize  int
+	flushInterval time.Duration
+	onError       func(err error)
+}
+
+// WithHeader adds a header to export requests, e.g. an API key of the collector.
+func WithHeader(key, value string) Option {
+	return func(o *options) {
+		o.header.Add(key, value)
+	}
+}
+
+// WithClient sets the HTTP client of export requests. Default is a client with a 10 second timeout.
+func WithClient(client *http.Client) Option {
+	return func(o *options) {
+		if client != nil {
+			o.client = client
+		}
+	}
+}
+
+// WithResource adds a resource attribute of exported records, e.g. "service.name".
+func WithResource(key string, value any) Option {
+	return func(o *options) {
+		o.resource = append(o.resource, KeyValue{Key: key, Value: Value(value)})
+	}
+}
+
+// WithLogOptions sets the options of [erro.LogFields] that build the attributes of a record.
+// Default is [erro.DefaultLogOptions] with the error ID.
+func WithLogOptions(opts ...erro.LogOption) Option {
+	return func(o *options) {
+		o.logOptions = opts
+	}
+}
+
+// WithBatchSize sets the number of records that triggers an export. Default is [DefaultBatchSize].
+func WithBatchSize(n int) Option {
+	return func(o *options) {
+		if n > 0 {
+			o.batchSize = n
+		}
+	}
+}
+
+// WithMaxQueueSize sets the number of buffered records after which new records are dropped
+// until the next export. Default is [DefaultMaxQueueSize].
+func WithMaxQueueSize(n int) Option {
+	return func(o *options) {
+		if n > 0 {
+			o.maxQueueSize = n
+		}
+	}
+}
+
+// WithFlushInterval sets the interval of background exports. Default is [DefaultFlushInterval].
+func WithFlushInterval(d time.Duration) Option {
+	return func(o *options) {
+		if d > 0 {
+			o.flushInterval = d
+		}
+	}
+}
+
+// WithErrorHandler sets the handler of errors of background exports and dropped records.
+// By default they are printed with the standard log package.
+func WithErrorHandler(handler func(err error)) Option {
+	return func(o *options) {
+		if handler != nil {
+			o.onError = handler
+		}
+	}
+}
+
+// Exporter batches errors as log records and exports them to an OTLP/HTTP logs endpoint
+// in the background. It is safe for concurrent use; call [Exporter.Close] on shutdown to
+// export the buffered records.
+type Exporter struct {
+	endpoint string
+	opts     options
+
+	mu      sync.Mutex
+	records []LogRecord
+	dropped int
+	closed  bool
+
+	flush chan struct{}
+	done  chan struct{}
+	wg    sync.WaitGroup
+}
+
+// NewExporter creates an [Exporter] that posts records to the endpoint, [DefaultEndpoint]
+// if it is empty, and starts its background export.
+//
+// Example:
+//
+//	exporter := otlp.NewExporter("http://collector:4318/v1/logs",
+//	    otlp.WithResource("service.name", "billing"),
+//	)
+//	defer exporter.Close(context.Background())
+//	erro.Configure(erro.WithAutoEvents(exporter, erro.MinSeverity(erro.SeverityMedium)))
+func NewExporter(endpoint string, opts ...Option) *Exporter {
+	if endpoint == "" {
+		endpoint = DefaultEndpoint
+	}
+	o := options{
+		client:        &http.Client{Timeout: 10 * time.Second},
+		header:        make(http.Header),
+		batchSize:     DefaultBatchSize,
+		maxQueueSize:  DefaultMaxQueueSize,
+		flushInterval: DefaultFlushInterval,
+		onError:       func(err error) { log.Printf("erro/otlp: %v", err) },
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if len(o.logOptions) == 0 {
+		o.logOptions = []erro.LogOption{func(lo *erro.LogOptions) {
+			*lo = erro.DefaultLogOptions
+			lo.IncludeID = true
+		}}
+	}
+
+	x := &Exporter{
+		endpoint: endpoint,
+		opts:     o,
+		flush:    make(chan struct{}, 1),
+		done:     make(chan struct{}),
+	}
+	x.wg.Add(1)
+	go x.run()
+	return x
+}
+
+// SendEvent implements [erro.EventDispatcher]: it buffers the error as a log record.
+func (x *Exporter) SendEvent(ctx context.Context, err erro.Error) {
+	x.RecordError(err)
+}
+
+// RecordError implements [erro.ErrorMetrics]: it buffers the error as a log record.
+func (x *Exporter) RecordError(err erro.Error) {
+	if err == nil {
+		return
+	}
+	record := Record(err, x.opts.logOptions...)
+
+	x.mu.Lock()
+	if x.closed || len(x.records) >= x.opts.maxQueueSize {
+		x.dropped++
+		x.mu.Unlock()
+		return
+	}
+	x.records = append(x.records, record)
+	full := len(x.records) >= x.opts.batchSize
+	x.mu.Unlock()
+
+	if full {
+		select {
+		case x.flush <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// Flush exports the buffered records.
+func (x *Exporter) Flush(ctx context.Context) error {
+	x.mu.Lock()
+	records, dropped := x.records, x.dropped
+	x.records, x.dropped = nil, 0
+	x.mu.Unlock()
+
+	if dropped > 0 {
+		x.opts.onError(erro.New("records dropped, the export queue is full", "dropped", dropped))
+	}
+	for len(records) > 0 {
+		n := len(records)
+		if n > x.opts.batchSize {
+			n = x.opts.batchSize
+		}
+		if err := x.Export(ctx, records[:n]); err != nil {
+			return err
+		}
+		records = records[n:]
+	}
+	return nil
+}
+
+// Close stops the background export and exports the buffered records.
+func (x *Exporter) Close(ctx context.Context) error {
+	x.mu.Lock()
+	if x.closed {
+		x.mu.Unlock()
+		return nil
+	}
+	x.closed = true
+	x.mu.Unlock()
+
+	close(x.done)
+	x.wg.Wait()
+	return x.Flush(ctx)
+}
+
+// Export posts the records to the endpoint in a single request. Rejected requests
+// return an error with [erro.ClassExternal], retryable for 429 and 5xx responses.
+func (x *Exporter) Export(ctx context.Context, records []LogRecord) error {
+	if len(records) == 0 {
+		return nil
+	}
+	body, err := json.Marshal(x.request(records))
+	if err != nil {
+		return erro.Wrap(err, "encode logs", erro.ClassInternal)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, x.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return erro.Wrap(err, "create request", "endpoint", x.endpoint, erro.ClassValidation)
+	}
+	for key, values := range x.opts.header {
+		req.Header[key] = append([]string(nil), values...)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := x.opts.client.Do(req)
+	if err != nil {
+		return erro.Wrap(err, ExportFailedMessage, "endpoint", x.endpoint, "records", len(records),
+			erro.ClassExternal, erro.Retryable())
+	}
+	defer resp.Body.Close()
+	snippet, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return nil
+	}
+	meta := []any{"endpoint", x.endpoint, "records", len(records), "status", resp.StatusCode,
+		"response", string(bytes.TrimSpace(snippet)), erro.ClassExternal}
+	if resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= http.StatusInternalServerError {
+		meta = append(meta, erro.Retryable())
+	}
+	return erro.New(ExportFailedMessage, meta...)
+}
+
+func (x *Exporter) run() {
+	defer x.wg.Done()
+	ticker := time.NewTicker(x.opts.flushInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-x.done:
+			return
+		case <-ticker.C:
+		case <-x.flush:
+		}
+		if err := x.Flush(context.Background()); err != nil {
+			x.opts.onError(err)
+		}
+	}
+}
+
+func (x *Exporter) request(records []LogRecord) exportRequest {
+	return exportRequest{ResourceLogs: []resourceLogs{{
+		Resource: resource{Attributes: x.opts.resource},
+		ScopeLogs: []scopeLogs{{
+			Scope:      scope{Name: ScopeName},
+			LogRecords: records,
+		}},
+	}}}
+}
+
+// LogRecord is an OpenTelemetry log record in the OTLP JSON encoding.
+type LogRecord struct {
+	TimeUnixNano         string     `json:"timeUnixNano"`
+	ObservedTimeUnixNano string     `json:"observedTimeUnixNano"`
+	SeverityNumber       int        `json:"severityNumber"`
+	SeverityText         string     `json:"severityText"`
+	Body                 AnyValue   `json:"body"`
+	Attributes           []KeyValue `json:"attributes,omitempty"`
+	TraceID              string     `json:"traceId,omitempty"`
+	SpanID               string     `json:"spanId,omitempty"`
+}
+
+// KeyValue is an attribute of a record or a resource.
+type KeyValue struct {
+	Key   string   `json:"key"`
+	Value AnyValue `json:"value"`
+}
+
+// AnyValue is an attribute value; exactly one of the fields is set.
+type AnyValue struct {
+	StringValue *string       `json:"stringValue,omitempty"`
+	BoolValue   *bool         `json:"boolValue,omitempty"`
+	IntValue    *string       `json:"intValue,omitempty"`
+	DoubleValue *float64      `json:"doubleValue,omitempty"`
+	ArrayValue  *ArrayValue   `json:"arrayValue,omitempty"`
+	KvlistValue *KeyValueList `json:"kvlistValue,omitempty"`
+}
+
+// ArrayValue is a list of values.
+type ArrayValue struct {
+	Values []AnyValue `json:"values"`
+}
+
+// KeyValueList is a list of key-value pairs.
+type KeyValueList struct {
+	Values []KeyValue `json:"values"`
+}
+
+type exportRequest struct {
+	ResourceLogs []resourceLogs `json:"resourceLogs"`
+}
+
+type resourceLogs struct {
+	Resource  resource    `json:"resource"`
+	ScopeLogs []scopeLogs `json:"scopeLogs"`
+}
+
+type resource struct {
+	Attributes []KeyValue `json:"attributes,omitempty"`
+}
+
+type scopeLogs struct {
+	Scope      scope       `json:"scope"`
+	LogRecords []LogRecord `json:"logRecords"`
+}
+
+type scope struct {
+	Name string `json:"name"`
+}
+
+// Record converts the error to a log record: the body is the message, the severity is
+// mapped with [Severity], the trace and span IDs of the span of the error correlate the record with
+// its trace, and the attributes are the [erro.LogFields] of the [erro.Error.Redacted]
+// copy of the error, so redacted and secret-like fields are never exported.
+func Record(err erro.Error, opts ...erro.LogOption) LogRecord {
+	redacted := err.Redacted()
+	number, text := Severity(err.Severity())
+	record := LogRecord{
+		TimeUnixNano:         strconv.FormatInt(err.Created().UnixNano(), 10),
+		ObservedTimeUnixNano: strconv.FormatInt(time.Now().UnixNano(), 10),
+		SeverityNumber:       number,
+		SeverityText:         text,
+		Body:                 Value(redacted.Message()),
+	}
+	if span := err.Span(); span != nil && isHexID(span.TraceID(), 16) && isHexID(span.SpanID(), 8) {
+		record.TraceID, record.SpanID = span.TraceID(), span.SpanID()
+	}
+
+	fields := erro.LogFields(redacted, opts...)
+	for i := 0; i+1 < len(fields); i += 2 {
+		record.Attributes = append(record.Attributes, KeyValue{Key: fmt.Sprint(fields[i]), Value: Value(fields[i+1])})
+	}
+	return record
+}
+
+// Severity returns the OpenTelemetry severity number and text of the error severity.
+// Errors without a severity are ERROR.
+func Severity(severity erro.ErrorSeverity) (number int, text string) {
+	switch severity {
+	case erro.SeverityCritical:
+		return 21, "FATAL"
+	case erro.SeverityHigh:
+		return 18, "ERROR2"
+	case erro.SeverityLow:
+		return 13, "WARN"
+	case erro.SeverityInfo:
+		return 9, "INFO"
+	default:
+		return 17, "ERROR"
+	}
+}
+
+// Value converts a field value to an attribute value. Slices become arrays, maps
+// with string keys become key-value lists and other types become strings.
+func Value(value any) AnyValue {
+	switch v := value.(type) {
+	case nil:
+		return AnyValue{StringValue: new(string)}
+	case string:
+		return AnyValue{StringValue: &v}
+	case bool:
+		return AnyValue{BoolValue: &v}
+	case time.Time:
+		s := v.Format(time.RFC3339Nano)
+		return AnyValue{StringValue: &s}
+	case time.Duration:
+		s := v.String()
+		return AnyValue{StringValue: &s}
+	case []byte:
+		s := string(v)
+		return AnyValue{StringValue: &s}
+	case fmt.Stringer:
+		s := v.String()
+		return AnyValue{StringValue: &s}
+	case error:
+		s := v.Error()
+		return AnyValue{StringValue: &s}
+	}
+
+	rv := reflect.ValueOf(value)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		s := strconv.FormatInt(rv.Int(), 10)
+		return AnyValue{IntValue: &s}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		s := strconv.FormatUint(rv.Uint(), 10)
+		return AnyValue{IntValue: &s}
+	case reflect.Float32, reflect.Float64:
+		f := rv.Float()
+		return AnyValue{DoubleValue: &f}
+	case reflect.String:
+		s := rv.String()
+		return AnyValue{StringValue: &s}
+	case reflect.Bool:
+		b := rv.Bool()
+		return AnyValue{BoolValue: &b}
+	case reflect.Slice, reflect.Array:
+		values := make([]AnyValue, rv.Len())
+		for i := range values {
+			values[i] = Value(rv.Index(i).Interface())
+		}
+		return AnyValue{ArrayValue: &ArrayValue{Values: values}}
+	case reflect.Map:
+		if rv.Type().Key().Kind() == reflect.String {
+			values := make([]KeyValue, 0, rv.Len())
+			iter := rv.MapRange()
+			for iter.Next() {
+				values = append(values, KeyValue{Key: iter.Key().String(), Value: Value(iter.Value().Interface())})
+			}
+			sort.Slice(values, func(i, j int) bool { return values[i].Key < values[j].Key })
+			return AnyValue{KvlistValue: &KeyValueList{Values: values}}
+		}
+	}
+	s := fmt.Sprint(value)
+	return AnyValue{StringValue: &s}
+}
+
+// isHexID reports whether the ID is a non-zero hex string of n bytes, as required by OTLP.
+func isHexID(id string, n int) bool {
+	decoded, err := hex.DecodeString(id)
+	if err != nil || len(decoded) != n {
+		return false
+	}
+	for _, b := range decoded {
+		if b != 0 {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,217 @@
+package otlp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/maxbolgarin/erro"
+)
+
+type span struct{ traceID, spanID string }
+
+func (s *span) RecordError(err erro.Error)      {}
+func (s *span) SetAttributes(attributes ...any) {}
+func (s *span) TraceID() string                 { return s.traceID }
+func (s *span) SpanID() string                  { return s.spanID }
+func (s *span) ParentSpanID() string            { return "" }
+func (s *span) IsSampled() bool                 { return true }
+
+type collector struct {
+	mu       sync.Mutex
+	requests []exportRequest
+	headers  []http.Header
+	status   int
+}
+
+func (c *collector) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var req exportRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.requests = append(c.requests, req)
+	c.headers = append(c.headers, r.Header)
+	if c.status != 0 {
+		w.WriteHeader(c.status)
+	}
+}
+
+func (c *collector) records() []LogRecord {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var records []LogRecord
+	for _, req := range c.requests {
+		for _, rl := range req.ResourceLogs {
+			for _, sl := range rl.ScopeLogs {
+				records = append(records, sl.LogRecords...)
+			}
+		}
+	}
+	return records
+}
+
+func attribute(record LogRecord, key string) (AnyValue, bool) {
+	for _, kv := range record.Attributes {
+		if kv.Key == key {
+			return kv.Value, true
+		}
+	}
+	return AnyValue{}, false
+}
+
+func TestRecord(t *testing.T) {
+	err := erro.New("charge failed", "amount", 42, "password", "hunter2", "card", erro.Redact("4242"),
+		erro.SeverityCritical, erro.CategoryPayment,
+		erro.RecordSpan(&span{traceID: "4bf92f3577b34da6a3ce929d0e0e4736", spanID: "00f067aa0ba902b7"}))
+	record := Record(err, erro.WithUserFields(true), erro.WithID(true))
+
+	if record.SeverityNumber != 21 || record.SeverityText != "FATAL" {
+		t.Errorf("unexpected severity %d %s", record.SeverityNumber, record.SeverityText)
+	}
+	if *record.Body.StringValue != "charge failed" {
+		t.Errorf("unexpected body %q", *record.Body.StringValue)
+	}
+	if record.TraceID != "4bf92f3577b34da6a3ce929d0e0e4736" || record.SpanID != "00f067aa0ba902b7" {
+		t.Errorf("unexpected trace correlation %q %q", record.TraceID, record.SpanID)
+	}
+	if record.TimeUnixNano == "" || record.ObservedTimeUnixNano == "" {
+		t.Error("expected timestamps")
+	}
+	if v, ok := attribute(record, "amount"); !ok || v.IntValue == nil || *v.IntValue != "42" {
+		t.Errorf("unexpected amount attribute %+v", v)
+	}
+	for _, key := range []string{"password", "card"} {
+		if v, ok := attribute(record, key); !ok || *v.StringValue != erro.RedactedPlaceholder {
+			t.Errorf("expected %s to be redacted, got %+v", key, v)
+		}
+	}
+	if v, ok := attribute(record, "id"); !ok || *v.StringValue != err.ID() {
+		t.Errorf("unexpected id attribute %+v", v)
+	}
+
+	// Invalid IDs are not used for correlation
+	err = erro.New("no trace", erro.RecordSpan(&span{traceID: "trace-1", spanID: "span-1"}))
+	if record := Record(err); record.TraceID != "" || record.SpanID != "" || record.SeverityText != "ERROR" {
+		t.Errorf("unexpected record %+v", record)
+	}
+}
+
+func TestSeverity(t *testing.T) {
+	tests := []struct {
+		severity erro.ErrorSeverity
+		number   int
+		text     string
+	}{
+		{erro.SeverityCritical, 21, "FATAL"},
+		{erro.SeverityHigh, 18, "ERROR2"},
+		{erro.SeverityMedium, 17, "ERROR"},
+		{erro.SeverityUnknown, 17, "ERROR"},
+		{erro.SeverityLow, 13, "WARN"},
+		{erro.SeverityInfo, 9, "INFO"},
+	}
+	for _, tt := range tests {
+		if number, text := Severity(tt.severity); number != tt.number || text != tt.text {
+			t.Errorf("%q: expected %d %s, got %d %s", tt.severity, tt.number, tt.text, number, text)
+		}
+	}
+}
+
+func TestValue(t *testing.T) {
+	data, err := json.Marshal([]AnyValue{
+		Value(nil), Value(true), Value(uint8(7)), Value(1.5), Value(time.Second),
+		Value([]any{"a", 1}), Value(map[string]any{"b": 2, "a": "x"}), Value(errors.New("boom")),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `[{"stringValue":""},{"boolValue":true},{"intValue":"7"},{"doubleValue":1.5},{"stringValue":"1s"},` +
+		`{"arrayValue":{"values":[{"stringValue":"a"},{"intValue":"1"}]}},` +
+		`{"kvlistValue":{"values":[{"key":"a","value":{"stringValue":"x"}},{"key":"b","value":{"intValue":"2"}}]}},` +
+		`{"stringValue":"boom"}]`
+	if string(data) != expected {
+		t.Errorf("unexpected values\n%s\n%s", data, expected)
+	}
+}
+
+func TestExporter(t *testing.T) {
+	c := &collector{}
+	srv := httptest.NewServer(c)
+	defer srv.Close()
+
+	x := NewExporter(srv.URL, WithBatchSize(2), WithFlushInterval(time.Hour),
+		WithResource("service.name", "billing"), WithHeader("X-Api-Key", "secret"))
+	x.SendEvent(context.Background(), erro.New("first"))
+	x.RecordError(erro.New("second"))
+
+	deadline := time.Now().Add(5 * time.Second)
+	for len(c.records()) < 2 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if records := c.records(); len(records) != 2 || *records[0].Body.StringValue != "first" {
+		t.Fatalf("expected a full batch to be exported, got %+v", records)
+	}
+
+	x.RecordError(erro.New("third"))
+	if err := x.Close(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if records := c.records(); len(records) != 3 {
+		t.Fatalf("expected Close to export the buffered record, got %d", len(records))
+	}
+	x.RecordError(erro.New("after close"))
+	if err := x.Flush(context.Background()); err != nil || len(c.records()) != 3 {
+		t.Error("records after Close must be dropped")
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	rl := c.requests[0].ResourceLogs[0]
+	if len(rl.Resource.Attributes) != 1 || *rl.Resource.Attributes[0].Value.StringValue != "billing" {
+		t.Errorf("unexpected resource %+v", rl.Resource)
+	}
+	if rl.ScopeLogs[0].Scope.Name != ScopeName {
+		t.Errorf("unexpected scope %q", rl.ScopeLogs[0].Scope.Name)
+	}
+	if c.headers[0].Get("X-Api-Key") != "secret" || c.headers[0].Get("Content-Type") != "application/json" {
+		t.Errorf("unexpected headers %v", c.headers[0])
+	}
+}
+
+func TestExporterErrors(t *testing.T) {
+	c := &collector{status: http.StatusServiceUnavailable}
+	srv := httptest.NewServer(c)
+	defer srv.Close()
+
+	var handled []error
+	var mu sync.Mutex
+	x := NewExporter(srv.URL, WithFlushInterval(time.Hour), WithMaxQueueSize(1),
+		WithErrorHandler(func(err error) {
+			mu.Lock()
+			handled = append(handled, err)
+			mu.Unlock()
+		}))
+	defer x.Close(context.Background())
+
+	x.RecordError(erro.New("kept"))
+	x.RecordError(erro.New("dropped"))
+	err := erro.ExtractError(x.Flush(context.Background()))
+	if err == nil || err.Class() != erro.ClassExternal || !err.IsRetryable() {
+		t.Errorf("expected a retryable external error, got %v", err)
+	}
+	if status, _ := erro.Field[int](err, "status"); status != http.StatusServiceUnavailable {
+		t.Errorf("unexpected status %d", status)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if len(handled) != 1 {
+		t.Errorf("expected the dropped record to be reported, got %v", handled)
+	}
+}
@@ -0,0 +1,86 @@
+package erro
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+)
+
+const (
+	// PanicMessage is the message of errors created by [FromPanic].
+	PanicMessage = "panic"
+	// PanicValueField is the field key of the recovered value added by [FromPanic].
+	PanicValueField = "panic_value"
+)
+
+// FromPanic converts a value returned by recover() to an error. It returns nil for nil.
+//
+// An error value is wrapped, so it stays in the chain; a [PanicError] from [Must]
+// is returned as is. Strings, [fmt.Stringer] values and other types are added as
+// the [PanicValueField] field, redacted if [WithRedactedPanicValues] is set.
+// The error has [ClassCritical], [SeverityCritical] unless the recovered error has
+// a severity, and a stack trace starting at the panicking function.
+//
+// Example:
+//
+//	defer func() {
+//	    if err := erro.FromPanic(recover()); err != nil {
+//	        erro.LogError(err, logger.Error)
+//	    }
+//	}()
+func FromPanic(recovered any) Error {
+	if recovered == nil {
+		return nil
+	}
+	if p, ok := recovered.(PanicError); ok && p.Err != nil {
+		return p.Err
+	}
+
+	meta := []any{ClassCritical}
+	var e *baseError
+	switch v := recovered.(type) {
+	case error:
+		if ExtractError(v).Severity() == SeverityUnknown {
+			meta = append(meta, SeverityCritical)
+		}
+		e = newWrapError(v, PanicMessage, meta...)
+	case string:
+		e = newBaseError(PanicMessage, append(meta, SeverityCritical, PanicValueField, panicValue(v))...)
+	case fmt.Stringer:
+		e = newBaseError(PanicMessage, append(meta, SeverityCritical, PanicValueField, panicValue(v.String()))...)
+	default:
+		e = newBaseError(PanicMessage, append(meta, SeverityCritical, PanicValueField, panicValue(v))...)
+	}
+	e.stack = trimPanicFrames(captureStack(2))
+	setStackTraceConfig(e, nil)
+	return e
+}
+
+func panicValue(v any) any {
+	if redactPanicValues.Load() {
+		return Redact(v)
+	}
+	return v
+}
+
+// trimPanicFrames drops the recovery frames: the deferred function, runtime.gopanic
+// and the runtime frames raising the panic (e.g. runtime.panicmem). The stack is kept
+// as is if it does not come from a panic.
+func trimPanicFrames(stack rawStack) rawStack {
+	for i, pc := range stack {
+		fn := runtime.FuncForPC(pc - 1)
+		if fn == nil || fn.Name() != "runtime.gopanic" {
+			continue
+		}
+		i++
+		for i < len(stack) {
+			fn := runtime.FuncForPC(stack[i] - 1)
+			if fn == nil || !strings.HasPrefix(fn.Name(), "runtime.") {
+				break
+			}
+			i++
+		}
+		return stack[i:]
+	}
+	return stack
+}
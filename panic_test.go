@@ -0,0 +1,98 @@
+package erro
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+type panicStringer struct{}
+
+func (panicStringer) String() string { return "stringer value" }
+
+func recoverPanic(f func()) (err Error) {
+	defer func() {
+		err = FromPanic(recover())
+	}()
+	f()
+	return nil
+}
+
+func panicWithNil() {
+	var m map[string]int
+	m["key"] = 1
+}
+
+func TestFromPanic(t *testing.T) {
+	if FromPanic(nil) != nil {
+		t.Error("expected nil for nil")
+	}
+
+	cause := errors.New("boom")
+	err := recoverPanic(func() { panic(cause) })
+	if !errors.Is(err, cause) || err.Message() != "panic: boom" {
+		t.Errorf("expected wrapped error, got %v", err)
+	}
+	if err.Class() != ClassCritical || err.Severity() != SeverityCritical {
+		t.Errorf("unexpected class or severity: %s %s", err.Class(), err.Severity())
+	}
+
+	err = recoverPanic(func() { panic(New("low", SeverityLow)) })
+	if err.Severity() != SeverityLow || err.Class() != ClassCritical {
+		t.Errorf("expected recovered severity to be kept, got %s %s", err.Class(), err.Severity())
+	}
+
+	for _, tc := range []struct {
+		value    any
+		expected any
+	}{
+		{"boom", "boom"},
+		{panicStringer{}, "stringer value"},
+		{42, 42},
+	} {
+		err := recoverPanic(func() { panic(tc.value) })
+		if fields := err.Fields(); len(fields) != 2 || fields[0] != PanicValueField || fields[1] != tc.expected {
+			t.Errorf("unexpected fields for %v: %v", tc.value, fields)
+		}
+	}
+
+	mustErr := New("must", ClassValidation)
+	if err := recoverPanic(func() { panic(PanicError{Err: mustErr}) }); err != mustErr {
+		t.Errorf("expected error of PanicError to be returned as is, got %v", err)
+	}
+
+	Configure(WithRedactedPanicValues())
+	err = recoverPanic(func() { panic("secret") })
+	Configure(WithRedactedPanicValues(false))
+	if strings.Contains(err.Error(), "secret") {
+		t.Errorf("expected redacted panic value, got %s", err.Error())
+	}
+}
+
+func TestFromPanicStack(t *testing.T) {
+	for name, f := range map[string]func(){
+		"explicit": func() { panicWithValue() },
+		"runtime":  panicWithNil,
+	} {
+		err := recoverPanic(f)
+		stack := err.Stack()
+		if len(stack) == 0 {
+			t.Fatalf("%s: expected stack trace", name)
+		}
+		expected := "panicWithValue"
+		if name == "runtime" {
+			expected = "panicWithNil"
+		}
+		if stack[0].Name != expected {
+			t.Errorf("%s: expected stack to start at the panicking function, got %s", name, stack[0].FullName)
+		}
+	}
+
+	if err := FromPanic("not recovered"); len(err.Stack()) == 0 || err.Stack()[0].Name != "TestFromPanicStack" {
+		t.Error("expected stack to start at the caller outside of a panic")
+	}
+}
+
+func panicWithValue() {
+	panic("value")
+}
@@ -0,0 +1,371 @@
+package erro
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"hash/crc32"
+	"io"
+	"os"
+	"sync"
+)
+
+// SetStore is the storage of a [PersistentSet], an append-only log of opaque records.
+// [FileStore] keeps it in a local file; implement it to keep records elsewhere, e.g. in
+// a table of an embedded SQLite database.
+type SetStore interface {
+	// Append durably appends the records in order.
+	Append(records [][]byte) error
+	// Load calls fn for every stored record in the order they were appended.
+	// Corrupted records are skipped.
+	Load(fn func(record []byte)) error
+	// Truncate removes all records.
+	Truncate() error
+	// Close releases the storage.
+	Close() error
+}
+
+// PersistentSet is a thread-safe error set that keeps its errors in a [SetStore], so
+// an agent that must not lose errors while offline can recover them after a crash or
+// a restart. It has the API of [SafeSet] plus [PersistentSet.Flush] to write the errors
+// added since the last flush and [PersistentSet.Recover] to load stored errors.
+//
+// Errors are stored as [ErrorToJSON] with their deduplication keys, so recovered errors
+// have no stack traces and spans, like errors decoded with [FromJSON].
+type PersistentSet struct {
+	mu       sync.Mutex
+	set      *Set
+	store    SetStore
+	pending  [][]byte
+	truncate bool // The store must be truncated on the next flush, see [PersistentSet.Clear]
+}
+
+// persistRecord is a record of an added error in a [SetStore].
+type persistRecord struct {
+	Key   string          `json:"key"`
+	Count int             `json:"count"`
+	Error json.RawMessage `json:"error"`
+}
+
+// NewPersistentSet creates a new error set backed by the store. Call
+// [PersistentSet.Recover] to load errors stored by a previous process.
+//
+// Example:
+//
+//	store, err := erro.OpenFileStore("/var/lib/agent/errors.log")
+//	if err != nil {
+//	    return err
+//	}
+//	set := erro.NewPersistentSet(store)
+//	if _, err := set.Recover(); err != nil {
+//	    return err
+//	}
+//	defer set.Close()
+//	// Periodically
+//	if err := set.Flush(); err != nil {
+//	    log.Println(err)
+//	}
+func NewPersistentSet(store SetStore) *PersistentSet {
+	return &PersistentSet{set: NewSet(), store: store}
+}
+
+// Add adds an error to the set. It is written to the store on the next
+// [PersistentSet.Flush]. If the error is nil, it is ignored.
+func (ps *PersistentSet) Add(err error) *PersistentSet {
+	if err == nil {
+		return ps
+	}
+	e := ExtractError(err)
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+	key := ps.set.keyGetter(e)
+	if key == "" {
+		return ps
+	}
+	ps.set.addKey(e, key)
+	if record, encodeErr := encodePersistRecord(e, key, 1); encodeErr == nil {
+		ps.pending = append(ps.pending, record)
+	}
+	return ps
+}
+
+// New creates a new error and adds it to the set.
+func (ps *PersistentSet) New(message string, meta ...any) *PersistentSet {
+	return ps.Add(newf(message, meta...))
+}
+
+// Wrap wraps an existing error and adds it to the set. It does nothing if err is nil.
+func (ps *PersistentSet) Wrap(err error, message string, meta ...any) *PersistentSet {
+	if err == nil {
+		return ps
+	}
+	return ps.Add(wrapf(err, message, meta...))
+}
+
+// Flush writes the errors added since the last flush to the store. If writing fails,
+// the errors are kept and written on the next flush.
+func (ps *PersistentSet) Flush() error {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+	if ps.truncate {
+		if err := ps.store.Truncate(); err != nil {
+			return Wrap(err, "truncate error store")
+		}
+		ps.truncate = false
+	}
+	if len(ps.pending) == 0 {
+		return nil
+	}
+	if err := ps.store.Append(ps.pending); err != nil {
+		return Wrap(err, "append to error store", "records", len(ps.pending))
+	}
+	ps.pending = nil
+	return nil
+}
+
+// Recover loads the errors from the store into the set and returns the number of loaded
+// records. Corrupted records and records that can't be decoded are skipped, so a crash
+// during a write loses at most the records being written.
+func (ps *PersistentSet) Recover() (int, error) {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+	loaded := 0
+	err := ps.store.Load(func(data []byte) {
+		var record persistRecord
+		if json.Unmarshal(data, &record) != nil || record.Key == "" || record.Count <= 0 {
+			return
+		}
+		e, decodeErr := FromJSON(record.Error)
+		if decodeErr != nil {
+			return
+		}
+		ps.set.addKeyCount(e, record.Key, record.Count)
+		loaded++
+	})
+	if err != nil {
+		return loaded, Wrap(err, "load error store")
+	}
+	return loaded, nil
+}
+
+// Close flushes the set and closes the store.
+func (ps *PersistentSet) Close() error {
+	flushErr := ps.Flush()
+	if err := ps.store.Close(); err != nil {
+		return Wrap(err, "close error store")
+	}
+	return flushErr
+}
+
+// WithKeyGetter sets the function used to generate deduplication keys for new errors.
+// Recovered errors keep their stored keys.
+func (ps *PersistentSet) WithKeyGetter(keyGetter KeyGetterFunc) *PersistentSet {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+	ps.set.WithKeyGetter(keyGetter)
+	return ps
+}
+
+// WithMaxKeys bounds the number of unique keys tracked in memory, see [Set.WithMaxKeys].
+// The store keeps all records until [PersistentSet.Clear].
+func (ps *PersistentSet) WithMaxKeys(maxKeys int) *PersistentSet {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+	ps.set.WithMaxKeys(maxKeys)
+	return ps
+}
+
+// Err returns a combined error from all errors in the set, see [Set.Err].
+func (ps *PersistentSet) Err() error {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+	return ps.set.Err()
+}
+
+// Errors returns a slice of all errors in the set as standard `error` interfaces.
+func (ps *PersistentSet) Errors() []error {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+	return ps.set.Errors()
+}
+
+// Errs returns a slice of all errors in the set as `erro.Error` interfaces.
+func (ps *PersistentSet) Errs() []Error {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+	return append([]Error(nil), ps.set.Errs()...)
+}
+
+// Len returns the number of unique errors in the set.
+func (ps *PersistentSet) Len() int {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+	return ps.set.Len()
+}
+
+// Empty returns true if the set contains no errors.
+func (ps *PersistentSet) Empty() bool {
+	return ps.Len() == 0
+}
+
+// NotEmpty returns true if the set contains at least one error.
+func (ps *PersistentSet) NotEmpty() bool {
+	return ps.Len() > 0
+}
+
+// Clear removes all errors from the set. The store is truncated on the next
+// [PersistentSet.Flush], e.g. after the errors were delivered.
+func (ps *PersistentSet) Clear() *PersistentSet {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+	ps.set.Clear()
+	ps.pending = nil
+	ps.truncate = true
+	return ps
+}
+
+// Count returns the number of occurrences of errors with the key of err.
+func (ps *PersistentSet) Count(err error) int {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+	return ps.set.Count(err)
+}
+
+// Counts returns a copy of the occurrence counts by keys.
+func (ps *PersistentSet) Counts() map[string]int {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+	return ps.set.Counts()
+}
+
+// Total returns the number of occurrences of all errors added to the set.
+func (ps *PersistentSet) Total() int {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+	return ps.set.Total()
+}
+
+// TopN returns the n most frequent errors, see [Set.TopN].
+func (ps *PersistentSet) TopN(n int) []ErrorCount {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+	return ps.set.TopN(n)
+}
+
+func encodePersistRecord(e Error, key string, count int) ([]byte, error) {
+	data, err := json.Marshal(ErrorToJSON(e))
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(persistRecord{Key: key, Count: count, Error: data})
+}
+
+// FileStore is a [SetStore] that keeps records in a local append-only file. Every
+// record is a line with its CRC-32 checksum, so a torn write or a damaged line is
+// detected and skipped on load without losing the following records.
+type FileStore struct {
+	mu   sync.Mutex
+	file *os.File
+}
+
+// OpenFileStore opens or creates the file of a [FileStore]. If the last record of the
+// file was not written completely, it is terminated so it is skipped on load.
+func OpenFileStore(path string) (*FileStore, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR|os.O_APPEND, 0o600)
+	if err != nil {
+		return nil, Wrap(err, "open error store", "path", path)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, Wrap(err, "stat error store", "path", path)
+	}
+	if size := info.Size(); size > 0 {
+		last := make([]byte, 1)
+		if _, err := file.ReadAt(last, size-1); err == nil && last[0] != '\n' {
+			_, err = file.Write([]byte{'\n'})
+		}
+		if err != nil {
+			file.Close()
+			return nil, Wrap(err, "repair error store", "path", path)
+		}
+	}
+	return &FileStore{file: file}, nil
+}
+
+// Append writes the records and syncs the file.
+func (s *FileStore) Append(records [][]byte) error {
+	var buf bytes.Buffer
+	var sum [4]byte
+	for _, record := range records {
+		crc := crc32.ChecksumIEEE(record)
+		sum[0], sum[1], sum[2], sum[3] = byte(crc>>24), byte(crc>>16), byte(crc>>8), byte(crc)
+		buf.WriteString(hex.EncodeToString(sum[:]))
+		buf.WriteByte(' ')
+		buf.Write(record)
+		buf.WriteByte('\n')
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, err := s.file.Write(buf.Bytes()); err != nil {
+		return err
+	}
+	return s.file.Sync()
+}
+
+// Load calls fn for every record with a valid checksum.
+func (s *FileStore) Load(fn func(record []byte)) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	data, err := io.ReadAll(io.NewSectionReader(s.file, 0, 1<<62))
+	if err != nil {
+		return err
+	}
+	for len(data) > 0 {
+		line := data
+		if i := bytes.IndexByte(data, '\n'); i >= 0 {
+			line, data = data[:i], data[i+1:]
+		} else {
+			data = nil
+		}
+		if record, ok := checkFileRecord(line); ok {
+			fn(record)
+		}
+	}
+	return nil
+}
+
+// Truncate removes all records and syncs the file.
+func (s *FileStore) Truncate() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.file.Truncate(0); err != nil {
+		return err
+	}
+	return s.file.Sync()
+}
+
+// Close closes the file.
+func (s *FileStore) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.file.Close()
+}
+
+// checkFileRecord returns the record of a line if its checksum matches.
+func checkFileRecord(line []byte) ([]byte, bool) {
+	const prefix = 9 // 8 hex digits and a space
+	if len(line) < prefix || line[prefix-1] != ' ' {
+		return nil, false
+	}
+	var sum [4]byte
+	if _, err := hex.Decode(sum[:], line[:prefix-1]); err != nil {
+		return nil, false
+	}
+	record := line[prefix:]
+	crc := crc32.ChecksumIEEE(record)
+	if sum != [4]byte{byte(crc >> 24), byte(crc >> 16), byte(crc >> 8), byte(crc)} {
+		return nil, false
+	}
+	return record, true
+}
@@ -0,0 +1,92 @@
+package erro
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestPersistentSet(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "errors.log")
+	store, err := OpenFileStore(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	set := NewPersistentSet(store)
+	set.New("disk full", "path", "/data", ClassResourceExhausted).New("disk full").Add(nil)
+	set.Wrap(os.ErrNotExist, "read config")
+	if set.Len() != 2 || set.Total() != 3 {
+		t.Fatalf("unexpected set: %d errors, %d total", set.Len(), set.Total())
+	}
+	if err := set.Close(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	store, err = OpenFileStore(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	recovered := NewPersistentSet(store)
+	defer recovered.Close()
+	n, err := recovered.Recover()
+	if err != nil || n != 3 {
+		t.Fatalf("expected 3 recovered records, got %d %v", n, err)
+	}
+	if recovered.Len() != 2 || recovered.Count(New("disk full")) != 2 {
+		t.Errorf("unexpected recovered counts: %v", recovered.Counts())
+	}
+	first := recovered.Errs()[0]
+	if first.Class() != ClassResourceExhausted || first.Error() != "disk full path=/data" {
+		t.Errorf("unexpected recovered error: %s %s", first.Class(), first.Error())
+	}
+
+	recovered.Clear()
+	if err := recovered.Flush(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if info, _ := os.Stat(path); info.Size() != 0 {
+		t.Errorf("expected store to be truncated, got %d bytes", info.Size())
+	}
+}
+
+func TestFileStore_Corruption(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "errors.log")
+	store, err := OpenFileStore(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	set := NewPersistentSet(store).New("first").New("second")
+	if err := set.Close(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Damage the first record and leave a torn write at the end
+	data, _ := os.ReadFile(path)
+	data[20] ^= 0xff
+	data = append(data, "0badc0de {\"key\":"...)
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	store, err = OpenFileStore(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := NewPersistentSet(store).New("third").Close(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	store, err = OpenFileStore(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	set = NewPersistentSet(store)
+	defer set.Close()
+	if n, err := set.Recover(); err != nil || n != 2 {
+		t.Fatalf("expected 2 valid records, got %d %v", n, err)
+	}
+	if set.Count(New("first")) != 0 || set.Count(New("second")) != 1 || set.Count(New("third")) != 1 {
+		t.Errorf("unexpected recovered counts: %v", set.Counts())
+	}
+}
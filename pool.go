@@ -0,0 +1,224 @@
+package erro
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Field keys attached by [Pool] to the errors of tasks.
+const (
+	PoolWorkerField   = "worker_id"
+	PoolQueueLagField = "queue_lag"
+)
+
+// PoolTaskFailedMessage is the message of the errors of tasks of a [Pool].
+const PoolTaskFailedMessage = "task failed"
+
+// DefaultPoolMaxErrors is the default number of errors collected by a [Pool].
+const DefaultPoolMaxErrors = 1000
+
+// PoolOptions configures a [WorkerPool], see [Pool].
+type PoolOptions struct {
+	// QueueSize is the number of tasks that can wait for a worker, the number of workers by default.
+	QueueSize int
+	// Errors collects the errors of tasks, a new list by default.
+	Errors *SafeList
+	// MaxErrors limits the number of errors collected in Errors; errors over it are still
+	// recorded and dispatched, see [WorkerPool.Dropped]. Default is [DefaultPoolMaxErrors],
+	// a negative value disables collecting.
+	MaxErrors int
+	// Metrics records the errors of tasks.
+	Metrics ErrorMetrics
+	// Dispatcher receives the errors of tasks with the context of [WorkerPool.Submit].
+	Dispatcher EventDispatcher
+}
+
+// PoolOption configures a [WorkerPool].
+type PoolOption func(*PoolOptions)
+
+// WithPoolQueueSize sets the number of tasks that can wait for a worker.
+func WithPoolQueueSize(size int) PoolOption {
+	return func(o *PoolOptions) { o.QueueSize = size }
+}
+
+// WithPoolErrors sets the list that collects the errors of tasks, e.g. to share it between pools.
+func WithPoolErrors(list *SafeList) PoolOption {
+	return func(o *PoolOptions) { o.Errors = list }
+}
+
+// WithPoolMaxErrors limits the number of collected errors, a negative value disables collecting.
+func WithPoolMaxErrors(n int) PoolOption {
+	return func(o *PoolOptions) { o.MaxErrors = n }
+}
+
+// WithPoolMetrics records the errors of tasks in the metrics.
+func WithPoolMetrics(m ErrorMetrics) PoolOption {
+	return func(o *PoolOptions) { o.Metrics = m }
+}
+
+// WithPoolDispatcher sends the errors of tasks to the dispatcher.
+func WithPoolDispatcher(d EventDispatcher) PoolOption {
+	return func(o *PoolOptions) { o.Dispatcher = d }
+}
+
+// WorkerPool runs tasks with a fixed number of workers and handles their failures
+// uniformly, see [Pool].
+type WorkerPool[T any] struct {
+	handler func(ctx context.Context, task T) error
+	opts    PoolOptions
+	tasks   chan poolTask[T]
+	wg      sync.WaitGroup
+
+	mu     sync.RWMutex
+	closed bool
+
+	errMu   sync.Mutex // Serializes checking the size of the errors and adding to them
+	dropped int64      // Errors not collected because of MaxErrors, accessed atomically
+}
+
+type poolTask[T any] struct {
+	ctx    context.Context
+	task   T
+	queued time.Time
+}
+
+// Pool starts n workers that run the handler for the submitted tasks. An error returned
+// by the handler or a panic, converted with [FromPanic], is wrapped with the
+// [PoolTaskFailedMessage] message, the [PoolWorkerField] and [PoolQueueLagField] fields
+// (the time the task waited for a worker), and [ClassCancelled] or [ClassTimeout] for
+// unclassified context errors. The error is recorded in the metrics, sent to the
+// dispatcher and added to the errors of the pool, up to [PoolOptions.MaxErrors] of them.
+// n below one starts one worker.
+//
+// Example:
+//
+//	pool := erro.Pool(8, func(ctx context.Context, job Job) error {
+//	    return process(ctx, job)
+//	}, erro.WithPoolMetrics(metrics), erro.WithPoolDispatcher(sentry))
+//	for job := range jobs {
+//	    pool.Submit(ctx, job)
+//	}
+//	if err := pool.Close(); err != nil {
+//	    erro.LogError(err, logger.Error)
+//	}
+func Pool[T any](n int, handler func(ctx context.Context, task T) error, opts ...PoolOption) *WorkerPool[T] {
+	if n < 1 {
+		n = 1
+	}
+	o := PoolOptions{QueueSize: n}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if o.QueueSize < 0 {
+		o.QueueSize = 0
+	}
+	if o.Errors == nil {
+		o.Errors = NewSafeList()
+	}
+	if o.MaxErrors == 0 {
+		o.MaxErrors = DefaultPoolMaxErrors
+	}
+
+	p := &WorkerPool[T]{
+		handler: handler,
+		opts:    o,
+		tasks:   make(chan poolTask[T], o.QueueSize),
+	}
+	p.wg.Add(n)
+	for i := 1; i <= n; i++ {
+		go p.work(i)
+	}
+	return p
+}
+
+// Submit queues the task, waiting for a free place in the queue. The context is passed
+// to the handler. It returns [ErrPoolClosed] after [WorkerPool.Close] and the error of
+// the context if it is done before the task is queued.
+func (p *WorkerPool[T]) Submit(ctx context.Context, task T) error {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	if p.closed {
+		return ErrPoolClosed
+	}
+	select {
+	case p.tasks <- poolTask[T]{ctx: ctx, task: task, queued: now()}:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Close stops accepting tasks, waits for the queued tasks to finish and returns the
+// errors of the tasks combined, see [SafeList.Err]. It is safe to call Close twice.
+func (p *WorkerPool[T]) Close() error {
+	p.mu.Lock()
+	if !p.closed {
+		p.closed = true
+		close(p.tasks)
+	}
+	p.mu.Unlock()
+	p.wg.Wait()
+	return p.opts.Errors.Err()
+}
+
+// Errors returns the list with the errors of the tasks.
+func (p *WorkerPool[T]) Errors() *SafeList {
+	return p.opts.Errors
+}
+
+// Dropped returns the number of errors of tasks that were not collected because the
+// errors of the pool reached [PoolOptions.MaxErrors].
+func (p *WorkerPool[T]) Dropped() int64 {
+	return atomic.LoadInt64(&p.dropped)
+}
+
+func (p *WorkerPool[T]) work(id int) {
+	defer p.wg.Done()
+	for t := range p.tasks {
+		lag := now().Sub(t.queued)
+		if err := p.run(t); err != nil {
+			p.report(t.ctx, err, id, lag)
+		}
+	}
+}
+
+// run calls the handler and converts a panic to an error.
+func (p *WorkerPool[T]) run(t poolTask[T]) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = FromPanic(r)
+		}
+	}()
+	return p.handler(t.ctx, t.task)
+}
+
+func (p *WorkerPool[T]) report(ctx context.Context, err error, id int, lag time.Duration) {
+	meta := make([]any, 0, 8)
+	meta = append(meta, PoolWorkerField, id, PoolQueueLagField, lag)
+	if ExtractError(err).Class() == ClassUnknown {
+		switch {
+		case errors.Is(err, context.Canceled):
+			meta = append(meta, ClassCancelled)
+		case errors.Is(err, context.DeadlineExceeded):
+			meta = append(meta, ClassTimeout)
+		}
+	}
+	if p.opts.Metrics != nil {
+		meta = append(meta, RecordMetrics(p.opts.Metrics))
+	}
+	if p.opts.Dispatcher != nil {
+		meta = append(meta, SendEvent(ctx, p.opts.Dispatcher))
+	}
+	wrapped := Wrap(err, PoolTaskFailedMessage, meta...)
+
+	p.errMu.Lock()
+	defer p.errMu.Unlock()
+	if p.opts.MaxErrors < 0 || p.opts.Errors.Len() >= p.opts.MaxErrors {
+		atomic.AddInt64(&p.dropped, 1)
+		return
+	}
+	p.opts.Errors.Add(wrapped)
+}
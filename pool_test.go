@@ -0,0 +1,122 @@
+package erro
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestPool(t *testing.T) {
+	metrics := &mockMetrics{}
+	dispatcher := &countingDispatcher{}
+	pool := Pool(1, func(ctx context.Context, task int) error {
+		switch task {
+		case 1:
+			return New("bad task", ClassValidation)
+		case 2:
+			panic("boom")
+		case 3:
+			return context.DeadlineExceeded
+		}
+		return nil
+	}, WithPoolMetrics(metrics), WithPoolDispatcher(dispatcher), WithPoolQueueSize(4))
+
+	for task := 0; task < 4; task++ {
+		if err := pool.Submit(context.Background(), task); err != nil {
+			t.Fatal(err)
+		}
+	}
+	err := pool.Close()
+	if err == nil || pool.Errors().Len() != 3 {
+		t.Fatalf("expected 3 errors, got %v", err)
+	}
+	if !metrics.recorded || dispatcher.events != 3 {
+		t.Errorf("expected the errors in metrics and dispatcher, got %v %d", metrics.recorded, dispatcher.events)
+	}
+
+	errs := pool.Errors().Errs()
+	if errs[0].Class() != ClassValidation || errs[0].Message() != PoolTaskFailedMessage+": bad task" {
+		t.Errorf("unexpected task error %v", errs[0])
+	}
+	if worker, _ := Field[int](errs[0], PoolWorkerField); worker != 1 {
+		t.Errorf("expected worker 1, got %d", worker)
+	}
+	if _, ok := Field[time.Duration](errs[0], PoolQueueLagField); !ok {
+		t.Error("expected the queue lag field")
+	}
+	if errs[1].Class() != ClassCritical || errs[1].Severity() != SeverityCritical {
+		t.Errorf("expected a critical panic error, got %s %s", errs[1].Class(), errs[1].Severity())
+	}
+	if errs[2].Class() != ClassTimeout || !errors.Is(errs[2], context.DeadlineExceeded) {
+		t.Errorf("expected a classified context error, got %v", errs[2])
+	}
+
+	if err := pool.Submit(context.Background(), 0); !errors.Is(err, ErrPoolClosed) {
+		t.Errorf("expected ErrPoolClosed, got %v", err)
+	}
+	if pool.Close() == nil {
+		t.Error("expected a second Close to return the errors")
+	}
+}
+
+func TestPool_Concurrent(t *testing.T) {
+	var done int64
+	pool := Pool(4, func(ctx context.Context, task int) error {
+		atomic.AddInt64(&done, 1)
+		if task%10 == 0 {
+			return New("failed", "task", task)
+		}
+		return nil
+	})
+	for task := 0; task < 100; task++ {
+		if err := pool.Submit(context.Background(), task); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if pool.Close() == nil || pool.Errors().Len() != 10 || atomic.LoadInt64(&done) != 100 {
+		t.Errorf("expected 100 tasks with 10 errors, got %d tasks and %d errors", done, pool.Errors().Len())
+	}
+
+	blocked := Pool(1, func(ctx context.Context, task int) error {
+		<-ctx.Done()
+		return nil
+	}, WithPoolQueueSize(0))
+	ctx, cancel := context.WithCancel(context.Background())
+	if err := blocked.Submit(ctx, 1); err != nil {
+		t.Fatal(err)
+	}
+	timeout, stop := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer stop()
+	if err := blocked.Submit(timeout, 2); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected the error of the context, got %v", err)
+	}
+	cancel()
+	blocked.Close()
+}
+
+func TestPool_MaxErrors(t *testing.T) {
+	dispatcher := &countingDispatcher{}
+	pool := Pool(1, func(ctx context.Context, task int) error {
+		return New("failed", "task", task)
+	}, WithPoolMaxErrors(2), WithPoolDispatcher(dispatcher))
+	for task := 0; task < 5; task++ {
+		if err := pool.Submit(context.Background(), task); err != nil {
+			t.Fatal(err)
+		}
+	}
+	pool.Close()
+	if pool.Errors().Len() != 2 || pool.Dropped() != 3 || dispatcher.events != 5 {
+		t.Errorf("expected 2 collected, 3 dropped and 5 dispatched errors, got %d %d %d",
+			pool.Errors().Len(), pool.Dropped(), dispatcher.events)
+	}
+
+	pool = Pool(1, func(ctx context.Context, task int) error {
+		return New("failed")
+	}, WithPoolMaxErrors(-1))
+	_ = pool.Submit(context.Background(), 0)
+	if err := pool.Close(); err != nil || pool.Dropped() != 1 {
+		t.Errorf("expected no collected errors, got %v %d", err, pool.Dropped())
+	}
+}
@@ -0,0 +1,133 @@
+package erro
+
+// Profile is a named bundle of options applied in one argument of [New], [Wrap] and
+// other constructors, templates and sentinels, so teams standardize call sites.
+// Build profiles with [NewProfile]; a profile is immutable.
+//
+// Example:
+//
+//	var PaymentCritical = erro.NewProfile().
+//	    Name("payment_critical").
+//	    Class(erro.ClassExternal).
+//	    Category(erro.CategoryPayment).
+//	    Severity(erro.SeverityCritical).
+//	    StackTrace().
+//	    Build()
+//
+//	err := erro.New("charge failed", "order_id", id, PaymentCritical)
+type Profile struct {
+	name string
+	opts []any
+}
+
+// Name returns the name of the profile.
+func (p *Profile) Name() string {
+	if p == nil {
+		return ""
+	}
+	return p.name
+}
+
+// Options returns a copy of the options of the profile.
+func (p *Profile) Options() []any {
+	if p == nil {
+		return nil
+	}
+	return append([]any(nil), p.opts...)
+}
+
+// ProfileBuilder builds a [Profile], see [NewProfile].
+type ProfileBuilder struct {
+	p Profile
+}
+
+// NewProfile starts building a [Profile].
+func NewProfile() *ProfileBuilder {
+	return &ProfileBuilder{}
+}
+
+// Name sets the name of the profile.
+func (b *ProfileBuilder) Name(name string) *ProfileBuilder {
+	b.p.name = name
+	return b
+}
+
+// Class sets the class of the errors.
+func (b *ProfileBuilder) Class(class ErrorClass) *ProfileBuilder {
+	return b.Options(class)
+}
+
+// Category sets the category of the errors.
+func (b *ProfileBuilder) Category(category ErrorCategory) *ProfileBuilder {
+	return b.Options(category)
+}
+
+// Severity sets the severity of the errors.
+func (b *ProfileBuilder) Severity(severity ErrorSeverity) *ProfileBuilder {
+	return b.Options(severity)
+}
+
+// StackTrace captures a stack trace for the errors, see [StackTrace].
+func (b *ProfileBuilder) StackTrace(c ...*StackTraceConfig) *ProfileBuilder {
+	return b.Options(StackTrace(c...))
+}
+
+// Formatter sets the message formatter of the errors, see [Formatter].
+func (b *ProfileBuilder) Formatter(f FormatErrorFunc) *ProfileBuilder {
+	return b.Options(Formatter(f))
+}
+
+// Retryable marks the errors as retryable.
+func (b *ProfileBuilder) Retryable() *ProfileBuilder {
+	return b.Options(Retryable())
+}
+
+// Fields adds key-value fields to the errors.
+func (b *ProfileBuilder) Fields(fields ...any) *ProfileBuilder {
+	return b.Options(Fields(fields...))
+}
+
+// Options adds any other options, including other profiles whose options are copied.
+func (b *ProfileBuilder) Options(opts ...any) *ProfileBuilder {
+	for _, opt := range opts {
+		if p, ok := opt.(*Profile); ok {
+			if p != nil {
+				b.p.opts = append(b.p.opts, p.opts...)
+			}
+			continue
+		}
+		b.p.opts = append(b.p.opts, opt)
+	}
+	return b
+}
+
+// Build returns the profile. The builder can be used further without affecting it.
+func (b *ProfileBuilder) Build() *Profile {
+	return &Profile{name: b.p.name, opts: append([]any(nil), b.p.opts...)}
+}
+
+// expandProfiles replaces profiles in meta with their options. It returns meta as is
+// if there are no profiles.
+func expandProfiles(meta []any) []any {
+	var expanded []any
+	for i, f := range meta {
+		p, ok := f.(*Profile)
+		if !ok {
+			if expanded != nil {
+				expanded = append(expanded, f)
+			}
+			continue
+		}
+		if expanded == nil {
+			expanded = make([]any, i, len(meta)+len(p.Options()))
+			copy(expanded, meta[:i])
+		}
+		if p != nil {
+			expanded = append(expanded, p.opts...)
+		}
+	}
+	if expanded == nil {
+		return meta
+	}
+	return expanded
+}
@@ -0,0 +1,55 @@
+package erro
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestProfile(t *testing.T) {
+	base := NewProfile().Category(CategoryPayment).Fields("team", "billing")
+	critical := NewProfile().
+		Name("payment_critical").
+		Options(base.Build()).
+		Class(ClassExternal).
+		Severity(SeverityCritical).
+		StackTrace().
+		Build()
+	base.Retryable()
+
+	if critical.Name() != "payment_critical" || len(critical.Options()) != 5 {
+		t.Fatalf("unexpected profile: %s %v", critical.Name(), critical.Options())
+	}
+
+	err := New("charge %s failed", "card", "order_id", 42, critical)
+	if err.Error() != "charge card failed order_id=42 team=billing" {
+		t.Errorf("unexpected message: %q", err.Error())
+	}
+	if err.Class() != ClassExternal || err.Category() != CategoryPayment || err.Severity() != SeverityCritical {
+		t.Errorf("expected metadata of the profile, got %s %s %s", err.Class(), err.Category(), err.Severity())
+	}
+	if err.IsRetryable() {
+		t.Error("expected built profile not to change with the builder")
+	}
+	if len(err.Stack()) == 0 {
+		t.Error("expected stack trace of the profile")
+	}
+
+	wrapped := Wrap(errors.New("declined"), "charge", critical, SeverityHigh)
+	if wrapped.Severity() != SeverityHigh || wrapped.Class() != ClassExternal {
+		t.Errorf("expected options after the profile to override it, got %s %s", wrapped.Severity(), wrapped.Class())
+	}
+
+	tmpl := NewTemplate("payment declined", critical)
+	if e := tmpl.New(); e.Category() != CategoryPayment {
+		t.Errorf("expected templates to accept profiles, got %s", e.Category())
+	}
+	if s := Sentinel("no funds", critical); s.Class() != ClassExternal {
+		t.Errorf("expected sentinels to accept profiles, got %s", s.Class())
+	}
+	if e := New("nil profile", (*Profile)(nil)); e.Fields() != nil {
+		t.Errorf("expected nil profile to be ignored, got %v", e.Fields())
+	}
+	if nilProfile := (*Profile)(nil); nilProfile.Name() != "" || nilProfile.Options() != nil {
+		t.Error("expected nil profile to have no name and options")
+	}
+}
@@ -0,0 +1,368 @@
+package erro
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Keys of the conditions of an [ErrorQuery]. Fields are matched with the [QueryFieldPrefix]
+// and the key of the field, e.g. "fields.user_id".
+const (
+	QueryClass       = "class"
+	QueryCategory    = "category"
+	QuerySeverity    = "severity"
+	QueryID          = "id"
+	QueryMessage     = "message"
+	QueryFieldPrefix = "fields."
+)
+
+// queryOp is an operator of a condition of an [ErrorQuery].
+type queryOp string
+
+const (
+	queryEqual    queryOp = "="
+	queryNotEqual queryOp = "!="
+	queryContains queryOp = "~"
+)
+
+// queryCond is a single condition of an [ErrorQuery], e.g. class=not_found.
+type queryCond struct {
+	key   string
+	op    queryOp
+	value string
+}
+
+// ErrorQuery selects the errors of a chain by their class, category, severity, ID,
+// message and fields. Create it with [NewQuery] or [ParseQuery] and run it with
+// [ErrorQuery.Find] or [Query].
+//
+// Conditions match the own values of a layer of the chain, not the ones it inherits
+// from the wrapped errors, so a wrapped error matches once. A query is a disjunction
+// of groups of conditions: a layer matches if it matches all conditions of a group.
+type ErrorQuery struct {
+	groups [][]queryCond
+}
+
+// NewQuery returns an empty query that matches every layer of a chain.
+//
+// Example:
+//
+//	matches := erro.NewQuery().Class(erro.ClassNotFound).Field("user_id", 42).Find(err)
+func NewQuery() *ErrorQuery {
+	return &ErrorQuery{}
+}
+
+// Class adds a condition on the class of the error.
+func (q *ErrorQuery) Class(class ErrorClass) *ErrorQuery {
+	return q.where(QueryClass, queryEqual, string(class))
+}
+
+// Category adds a condition on the category of the error.
+func (q *ErrorQuery) Category(category ErrorCategory) *ErrorQuery {
+	return q.where(QueryCategory, queryEqual, string(category))
+}
+
+// Severity adds a condition on the severity of the error.
+func (q *ErrorQuery) Severity(severity ErrorSeverity) *ErrorQuery {
+	return q.where(QuerySeverity, queryEqual, string(severity))
+}
+
+// ID adds a condition on the ID of the error.
+func (q *ErrorQuery) ID(id string) *ErrorQuery {
+	return q.where(QueryID, queryEqual, id)
+}
+
+// MessageContains adds a condition on a substring of the own message of the error.
+func (q *ErrorQuery) MessageContains(substr string) *ErrorQuery {
+	return q.where(QueryMessage, queryContains, substr)
+}
+
+// Field adds a condition on the field of the error. Values are compared as strings,
+// so 42 matches both the int and the "42" string field.
+func (q *ErrorQuery) Field(key string, value any) *ErrorQuery {
+	return q.where(QueryFieldPrefix+key, queryEqual, valueToString(value))
+}
+
+// Or starts a new group of conditions: the query matches the layers that match
+// the conditions before or the conditions after it.
+func (q *ErrorQuery) Or() *ErrorQuery {
+	if len(q.groups) > 0 && len(q.groups[len(q.groups)-1]) > 0 {
+		q.groups = append(q.groups, nil)
+	}
+	return q
+}
+
+func (q *ErrorQuery) where(key string, op queryOp, value string) *ErrorQuery {
+	if len(q.groups) == 0 {
+		q.groups = append(q.groups, nil)
+	}
+	last := len(q.groups) - 1
+	q.groups[last] = append(q.groups[last], queryCond{key: key, op: op, value: value})
+	return q
+}
+
+// String returns the query in the syntax of [ParseQuery].
+func (q *ErrorQuery) String() string {
+	var b strings.Builder
+	for i, group := range q.groups {
+		if len(group) == 0 {
+			continue
+		}
+		if i > 0 && b.Len() > 0 {
+			b.WriteString(" OR ")
+		}
+		for j, c := range group {
+			if j > 0 {
+				b.WriteString(" AND ")
+			}
+			b.WriteString(c.key)
+			b.WriteString(string(c.op))
+			if isQueryWord(c.value) {
+				b.WriteString(c.value)
+			} else {
+				b.WriteString(strconv.Quote(c.value))
+			}
+		}
+	}
+	return b.String()
+}
+
+// Match reports whether the error matches the query by its own values.
+// It returns false for errors that are not an [Error].
+func (q *ErrorQuery) Match(err error) bool {
+	e, ok := err.(Error)
+	if !ok || e == nil {
+		return false
+	}
+	if len(q.groups) == 0 {
+		return true
+	}
+	for _, group := range q.groups {
+		if len(group) > 0 && matchQueryGroup(e, group) {
+			return true
+		}
+	}
+	return false
+}
+
+// Find returns the layers of the chain matching the query, from the outermost one.
+// It searches wrapped errors and the elements of multi-errors, limited by [MaxWrapDepth].
+func (q *ErrorQuery) Find(err error) []Error {
+	var matches []Error
+	q.find(err, 0, &matches)
+	return matches
+}
+
+func (q *ErrorQuery) find(err error, depth int, matches *[]Error) {
+	if err == nil {
+		return
+	}
+	if q.Match(err) {
+		*matches = append(*matches, err.(Error))
+	}
+	if depth >= MaxWrapDepth {
+		return
+	}
+	switch e := err.(type) {
+	case interface{ Unwrap() []error }:
+		for _, child := range e.Unwrap() {
+			q.find(child, depth+1, matches)
+		}
+	case interface{ Unwrap() error }:
+		q.find(e.Unwrap(), depth+1, matches)
+	}
+}
+
+// Query returns the layers of the chain of err matching the expression, see [ParseQuery].
+// It returns an error wrapping [ErrInvalidQuery] if the expression is invalid.
+//
+// Example:
+//
+//	matches, err := erro.Query(captured, "class=not_found AND fields.user_id=42")
+func Query(err error, expr string) ([]Error, error) {
+	q, parseErr := ParseQuery(expr)
+	if parseErr != nil {
+		return nil, parseErr
+	}
+	return q.Find(err), nil
+}
+
+// ParseQuery parses a query expression: conditions joined with AND, and groups of
+// conditions joined with OR, which binds weaker than AND. A condition is a key, an
+// operator and a value: "=" for equality, "!=" for inequality and "~" for a substring.
+// Keys are [QueryClass], [QueryCategory], [QuerySeverity], [QueryID], [QueryMessage] and
+// fields with the [QueryFieldPrefix]. Values with spaces or operators must be quoted
+// with double quotes. A missing field has an empty value.
+//
+// Example:
+//
+//	q, err := erro.ParseQuery(`severity=critical OR class=not_found AND message~"user 42"`)
+func ParseQuery(expr string) (*ErrorQuery, error) {
+	tokens, err := tokenizeQuery(expr)
+	if err != nil {
+		return nil, err
+	}
+	if len(tokens) == 0 {
+		return nil, Wrap(ErrInvalidQuery, "empty query")
+	}
+
+	q := NewQuery()
+	for i := 0; i < len(tokens); {
+		if i+3 > len(tokens) {
+			return nil, Wrap(ErrInvalidQuery, "incomplete condition", "position", tokens[i].pos)
+		}
+		key, op, value := tokens[i], tokens[i+1], tokens[i+2]
+		if key.kind != queryTokenWord || !isQueryKey(key.text) {
+			return nil, Wrap(ErrInvalidQuery, "unknown key", "key", key.text, "position", key.pos)
+		}
+		if op.kind != queryTokenOp {
+			return nil, Wrap(ErrInvalidQuery, "expected operator", "token", op.text, "position", op.pos)
+		}
+		if value.kind == queryTokenOp {
+			return nil, Wrap(ErrInvalidQuery, "expected value", "token", value.text, "position", value.pos)
+		}
+		q.where(key.text, queryOp(op.text), value.text)
+		i += 3
+
+		if i == len(tokens) {
+			break
+		}
+		switch next := tokens[i]; {
+		case next.kind == queryTokenWord && strings.EqualFold(next.text, "AND"):
+		case next.kind == queryTokenWord && strings.EqualFold(next.text, "OR"):
+			q.Or()
+		default:
+			return nil, Wrap(ErrInvalidQuery, "expected AND or OR", "token", next.text, "position", next.pos)
+		}
+		if i++; i == len(tokens) {
+			return nil, Wrap(ErrInvalidQuery, "incomplete condition", "position", len(expr))
+		}
+	}
+	return q, nil
+}
+
+func matchQueryGroup(e Error, group []queryCond) bool {
+	for _, c := range group {
+		actual := queryValue(e, c.key)
+		var ok bool
+		switch c.op {
+		case queryEqual:
+			ok = actual == c.value
+		case queryNotEqual:
+			ok = actual != c.value
+		case queryContains:
+			ok = strings.Contains(actual, c.value)
+		}
+		if !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// queryValue returns the own value of the layer for the key of a condition.
+func queryValue(err Error, key string) string {
+	e, isBase := err.(*baseError)
+	switch key {
+	case QueryClass:
+		if isBase {
+			return string(e.class)
+		}
+		return string(err.Class())
+	case QueryCategory:
+		if isBase {
+			return string(e.category)
+		}
+		return string(err.Category())
+	case QuerySeverity:
+		if isBase {
+			return string(e.severity)
+		}
+		return string(err.Severity())
+	case QueryID:
+		if isBase {
+			return e.id
+		}
+		return err.ID()
+	case QueryMessage:
+		return buildMessage(err)
+	}
+	if isBase {
+		return ownField(e.fields, strings.TrimPrefix(key, QueryFieldPrefix))
+	}
+	return ownField(err.Fields(), strings.TrimPrefix(key, QueryFieldPrefix))
+}
+
+func isQueryKey(key string) bool {
+	switch key {
+	case QueryClass, QueryCategory, QuerySeverity, QueryID, QueryMessage:
+		return true
+	}
+	return strings.HasPrefix(key, QueryFieldPrefix) && len(key) > len(QueryFieldPrefix)
+}
+
+type queryTokenKind int
+
+const (
+	queryTokenWord queryTokenKind = iota
+	queryTokenQuoted
+	queryTokenOp
+)
+
+type queryToken struct {
+	kind queryTokenKind
+	text string
+	pos  int
+}
+
+func tokenizeQuery(expr string) ([]queryToken, error) {
+	var tokens []queryToken
+	for i := 0; i < len(expr); {
+		switch c := expr[i]; {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case c == '=' || c == '~':
+			tokens = append(tokens, queryToken{kind: queryTokenOp, text: expr[i : i+1], pos: i})
+			i++
+		case c == '!':
+			if !strings.HasPrefix(expr[i:], string(queryNotEqual)) {
+				return nil, Wrap(ErrInvalidQuery, "unknown operator", "position", i)
+			}
+			tokens = append(tokens, queryToken{kind: queryTokenOp, text: string(queryNotEqual), pos: i})
+			i += 2
+		case c == '"':
+			end := i + 1
+			for end < len(expr) && expr[end] != '"' {
+				if expr[end] == '\\' {
+					end++
+				}
+				end++
+			}
+			if end >= len(expr) {
+				return nil, Wrap(ErrInvalidQuery, "unterminated string", "position", i)
+			}
+			text, err := strconv.Unquote(expr[i : end+1])
+			if err != nil {
+				return nil, Wrap(ErrInvalidQuery, "invalid string", "position", i)
+			}
+			tokens = append(tokens, queryToken{kind: queryTokenQuoted, text: text, pos: i})
+			i = end + 1
+		default:
+			end := i
+			for end < len(expr) && !strings.ContainsRune(" \t\n\r=~!\"", rune(expr[end])) {
+				end++
+			}
+			tokens = append(tokens, queryToken{kind: queryTokenWord, text: expr[i:end], pos: i})
+			i = end
+		}
+	}
+	return tokens, nil
+}
+
+// isQueryWord reports whether the value can be written without quotes.
+func isQueryWord(value string) bool {
+	if value == "" || strings.EqualFold(value, "AND") || strings.EqualFold(value, "OR") {
+		return false
+	}
+	return !strings.ContainsAny(value, " \t\n\r=~!\"")
+}
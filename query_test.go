@@ -0,0 +1,55 @@
+package erro
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestQuery(t *testing.T) {
+	notFound := New("user not found", ClassNotFound, "user_id", 42)
+	wrapped := Wrap(notFound, "load profile", "request_id", "r1")
+	other := New("db timeout", ClassTimeout, SeverityCritical, "user_id", 42)
+	err := NewList().Add(fmt.Errorf("handler: %w", wrapped)).Add(other).Add(errors.New("plain")).Err()
+
+	matches, qErr := Query(err, "class=not_found AND fields.user_id=42")
+	if qErr != nil {
+		t.Fatal(qErr)
+	}
+	if len(matches) != 1 || matches[0] != notFound {
+		t.Errorf("expected the not found error, got %v", matches)
+	}
+
+	matches, qErr = Query(err, `fields.user_id=42 AND message~"timeout" OR fields.request_id=r1`)
+	if qErr != nil {
+		t.Fatal(qErr)
+	}
+	if len(matches) != 2 || matches[0] != wrapped || matches[1] != other {
+		t.Errorf("unexpected matches %v", matches)
+	}
+
+	if matches, _ := Query(err, "severity!=critical AND fields.user_id=42"); len(matches) != 1 || matches[0] != notFound {
+		t.Errorf("unexpected matches for inequality %v", matches)
+	}
+
+	q := NewQuery().Class(ClassNotFound).Field("user_id", 42).Or().MessageContains("db timeout")
+	if got := q.Find(err); len(got) != 2 || got[0] != notFound || got[1] != other {
+		t.Errorf("unexpected matches of the builder %v", got)
+	}
+	if q.String() != `class=not_found AND fields.user_id=42 OR message~"db timeout"` {
+		t.Errorf("unexpected query string %s", q.String())
+	}
+	parsed, qErr := ParseQuery(q.String())
+	if qErr != nil || parsed.String() != q.String() {
+		t.Errorf("expected the query string to parse back, got %v, %v", parsed, qErr)
+	}
+	if got := NewQuery().Find(wrapped); len(got) != 2 {
+		t.Errorf("expected an empty query to match every layer, got %v", got)
+	}
+
+	for _, expr := range []string{"", "class", "class=", "unknown=1", "class=a AND", "class=a XOR id=1", `message="open`, "class!1", "fields.=1"} {
+		if _, qErr := ParseQuery(expr); !errors.Is(qErr, ErrInvalidQuery) {
+			t.Errorf("expected ErrInvalidQuery for %q, got %v", expr, qErr)
+		}
+	}
+}
@@ -0,0 +1,120 @@
+package erro
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// EventRoute is a rule of an [EventRouter].
+type EventRoute struct {
+	// Name identifies the route in [EventRouter.Dropped], e.g. "security-webhook".
+	Name string
+	// Match selects the errors of the route. Nil matches all errors.
+	Match AutoRule
+	// Dispatcher receives the events of the route.
+	Dispatcher EventDispatcher
+	// Limit is the maximum number of events sent to Dispatcher per Every. Zero means no limit.
+	Limit int
+	// Every is the window of Limit. Default is a second.
+	Every time.Duration
+	// Fallback receives the events over the limit. If it is nil, they are dropped.
+	Fallback EventDispatcher
+}
+
+// EventRouter is an [EventDispatcher] that sends every event to the dispatcher of the
+// first matching route, or to the fallback dispatcher if no route matches. Routes may
+// limit the rate of their events, so a burst of errors of one kind does not flood a
+// chat channel. Rate windows are measured with the clock of [SetClock]. It is safe for
+// concurrent use.
+type EventRouter struct {
+	routes   []*eventRoute
+	fallback EventDispatcher
+}
+
+type eventRoute struct {
+	EventRoute
+
+	mu          sync.Mutex
+	windowStart time.Time
+	sent        int
+	dropped     int64
+}
+
+// NewEventRouter creates a new [EventRouter] with the routes checked in order. Events
+// that match no route are sent to fallback; nil fallback drops them.
+//
+// Example:
+//
+//	router := erro.NewEventRouter(sentry,
+//	    erro.EventRoute{Name: "security", Match: erro.Categories(erro.CategorySecurity), Dispatcher: webhook},
+//	    erro.EventRoute{Name: "payments", Match: erro.Categories(erro.CategoryPayment), Dispatcher: slack,
+//	        Limit: 10, Every: time.Minute, Fallback: sentry},
+//	)
+//	erro.Configure(erro.WithAutoEvents(router))
+func NewEventRouter(fallback EventDispatcher, routes ...EventRoute) *EventRouter {
+	r := &EventRouter{fallback: fallback, routes: make([]*eventRoute, 0, len(routes))}
+	for _, route := range routes {
+		if route.Every <= 0 {
+			route.Every = time.Second
+		}
+		r.routes = append(r.routes, &eventRoute{EventRoute: route})
+	}
+	return r
+}
+
+// SendEvent sends the error to the dispatcher of its route.
+func (r *EventRouter) SendEvent(ctx context.Context, err Error) {
+	if err == nil {
+		return
+	}
+	for _, route := range r.routes {
+		if route.Match != nil && !route.Match(err) {
+			continue
+		}
+		d := route.Dispatcher
+		if !route.allow(now()) {
+			d = route.Fallback
+		}
+		if d != nil {
+			d.SendEvent(ctx, err)
+		}
+		return
+	}
+	if r.fallback != nil {
+		r.fallback.SendEvent(ctx, err)
+	}
+}
+
+// Dropped returns the number of events over the limit per route name, including the
+// events sent to the fallback of a route.
+func (r *EventRouter) Dropped() map[string]int64 {
+	dropped := make(map[string]int64, len(r.routes))
+	for _, route := range r.routes {
+		route.mu.Lock()
+		if route.dropped > 0 {
+			dropped[route.Name] += route.dropped
+		}
+		route.mu.Unlock()
+	}
+	return dropped
+}
+
+// allow returns true if an event may be sent to the dispatcher of the route.
+func (r *eventRoute) allow(at time.Time) bool {
+	if r.Limit <= 0 {
+		return true
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if !at.Before(r.windowStart.Add(r.Every)) {
+		r.windowStart = at
+		r.sent = 0
+	}
+	if r.sent >= r.Limit {
+		r.dropped++
+		return false
+	}
+	r.sent++
+	return true
+}
@@ -0,0 +1,49 @@
+package erro
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestEventRouter(t *testing.T) {
+	at := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	SetClock(func() time.Time { return at })
+	defer SetClock(nil)
+
+	security, payments, sentry := &countingDispatcher{}, &countingDispatcher{}, &countingDispatcher{}
+	router := NewEventRouter(sentry,
+		EventRoute{Name: "security", Match: Categories(CategorySecurity), Dispatcher: security},
+		EventRoute{Name: "payments", Match: Categories(CategoryPayment), Dispatcher: payments, Limit: 2, Every: time.Minute, Fallback: sentry},
+	)
+
+	ctx := context.Background()
+	router.SendEvent(ctx, New("forbidden", CategorySecurity))
+	router.SendEvent(ctx, New("timeout"))
+	for i := 0; i < 3; i++ {
+		router.SendEvent(ctx, New("card declined", CategoryPayment))
+	}
+	router.SendEvent(ctx, nil)
+	if security.events != 1 || payments.events != 2 || sentry.events != 2 {
+		t.Errorf("unexpected events: security=%d payments=%d sentry=%d", security.events, payments.events, sentry.events)
+	}
+	if dropped := router.Dropped(); len(dropped) != 1 || dropped["payments"] != 1 {
+		t.Errorf("unexpected dropped events: %v", dropped)
+	}
+
+	at = at.Add(time.Minute)
+	router.SendEvent(ctx, New("card declined", CategoryPayment))
+	if payments.events != 3 {
+		t.Errorf("expected the limit to reset after the window, got %d events", payments.events)
+	}
+
+	// Without a fallback, events over the limit and unmatched events are dropped
+	limited := &countingDispatcher{}
+	router = NewEventRouter(nil, EventRoute{Name: "all", Dispatcher: limited, Limit: 1})
+	router.SendEvent(ctx, New("first"))
+	router.SendEvent(ctx, New("second"))
+	if limited.events != 1 || router.Dropped()["all"] != 1 {
+		t.Errorf("unexpected limited events: %d sent, %v dropped", limited.events, router.Dropped())
+	}
+	NewEventRouter(nil).SendEvent(ctx, New("unrouted"))
+}
@@ -0,0 +1,217 @@
+package erro
+
+import (
+	"errors"
+	"time"
+)
+
+// DefaultGenericMessage is the message used by [Sanitize] for errors whose class is not public.
+const DefaultGenericMessage = "internal error"
+
+// defaultPublicClasses are classes whose messages describe the client's own request
+// and are safe to return to external clients as is.
+var defaultPublicClasses = []ErrorClass{
+	ClassValidation,
+	ClassNotFound,
+	ClassAlreadyExists,
+	ClassPermissionDenied,
+	ClassUnauthenticated,
+	ClassConflict,
+	ClassRateLimited,
+	ClassTimeout,
+	ClassCancelled,
+	ClassNotImplemented,
+}
+
+// ownMessage returns the message of the outer layer without the wrapped errors.
+func ownMessage(err Error) string {
+	if e, ok := err.(*baseError); ok {
+		return e.message
+	}
+	return ""
+}
+
+// SanitizePolicy controls what information [Sanitize] keeps in the user-facing copy of an error.
+type SanitizePolicy struct {
+	// AllowedFields is a list of field keys that are kept as is. All other field values are redacted.
+	AllowedFields []string
+	// PublicClasses is a list of classes whose messages are kept. Messages of errors with other
+	// classes are replaced with GenericMessage. If nil, a default set of client-caused classes is used
+	// (validation, not found, already exists, permission denied, unauthenticated, conflict,
+	// rate limited, timeout, cancelled, not implemented).
+	PublicClasses []ErrorClass
+	// GenericMessage replaces messages of non-public errors. Default is [DefaultGenericMessage].
+	GenericMessage string
+}
+
+// Sanitize returns a copy of the error that is safe to return to external clients.
+//
+// The copy has no stack trace, no trace span and no wrapped errors. Field values are
+// redacted except the ones listed in [SanitizePolicy.AllowedFields], the field errors of
+// [ValidationErrors] and [Sensitive] values of [SensitivityPublic]; other [Sensitive]
+// values are redacted even if listed. Only the own message of the outermost error is kept,
+// and it is replaced with a generic text if the class of the error is not public.
+// ID, class, category, severity, retryable flag and creation time are kept for correlation,
+// and the [DocURL] and [UserMsg] are kept for clients. The [Hint] is dropped as it may
+// describe internals. The user message replaces the generic text if it is set.
+//
+// Example:
+//
+//	public := erro.Sanitize(err, erro.SanitizePolicy{AllowedFields: []string{"field"}})
+//	json.NewEncoder(w).Encode(public)
+func Sanitize(err error, policy SanitizePolicy) Error {
+	e := ExtractError(err)
+	if e == nil {
+		return nil
+	}
+
+	publicClasses := policy.PublicClasses
+	if publicClasses == nil {
+		publicClasses = defaultPublicClasses
+	}
+	genericMessage := policy.GenericMessage
+	if genericMessage == "" {
+		genericMessage = DefaultGenericMessage
+	}
+
+	class := e.Class()
+	userMessage := UserMessageOf(e)
+	message := genericMessage
+	if userMessage != "" {
+		message = userMessage
+	}
+	for _, c := range publicClasses {
+		if c == class && class != ClassUnknown {
+			// Only the own message of the outer layer: wrapped errors may describe internals
+			if own := ownMessage(e); own != "" {
+				message = own
+			}
+			break
+		}
+	}
+
+	sanitized := &baseError{
+		id:        e.ID(),
+		class:     class,
+		category:  e.Category(),
+		severity:  e.Severity(),
+		retryable: e.IsRetryable(),
+		created:   e.Created(),
+		message:   truncateString(message, MaxMessageLength),
+		formatter: FormatErrorWithFields,
+	}
+	sanitized.setExtras(0, 0, "", userMessage, DocURLOf(e))
+
+	var fields []any
+	appendFields := func(layerFields []any, fieldErrors bool) {
+		for i := 0; i+1 < len(layerFields); i += 2 {
+			key, value := layerFields[i], layerFields[i+1]
+			if s, ok := value.(SensitiveValue); ok {
+				// The class declared at the call site takes precedence over the allowed fields.
+				if value = s.Value; s.Level > SensitivityPublic {
+					value = redacted()
+				}
+				fields = append(fields, key, value)
+				continue
+			}
+			if _, ok := value.(RedactedValue); ok || !isAllowedField(valueToString(key), policy.AllowedFields) &&
+				!(fieldErrors && isValidationField(key)) {
+				value = redacted()
+			}
+			fields = append(fields, key, value)
+		}
+	}
+	if b, ok := e.(*baseError); ok {
+		for layer := b; layer != nil; layer = layer.wrappedErr {
+			appendFields(layer.fields, layer.fieldErrors)
+		}
+	} else {
+		appendFields(e.AllFields(), false)
+	}
+	sanitized.fields = fields
+
+	return sanitized
+}
+
+// Redacted returns a frozen deep copy of the error chain that is safe to persist to
+// third-party systems: [Redact] values, [SensitivitySecret] values and values of fields
+// with [DefaultScrubKeys] are replaced with the placeholder, nested values are scrubbed with [Scrub], and other
+// values that are not strings, numbers, booleans or times are replaced with their text,
+// so the copy keeps no references to the original values. Wrapped non-erro errors are
+// replaced with errors of the same text, except sentinels, and the span and attachments
+// are dropped. Messages are kept as is.
+//
+// Example:
+//
+//	archive.Store(err.Redacted())
+func (e *baseError) Redacted() Error {
+	return e.redactedCopy()
+}
+
+func (e *baseError) redactedCopy() *baseError {
+	c := e.copyLayer()
+	c.span = nil
+	if c.extras != nil {
+		c.extras.attachments = nil
+	}
+	if c.wrappedErr != nil {
+		c.wrappedErr = c.wrappedErr.redactedCopy()
+	}
+	if c.originalErr != nil {
+		if _, ok := c.originalErr.(*SentinelError); !ok {
+			c.originalErr = errors.New(c.originalErr.Error())
+		}
+	}
+	for i := 1; i < len(c.fields); i += 2 {
+		s, sensitive := c.fields[i].(SensitiveValue)
+		if _, ok := c.fields[i].(RedactedValue); ok || (sensitive && s.Level >= SensitivitySecret) ||
+			isScrubKey(valueToString(c.fields[i-1]), DefaultScrubKeys) {
+			c.fields[i] = redacted()
+			continue
+		}
+		if sensitive {
+			c.fields[i] = SensitiveValue{Value: detachValue(Scrub(s.Value)), Level: s.Level}
+			continue
+		}
+		c.fields[i] = detachValue(Scrub(c.fields[i]))
+	}
+	c.Freeze()
+	return c
+}
+
+// detachValue replaces values of a scrubbed value that may reference other data with their text.
+func detachValue(value any) any {
+	switch v := value.(type) {
+	case nil, string, bool, int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64,
+		float32, float64, time.Time, time.Duration:
+		return v
+	case map[string]any:
+		for key, nested := range v {
+			v[key] = detachValue(nested)
+		}
+		return v
+	case []any:
+		for i, nested := range v {
+			v[i] = detachValue(nested)
+		}
+		return v
+	default:
+		return valueToString(v)
+	}
+}
+
+// isValidationField reports whether the key is a field of the [ValidationGroup] group.
+// Such fields are kept only on layers built by [ValidationErrors].
+func isValidationField(key any) bool {
+	_, ok := validationFieldName(valueToString(key))
+	return ok
+}
+
+func isAllowedField(key string, allowed []string) bool {
+	for _, a := range allowed {
+		if a == key {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,143 @@
+package erro
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// DefaultScrubKeys are the keys redacted by [Scrub] when no keys are given.
+var DefaultScrubKeys = []string{"password", "passwd", "secret", "token", "api_key", "apikey", "authorization", "cookie"}
+
+// maxScrubDepth limits the nesting depth traversed by [Scrub] to protect from cyclic values.
+const maxScrubDepth = 32
+
+var (
+	jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+	stringerType      = reflect.TypeOf((*fmt.Stringer)(nil)).Elem()
+	errorType         = reflect.TypeOf((*error)(nil)).Elem()
+)
+
+// Scrub returns a copy of the value with values of matching keys replaced with
+// [RedactedPlaceholder] at any nesting depth. Keys are matched case-insensitively;
+// if no keys are given, [DefaultScrubKeys] are used.
+//
+// Maps with string keys and structs become map[string]any (struct keys are taken
+// from json tags or field names, unexported fields are skipped), slices and arrays
+// become []any. Values implementing [json.Marshaler], [encoding.TextMarshaler],
+// [fmt.Stringer] or error are kept as is. Use it for structured values attached to
+// errors that mix safe and sensitive data; use [Redact] for whole values.
+//
+// Example:
+//
+//	err := erro.New("login failed", "request", erro.Scrub(req, "password"))
+func Scrub(value any, keys ...string) any {
+	if len(keys) == 0 {
+		keys = DefaultScrubKeys
+	}
+	return scrubValue(reflect.ValueOf(value), keys, 0)
+}
+
+func scrubValue(v reflect.Value, keys []string, depth int) any {
+	if !v.IsValid() {
+		return nil
+	}
+	if depth >= maxScrubDepth {
+		return redacted()
+	}
+	if v.Kind() == reflect.Interface || v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return nil
+		}
+		if isScrubLeaf(v.Type()) {
+			return v.Interface()
+		}
+		return scrubValue(v.Elem(), keys, depth+1)
+	}
+	if isScrubLeaf(v.Type()) {
+		if v.CanInterface() {
+			return v.Interface()
+		}
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.Map:
+		if v.Type().Key().Kind() != reflect.String {
+			return v.Interface()
+		}
+		out := make(map[string]any, v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			key := iter.Key().String()
+			if isScrubKey(key, keys) {
+				out[key] = redacted()
+				continue
+			}
+			out[key] = scrubValue(iter.Value(), keys, depth+1)
+		}
+		return out
+
+	case reflect.Struct:
+		t := v.Type()
+		out := make(map[string]any, t.NumField())
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			if field.PkgPath != "" {
+				continue
+			}
+			key := field.Name
+			if tag := field.Tag.Get("json"); tag != "" {
+				name, _, _ := strings.Cut(tag, ",")
+				if name == "-" {
+					continue
+				}
+				if name != "" {
+					key = name
+				}
+			}
+			if isScrubKey(key, keys) || isScrubKey(field.Name, keys) {
+				out[key] = redacted()
+				continue
+			}
+			out[key] = scrubValue(v.Field(i), keys, depth+1)
+		}
+		return out
+
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice && v.IsNil() {
+			return nil
+		}
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			return v.Interface()
+		}
+		out := make([]any, v.Len())
+		for i := range out {
+			out[i] = scrubValue(v.Index(i), keys, depth+1)
+		}
+		return out
+	}
+
+	if v.CanInterface() {
+		return v.Interface()
+	}
+	return nil
+}
+
+func isScrubLeaf(t reflect.Type) bool {
+	return t.Implements(jsonMarshalerType) || t.Implements(textMarshalerType) ||
+		t.Implements(stringerType) || t.Implements(errorType) || t == reflect.TypeOf(RedactedValue{}) ||
+		t == reflect.TypeOf(SensitiveValue{})
+}
+
+func isScrubKey(key string, keys []string) bool {
+	for _, k := range keys {
+		if strings.EqualFold(key, k) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,66 @@
+package erro
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+type scrubCredentials struct {
+	User     string `json:"user"`
+	Password string `json:"pass"`
+	Token    string `json:"-"`
+	secret   string
+}
+
+type scrubRequest struct {
+	Path    string
+	Auth    *scrubCredentials
+	Headers map[string][]string
+	Items   []map[string]any
+	Created time.Time
+}
+
+func TestScrub(t *testing.T) {
+	req := scrubRequest{
+		Path:    "/login",
+		Auth:    &scrubCredentials{User: "bob", Password: "hunter2", Token: "t", secret: "s"},
+		Headers: map[string][]string{"Authorization": {"Bearer x"}, "Accept": {"json"}},
+		Items:   []map[string]any{{"id": 1, "api_key": "k", "nested": map[string]any{"TOKEN": "t"}}},
+		Created: time.Unix(0, 0),
+	}
+
+	got := Scrub(req, "password", "authorization", "token", "api_key")
+	expected := map[string]any{
+		"Path":    "/login",
+		"Auth":    map[string]any{"user": "bob", "pass": RedactedPlaceholder},
+		"Headers": map[string]any{"Authorization": RedactedPlaceholder, "Accept": []any{"json"}},
+		"Items":   []any{map[string]any{"id": 1, "api_key": RedactedPlaceholder, "nested": map[string]any{"TOKEN": RedactedPlaceholder}}},
+		"Created": time.Unix(0, 0),
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("unexpected scrubbed value:\n got %#v\nwant %#v", got, expected)
+	}
+	if req.Auth.Password != "hunter2" || req.Headers["Authorization"][0] != "Bearer x" {
+		t.Error("expected original value not to be modified")
+	}
+
+	err := New("login failed", "request", Scrub(map[string]any{"password": "p", "user": "bob"}))
+	if msg := err.Error(); strings.Contains(msg, "p ") || !strings.Contains(msg, RedactedPlaceholder) {
+		t.Errorf("expected default keys to be scrubbed, got %s", msg)
+	}
+
+	if Scrub(nil) != nil || Scrub(42) != 42 || Scrub("str") != "str" {
+		t.Error("expected scalars to be kept as is")
+	}
+
+	type node struct {
+		Next *node
+	}
+	cyclic := &node{}
+	cyclic.Next = cyclic
+	if Scrub(cyclic) == nil {
+		t.Error("expected cyclic value to be scrubbed up to the depth limit")
+	}
+}
@@ -0,0 +1,78 @@
+package erro
+
+// Sensitivity is the sensitivity class of a field value added with [Sensitive].
+// Outputs declare the maximum class they render, values of a higher class are
+// replaced with the redacted placeholder.
+type Sensitivity int8
+
+const (
+	// SensitivityPublic is for values that are safe to show to external clients.
+	SensitivityPublic Sensitivity = iota + 1
+	// SensitivityInternal is for values that are safe for internal logs and tooling.
+	SensitivityInternal
+	// SensitivitySecret is for values that are never rendered.
+	SensitivitySecret
+)
+
+// String returns the name of the sensitivity class.
+func (s Sensitivity) String() string {
+	switch s {
+	case SensitivityPublic:
+		return "public"
+	case SensitivityInternal:
+		return "internal"
+	case SensitivitySecret:
+		return "secret"
+	default:
+		return ""
+	}
+}
+
+// SensitiveValue is a field value with a sensitivity class, see [Sensitive].
+type SensitiveValue struct {
+	Value any
+	Level Sensitivity
+}
+
+// Sensitive adds a field with a sensitivity class. Error(), log fields and JSON render
+// values up to [SensitivityInternal] by default, see [WithRenderSensitivity]; log and
+// JSON outputs can override it with [WithLogSensitivity] and [WithJSONSensitivity].
+// [Sanitize] and [ToProblemDetails] render only [SensitivityPublic] values.
+//
+// Example:
+//
+//	err := erro.New("payment failed",
+//	    erro.Sensitive(erro.SensitivityPublic, "order_id", orderID),
+//	    erro.Sensitive(erro.SensitivityInternal, "user_email", email),
+//	    erro.Sensitive(erro.SensitivitySecret, "card_number", card),
+//	)
+func Sensitive(level Sensitivity, key string, value any) errorFields {
+	return func() []any {
+		return []any{key, SensitiveValue{Value: value, Level: level}}
+	}
+}
+
+// renderSensitivity returns the configured maximum sensitivity class of rendered values.
+func renderSensitivity() Sensitivity {
+	if level := renderSensitivityLevel.Load(); level != 0 {
+		return level
+	}
+	return SensitivityInternal
+}
+
+// resolveSensitive returns the value of a [SensitiveValue] if its class is not higher
+// than max, or a [RedactedValue] otherwise. Other values are returned as is.
+// Zero max means the configured level, see [WithRenderSensitivity].
+func resolveSensitive(value any, max Sensitivity) any {
+	s, ok := value.(SensitiveValue)
+	if !ok {
+		return value
+	}
+	if max == 0 {
+		max = renderSensitivity()
+	}
+	if s.Level > max {
+		return RedactedValue{Value: s.Value}
+	}
+	return s.Value
+}
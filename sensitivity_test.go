@@ -0,0 +1,79 @@
+package erro
+
+import (
+	"strings"
+	"testing"
+)
+
+func newSensitiveError() Error {
+	return New("payment failed",
+		Sensitive(SensitivityPublic, "order_id", "o-1"),
+		Sensitive(SensitivityInternal, "email", "a@b.c"),
+		Sensitive(SensitivitySecret, "card", "4242"),
+	)
+}
+
+func TestSensitiveRendering(t *testing.T) {
+	err := newSensitiveError()
+
+	msg := err.Error()
+	if !strings.Contains(msg, "order_id=o-1") || !strings.Contains(msg, "email=a@b.c") {
+		t.Errorf("expected public and internal values in Error(), got %s", msg)
+	}
+	if strings.Contains(msg, "4242") || !strings.Contains(msg, "card="+RedactedPlaceholder) {
+		t.Errorf("expected secret value to be redacted, got %s", msg)
+	}
+
+	fields := LogFieldsMap(err)
+	if fields["email"] != "a@b.c" || fields["card"] != RedactedPlaceholder {
+		t.Errorf("unexpected log fields: %v", fields)
+	}
+	fields = LogFieldsMap(err, WithUserFields(true), WithLogSensitivity(SensitivityPublic))
+	if fields["order_id"] != "o-1" || fields["email"] != RedactedPlaceholder {
+		t.Errorf("unexpected public log fields: %v", fields)
+	}
+
+	schema := ErrorToJSON(err, WithJSONSensitivity(SensitivityPublic))
+	if len(schema.Fields) != 6 || schema.Fields[1] != "o-1" || schema.Fields[3] != RedactedPlaceholder || schema.Fields[5] != RedactedPlaceholder {
+		t.Errorf("unexpected JSON fields: %v", schema.Fields)
+	}
+	schema = ErrorToJSON(err, WithJSONSensitivity(SensitivitySecret))
+	if schema.Fields[5] != "4242" {
+		t.Errorf("expected secret value with secret level, got %v", schema.Fields)
+	}
+
+	if v, ok := Field[string](err, "email"); !ok || v != "a@b.c" {
+		t.Errorf("expected internal field value, got %q %v", v, ok)
+	}
+	if _, ok := Field[string](err, "card"); ok {
+		t.Error("expected secret field to be inaccessible")
+	}
+}
+
+func TestWithRenderSensitivity(t *testing.T) {
+	Configure(WithRenderSensitivity(SensitivityPublic))
+	defer Configure(WithRenderSensitivity(0))
+
+	msg := newSensitiveError().Error()
+	if strings.Contains(msg, "a@b.c") || !strings.Contains(msg, "order_id=o-1") {
+		t.Errorf("expected only public values, got %s", msg)
+	}
+}
+
+func TestSensitiveSanitize(t *testing.T) {
+	err := newSensitiveError()
+
+	s := Sanitize(err, SanitizePolicy{AllowedFields: []string{"email"}})
+	fields := s.AllFields()
+	if len(fields) != 6 || fields[1] != "o-1" || fields[3] != RedactedPlaceholder || fields[5] != RedactedPlaceholder {
+		t.Errorf("unexpected sanitized fields: %v", fields)
+	}
+
+	r := err.Redacted()
+	if msg := r.Error(); !strings.Contains(msg, "email=a@b.c") || strings.Contains(msg, "4242") {
+		t.Errorf("unexpected redacted copy: %s", msg)
+	}
+	if v, ok := r.AllFields()[3].(SensitiveValue); !ok || v.Level != SensitivityInternal {
+		t.Errorf("expected sensitivity class to be kept, got %#v", r.AllFields()[3])
+	}
+}
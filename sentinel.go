@@ -0,0 +1,70 @@
+package erro
+
+// SentinelError is a lightweight error that carries a class, a category and
+// a severity. Create it with [Sentinel].
+type SentinelError struct {
+	message  string
+	class    ErrorClass
+	category ErrorCategory
+	severity ErrorSeverity
+}
+
+// Sentinel creates a sentinel error, like [errors.New] but with metadata. Only
+// [ErrorClass], [ErrorCategory] and [ErrorSeverity] values are used from meta.
+//
+// Every call returns a distinct pointer, so [errors.Is] matches only the sentinel
+// itself, as with stdlib sentinels, even if another one has the same message and
+// metadata. An error wrapping a sentinel with [Wrap] inherits its class, category
+// and severity unless they are set explicitly, so [HTTPCode] maps it properly.
+//
+// Example:
+//
+//	var ErrNoInventory = erro.Sentinel("no inventory", erro.ClassConflict)
+//
+//	err := erro.Wrap(ErrNoInventory, "reserve item", "sku", sku)
+//	errors.Is(err, ErrNoInventory) // true
+//	erro.HTTPCode(err)             // 409
+func Sentinel(message string, meta ...any) *SentinelError {
+	s := &SentinelError{message: message}
+	for _, m := range expandProfiles(meta) {
+		switch v := m.(type) {
+		case ErrorClass:
+			s.class = v
+		case ErrorCategory:
+			s.category = v
+		case ErrorSeverity:
+			s.severity = v
+		}
+	}
+	return s
+}
+
+// Error returns the message of the sentinel.
+func (s *SentinelError) Error() string { return s.message }
+
+// Class returns the class of the sentinel.
+func (s *SentinelError) Class() ErrorClass { return s.class }
+
+// Category returns the category of the sentinel.
+func (s *SentinelError) Category() ErrorCategory { return s.category }
+
+// Severity returns the severity of the sentinel.
+func (s *SentinelError) Severity() ErrorSeverity { return s.severity }
+
+// inheritSentinel copies metadata of a sentinel found in the chain of err.
+// It walks the chain manually because [errors.As] allocates on every wrap.
+func inheritSentinel(e *baseError, err error) {
+	for depth := 0; err != nil && depth < MaxWrapDepth; depth++ {
+		if s, ok := err.(*SentinelError); ok {
+			e.class = s.class
+			e.category = s.category
+			e.severity = s.severity
+			return
+		}
+		u, ok := err.(interface{ Unwrap() error })
+		if !ok {
+			return
+		}
+		err = u.Unwrap()
+	}
+}
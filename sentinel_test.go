@@ -0,0 +1,48 @@
+package erro
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+var errNoInventory = Sentinel("no inventory", ClassConflict, CategoryDatabase, "ignored", SeverityLow)
+
+func TestSentinel(t *testing.T) {
+	if errNoInventory.Error() != "no inventory" || errNoInventory.Class() != ClassConflict ||
+		errNoInventory.Category() != CategoryDatabase || errNoInventory.Severity() != SeverityLow {
+		t.Errorf("unexpected sentinel: %+v", errNoInventory)
+	}
+
+	err := Wrap(errNoInventory, "reserve item", "sku", "A-1")
+	if !errors.Is(err, errNoInventory) {
+		t.Error("expected wrapped error to match the sentinel")
+	}
+	if err.Class() != ClassConflict || err.Category() != CategoryDatabase || err.Severity() != SeverityLow {
+		t.Errorf("expected metadata to be inherited, got %s %s %s", err.Class(), err.Category(), err.Severity())
+	}
+	if code := HTTPCode(err); code != http.StatusConflict {
+		t.Errorf("expected status %d, got %d", http.StatusConflict, code)
+	}
+	if code := HTTPCode(errNoInventory); code != http.StatusConflict {
+		t.Errorf("expected status %d for bare sentinel, got %d", http.StatusConflict, code)
+	}
+
+	std := Wrap(fmt.Errorf("lookup: %w", errNoInventory), "reserve item")
+	if std.Class() != ClassConflict || !errors.Is(std, errNoInventory) {
+		t.Error("expected sentinel to be found through standard wrapping")
+	}
+
+	overridden := Wrap(errNoInventory, "reserve item", ClassNotFound)
+	if overridden.Class() != ClassNotFound {
+		t.Errorf("expected explicit class to win, got %s", overridden.Class())
+	}
+
+	if errors.Is(err, Sentinel("no inventory", ClassNotFound)) {
+		t.Error("expected sentinels with different metadata not to match")
+	}
+	if errors.Is(err, Sentinel("no inventory", ClassConflict, CategoryDatabase, SeverityLow)) {
+		t.Error("expected sentinels with the same message and metadata not to match")
+	}
+}
@@ -0,0 +1,258 @@
+package erro
+
+import (
+	"runtime"
+	"sync"
+)
+
+// ShardedSet is a thread-safe error set for high-throughput deduplication. Unlike
+// [SafeSet] with a single lock, it spreads keys over shards, each a [Set] with its
+// own lock, and computes the key of an error before taking a lock, so goroutines
+// adding errors of different kinds rarely contend.
+//
+// Errors are ordered by shard and then by the order they were added, not globally.
+// Methods reading the whole set, such as [ShardedSet.Err], lock shards one by one,
+// so they see a consistent state of every shard, but not of the whole set.
+type ShardedSet struct {
+	shards    []setShard
+	keyGetter atomicValue[KeyGetterFunc]
+}
+
+type setShard struct {
+	mu  sync.Mutex
+	set *Set
+	_   [40]byte // Keeps locks of neighbor shards on different cache lines
+}
+
+// NewShardedSet creates a new sharded error set. Zero or less shards means four
+// shards per CPU.
+//
+// Example:
+//
+//	set := erro.NewShardedSet(0).WithKeyGetter(erro.FingerprintKeyGetter)
+//	// In many goroutines
+//	set.Add(err)
+func NewShardedSet(shards int) *ShardedSet {
+	if shards <= 0 {
+		shards = 4 * runtime.GOMAXPROCS(0)
+	}
+	s := &ShardedSet{shards: make([]setShard, shards)}
+	for i := range s.shards {
+		s.shards[i].set = NewSet()
+	}
+	s.keyGetter.Store(MessageKeyGetter)
+	return s
+}
+
+// Add adds an error to the set.
+func (s *ShardedSet) Add(err error) *ShardedSet {
+	if err == nil {
+		return s
+	}
+	e := ExtractError(err)
+	key := s.keyGetter.Load()(e)
+	if key == "" {
+		return s
+	}
+	shard := s.shard(key)
+	shard.mu.Lock()
+	shard.set.addKey(e, key)
+	shard.mu.Unlock()
+	return s
+}
+
+// New creates a new error and adds it to the set.
+func (s *ShardedSet) New(message string, meta ...any) *ShardedSet {
+	return s.Add(newf(message, meta...))
+}
+
+// Wrap wraps an existing error and adds it to the set. It does nothing if err is nil.
+func (s *ShardedSet) Wrap(err error, message string, meta ...any) *ShardedSet {
+	if err == nil {
+		return s
+	}
+	return s.Add(wrapf(err, message, meta...))
+}
+
+// WithKeyGetter sets the function used to generate deduplication keys. Errors already
+// in the set are moved to the shards of their new keys. Configure the set before
+// sharing it: errors added concurrently may be keyed with the previous function.
+func (s *ShardedSet) WithKeyGetter(keyGetter KeyGetterFunc) *ShardedSet {
+	if keyGetter == nil {
+		return s
+	}
+	s.lockAll()
+	defer s.unlockAll()
+
+	old := make([]*Set, len(s.shards))
+	for i := range s.shards {
+		old[i] = s.shards[i].set
+		s.shards[i].set = NewSet().WithKeyGetter(keyGetter).WithMaxKeys(old[i].maxKeys)
+	}
+	s.keyGetter.Store(keyGetter)
+	for i, set := range old {
+		s.shards[i].set.others += set.others
+		for _, err := range set.List.errors {
+			if key := keyGetter(err); key != "" {
+				s.shard(key).set.addKeyCount(err, key, set.count(err))
+			}
+		}
+	}
+	return s
+}
+
+// WithMaxKeys bounds the number of unique keys tracked by the set, see [Set.WithMaxKeys].
+// The limit is split evenly between the shards, so the least recently seen keys are
+// evicted per shard. Zero or less disables the limit.
+func (s *ShardedSet) WithMaxKeys(maxKeys int) *ShardedSet {
+	perShard := 0
+	if maxKeys > 0 {
+		perShard = (maxKeys + len(s.shards) - 1) / len(s.shards)
+	}
+	for i := range s.shards {
+		shard := &s.shards[i]
+		shard.mu.Lock()
+		shard.set.WithMaxKeys(perShard)
+		shard.mu.Unlock()
+	}
+	return s
+}
+
+// Err returns a combined error from all errors in the set, see [Set.Err].
+func (s *ShardedSet) Err() error {
+	return s.merged().Err()
+}
+
+// Errors returns a slice of all errors in the set as standard `error` interfaces.
+func (s *ShardedSet) Errors() []error {
+	return s.merged().Errors()
+}
+
+// Errs returns a slice of all errors in the set as `erro.Error` interfaces.
+func (s *ShardedSet) Errs() []Error {
+	return s.merged().Errs()
+}
+
+// Range calls f for each error of a snapshot of the set until f returns false.
+func (s *ShardedSet) Range(f func(err Error) bool) {
+	s.Snapshot().Range(f)
+}
+
+// Snapshot returns an immutable view of the errors currently in the set.
+func (s *ShardedSet) Snapshot() Snapshot {
+	return s.merged().Snapshot()
+}
+
+// Len returns the number of unique errors in the set.
+func (s *ShardedSet) Len() int {
+	n := 0
+	for i := range s.shards {
+		shard := &s.shards[i]
+		shard.mu.Lock()
+		n += shard.set.Len()
+		shard.mu.Unlock()
+	}
+	return n
+}
+
+// Empty returns true if the set contains no errors.
+func (s *ShardedSet) Empty() bool {
+	return s.Len() == 0
+}
+
+// NotEmpty returns true if the set contains at least one error.
+func (s *ShardedSet) NotEmpty() bool {
+	return s.Len() > 0
+}
+
+// Clear removes all errors from the set.
+func (s *ShardedSet) Clear() *ShardedSet {
+	for i := range s.shards {
+		shard := &s.shards[i]
+		shard.mu.Lock()
+		shard.set.Clear()
+		shard.mu.Unlock()
+	}
+	return s
+}
+
+// Count returns the number of occurrences of errors with the key of err.
+func (s *ShardedSet) Count(err error) int {
+	if err == nil {
+		return 0
+	}
+	key := s.keyGetter.Load()(err)
+	if key == "" {
+		return 0
+	}
+	shard := s.shard(key)
+	shard.mu.Lock()
+	defer shard.mu.Unlock()
+	return shard.set.seen[key]
+}
+
+// Counts returns a copy of the occurrence counts by keys.
+func (s *ShardedSet) Counts() map[string]int {
+	counts := make(map[string]int)
+	for i := range s.shards {
+		shard := &s.shards[i]
+		shard.mu.Lock()
+		for k, v := range shard.set.seen {
+			counts[k] = v
+		}
+		shard.mu.Unlock()
+	}
+	return counts
+}
+
+// Total returns the number of occurrences of all errors, see [Set.Total].
+func (s *ShardedSet) Total() int {
+	total := 0
+	for i := range s.shards {
+		shard := &s.shards[i]
+		shard.mu.Lock()
+		total += shard.set.Total()
+		shard.mu.Unlock()
+	}
+	return total
+}
+
+// TopN returns the n most frequent errors, see [Set.TopN].
+func (s *ShardedSet) TopN(n int) []ErrorCount {
+	return s.merged().TopN(n)
+}
+
+// shard returns the shard of the key.
+func (s *ShardedSet) shard(key string) *setShard {
+	// FNV-1a, inlined to avoid allocations of hash.Hash
+	h := uint32(2166136261)
+	for i := 0; i < len(key); i++ {
+		h ^= uint32(key[i])
+		h *= 16777619
+	}
+	return &s.shards[h%uint32(len(s.shards))]
+}
+
+// merged returns a [Set] with the errors and counts of all shards.
+func (s *ShardedSet) merged() *Set {
+	merged := NewSet().WithKeyGetter(s.keyGetter.Load())
+	for i := range s.shards {
+		shard := &s.shards[i]
+		shard.mu.Lock()
+		merged.Union(shard.set)
+		shard.mu.Unlock()
+	}
+	return merged
+}
+
+func (s *ShardedSet) lockAll() {
+	for i := range s.shards {
+		s.shards[i].mu.Lock()
+	}
+}
+
+func (s *ShardedSet) unlockAll() {
+	for i := range s.shards {
+		s.shards[i].mu.Unlock()
+	}
+}
@@ -0,0 +1,57 @@
+package erro
+
+import (
+	"errors"
+	"sync"
+	"testing"
+)
+
+func TestShardedSet(t *testing.T) {
+	set := NewShardedSet(4)
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 100; i++ {
+				set.New("db down", "attempt", i)
+				set.Wrap(errors.New("timeout"), "call api")
+				set.Add(nil)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if set.Len() != 2 || set.Total() != 1600 {
+		t.Fatalf("expected 2 unique errors and 1600 occurrences, got %d and %d", set.Len(), set.Total())
+	}
+	if n := set.Count(New("db down")); n != 800 {
+		t.Errorf("expected 800 occurrences, got %d", n)
+	}
+	if counts := set.Counts(); counts["db down"] != 800 || counts["call api: timeout"] != 800 {
+		t.Errorf("unexpected counts: %v", counts)
+	}
+	if top := set.TopN(1); len(top) != 1 || top[0].Count != 800 {
+		t.Errorf("unexpected top errors: %v", top)
+	}
+	var multi *multiErrorSet
+	if err := set.Err(); !errors.As(err, &multi) || len(multi.errors) != 2 {
+		t.Errorf("expected multi-error of 2 errors, got %v", err)
+	}
+
+	set.WithKeyGetter(func(err error) string { return "same" })
+	if set.Len() != 1 || set.Count(New("other")) != 1600 {
+		t.Errorf("expected errors to be re-keyed, got %d errors and %v", set.Len(), set.Counts())
+	}
+
+	set.Clear()
+	if set.NotEmpty() || set.Err() != nil {
+		t.Error("expected empty set after Clear")
+	}
+
+	bounded := NewShardedSet(1).WithMaxKeys(1)
+	bounded.New("first").New("second")
+	if bounded.Len() != 1 || bounded.Total() != 2 {
+		t.Errorf("expected evicted error to be counted, got %d errors of %d", bounded.Len(), bounded.Total())
+	}
+}
@@ -0,0 +1,198 @@
+//go:build go1.21
+
+package erro
+
+import (
+	"context"
+	"log/slog"
+	"strconv"
+)
+
+// SlogGroupKey is the key of the group produced by [SlogGroup].
+const SlogGroupKey = "error"
+
+// SlogGroup renders the error chain as a nested [slog.Group] with the [SlogGroupKey] key,
+// so JSON logs show the chain structurally instead of a single concatenated string.
+//
+// Each layer is a group with its own message, class, category and severity if set, its
+// fields, the origin frame if a stack trace was captured, and the wrapped error as the
+// "cause" group. It returns an empty attribute for nil, which slog ignores.
+//
+// Example:
+//
+//	slog.Error("request failed", erro.SlogGroup(err))
+//	// {"msg":"request failed","error":{"message":"get user","fields":{"user_id":42},"cause":{"message":"connection refused"}}}
+func SlogGroup(err error) slog.Attr {
+	if err == nil {
+		return slog.Attr{}
+	}
+	return slog.Attr{Key: SlogGroupKey, Value: slog.GroupValue(slogLayer(err, 0)...)}
+}
+
+// slogLayer renders a layer and its causes, limited by [MaxWrapDepth].
+func slogLayer(err error, depth int) []slog.Attr {
+	e, ok := err.(*baseError)
+	if !ok {
+		attrs := []slog.Attr{slog.String("message", err.Error())}
+		if cause := unwrapOnce(err); cause != nil && depth < MaxWrapDepth {
+			attrs = append(attrs, slog.Attr{Key: "cause", Value: slog.GroupValue(slogLayer(cause, depth+1)...)})
+		}
+		return attrs
+	}
+
+	attrs := make([]slog.Attr, 0, 8)
+	attrs = append(attrs, slog.String("message", buildMessage(e)))
+	if e.wrappedErr == nil && e.id != "" {
+		attrs = append(attrs, slog.String("id", e.id))
+	}
+	if e.class != "" {
+		attrs = append(attrs, slog.String("class", e.class.String()))
+	}
+	if e.category != "" {
+		attrs = append(attrs, slog.String("category", e.category.String()))
+	}
+	if e.severity != "" {
+		attrs = append(attrs, slog.String("severity", e.severity.String()))
+	}
+
+	if len(e.fields) > 0 {
+		fields := make([]slog.Attr, 0, len(e.fields)/2)
+		for i := 0; i+1 < len(e.fields); i += 2 {
+			value := resolveSensitive(e.fields[i+1], 0)
+			if _, ok := value.(RedactedValue); ok {
+				value = redacted()
+			}
+			fields = append(fields, slog.Any(valueToString(e.fields[i]), value))
+		}
+		attrs = append(attrs, slog.Attr{Key: "fields", Value: slog.GroupValue(fields...)})
+	}
+
+	if frames := e.ownStack(); len(frames) > 0 {
+		frame := frames.TopUserFrame()
+		if frame == nil && len(frames) > 0 {
+			frame = &frames[0]
+		}
+		if frame != nil {
+			attrs = append(attrs, slog.Group("origin",
+				slog.String("function", frame.FullName),
+				slog.String("file", frame.File+":"+strconv.Itoa(frame.Line)),
+			))
+		}
+	}
+
+	if cause := e.Unwrap(); cause != nil && depth < MaxWrapDepth {
+		attrs = append(attrs, slog.Attr{Key: "cause", Value: slog.GroupValue(slogLayer(cause, depth+1)...)})
+	}
+	return attrs
+}
+
+func unwrapOnce(err error) error {
+	if u, ok := err.(interface{ Unwrap() error }); ok {
+		return u.Unwrap()
+	}
+	return nil
+}
+
+// SlogHandler is a [slog.Handler] that expands erro errors in record attributes into
+// structured groups before passing records to the inner handler, see [NewSlogHandler].
+type SlogHandler struct {
+	inner slog.Handler
+}
+
+// NewSlogHandler returns a [slog.Handler] that replaces attributes holding erro errors,
+// including errors wrapping them, with groups of the chain as rendered by [SlogGroup]:
+// message, class, ID, fields, origin and causes. The attribute key is kept. Other
+// attributes and errors without erro layers are passed to the inner handler as is,
+// so existing logging call sites gain structure without switching to [LogError].
+//
+// Example:
+//
+//	logger := slog.New(erro.NewSlogHandler(slog.NewJSONHandler(os.Stdout, nil)))
+//	logger.Error("request failed", "err", err)
+//	// {"msg":"request failed","err":{"message":"get user","class":"not_found","fields":{"user_id":42}}}
+func NewSlogHandler(inner slog.Handler) *SlogHandler {
+	return &SlogHandler{inner: inner}
+}
+
+// Enabled reports whether the inner handler handles records at the given level.
+func (h *SlogHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.inner.Enabled(ctx, level)
+}
+
+// Handle expands erro errors in the record attributes and passes it to the inner handler.
+func (h *SlogHandler) Handle(ctx context.Context, r slog.Record) error {
+	expanded := false
+	r.Attrs(func(a slog.Attr) bool {
+		if _, ok := expandSlogAttr(a); ok {
+			expanded = true
+			return false
+		}
+		return true
+	})
+	if !expanded {
+		return h.inner.Handle(ctx, r)
+	}
+
+	out := slog.NewRecord(r.Time, r.Level, r.Message, r.PC)
+	r.Attrs(func(a slog.Attr) bool {
+		if e, ok := expandSlogAttr(a); ok {
+			a = e
+		}
+		out.AddAttrs(a)
+		return true
+	})
+	return h.inner.Handle(ctx, out)
+}
+
+// WithAttrs returns a handler with the attributes expanded and added to the inner handler.
+func (h *SlogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	expanded := make([]slog.Attr, len(attrs))
+	for i, a := range attrs {
+		if e, ok := expandSlogAttr(a); ok {
+			a = e
+		}
+		expanded[i] = a
+	}
+	return &SlogHandler{inner: h.inner.WithAttrs(expanded)}
+}
+
+// WithGroup returns a handler with the group opened in the inner handler.
+func (h *SlogHandler) WithGroup(name string) slog.Handler {
+	return &SlogHandler{inner: h.inner.WithGroup(name)}
+}
+
+// expandSlogAttr replaces an attribute holding an erro error with its group, also in
+// nested groups. It reports whether anything was replaced.
+func expandSlogAttr(a slog.Attr) (slog.Attr, bool) {
+	switch a.Value.Kind() {
+	case slog.KindAny:
+		err, ok := a.Value.Any().(error)
+		if !ok || err == nil {
+			return a, false
+		}
+		var target Error
+		if !As(err, &target) {
+			return a, false
+		}
+		return slog.Attr{Key: a.Key, Value: slog.GroupValue(slogLayer(err, 0)...)}, true
+	case slog.KindGroup:
+		group := a.Value.Group()
+		var out []slog.Attr
+		for i, nested := range group {
+			e, ok := expandSlogAttr(nested)
+			if !ok {
+				continue
+			}
+			if out == nil {
+				out = append([]slog.Attr(nil), group...)
+			}
+			out[i] = e
+		}
+		if out == nil {
+			return a, false
+		}
+		return slog.Attr{Key: a.Key, Value: slog.GroupValue(out...)}, true
+	default:
+		return a, false
+	}
+}
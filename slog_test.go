@@ -0,0 +1,97 @@
+//go:build go1.21
+
+package erro
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestSlogGroup(t *testing.T) {
+	if attr := SlogGroup(nil); !attr.Equal(slog.Attr{}) {
+		t.Errorf("expected empty attribute for nil, got %v", attr)
+	}
+
+	root := fmt.Errorf("dial: %w", fmt.Errorf("connection refused"))
+	inner := Wrap(root, "query failed", ClassTimeout, "query", "SELECT 1", "password", Redact("secret"), StackTrace())
+	err := Wrap(inner, "get user", SeverityHigh, "user_id", 42)
+
+	var buf bytes.Buffer
+	slog.New(slog.NewJSONHandler(&buf, nil)).Error("request failed", SlogGroup(err))
+
+	var entry struct {
+		Error map[string]any `json:"error"`
+	}
+	if jsonErr := json.Unmarshal(buf.Bytes(), &entry); jsonErr != nil {
+		t.Fatalf("failed to decode log entry: %v\n%s", jsonErr, buf.String())
+	}
+
+	outer := entry.Error
+	if outer["message"] != "get user" || outer["severity"] != "high" || outer["fields"].(map[string]any)["user_id"] != float64(42) {
+		t.Errorf("unexpected outer layer: %v", outer)
+	}
+	if _, ok := outer["origin"]; ok {
+		t.Error("expected no origin for a layer without stack trace")
+	}
+
+	middle := outer["cause"].(map[string]any)
+	fields := middle["fields"].(map[string]any)
+	if middle["message"] != "query failed" || middle["class"] != "timeout" || fields["password"] != redacted() {
+		t.Errorf("unexpected middle layer: %v", middle)
+	}
+	origin, _ := middle["origin"].(map[string]any)
+	if !strings.HasSuffix(fmt.Sprint(origin["function"]), "TestSlogGroup") || !strings.Contains(fmt.Sprint(origin["file"]), "slog_test.go:") {
+		t.Errorf("unexpected origin: %v", origin)
+	}
+
+	std := middle["cause"].(map[string]any)
+	if std["message"] != "dial: connection refused" || std["cause"].(map[string]any)["message"] != "connection refused" {
+		t.Errorf("unexpected standard error layers: %v", std)
+	}
+}
+
+func TestSlogHandler(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(NewSlogHandler(slog.NewJSONHandler(&buf, nil)))
+
+	err := Wrap(fmt.Errorf("connection refused"), "get user", ClassNotFound, ID("err-1"), "user_id", 42)
+	logger.With("component", "api").WithGroup("req").Error("request failed",
+		"err", fmt.Errorf("handler: %w", err), slog.Group("nested", "cause", err), "plain", fmt.Errorf("plain"))
+
+	var entry struct {
+		Component string `json:"component"`
+		Req       struct {
+			Err    map[string]any `json:"err"`
+			Nested struct {
+				Cause map[string]any `json:"cause"`
+			} `json:"nested"`
+			Plain string `json:"plain"`
+		} `json:"req"`
+	}
+	if jsonErr := json.Unmarshal(buf.Bytes(), &entry); jsonErr != nil {
+		t.Fatalf("failed to decode log entry: %v\n%s", jsonErr, buf.String())
+	}
+	if entry.Component != "api" || entry.Req.Plain != "plain" {
+		t.Errorf("expected other attributes to be kept, got %s", buf.String())
+	}
+	if msg, _ := entry.Req.Err["message"].(string); !strings.HasPrefix(msg, "handler: get user") {
+		t.Errorf("unexpected expanded error: %v", entry.Req.Err)
+	}
+	cause, _ := entry.Req.Err["cause"].(map[string]any)
+	if cause["class"] != "not_found" || cause["fields"].(map[string]any)["user_id"] != float64(42) {
+		t.Errorf("unexpected expanded erro layer: %v", cause)
+	}
+	if entry.Req.Nested.Cause["message"] != "get user" {
+		t.Errorf("expected error in nested group to be expanded, got %v", entry.Req.Nested.Cause)
+	}
+
+	buf.Reset()
+	logger.With("err", err).Info("with attrs")
+	if !strings.Contains(buf.String(), `"err":{"message":"get user"`) {
+		t.Errorf("expected handler attributes to be expanded, got %s", buf.String())
+	}
+}
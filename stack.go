@@ -5,8 +5,10 @@ import (
 	"path/filepath"
 	"runtime"
 	"runtime/debug"
+	"sort"
 	"strconv"
 	"strings"
+	"sync"
 )
 
 var (
@@ -89,6 +91,70 @@ func StrictStackTraceConfig() *StackTraceConfig {
 	}
 }
 
+type scopedStackConfig struct {
+	prefix string
+	cfg    *StackTraceConfig
+}
+
+var (
+	scopedStackConfigs   atomicValue[[]scopedStackConfig]
+	scopedStackConfigsMu sync.Mutex
+)
+
+// WithStackConfigFor registers the stack trace configuration of frames in packages with
+// the path prefix, e.g. a module path. It overrides the configuration passed to
+// [StackTrace] for these frames only, so sensitive packages stay redacted in the stack
+// of any error. The prefix matches whole path elements and the longest prefix wins.
+// A nil config removes the prefix.
+//
+// Example:
+//
+//	erro.Configure(erro.WithStackConfigFor("github.com/acme/internal/payments", erro.StrictStackTraceConfig()))
+func WithStackConfigFor(prefix string, cfg *StackTraceConfig) ConfigOption {
+	prefix = strings.TrimSuffix(prefix, "/")
+	var cfgCopy *StackTraceConfig
+	if cfg != nil {
+		copied := *cfg
+		cfgCopy = &copied
+	}
+	return func() {
+		scopedStackConfigsMu.Lock()
+		defer scopedStackConfigsMu.Unlock()
+
+		current := scopedStackConfigs.Load()
+		updated := make([]scopedStackConfig, 0, len(current)+1)
+		for _, sc := range current {
+			if sc.prefix != prefix {
+				updated = append(updated, sc)
+			}
+		}
+		if cfgCopy != nil {
+			updated = append(updated, scopedStackConfig{prefix: prefix, cfg: cfgCopy})
+			sort.SliceStable(updated, func(i, j int) bool {
+				return len(updated[i].prefix) > len(updated[j].prefix)
+			})
+		}
+		scopedStackConfigs.Store(updated)
+	}
+}
+
+// applyScopedStackConfigs sets the configurations of [WithStackConfigFor] to the frames.
+func applyScopedStackConfigs(frames Stack) {
+	scoped := scopedStackConfigs.Load()
+	if len(scoped) == 0 {
+		return
+	}
+	for i := range frames {
+		pkg := packagePath(frames[i].FullName)
+		for _, sc := range scoped {
+			if pkg == sc.prefix || strings.HasPrefix(pkg, sc.prefix) && pkg[len(sc.prefix)] == '/' {
+				frames[i].StackTraceConfig = sc.cfg
+				break
+			}
+		}
+	}
+}
+
 // StackFrame stores a frame's runtime information in a human-readable format,
 // enhanced with additional context for better error diagnostics.
 type StackFrame struct {
@@ -265,6 +331,60 @@ func (f StackFrame) getFileName() string {
 // Stack represents a collection of stack frames with enhanced analysis capabilities.
 type Stack []StackFrame
 
+// NewStackFromFrames creates a stack from frames, e.g. frames of a report from another
+// process, with the innermost frame first. Empty Name, Package and FileName are derived
+// from FullName and File. The frames are copied.
+//
+// Example:
+//
+//	stack := erro.NewStackFromFrames([]erro.StackFrame{
+//	    {FullName: "github.com/app/worker.process", File: "/src/app/worker/process.go", Line: 42},
+//	})
+//	err := erro.New("job failed", erro.AttachStack(stack))
+func NewStackFromFrames(frames []StackFrame) Stack {
+	if len(frames) == 0 {
+		return nil
+	}
+	stack := make(Stack, len(frames))
+	for i, frame := range frames {
+		if frame.Name == "" {
+			frame.Name = extractShortName(frame.FullName)
+		}
+		if frame.Package == "" {
+			frame.Package = extractPackageFromFunction(frame.FullName)
+		}
+		if frame.FileName == "" && frame.File != "" {
+			frame.FileName = filepath.Base(frame.File)
+		}
+		stack[i] = frame
+	}
+	return stack
+}
+
+// Append returns a new stack with the frames of other after the frames of s, e.g. to
+// join the stack of a goroutine with the stack of the code that started it.
+func (s Stack) Append(other Stack) Stack {
+	if len(s)+len(other) == 0 {
+		return nil
+	}
+	stack := make(Stack, 0, len(s)+len(other))
+	stack = append(stack, s...)
+	return append(stack, other...)
+}
+
+// TrimBelow returns the stack without the callers of the first frame of the function,
+// e.g. to cut the frames of a framework below a handler. The function is matched by
+// its full name ("github.com/app/api.handle") or by its name ("handle", "Server.handle").
+// The stack is returned as is if there is no such frame.
+func (s Stack) TrimBelow(funcName string) Stack {
+	for i, frame := range s {
+		if frame.FullName == funcName || frame.Name == funcName || strings.HasSuffix(frame.FullName, "."+funcName) {
+			return s[: i+1 : i+1]
+		}
+	}
+	return s
+}
+
 // String returns a formatted string representation of the entire stack.
 func (s Stack) String() string {
 	var builder strings.Builder
@@ -443,6 +563,82 @@ func (s Stack) FilterByPackage(packageName string) Stack {
 	return filtered
 }
 
+// StackDiffResult is the result of comparing user frames of two stacks with [StackDiff].
+type StackDiffResult struct {
+	// Common is the common prefix of user frames, starting from the origin of the errors.
+	Common Stack
+	// OnlyA and OnlyB are the user frames of each stack after the divergence point.
+	OnlyA Stack
+	OnlyB Stack
+}
+
+// Identical returns true if both stacks have the same user frames.
+func (d StackDiffResult) Identical() bool {
+	return len(d.OnlyA) == 0 && len(d.OnlyB) == 0
+}
+
+// DivergedAt returns the pair of frames where the stacks diverge. A frame is nil
+// if its stack ended before the point of divergence; both are nil for identical stacks.
+func (d StackDiffResult) DivergedAt() (a, b *StackFrame) {
+	if len(d.OnlyA) > 0 {
+		a = &d.OnlyA[0]
+	}
+	if len(d.OnlyB) > 0 {
+		b = &d.OnlyB[0]
+	}
+	return a, b
+}
+
+// String returns a human-readable description of the difference.
+func (d StackDiffResult) String() string {
+	if d.Identical() {
+		return "identical stacks: " + d.Common.String()
+	}
+	var builder strings.Builder
+	builder.WriteString("common: ")
+	builder.WriteString(d.Common.String())
+	builder.WriteString("\n  a: ")
+	builder.WriteString(d.OnlyA.String())
+	builder.WriteString("\n  b: ")
+	builder.WriteString(d.OnlyB.String())
+	return builder.String()
+}
+
+// StackDiff compares user frames of the stack traces of two errors. Frames are
+// equal if they have the same function, file and line. It is useful to spot a new
+// code path leading to an error with a known fingerprint.
+//
+// Example:
+//
+//	diff := erro.StackDiff(known, current)
+//	if !diff.Identical() {
+//	    a, b := diff.DivergedAt()
+//	    log.Printf("new code path: %v instead of %v", b, a)
+//	}
+func StackDiff(a, b Error) StackDiffResult {
+	var stackA, stackB Stack
+	if a != nil {
+		stackA = a.Stack().UserFrames()
+	}
+	if b != nil {
+		stackB = b.Stack().UserFrames()
+	}
+
+	common := 0
+	for common < len(stackA) && common < len(stackB) && sameFrame(stackA[common], stackB[common]) {
+		common++
+	}
+	return StackDiffResult{
+		Common: stackA[:common:common],
+		OnlyA:  stackA[common:],
+		OnlyB:  stackB[common:],
+	}
+}
+
+func sameFrame(a, b StackFrame) bool {
+	return a.FullName == b.FullName && a.File == b.File && a.Line == b.Line
+}
+
 // StackContext extracts contextual information from the stack frame.
 type StackContext struct {
 	Function   string            `json:"function" bson:"function" db:"function"`
@@ -570,6 +766,63 @@ func extractModule(fullName string) string {
 	return ""
 }
 
+// StackCapturer captures stack traces of errors created with [StackTrace] and
+// [WithAutoStackSeverity], see [WithStackCapturer]. Replace the default to include
+// frames the Go runtime cannot see, e.g. C frames of cgo calls unwound with libunwind
+// or frames reported by an eBPF agent.
+type StackCapturer interface {
+	// Capture returns the stack of the caller of [New] or [Wrap]. Passing skip to
+	// runtime.Callers called directly in Capture starts the stack at that call site.
+	// The frames get the [StackTraceConfig] of the error.
+	Capture(skip int) Stack
+}
+
+// GoStackCapturer captures Go frames with runtime.Callers. Unlike the default capturer,
+// it resolves frames on capture; use it to combine Go frames with frames of another source.
+type GoStackCapturer struct{}
+
+// Capture implements [StackCapturer].
+func (GoStackCapturer) Capture(skip int) Stack {
+	return captureStack(skip).toFrames(nil)
+}
+
+type stackCapturerBox struct {
+	capturer StackCapturer
+}
+
+var stackCapturer atomicValue[stackCapturerBox]
+
+// captureErrorStack sets the stack trace of the error with the configured [StackCapturer].
+// The default capturer stores program counters that are resolved on demand.
+func captureErrorStack(e *baseError, skip int) {
+	if skip != 0 {
+		skip += e.ext().callerSkip
+	}
+	c := stackCapturer.Load().capturer
+	if c == nil {
+		e.stack = captureStack(skip + 1)
+		return
+	}
+	if skip == 0 {
+		return
+	}
+	frames := c.Capture(skip + 2)
+	if len(frames) == 0 {
+		return
+	}
+	cfg := e.StackTraceConfig()
+	if cfg == nil {
+		cfg = DevelopmentStackTraceConfig()
+	}
+	frames = append(Stack(nil), frames...)
+	for i := range frames {
+		frames[i].StackTraceConfig = cfg
+	}
+	applyScopedStackConfigs(frames)
+	e.stack = rawStack{}
+	e.frames.Store(frames)
+}
+
 type rawStack []uintptr
 
 func captureStack(skip int) rawStack {
@@ -628,6 +881,7 @@ func (rs rawStack) toFrames(cfg *StackTraceConfig) Stack {
 			break
 		}
 	}
+	applyScopedStackConfigs(frames)
 
 	return frames
 }
@@ -1,6 +1,8 @@
 package erro
 
 import (
+	"errors"
+	"fmt"
 	"strings"
 	"testing"
 )
@@ -20,6 +22,40 @@ func TestStackTraceConfig(t *testing.T) {
 	}
 }
 
+func TestWithStackConfigFor(t *testing.T) {
+	Configure(
+		WithStackConfigFor("github.com/maxbolgarin/erro", StrictStackTraceConfig()),
+		WithStackConfigFor("github.com/maxbolgarin/err", StrictStackTraceConfig()),
+	)
+	defer Configure(
+		WithStackConfigFor("github.com/maxbolgarin/erro", nil),
+		WithStackConfigFor("github.com/maxbolgarin/err", nil),
+	)
+
+	err := New("payment failed", StackTrace(DevelopmentStackTraceConfig()))
+	frames := err.Stack()
+	if len(frames) == 0 {
+		t.Fatal("expected a stack trace")
+	}
+	top := frames[0]
+	if top.StackTraceConfig.ShowFunctionNames {
+		t.Errorf("expected the strict config for the frame of the package, got %+v", top.StackTraceConfig)
+	}
+	if top.String() != "[some_function] ("+top.FileName+":"+fmt.Sprint(top.Line)+")" {
+		t.Errorf("expected a redacted frame, got %s", top.String())
+	}
+	for _, frame := range frames {
+		if strings.HasPrefix(frame.FullName, "testing.") && !frame.StackTraceConfig.ShowFunctionNames {
+			t.Errorf("expected the config of the call site for %s", frame.FullName)
+		}
+	}
+
+	Configure(WithStackConfigFor("github.com/maxbolgarin/erro", nil))
+	if frames := New("payment failed", StackTrace()).Stack(); !frames[0].StackTraceConfig.ShowFunctionNames {
+		t.Error("expected the removed config to not apply")
+	}
+}
+
 func TestStackFrame_String(t *testing.T) {
 	frame := StackFrame{
 		Name:     "main",
@@ -862,3 +898,188 @@ func TestExtractPathElements_EdgeCases(t *testing.T) {
 		t.Errorf("expected cleaned path, got %s", extractPathElements(path, 2))
 	}
 }
+
+func stackDiffOrigin(path string) Error {
+	if path == "a" {
+		return stackDiffCallerA()
+	}
+	return stackDiffCallerB()
+}
+
+//go:noinline
+func stackDiffCallerA() Error { return stackDiffCreate() }
+
+//go:noinline
+func stackDiffCallerB() Error { return stackDiffCreate() }
+
+//go:noinline
+func stackDiffCreate() Error { return New("test error", StackTrace()) }
+
+func TestStackDiff(t *testing.T) {
+	a := stackDiffOrigin("a")
+	b := stackDiffOrigin("b")
+
+	diff := StackDiff(a, b)
+	if diff.Identical() {
+		t.Fatal("expected stacks from different callers to differ")
+	}
+	if len(diff.Common) != 1 || diff.Common[0].Name != "stackDiffCreate" {
+		t.Errorf("expected common prefix to be the origin frame, got %s", diff.Common)
+	}
+	fa, fb := diff.DivergedAt()
+	if fa == nil || fb == nil || fa.Name != "stackDiffCallerA" || fb.Name != "stackDiffCallerB" {
+		t.Errorf("unexpected divergence point: %v %v", fa, fb)
+	}
+	if !strings.Contains(diff.String(), "common: ") {
+		t.Errorf("unexpected diff string: %s", diff.String())
+	}
+
+	same := StackDiff(a, a)
+	if !same.Identical() || len(same.Common) == 0 {
+		t.Errorf("expected error to be identical to itself")
+	}
+	if fa, fb := same.DivergedAt(); fa != nil || fb != nil {
+		t.Errorf("expected no divergence point for identical stacks")
+	}
+
+	empty := StackDiff(a, New("no stack"))
+	if len(empty.Common) != 0 || len(empty.OnlyA) == 0 || len(empty.OnlyB) != 0 {
+		t.Errorf("unexpected diff with an empty stack: %+v", empty)
+	}
+	if nilDiff := StackDiff(nil, nil); !nilDiff.Identical() {
+		t.Errorf("expected nil errors to be identical")
+	}
+}
+
+func TestNewStackFromFrames(t *testing.T) {
+	if NewStackFromFrames(nil) != nil {
+		t.Error("expected nil stack for no frames")
+	}
+	frames := []StackFrame{
+		{FullName: "github.com/app/worker.(*Pool).process", File: "/src/app/worker/pool.go", Line: 42},
+		{FullName: "github.com/app/api.handle", Name: "custom", File: "/src/app/api/api.go", Line: 7},
+	}
+	stack := NewStackFromFrames(frames)
+	frames[0].Line = 1
+	if stack[0].Line != 42 {
+		t.Error("frames must be copied")
+	}
+	if stack[0].Name != "process" || stack[0].Package != "worker" || stack[0].FileName != "pool.go" {
+		t.Errorf("unexpected derived fields %v", stack[0].Name)
+	}
+	if stack[1].Name != "custom" {
+		t.Errorf("set fields must be kept, got %q", stack[1].Name)
+	}
+
+	err := Wrap(New("job failed", AttachStack(stack)), "outer")
+	got := err.Stack()
+	if len(got) != 2 || got[0].FullName != stack[0].FullName {
+		t.Errorf("unexpected attached stack %v", got)
+	}
+	if top := got.TopUserFrame(); top == nil || top.Line != 42 {
+		t.Errorf("unexpected top frame %v", top)
+	}
+	if !strings.Contains(fmt.Sprintf("%+v", err), "/src/app/worker/pool.go:42") {
+		t.Errorf("attached stack must be printed, got %q", fmt.Sprintf("%+v", err))
+	}
+	if New("no stack", AttachStack(nil)).Stack() != nil {
+		t.Error("expected no stack for an empty attached stack")
+	}
+}
+
+func TestStackAppendAndTrimBelow(t *testing.T) {
+	inner := NewStackFromFrames([]StackFrame{
+		{FullName: "github.com/app/db.query"},
+		{FullName: "github.com/app/api.(*Server).handle"},
+	})
+	outer := NewStackFromFrames([]StackFrame{
+		{FullName: "net/http.HandlerFunc.ServeHTTP"},
+		{FullName: "net/http.(*conn).serve"},
+	})
+
+	joined := inner.Append(outer)
+	if len(joined) != 4 || joined[2].FullName != "net/http.HandlerFunc.ServeHTTP" {
+		t.Fatalf("unexpected joined stack %v", joined)
+	}
+	joined[0].Line = 100
+	if inner[0].Line == 100 {
+		t.Error("Append must not modify the receiver")
+	}
+	if Stack(nil).Append(nil) != nil {
+		t.Error("expected nil for empty stacks")
+	}
+
+	for _, name := range []string{"github.com/app/api.(*Server).handle", "(*Server).handle", "handle"} {
+		if trimmed := joined.TrimBelow(name); len(trimmed) != 2 || trimmed[1].FullName != "github.com/app/api.(*Server).handle" {
+			t.Errorf("TrimBelow(%q) = %v", name, trimmed)
+		}
+	}
+	if trimmed := joined.TrimBelow("missing"); len(trimmed) != 4 {
+		t.Errorf("expected the stack as is, got %v", trimmed)
+	}
+	if trimmed := joined.TrimBelow("query"); len(trimmed.Append(outer)) != 3 || joined[1].FullName != "github.com/app/api.(*Server).handle" {
+		t.Error("appending to a trimmed stack must not overwrite the original")
+	}
+}
+
+type cStackCapturer struct{}
+
+func (cStackCapturer) Capture(skip int) Stack {
+	frames := NewStackFromFrames([]StackFrame{{FullName: "png_read_image", File: "/usr/src/libpng/pngread.c", Line: 730}})
+	return frames.Append(GoStackCapturer{}.Capture(skip + 1))
+}
+
+func TestStackCapturer(t *testing.T) {
+	Configure(WithStackCapturer(GoStackCapturer{}))
+	defer Configure(WithStackCapturer(nil))
+
+	stack := New("decode failed", StackTrace(ProductionStackTraceConfig())).Stack()
+	if len(stack) == 0 || stack[0].Name != "TestStackCapturer" {
+		t.Fatalf("expected the stack to start at the call site, got %v", stack)
+	}
+	if stack[0].StackTraceConfig == nil || stack[0].StackTraceConfig.ShowFullPaths {
+		t.Error("expected the config of the error on captured frames")
+	}
+
+	Configure(WithStackCapturer(cStackCapturer{}))
+	err := Wrap(fmt.Errorf("bad header"), "decode failed", StackTraceWithSkip(0))
+	stack = err.Stack()
+	if len(stack) < 2 || stack[0].FullName != "png_read_image" || stack[1].Name != "TestStackCapturer" {
+		t.Fatalf("expected the C frame on top of Go frames, got %v", stack)
+	}
+	if !strings.Contains(fmt.Sprintf("%+v", err), "pngread.c:730") {
+		t.Error("expected the C frame in the verbose output")
+	}
+
+	Configure(WithStackCapturer(nil))
+	if stack := New("default", StackTrace()).Stack(); len(stack) == 0 || stack[0].Name != "TestStackCapturer" {
+		t.Errorf("expected the default capturer to be restored, got %v", stack)
+	}
+}
+
+func wrapSkipHelper(err error) Error {
+	return WrapSkip(1, err, "helper %s", "failed", "key", "value", StackTrace())
+}
+
+func newSkipHelper() Error {
+	return NewSkip(1, "helper failed", StackTrace())
+}
+
+func TestWrapSkip(t *testing.T) {
+	err := wrapSkipHelper(errors.New("root"))
+	if err.Error() != "helper failed key=value: root" {
+		t.Errorf("unexpected message: %q", err.Error())
+	}
+	if top := err.Stack()[0].FullName; !strings.HasSuffix(top, ".TestWrapSkip") {
+		t.Errorf("expected stack to start at the caller of the helper, got %s", top)
+	}
+	if top := newSkipHelper().Stack()[0].FullName; !strings.HasSuffix(top, ".TestWrapSkip") {
+		t.Errorf("expected stack to start at the caller of the helper, got %s", top)
+	}
+	if top := NewSkip(0, "direct", StackTrace()).Stack()[0].FullName; !strings.HasSuffix(top, ".TestWrapSkip") {
+		t.Errorf("expected skip 0 to work as New, got %s", top)
+	}
+	if WrapSkip(1, nil, "nil") != nil {
+		t.Error("expected nil for nil error")
+	}
+}
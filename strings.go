@@ -1,8 +1,10 @@
 package erro
 
 import (
+	"bufio"
 	"fmt"
 	"math/rand"
+	"sort"
 	"strconv"
 	"strings"
 	"sync/atomic"
@@ -38,6 +40,12 @@ func FormatErrorWithFields(err Error) string {
 	return buildFieldsMessage(buildMessage(err), err.Fields())
 }
 
+// FormatErrorWithSortedFields formats an [Error] with its message and fields sorted by key.
+// The output is byte-stable for the same error regardless of the order fields were added.
+func FormatErrorWithSortedFields(err Error) string {
+	return buildFieldsMessage(buildMessage(err), sortFieldPairs(err.Fields()))
+}
+
 // FormatErrorMessage formats an error with its message only.
 func FormatErrorMessage(err Error) string {
 	return buildMessage(err)
@@ -50,6 +58,9 @@ func buildMessage(err Error) string {
 	}
 
 	if len(e.message) > 0 {
+		if e.interpolate {
+			return interpolateFields(e.message, e.AllFields())
+		}
 		return e.message
 	}
 
@@ -101,12 +112,39 @@ func buildFieldsMessage(message string, fields []any) (out string) {
 		msg.WriteRune(' ')
 		appendValue(&msg, fields[i], MaxKeyLength)
 		msg.WriteRune('=')
-		appendValue(&msg, fields[i+1], MaxValueLength)
+		appendFieldValue(&msg, fields[i+1], MaxValueLength, false)
 	}
 
 	return msg.String()
 }
 
+// sortFieldPairs returns a copy of key-value pairs stably sorted by key.
+// A trailing key without a value is kept at the end.
+func sortFieldPairs(fields []any) []any {
+	if len(fields) < 4 {
+		return fields
+	}
+	pairs := len(fields) / 2
+	keys := make([]string, pairs)
+	order := make([]int, pairs)
+	for i := 0; i < pairs; i++ {
+		keys[i] = valueToString(fields[i*2])
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return keys[order[i]] < keys[order[j]]
+	})
+
+	sorted := make([]any, 0, len(fields))
+	for _, idx := range order {
+		sorted = append(sorted, fields[idx*2], fields[idx*2+1])
+	}
+	if len(fields)%2 != 0 {
+		sorted = append(sorted, fields[len(fields)-1])
+	}
+	return sorted
+}
+
 func truncateString[T ~string](s T, maxLen int) T {
 	if len(s) <= maxLen {
 		return s
@@ -197,36 +235,64 @@ func ApplyFormatVerbs(format string, args ...any) (string, []any) {
 
 func formatError(err Error, s fmt.State, verb rune) {
 	switch verb {
-	case 'v':
-		if s.Flag('+') {
-			fmt.Fprint(s, err.Error())
-			stack := err.Stack()
-			if len(stack) > 0 {
-				fmt.Fprint(s, "\nStack trace:\n")
-				fmt.Fprint(s, stack.FormatFull())
+	case 'v', 's':
+	default:
+		return
+	}
+	w := bufio.NewWriter(s)
+	defer w.Flush()
+
+	writeErrorChain(&chainWriter{w: w}, err, verb == 'v' && s.Flag('+'))
+	if verb != 'v' || !s.Flag('+') {
+		return
+	}
+	if hint := HintOf(err); hint != "" {
+		w.WriteString("\nHint: ")
+		w.WriteString(hint)
+	}
+	if docURL := DocURLOf(err); docURL != "" {
+		w.WriteString("\nDocs: ")
+		w.WriteString(docURL)
+	}
+	if stack := err.Stack(); len(stack) > 0 {
+		w.WriteString("\nStack trace:\n")
+		for i, frame := range stack {
+			if i > 0 {
+				w.WriteByte('\n')
 			}
-		} else {
-			fmt.Fprint(s, err.Error())
+			w.WriteString(frame.FormatFull())
 		}
-	case 's':
-		fmt.Fprint(s, err.Error())
 	}
 }
 
 const alphabet = "0123456789abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ"
 
-var globalSequence int64
+var globalSequence uint64
 
+// newID returns a unique ID for an error created at the given time: the time in a fixed
+// width of 11 base62 digits followed by a process-wide sequence number of at least
+// 3 digits. The sequence never wraps, so IDs stay unique under a frozen or zero clock
+// set with [SetClock], and the fixed width of the time keeps the two parts unambiguous.
 func newID(seed int64) string {
-	seq := atomic.AddInt64(&globalSequence, 1) & 0xFFF
-	combined := (seed << 12) | seq
-	return encodeCompact(combined)
+	if id, ok := seededID(); ok {
+		return id
+	}
+	seq := atomic.AddUint64(&globalSequence, 1)
+	return padCompact(uint64(seed), maxCompactLen) + padCompact(seq, 3)
 }
 
-func encodeCompact(n int64) string {
-	var buf [11]byte // Enough for 64-bit in base62
-	i := 10
-	for n > 0 {
+// maxCompactLen is the length of the largest 64-bit number in base62.
+const maxCompactLen = 11
+
+func encodeCompact(n uint64) string {
+	return padCompact(n, 1)
+}
+
+// padCompact encodes the number in base62 with at least width digits.
+func padCompact(n uint64, width int) string {
+	var buf [maxCompactLen]byte
+	i := maxCompactLen - 1
+	for n > 0 || maxCompactLen-1-i < width {
 		buf[i] = alphabet[n%62]
 		n /= 62
 		i--
@@ -260,16 +326,85 @@ func mergeFields(fields []any, opts []any) []any {
 	return newFields
 }
 
+// interpolateFields replaces {key} placeholders in the message with values of matching fields.
+func interpolateFields(message string, fields []any) string {
+	if len(fields) < 2 || strings.IndexByte(message, '{') == -1 {
+		return message
+	}
+
+	var result strings.Builder
+	result.Grow(len(message) + len(fields)*8)
+	for i := 0; i < len(message); i++ {
+		if message[i] != '{' {
+			result.WriteByte(message[i])
+			continue
+		}
+		end := strings.IndexByte(message[i+1:], '}')
+		if end == -1 {
+			result.WriteString(message[i:])
+			break
+		}
+		key := message[i+1 : i+1+end]
+		value, ok := lookupField(fields, key)
+		if !ok {
+			result.WriteByte(message[i])
+			continue
+		}
+		appendValue(&result, value, MaxValueLength)
+		i += end + 1
+	}
+	return result.String()
+}
+
+// lookupField returns the value of the first field pair with the given key.
+func lookupField(fields []any, key string) (any, bool) {
+	for i := 0; i+1 < len(fields); i += 2 {
+		if k, ok := fields[i].(string); ok && k == key {
+			return fields[i+1], true
+		}
+	}
+	return nil, false
+}
+
+// countVerbs returns the number of arguments consumed by the format verbs of s,
+// taking explicit argument indexes like %[2]s into account.
 func countVerbs(s string) int {
-	count := 0
+	count, argNum := 0, 0
 	for i := 0; i < len(s); i++ {
+		if s[i] != '%' {
+			continue
+		}
+		i++
+		if i >= len(s) {
+			break
+		}
 		if s[i] == '%' {
-			if i+1 < len(s) && s[i+1] != '%' {
-				count++
-			} else {
-				i++
+			continue
+		}
+		for i < len(s) && strings.IndexByte("+-# 0", s[i]) >= 0 {
+			i++
+		}
+		if i < len(s) && s[i] == '[' {
+			if end := strings.IndexByte(s[i:], ']'); end > 0 {
+				if index, err := strconv.Atoi(s[i+1 : i+end]); err == nil && index > 0 {
+					argNum = index - 1
+				}
+				i += end + 1
 			}
 		}
+		for i < len(s) && (s[i] >= '0' && s[i] <= '9' || s[i] == '.' || s[i] == '*') {
+			if s[i] == '*' {
+				argNum++
+			}
+			i++
+		}
+		if i >= len(s) {
+			break
+		}
+		argNum++
+		if argNum > count {
+			count = argNum
+		}
 	}
 	return count
 }
@@ -285,7 +420,9 @@ func appendValue(b *strings.Builder, value any, maxLen int) {
 	case string:
 		b.WriteString(truncateString(v, maxLen))
 	case RedactedValue:
-		b.WriteString(RedactedPlaceholder)
+		b.WriteString(redacted())
+	case SensitiveValue:
+		appendValue(b, resolveSensitive(v, 0), maxLen)
 	case []byte:
 		if len(v) > maxLen {
 			v = v[:maxLen]
@@ -343,7 +480,9 @@ func valueToString(value any) string {
 	case string:
 		str = v
 	case RedactedValue:
-		return RedactedPlaceholder
+		return redacted()
+	case SensitiveValue:
+		return valueToString(resolveSensitive(v, 0))
 	case []byte:
 		str = string(v)
 	case time.Time:
@@ -386,3 +525,45 @@ func valueToString(value any) string {
 
 	return str
 }
+
+// appendFieldValue appends a field value, quoting it as in logfmt if it contains spaces,
+// quotes, '=', ':' or control characters. In strict mode empty values are quoted too.
+func appendFieldValue(b *strings.Builder, value any, maxLen int, strict bool) {
+	var s string
+	switch v := value.(type) {
+	case nil:
+		if strict {
+			b.WriteString(`""`)
+		}
+		return
+	case string:
+		s = truncateString(v, maxLen)
+	case []byte:
+		s = truncateString(string(v), maxLen)
+	case time.Time:
+		s = v.Format(time.RFC3339)
+	case SensitiveValue:
+		appendFieldValue(b, resolveSensitive(v, 0), maxLen, strict)
+		return
+	case RedactedValue, int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64, bool:
+		appendValue(b, value, maxLen)
+		return
+	default:
+		s = truncateString(valueToString(v), maxLen)
+	}
+	if needsLogfmtQuoting(s) || (strict && s == "") {
+		b.WriteString(strconv.Quote(s))
+		return
+	}
+	b.WriteString(s)
+}
+
+func needsLogfmtQuoting(s string) bool {
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c <= ' ' || c == '=' || c == '"' || c == ':' || c == 0x7f {
+			return true
+		}
+	}
+	return false
+}
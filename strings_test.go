@@ -340,11 +340,17 @@ func (e *errorWrapper) Error() string                                  { return
 func (e *errorWrapper) Message() string                                { return e.err.Error() }
 func (e *errorWrapper) Fields() []any                                  { return nil }
 func (e *errorWrapper) AllFields() []any                               { return nil }
+func (e *errorWrapper) LayerCount() int                                { return 1 }
+func (e *errorWrapper) FieldsAt(int) []any                             { return nil }
 func (e *errorWrapper) ID() string                                     { return "" }
 func (e *errorWrapper) Class() ErrorClass                              { return "" }
 func (e *errorWrapper) Category() ErrorCategory                        { return "" }
 func (e *errorWrapper) Severity() ErrorSeverity                        { return "" }
 func (e *errorWrapper) IsRetryable() bool                              { return false }
+func (e *errorWrapper) Redacted() Error                                { return e }
+func (e *errorWrapper) Attempt() int                                   { return 0 }
+func (e *errorWrapper) MaxAttempts() int                               { return 0 }
+func (e *errorWrapper) RetryAfter() (time.Duration, bool)              { return 0, false }
 func (e *errorWrapper) Stack() Stack                                   { return nil }
 func (e *errorWrapper) Created() time.Time                             { return time.Time{} }
 func (e *errorWrapper) Span() TraceSpan                                { return nil }
@@ -357,6 +363,13 @@ func (e *errorWrapper) Unwrap() error                                  { return
 func (e *errorWrapper) Format(s fmt.State, verb rune)                  { formatError(e, s, verb) }
 func (e *errorWrapper) MarshalJSON() ([]byte, error)                   { return nil, nil }
 func (e *errorWrapper) UnmarshalJSON(data []byte) error                { return nil }
+func (e *errorWrapper) Wrap(message string, fields ...any) Error {
+	return Wrap(e, message, fields...)
+}
+func (e *errorWrapper) DebugBundle() ([]byte, error) { return nil, nil }
+func (e *errorWrapper) TTL() (time.Duration, bool)   { return 0, false }
+func (e *errorWrapper) Expired() bool                { return false }
+func (e *errorWrapper) Tenant() string               { return "" }
 
 func TestBuildMessage_EdgeCases(t *testing.T) {
 	// Test case: error with empty message but category and class
@@ -720,6 +733,26 @@ func TestNewID_Uniqueness(t *testing.T) {
 	}
 }
 
+func TestNewID_ZeroClock(t *testing.T) {
+	SetClock(func() time.Time { return time.Unix(0, 0) })
+	defer SetClock(nil)
+
+	seen := make(map[string]bool)
+	for i := 0; i < 10000; i++ {
+		id := New("frozen").ID()
+		if id == "" {
+			t.Fatalf("expected non-empty ID at %d", i)
+		}
+		if seen[id] {
+			t.Fatalf("duplicate ID %q at %d", id, i)
+		}
+		seen[id] = true
+	}
+	if Is(New("first"), New("second")) {
+		t.Error("expected unrelated errors not to match under a frozen clock")
+	}
+}
+
 func TestMergeFields(t *testing.T) {
 	fields := []any{"key1", "value1", "key2", "value2"}
 	opts := []any{"opt1", "optval1", "opt2", "optval2"}
@@ -763,6 +796,11 @@ func TestCountVerbs(t *testing.T) {
 	if countVerbs("test %") != 0 {
 		t.Error("expected 0 verbs for percent at end")
 	}
+
+	// Test case: explicit argument indexes
+	if countVerbs("%[2]s %[1]d") != 2 || countVerbs("%[1]s %[1]s") != 1 || countVerbs("%[2]s %s") != 3 {
+		t.Error("expected indexed verbs to count arguments")
+	}
 }
 
 func TestFormatError_EdgeCases(t *testing.T) {
@@ -807,3 +845,56 @@ func (t *testState) Width() (int, bool) {
 func (t *testState) Precision() (int, bool) {
 	return 0, false
 }
+
+func TestFormatErrorWithSortedFields(t *testing.T) {
+	err1 := New("test", "z", 1, "a", "x", "m", true, Formatter(FormatErrorWithSortedFields))
+	err2 := New("test", "m", true, "z", 1, "a", "x", Formatter(FormatErrorWithSortedFields))
+	if err1.Error() != "test a=x m=true z=1" {
+		t.Errorf("unexpected sorted message: %s", err1.Error())
+	}
+	if err1.Error() != err2.Error() {
+		t.Errorf("expected byte-stable output, got %q and %q", err1.Error(), err2.Error())
+	}
+
+	// Odd number of fields keeps the dangling key at the end
+	sorted := sortFieldPairs([]any{"b", 1, "a", 2, "c"})
+	if len(sorted) != 5 || sorted[0] != "a" || sorted[4] != "c" {
+		t.Errorf("unexpected sorted pairs: %v", sorted)
+	}
+}
+
+func TestInterpolateFields(t *testing.T) {
+	tests := []struct {
+		message  string
+		fields   []any
+		expected string
+	}{
+		{"user {id} not found", []any{"id", 42}, "user 42 not found"},
+		{"{a}{b}", []any{"a", "x", "b", "y"}, "xy"},
+		{"no placeholders", []any{"id", 1}, "no placeholders"},
+		{"unknown {key}", []any{"id", 1}, "unknown {key}"},
+		{"unterminated {id", []any{"id", 1}, "unterminated {id"},
+		{"{{id}}", []any{"id", 1}, "{1}"},
+		{"secret {pwd}", []any{"pwd", Redact("p")}, "secret " + RedactedPlaceholder},
+	}
+	for _, tt := range tests {
+		if got := interpolateFields(tt.message, tt.fields); got != tt.expected {
+			t.Errorf("interpolateFields(%q) = %q, expected %q", tt.message, got, tt.expected)
+		}
+	}
+}
+
+func TestNewID(t *testing.T) {
+	seed := time.Now().UnixNano()
+	seen := make(map[string]bool)
+	for i := 0; i < 100; i++ {
+		id := newID(seed)
+		if id == "" {
+			t.Fatal("expected non-empty ID")
+		}
+		if seen[id] {
+			t.Fatalf("duplicate ID %q for the same seed", id)
+		}
+		seen[id] = true
+	}
+}
@@ -1,47 +1,357 @@
 package erro
 
-import "fmt"
+import (
+	"fmt"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+)
 
 // ErrorTemplate represents a template for creating errors with predefined metadata.
 type ErrorTemplate struct {
 	messageTemplate string
 	opts            []any
+	args            []string
+	site            StackFrame // Call site of NewTemplate
 }
 
+var (
+	templatesMu   sync.Mutex
+	templates     []*ErrorTemplate
+	templateSites = make(map[string]int) // Index in templates by the declaration site and message
+)
+
 // NewTemplate creates a new error template with a message and predefined options.
+// The template is registered for [ValidateTemplates] by its declaration site and message:
+// a template with the same message created again at the same site, e.g. in a function,
+// replaces the previous one, while templates with different messages created by a shared
+// helper are all kept.
 func NewTemplate(messageTemplate string, opts ...any) *ErrorTemplate {
-	return &ErrorTemplate{
+	t := &ErrorTemplate{
 		messageTemplate: messageTemplate,
 		opts:            opts,
+		site:            definitionSite(),
+	}
+	registerTemplate(t)
+	return t
+}
+
+// registerTemplate adds the template to the registry of [ValidateTemplates].
+func registerTemplate(t *ErrorTemplate) {
+	key := t.site.File + ":" + strconv.Itoa(t.site.Line) + ":" + t.messageTemplate
+	templatesMu.Lock()
+	defer templatesMu.Unlock()
+	if i, ok := templateSites[key]; ok {
+		templates[i] = t
+		return
+	}
+	templateSites[key] = len(templates)
+	templates = append(templates, t)
+}
+
+// Site returns the call site of [NewTemplate] that declared the template.
+func (t *ErrorTemplate) Site() StackFrame {
+	return t.site
+}
+
+// TemplateSite returns the declaration site of the template of the closest error in the
+// chain created from a template, so a log line with a parameterized message can be traced
+// to the declared error. Add it to log fields with [WithTemplateSite].
+//
+// Example:
+//
+//	if site, ok := erro.TemplateSite(err); ok {
+//	    log.Printf("%s (declared at %s:%d)", err, site.File, site.Line)
+//	}
+func TemplateSite(err error) (StackFrame, bool) {
+	var e *baseError
+	if !As(err, &e) {
+		return StackFrame{}, false
+	}
+	for w := e; w != nil; w = w.wrappedErr {
+		if w.template != nil && w.template.site.Line > 0 {
+			return w.template.site, true
+		}
 	}
+	return StackFrame{}, false
+}
+
+// definitionSite returns the caller of the function that called it.
+// Only the first frame is captured, it is cheap enough for every template.
+func definitionSite() StackFrame {
+	pc, file, line, ok := runtime.Caller(2)
+	if !ok {
+		return StackFrame{}
+	}
+	var function string
+	if fn := runtime.FuncForPC(pc); fn != nil {
+		function = fn.Name()
+	}
+	return StackFrame{
+		Name:     extractShortName(function),
+		FullName: function,
+		Package:  extractPackageFromFunction(function),
+		File:     file,
+		FileName: filepath.Base(file),
+		Line:     line,
+	}
+}
+
+// WithArgs declares the names of the template arguments, in the order of the format
+// verbs for [ErrorTemplate.New] or as {name} placeholders for [ErrorTemplate.NewKV].
+// [ErrorTemplate.Validate] checks the message against them.
+//
+// Example:
+//
+//	var ErrStock = erro.NewTemplate("%d items of %s left").WithArgs("count", "product")
+func (t *ErrorTemplate) WithArgs(names ...string) *ErrorTemplate {
+	t.args = names
+	return t
 }
 
 // New creates an error from the template.
 func (t *ErrorTemplate) New(fields ...any) Error {
 	numVerbs := countVerbs(t.messageTemplate)
 	if len(fields) < numVerbs {
-		return newBaseError(t.messageTemplate, mergeFields(fields, t.opts)...)
+		return newBaseError(t.messageTemplate, t.meta(fields)...)
 	}
 
 	formatArgs := fields[:numVerbs]
 	metaFields := fields[numVerbs:]
 
 	message := fmt.Sprintf(t.messageTemplate, formatArgs...)
-	return newf(message, mergeFields(metaFields, t.opts)...)
+	return newf(message, t.meta(metaFields)...)
 }
 
 // Wrap wraps an existing error with the template's message and options.
 func (t *ErrorTemplate) Wrap(originalErr error, fields ...any) Error {
 	numVerbs := countVerbs(t.messageTemplate)
 	if len(fields) < numVerbs {
-		return newWrapError(originalErr, t.messageTemplate, mergeFields(fields, t.opts)...)
+		return newWrapError(originalErr, t.messageTemplate, t.meta(fields)...)
 	}
 
 	formatArgs := fields[:numVerbs]
 	metaFields := fields[numVerbs:]
 
 	message := fmt.Sprintf(t.messageTemplate, formatArgs...)
-	return wrapf(originalErr, message, mergeFields(metaFields, t.opts)...)
+	return wrapf(originalErr, message, t.meta(metaFields)...)
+}
+
+// NewKV creates an error from the template using named parameters.
+//
+// Fields are key-value pairs that become structured fields of the error and
+// also replace {key} placeholders in the template message. Placeholders without
+// a matching field are left as is.
+//
+// Example:
+//
+//	tmpl := erro.NewTemplate("product {product_id} is out of stock", erro.ClassConflict)
+//	err := tmpl.NewKV("product_id", 999) // "product 999 is out of stock product_id=999"
+func (t *ErrorTemplate) NewKV(fields ...any) Error {
+	message := interpolateFields(t.messageTemplate, collectFields(fields))
+	return newBaseError(message, t.meta(fields)...)
+}
+
+// WrapKV wraps an existing error with the template's message using named parameters.
+// See [ErrorTemplate.NewKV] for placeholder rules.
+func (t *ErrorTemplate) WrapKV(originalErr error, fields ...any) Error {
+	message := interpolateFields(t.messageTemplate, collectFields(fields))
+	return newWrapError(originalErr, message, t.meta(fields)...)
+}
+
+// meta merges the options of the template with the fields and marks the error as
+// created from the template.
+func (t *ErrorTemplate) meta(fields []any) []any {
+	meta := make([]any, 0, len(t.opts)+len(fields)+1)
+	meta = append(meta, t.opts...)
+	meta = append(meta, fields...)
+	return append(meta, fromTemplate(t))
+}
+
+func fromTemplate(t *ErrorTemplate) errorOpt {
+	return func(e *baseError) {
+		e.template = t
+	}
+}
+
+// Hint returns the hint set on the template with [Hint], for listing templates as an error catalog.
+func (t *ErrorTemplate) Hint() string {
+	return t.probe().ext().hint
+}
+
+// DocURL returns the documentation link set on the template with [DocURL].
+func (t *ErrorTemplate) DocURL() string {
+	return t.probe().ext().docURL
+}
+
+// probe applies the options of the template that set metadata to an empty error.
+func (t *ErrorTemplate) probe() *baseError {
+	e := &baseError{}
+	for _, opt := range expandProfiles(t.opts) {
+		if opt, ok := opt.(errorOpt); ok {
+			opt(e)
+		}
+	}
+	return e
+}
+
+// Validate checks the message of the template: every format verb is complete and known,
+// explicit argument indexes like %[2]s leave no argument unused, and the declared
+// arguments from [ErrorTemplate.WithArgs] match the arguments of the verbs or the {name}
+// placeholders. It returns nil
+// for a valid template, an error wrapping [ErrInvalidTemplate] for a single problem,
+// or a multi-error of them.
+//
+// Example:
+//
+//	err := erro.NewTemplate("%d items %s").WithArgs("count").Validate()
+//	// format verbs do not match declared args template="%d items %s" verbs=2 args=1: invalid error template
+func (t *ErrorTemplate) Validate() error {
+	list := NewList()
+	issue := func(problem string, fields ...any) {
+		list.Add(Wrap(ErrInvalidTemplate, problem, append([]any{"template", t.messageTemplate}, fields...)...))
+	}
+
+	// verbs is the number of arguments consumed by the verbs, see [countVerbs]
+	verbs, verbCount, argNum := 0, 0, 0
+	used := make(map[int]bool)
+	msg := t.messageTemplate
+	for i := 0; i < len(msg); i++ {
+		if msg[i] != '%' {
+			continue
+		}
+		start := i
+		i++
+		if i < len(msg) && msg[i] == '%' {
+			continue
+		}
+		for i < len(msg) && strings.IndexByte("+-# 0", msg[i]) >= 0 {
+			i++
+		}
+		if i < len(msg) && msg[i] == '[' {
+			end := strings.IndexByte(msg[i:], ']')
+			if end == -1 {
+				issue("bad argument index", "position", start)
+				break
+			}
+			index, err := strconv.Atoi(msg[i+1 : i+end])
+			if err != nil || index < 1 {
+				issue("bad argument index", "position", start)
+			} else {
+				argNum = index - 1
+			}
+			i += end + 1
+		}
+		for i < len(msg) && (msg[i] >= '0' && msg[i] <= '9' || msg[i] == '.' || msg[i] == '*') {
+			if msg[i] == '*' {
+				issue("star width or precision is not supported", "position", start)
+			}
+			i++
+		}
+		if i >= len(msg) {
+			issue("missing verb", "position", start)
+			break
+		}
+		if strings.IndexByte(knownVerbs, msg[i]) == -1 {
+			issue("unknown verb", "position", start, "verb", msg[start:i+1])
+		}
+		used[argNum] = true
+		argNum++
+		verbCount++
+		if argNum > verbs {
+			verbs = argNum
+		}
+	}
+	if len(used) < verbs {
+		issue("argument index out of range", "index", verbs, "verbs", verbCount)
+	}
+
+	if t.args == nil {
+		return list.Err()
+	}
+	seen := make(map[string]bool, len(t.args))
+	for _, name := range t.args {
+		if name == "" || seen[name] {
+			issue("empty or duplicate arg name", "arg", name)
+		}
+		seen[name] = true
+	}
+	placeholders := templatePlaceholders(msg)
+	switch {
+	case verbs > 0 && len(placeholders) > 0:
+		issue("template mixes format verbs and placeholders", "verbs", verbCount, "placeholders", len(placeholders))
+	case verbs > 0 || len(placeholders) == 0:
+		if verbs != len(t.args) {
+			issue("format verbs do not match declared args", "verbs", verbs, "args", len(t.args))
+		}
+	default:
+		for _, name := range placeholders {
+			if !seen[name] {
+				issue("undeclared placeholder", "placeholder", name)
+			}
+			delete(seen, name)
+		}
+		for _, name := range t.args {
+			if seen[name] {
+				issue("declared arg is not used", "arg", name)
+			}
+		}
+	}
+	return list.Err()
+}
+
+// knownVerbs are the verbs of the fmt package.
+const knownVerbs = "vTtbcdoOqxXUeEfFgGsp"
+
+// templatePlaceholders returns the {name} placeholders of the message.
+func templatePlaceholders(message string) []string {
+	var names []string
+	for i := 0; i < len(message); i++ {
+		if message[i] != '{' {
+			continue
+		}
+		end := strings.IndexByte(message[i+1:], '}')
+		if end == -1 {
+			break
+		}
+		names = append(names, message[i+1:i+1+end])
+		i += end + 1
+	}
+	return names
+}
+
+// ValidateTemplates validates all templates created with [NewTemplate], including
+// the predefined ones, see [ErrorTemplate.Validate]. It returns nil if all of them
+// are valid, or the problems of all invalid templates. See errtest.ValidateTemplates
+// for a test helper.
+//
+// Example:
+//
+//	func TestTemplates(t *testing.T) {
+//	    if err := erro.ValidateTemplates(); err != nil {
+//	        t.Fatal(err)
+//	    }
+//	}
+func ValidateTemplates() error {
+	templatesMu.Lock()
+	all := append([]*ErrorTemplate(nil), templates...)
+	templatesMu.Unlock()
+
+	list := NewList()
+	for _, t := range all {
+		if err := t.Validate(); err != nil {
+			if multi, ok := err.(*multiError); ok {
+				for _, e := range multi.errors {
+					list.Add(e)
+				}
+				continue
+			}
+			list.Add(err)
+		}
+	}
+	return list.Err()
 }
 
 // Predefined error templates.
@@ -0,0 +1,12 @@
+//go:build errocheck
+
+package erro
+
+// With the errocheck build tag, the templates registered when the package is initialized,
+// including the predefined ones, are validated and an invalid one panics. Validate the
+// templates of an application in a test with [ValidateTemplates].
+func init() {
+	if err := ValidateTemplates(); err != nil {
+		panic(err)
+	}
+}
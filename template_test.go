@@ -2,6 +2,7 @@ package erro_test
 
 import (
 	"errors"
+	"strconv"
 	"strings"
 	"testing"
 
@@ -177,3 +178,157 @@ func TestPredefinedTemplates(t *testing.T) {
 		})
 	}
 }
+
+func TestTemplateNewKV(t *testing.T) {
+	tmpl := erro.NewTemplate("product {product_id} is out of stock in {warehouse}", erro.ClassConflict)
+
+	err := tmpl.NewKV("product_id", 999, "warehouse", "eu-1", "extra", true)
+	if err.Message() != "product 999 is out of stock in eu-1" {
+		t.Errorf("unexpected message: %s", err.Message())
+	}
+	if err.Class() != erro.ClassConflict {
+		t.Errorf("expected template class, got %s", err.Class())
+	}
+	fields := err.LogFieldsMap()
+	if fields["product_id"] != 999 || fields["warehouse"] != "eu-1" || fields["extra"] != true {
+		t.Errorf("expected parameters to be fields, got %v", fields)
+	}
+
+	// Missing parameters keep placeholders, options are not treated as fields
+	err = tmpl.NewKV("product_id", 1, erro.SeverityHigh, erro.Fields("other", "x"))
+	if err.Message() != "product 1 is out of stock in {warehouse}" {
+		t.Errorf("unexpected message: %s", err.Message())
+	}
+	if err.Severity() != erro.SeverityHigh {
+		t.Errorf("expected severity option to be applied, got %s", err.Severity())
+	}
+}
+
+func TestTemplateWrapKV(t *testing.T) {
+	tmpl := erro.NewTemplate("fetch user {user_id}", erro.CategoryDatabase)
+	base := errors.New("connection reset")
+
+	err := tmpl.WrapKV(base, "user_id", "u-42")
+	if err.Error() != "fetch user u-42 user_id=u-42: connection reset" {
+		t.Errorf("unexpected error string: %s", err.Error())
+	}
+	if !errors.Is(err, base) {
+		t.Error("expected wrapped error to match original")
+	}
+}
+
+func TestTemplateValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		tmpl    *erro.ErrorTemplate
+		problem string
+	}{
+		{"valid", erro.NewTemplate("%d items of %s left"), ""},
+		{"valid args", erro.NewTemplate("%d items of %-10s left, %%").WithArgs("count", "product"), ""},
+		{"valid index", erro.NewTemplate("%[2]s %[1]d").WithArgs("count", "product"), ""},
+		{"valid repeated index", erro.NewTemplate("%[1]s is %[1]q").WithArgs("name"), ""},
+		{"valid placeholders", erro.NewTemplate("product {product_id} is out of stock").WithArgs("product_id"), ""},
+		{"missing verb", erro.NewTemplate("100%"), "missing verb"},
+		{"unknown verb", erro.NewTemplate("%y items"), "unknown verb"},
+		{"star", erro.NewTemplate("%*d items"), "star width"},
+		{"bad index", erro.NewTemplate("%[0]d items"), "bad argument index"},
+		{"index out of range", erro.NewTemplate("%[3]d items %s"), "argument index out of range"},
+		{"unused index", erro.NewTemplate("%[2]s %[2]s"), "argument index out of range"},
+		{"indexed args", erro.NewTemplate("%[2]s %s").WithArgs("a", "b"), "format verbs do not match declared args"},
+		{"too few args", erro.NewTemplate("%d items %s").WithArgs("count"), "format verbs do not match declared args"},
+		{"too many args", erro.NewTemplate("%d items").WithArgs("count", "product"), "format verbs do not match declared args"},
+		{"duplicate arg", erro.NewTemplate("%d %d").WithArgs("count", "count"), "duplicate arg name"},
+		{"mixed", erro.NewTemplate("%d items of {product}").WithArgs("count", "product"), "mixes format verbs and placeholders"},
+		{"undeclared", erro.NewTemplate("{product} by {user}").WithArgs("product"), "undeclared placeholder"},
+		{"unused", erro.NewTemplate("{product} missing").WithArgs("product", "user"), "declared arg is not used"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.tmpl.Validate()
+			if tt.problem == "" {
+				if err != nil {
+					t.Fatalf("expected valid template, got %v", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatal("expected an error")
+			}
+			if !errors.Is(err, erro.ErrInvalidTemplate) {
+				t.Errorf("expected ErrInvalidTemplate, got %v", err)
+			}
+			if !strings.Contains(err.Error(), tt.problem) {
+				t.Errorf("expected %q in %q", tt.problem, err.Error())
+			}
+		})
+	}
+}
+
+func TestTemplateValidateFields(t *testing.T) {
+	err := erro.NewTemplate("%d items %s").WithArgs("count").Validate()
+	fields := erro.ExtractError(err).AllFields()
+	want := map[string]any{"template": "%d items %s", "verbs": 2, "args": 1}
+	for i := 0; i+1 < len(fields); i += 2 {
+		if v, ok := want[fields[i].(string)]; ok && v == fields[i+1] {
+			delete(want, fields[i].(string))
+		}
+	}
+	if len(want) > 0 {
+		t.Errorf("missing fields %v in %v", want, fields)
+	}
+}
+
+func TestValidateTemplates(t *testing.T) {
+	erro.NewTemplate("broken %")
+	err := erro.ValidateTemplates()
+	if err == nil || !strings.Contains(err.Error(), "broken %") {
+		t.Fatalf("expected the invalid template in %v", err)
+	}
+	for i := 0; i < 3; i++ {
+		erro.NewTemplate("runtime %")
+	}
+	if err := erro.ValidateTemplates(); strings.Count(err.Error(), "runtime %") != 1 {
+		t.Errorf("expected templates of the same site to be registered once, got %v", err)
+	}
+
+	mk := func(message string) *erro.ErrorTemplate { return erro.NewTemplate(message) }
+	mk("helper %")
+	mk("helper valid %s")
+	if err := erro.ValidateTemplates(); err == nil || !strings.Contains(err.Error(), "helper %") {
+		t.Errorf("expected the invalid template of a shared helper in %v", err)
+	}
+	for _, tmpl := range []*erro.ErrorTemplate{erro.ValidationError, erro.NotFoundError, erro.DatabaseError} {
+		if err := tmpl.Validate(); err != nil {
+			t.Errorf("predefined template is invalid: %v", err)
+		}
+	}
+}
+
+var errDeclaredStock = erro.NewTemplate("%d items of %s left", erro.ClassConflict)
+
+func TestTemplateSite(t *testing.T) {
+	site := errDeclaredStock.Site()
+	if site.FileName != "template_test.go" || site.Line == 0 || site.Package != "erro_test" {
+		t.Fatalf("unexpected definition site %+v", site)
+	}
+
+	err := erro.Wrap(errDeclaredStock.New(3, "apples"), "reserve", erro.StackTrace())
+	got, ok := erro.TemplateSite(err)
+	if !ok || got != site {
+		t.Errorf("expected the site of the template, got %+v", got)
+	}
+	if _, ok := erro.TemplateSite(erro.New("plain")); ok {
+		t.Error("errors not created from a template have no site")
+	}
+	if _, ok := erro.TemplateSite(errors.New("std")); ok {
+		t.Error("standard errors have no site")
+	}
+
+	fields := erro.LogFieldsMap(err, erro.WithTemplateSite())
+	if expected := "erro_test/template_test.go:" + strconv.Itoa(site.Line); fields["template_site"] != expected {
+		t.Errorf("expected %q, got %v", expected, fields)
+	}
+	if _, ok := erro.LogFieldsMap(err)["error_template_site"]; ok {
+		t.Error("the template site must be logged only with WithTemplateSite")
+	}
+}
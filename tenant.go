@@ -0,0 +1,108 @@
+package erro
+
+import (
+	"log"
+	"runtime"
+)
+
+// TenantField is the reserved field key with the tenant of an error, see [Tenant].
+const TenantField = "tenant"
+
+// TenantPolicy requires errors created in some packages to carry a tenant, see [WithTenantPolicy].
+type TenantPolicy struct {
+	// Packages are the patterns of packages whose errors must have a tenant, matched
+	// against the origin frame of an error with the syntax of [WithPackageCategory].
+	Packages []string
+	// Panic makes a violation panic with an error wrapping [ErrMissingTenant].
+	// By default violations are printed with the standard log package.
+	Panic bool
+}
+
+// Tenant adds the tenant of the error as the [TenantField] field. An empty id adds nothing.
+//
+// Example:
+//
+//	err := erro.New("quota exceeded", erro.Tenant(tenantID), erro.ClassRateLimited)
+func Tenant(id string) errorFields {
+	return func() []any {
+		if id == "" {
+			return nil
+		}
+		return []any{TenantField, id}
+	}
+}
+
+// TenantOf returns the tenant of the error chain added with [Tenant], the outermost
+// one wins.
+func TenantOf(err error) string {
+	var e interface{ Tenant() string }
+	if !As(err, &e) {
+		return ""
+	}
+	return e.Tenant()
+}
+
+// ownField returns the string value of the first field with the key.
+func ownField(fields []any, key string) string {
+	for i := 0; i+1 < len(fields); i += 2 {
+		if valueToString(fields[i]) == key {
+			return valueToString(fields[i+1])
+		}
+	}
+	return ""
+}
+
+// checkTenant reports an error without a tenant created in a package of the [TenantPolicy].
+// It must be called directly from applyMeta to keep the caller frame depth.
+func checkTenant(e *baseError) {
+	policy := tenantPolicy.Load()
+	if policy == nil || len(policy.Packages) == 0 || e.Tenant() != "" {
+		return
+	}
+	function := callerFunction(e)
+	pkg := packagePath(function)
+	if pkg == "" {
+		return
+	}
+	for _, pattern := range policy.Packages {
+		if matchPackagePattern(pattern, pkg) {
+			reportMissingTenant(e, function, policy.Panic)
+			return
+		}
+	}
+}
+
+// reportMissingTenant is set in init, as [ErrMissingTenant] itself is created with New.
+var reportMissingTenant func(e *baseError, function string, panics bool)
+
+func init() {
+	reportMissingTenant = func(e *baseError, function string, panics bool) {
+		if e.wrappedErr == ErrMissingTenant {
+			return
+		}
+		if panics {
+			panic(Wrap(ErrMissingTenant, buildMessage(e), "function", function))
+		}
+		log.Printf("erro: error %q created in %s has no tenant", buildMessage(e), function)
+	}
+}
+
+// callerFunction returns the origin function of the error from its own stack or,
+// without one, from the callers of applyMeta.
+func callerFunction(e *baseError) string {
+	if e.stack != nil {
+		return originFunction(e)
+	}
+	var pcs [4]uintptr
+	n := runtime.Callers(defaultSkipFrames+1+e.ext().callerSkip, pcs[:])
+	frames := runtime.CallersFrames(pcs[:n])
+	for {
+		frame, more := frames.Next()
+		if frame.Function != "" && !isUselessRuntimeFrame(frame.Function, frame.File) {
+			return frame.Function
+		}
+		if !more {
+			return ""
+		}
+	}
+}
@@ -0,0 +1,71 @@
+package erro
+
+import (
+	"bytes"
+	"log"
+	"strings"
+	"testing"
+)
+
+func TestTenant(t *testing.T) {
+	err := New("quota exceeded", Tenant("acme"))
+	if TenantOf(err) != "acme" {
+		t.Errorf("expected tenant acme, got %q", TenantOf(err))
+	}
+	wrapped := Wrap(err, "charge")
+	if TenantOf(wrapped) != "acme" || wrapped.Tenant() != "acme" {
+		t.Errorf("expected the tenant of the wrapped error, got %q", wrapped.Tenant())
+	}
+	if outer := Wrap(err, "charge", Tenant("globex")); TenantOf(outer) != "globex" {
+		t.Errorf("expected the outermost tenant, got %q", TenantOf(outer))
+	}
+	if TenantOf(New("no tenant", Tenant(""))) != "" || len(New("no tenant", Tenant("")).Fields()) != 0 {
+		t.Error("an empty tenant must add nothing")
+	}
+	if !strings.Contains(err.Error(), "tenant=acme") {
+		t.Errorf("expected the tenant field in the message, got %q", err.Error())
+	}
+}
+
+func TestTenantPolicy(t *testing.T) {
+	defer Configure(WithTenantPolicy(nil))
+
+	var buf bytes.Buffer
+	defer log.SetOutput(log.Writer())
+	log.SetOutput(&buf)
+
+	Configure(WithTenantPolicy(&TenantPolicy{Packages: []string{"github.com/maxbolgarin/erro"}}))
+	_ = New("no tenant")
+	if !strings.Contains(buf.String(), `erro: error "no tenant" created in github.com/maxbolgarin/erro.TestTenantPolicy has no tenant`) {
+		t.Errorf("unexpected log output %q", buf.String())
+	}
+
+	buf.Reset()
+	_ = New("tenant", Tenant("acme"))
+	_ = Wrap(New("tenant", Tenant("acme")), "wrapped")
+	_ = New("stack", Tenant("acme"), StackTrace())
+	if buf.Len() != 0 {
+		t.Errorf("errors with a tenant must pass, got %q", buf.String())
+	}
+
+	Configure(WithTenantPolicy(&TenantPolicy{Packages: []string{".../other/..."}}))
+	_ = New("other package")
+	if buf.Len() != 0 {
+		t.Errorf("errors of other packages must pass, got %q", buf.String())
+	}
+
+	Configure(WithTenantPolicy(&TenantPolicy{Packages: []string{"github.com/maxbolgarin/erro"}, Panic: true}))
+	func() {
+		defer func() {
+			r := recover()
+			panicErr, ok := r.(error)
+			if !ok || !Is(panicErr, ErrMissingTenant) {
+				t.Fatalf("expected a panic with ErrMissingTenant, got %v", r)
+			}
+			if fn, _ := Field[string](panicErr, "function"); !strings.HasSuffix(fn, "TestTenantPolicy.func1") {
+				t.Errorf("unexpected function %q", fn)
+			}
+		}()
+		_ = New("with stack", StackTrace())
+	}()
+}
@@ -0,0 +1,91 @@
+package erro
+
+import (
+	"sync"
+	"time"
+)
+
+// SuppressedCountField is the field with the number of occurrences of an error that were
+// not logged since the previous line, see [LogErrorThrottled].
+const SuppressedCountField = "suppressed_count"
+
+// DefaultThrottleKeys is the number of distinct fingerprints tracked by [LogErrorThrottled].
+const DefaultThrottleKeys = 10000
+
+type throttleEntry struct {
+	logged     time.Time
+	every      time.Duration
+	suppressed int
+}
+
+var logThrottle = struct {
+	mu      sync.Mutex
+	entries map[string]*throttleEntry
+}{entries: make(map[string]*throttleEntry)}
+
+// LogErrorThrottled logs the error as [LogError] does at most once per interval for
+// errors of the same [Fingerprint], so a flapping dependency produces one line per
+// interval instead of a line per failure. The occurrences in between are dropped and
+// their number is added to the next line as the [SuppressedCountField] field. Intervals
+// are measured with the clock of [SetClock]. A non-positive interval logs every error.
+//
+// Example:
+//
+//	erro.LogErrorThrottled(err, logger.Error, time.Minute)
+//	// get user: connection refused suppressed_count=1234
+func LogErrorThrottled(err error, logFunc func(message string, fields ...any), every time.Duration, optFuncs ...LogOption) {
+	if err == nil || logFunc == nil {
+		return
+	}
+	if every <= 0 {
+		LogError(err, logFunc, optFuncs...)
+		return
+	}
+	suppressed, ok := throttle(Fingerprint(err), every, now())
+	if !ok {
+		return
+	}
+	if suppressed == 0 {
+		LogError(err, logFunc, optFuncs...)
+		return
+	}
+	LogError(err, func(message string, fields ...any) {
+		logFunc(message, append(fields, SuppressedCountField, suppressed)...)
+	}, optFuncs...)
+}
+
+// throttle returns true with the number of suppressed occurrences if the key may be logged.
+func throttle(key string, every time.Duration, at time.Time) (int, bool) {
+	logThrottle.mu.Lock()
+	defer logThrottle.mu.Unlock()
+
+	entry, ok := logThrottle.entries[key]
+	if !ok {
+		if len(logThrottle.entries) >= DefaultThrottleKeys {
+			pruneThrottle(at)
+		}
+		logThrottle.entries[key] = &throttleEntry{logged: at, every: every}
+		return 0, true
+	}
+	entry.every = every
+	if at.Sub(entry.logged) < every {
+		entry.suppressed++
+		return 0, false
+	}
+	suppressed := entry.suppressed
+	entry.logged = at
+	entry.suppressed = 0
+	return suppressed, true
+}
+
+// pruneThrottle forgets the keys whose interval has passed or, if there are none, all keys.
+func pruneThrottle(at time.Time) {
+	for key, entry := range logThrottle.entries {
+		if at.Sub(entry.logged) >= entry.every {
+			delete(logThrottle.entries, key)
+		}
+	}
+	if len(logThrottle.entries) >= DefaultThrottleKeys {
+		logThrottle.entries = make(map[string]*throttleEntry)
+	}
+}
@@ -0,0 +1,51 @@
+package erro
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestLogErrorThrottled(t *testing.T) {
+	current := time.Unix(1000, 0)
+	SetClock(func() time.Time { return current })
+	defer SetClock(nil)
+
+	var lines [][]any
+	logFunc := func(message string, fields ...any) {
+		lines = append(lines, fields)
+	}
+	newErr := func(id int) error {
+		return New("throttled dependency failed", "attempt", id)
+	}
+
+	for i := 0; i < 5; i++ {
+		LogErrorThrottled(newErr(i), logFunc, time.Minute, WithUserFields())
+	}
+	LogErrorThrottled(New("another kind"), logFunc, time.Minute, WithUserFields())
+	if len(lines) != 2 {
+		t.Fatalf("expected one line per kind of error, got %d", len(lines))
+	}
+
+	current = current.Add(time.Minute)
+	LogErrorThrottled(newErr(5), logFunc, time.Minute, WithUserFields())
+	if len(lines) != 3 {
+		t.Fatalf("expected a line after the interval, got %d", len(lines))
+	}
+	fields := lines[2]
+	if !reflect.DeepEqual(fields[len(fields)-2:], []any{SuppressedCountField, 4}) {
+		t.Errorf("expected suppressed count of 4, got %v", fields)
+	}
+
+	current = current.Add(2 * time.Minute)
+	LogErrorThrottled(newErr(6), logFunc, time.Minute, WithUserFields())
+	if fields := lines[3]; fields[len(fields)-2] == SuppressedCountField {
+		t.Errorf("expected no suppressed count without suppressed errors, got %v", fields)
+	}
+
+	LogErrorThrottled(newErr(7), logFunc, 0)
+	LogErrorThrottled(nil, logFunc, time.Minute)
+	if len(lines) != 5 {
+		t.Errorf("expected zero interval to log every error and nil to be ignored, got %d lines", len(lines))
+	}
+}
@@ -0,0 +1,45 @@
+package erro
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// InvalidUTF8Field is the field added to an error created with invalid UTF-8 in its
+// message or in string keys and values of its fields, see [WithUTF8Sanitization].
+const InvalidUTF8Field = "invalid_utf8"
+
+// validUTF8 replaces invalid UTF-8 sequences of the string with U+FFFD unless
+// sanitization is disabled with [WithUTF8Sanitization].
+func validUTF8(s string) string {
+	if utf8.ValidString(s) || rawUTF8.Load() {
+		return s
+	}
+	return strings.ToValidUTF8(s, string(utf8.RuneError))
+}
+
+// validUTF8Value sanitizes a string value, other values are returned as is.
+// Valid strings are returned without boxing them again.
+func validUTF8Value(value any) any {
+	if s, ok := value.(string); ok && !utf8.ValidString(s) {
+		return validUTF8(s)
+	}
+	return value
+}
+
+// markInvalidUTF8 adds the [InvalidUTF8Field] field if the own message or fields of
+// the error have invalid UTF-8.
+func markInvalidUTF8(e *baseError) {
+	if rawUTF8.Load() {
+		return
+	}
+	invalid := !utf8.ValidString(e.message)
+	for i := 0; i < len(e.fields) && !invalid; i++ {
+		if s, ok := e.fields[i].(string); ok && !utf8.ValidString(s) {
+			invalid = true
+		}
+	}
+	if invalid {
+		e.fields = append(e.fields, InvalidUTF8Field, true)
+	}
+}
@@ -0,0 +1,43 @@
+package erro
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestUTF8Sanitization(t *testing.T) {
+	err := New("bad \xff message", "path", "/tmp/\xc3\x28", "count", 1)
+	if err.Error() != "bad � message path=/tmp/�( count=1 invalid_utf8=true" {
+		t.Errorf("unexpected message %q", err.Error())
+	}
+	fields := LogFieldsMap(err)
+	if fields["error"] != "bad � message" || fields["path"] != "/tmp/�(" || fields[InvalidUTF8Field] != true {
+		t.Errorf("unexpected log fields %v", fields)
+	}
+	schema := ErrorToJSON(err)
+	if schema.Message != "bad � message" || schema.Fields[1] != "/tmp/�(" {
+		t.Errorf("unexpected schema %+v", schema)
+	}
+	if _, mErr := json.Marshal(schema); mErr != nil {
+		t.Fatal(mErr)
+	}
+	if value, _ := Field[string](err, "path"); value != "/tmp/\xc3\x28" {
+		t.Error("stored values must be kept as is")
+	}
+
+	valid := Wrap(New("héllo"), "wrapped", "key", "välue")
+	if _, ok := Field[bool](valid, InvalidUTF8Field); ok || strings.ContainsRune(valid.Error(), '�') {
+		t.Errorf("valid UTF-8 must not be flagged, got %q", valid.Error())
+	}
+	if w := Wrap(err, "outer"); !strings.HasPrefix(w.Error(), "outer: bad � message") {
+		t.Errorf("unexpected wrapped message %q", w.Error())
+	}
+
+	defer Configure(WithUTF8Sanitization(true))
+	Configure(WithUTF8Sanitization(false))
+	raw := New("raw \xff")
+	if raw.Error() != "raw \xff" || len(raw.Fields()) != 0 {
+		t.Errorf("expected the original bytes without the flag, got %q %v", raw.Error(), raw.Fields())
+	}
+}
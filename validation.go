@@ -0,0 +1,112 @@
+package erro
+
+import (
+	"sort"
+	"strings"
+)
+
+// ValidationGroup is the group of the fields of [ValidationErrors], e.g. "validation.email".
+const ValidationGroup = "validation"
+
+// ValidationMessage is the message of errors created with [ValidationErrors].
+const ValidationMessage = "validation failed"
+
+// FieldError is the validation error of an input field, see [ValidationErrors].
+type FieldError struct {
+	Field   string `json:"field" bson:"field" db:"field"`
+	Message string `json:"message" bson:"message" db:"message"`
+}
+
+// ValidationErrors creates an error of [ClassValidation] with a field per invalid
+// input field in the [ValidationGroup] group, ordered by field name. The fields are
+// rendered as the field_errors array of [ErrorToJSON] and the errors array of
+// [ProblemDetails], and [Sanitize] keeps them. It returns nil for an empty map.
+// Use [MergeValidationErrors] to combine the errors of several validators.
+//
+// Example:
+//
+//	err := erro.ValidationErrors(map[string]string{
+//	    "email": "must be a valid address",
+//	    "age":   "must be positive",
+//	})
+//	// validation failed validation.age="must be positive" validation.email="must be a valid address"
+//	erro.WriteHTTPError(w, err)
+//	// {"status":400,"detail":"validation failed","errors":[{"field":"age","message":"must be positive"},...]}
+func ValidationErrors(errs map[string]string) Error {
+	if len(errs) == 0 {
+		return nil
+	}
+	names := make([]string, 0, len(errs))
+	for name := range errs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	fields := make([]any, 0, 2*len(names)+1)
+	for _, name := range names {
+		fields = append(fields, ValidationGroup+GroupSeparator+name, errs[name])
+	}
+	return newf(ValidationMessage, append(fields, ClassValidation, markFieldErrors())...)
+}
+
+// markFieldErrors marks the layer built by [ValidationErrors], so [Sanitize] keeps
+// its fields: they describe the client's own input.
+func markFieldErrors() errorOpt {
+	return func(e *baseError) {
+		e.fieldErrors = true
+	}
+}
+
+// MergeValidationErrors combines the field errors of the errors into one error created
+// with [ValidationErrors]. Messages of the same field are joined with "; ". Errors without
+// field errors are ignored; it returns nil if no error has them.
+//
+// Example:
+//
+//	err := erro.MergeValidationErrors(validateUser(u), validateAddress(u.Address))
+func MergeValidationErrors(errs ...error) Error {
+	merged := make(map[string]string)
+	for _, err := range errs {
+		for _, fe := range FieldErrors(err) {
+			if msg, ok := merged[fe.Field]; ok && msg != fe.Message {
+				fe.Message = msg + "; " + fe.Message
+			}
+			merged[fe.Field] = fe.Message
+		}
+	}
+	return ValidationErrors(merged)
+}
+
+// FieldErrors returns the field errors of the error chain added with [ValidationErrors],
+// the outermost error first. It returns nil if there are none.
+func FieldErrors(err error) []FieldError {
+	if err == nil {
+		return nil
+	}
+	var e Error
+	if !As(err, &e) {
+		return nil
+	}
+	return fieldErrorsFromFields(e.AllFields())
+}
+
+// fieldErrorsFromFields returns the field errors of the [ValidationGroup] fields.
+func fieldErrorsFromFields(fields []any) []FieldError {
+	var out []FieldError
+	for i := 0; i+1 < len(fields); i += 2 {
+		name, ok := validationFieldName(valueToString(fields[i]))
+		if !ok {
+			continue
+		}
+		out = append(out, FieldError{Field: name, Message: valueToString(fields[i+1])})
+	}
+	return out
+}
+
+// validationFieldName returns the input field name of a [ValidationGroup] key.
+func validationFieldName(key string) (string, bool) {
+	const prefix = ValidationGroup + GroupSeparator
+	if !strings.HasPrefix(key, prefix) || len(key) == len(prefix) {
+		return "", false
+	}
+	return key[len(prefix):], true
+}
@@ -0,0 +1,56 @@
+package erro
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestValidationErrors(t *testing.T) {
+	if ValidationErrors(nil) != nil {
+		t.Error("expected nil for no field errors")
+	}
+
+	err := ValidationErrors(map[string]string{"email": "must be a valid address", "age": "must be positive"})
+	if err.Class() != ClassValidation {
+		t.Errorf("expected validation class, got %q", err.Class())
+	}
+	if err.Error() != `validation failed validation.age="must be positive" validation.email="must be a valid address"` {
+		t.Errorf("unexpected message: %q", err.Error())
+	}
+
+	expected := []FieldError{{Field: "age", Message: "must be positive"}, {Field: "email", Message: "must be a valid address"}}
+	if got := FieldErrors(Wrap(err, "create user")); !reflect.DeepEqual(got, expected) {
+		t.Errorf("unexpected field errors: %v", got)
+	}
+	if schema := ErrorToJSON(err); !reflect.DeepEqual(schema.FieldErrors, expected) {
+		t.Errorf("unexpected JSON field errors: %v", schema.FieldErrors)
+	}
+
+	rec := httptest.NewRecorder()
+	WriteHTTPError(rec, Sanitize(err, SanitizePolicy{}))
+	var pd ProblemDetails
+	if decodeErr := json.Unmarshal(rec.Body.Bytes(), &pd); decodeErr != nil {
+		t.Fatalf("failed to decode response: %v", decodeErr)
+	}
+	if pd.Detail != ValidationMessage || !reflect.DeepEqual(pd.Errors, expected) {
+		t.Errorf("unexpected problem details: %+v", pd)
+	}
+}
+
+func TestMergeValidationErrors(t *testing.T) {
+	if MergeValidationErrors(nil, New("other")) != nil {
+		t.Error("expected nil without field errors")
+	}
+
+	err := MergeValidationErrors(
+		ValidationErrors(map[string]string{"email": "is required"}),
+		nil,
+		Wrap(ValidationErrors(map[string]string{"email": "is too long", "name": "is required"}), "validate profile"),
+	)
+	expected := []FieldError{{Field: "email", Message: "is required; is too long"}, {Field: "name", Message: "is required"}}
+	if got := FieldErrors(err); !reflect.DeepEqual(got, expected) {
+		t.Errorf("unexpected merged field errors: %v", got)
+	}
+}